@@ -257,13 +257,16 @@ func deserializeTxListType(txlist []event.Tx) ([]*blockchain.DefaultTransaction,
 
 func deserializeTxType(tx event.Tx) (*blockchain.DefaultTransaction, error) {
 	return &blockchain.DefaultTransaction{
-		ID:        tx.ID,
-		ICodeID:   tx.ICodeID,
-		PeerID:    tx.PeerID,
-		Timestamp: tx.TimeStamp,
-		Jsonrpc:   tx.Jsonrpc,
-		Function:  tx.Function,
-		Args:      tx.Args,
-		Signature: tx.Signature,
+		ID:             tx.ID,
+		ICodeID:        tx.ICodeID,
+		PeerID:         tx.PeerID,
+		Timestamp:      tx.TimeStamp,
+		Jsonrpc:        tx.Jsonrpc,
+		Function:       tx.Function,
+		Args:           tx.Args,
+		Signature:      tx.Signature,
+		Deadline:       blockchain.ToDeadline(tx.Deadline),
+		MaxHeight:      tx.MaxHeight,
+		IdempotencyKey: tx.IdempotencyKey,
 	}, nil
 }
@@ -0,0 +1,148 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api_gateway
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/it-chain/engine/common/command"
+	"github.com/it-chain/engine/common/event"
+	"github.com/it-chain/engine/common/rabbitmq/rpc"
+	"github.com/it-chain/engine/conf"
+	"github.com/it-chain/engine/consensus/pbft"
+)
+
+type Misbehaviour struct {
+	Type       string
+	OffenderID string
+	StateID    string
+	Height     uint64
+	DetectedAt time.Time
+	Evidence   []byte
+}
+
+type ConsensusQueryApi struct {
+	misbehaviourRepository *MisbehaviourRepository
+}
+
+func NewConsensusQueryApi(misbehaviourRepository *MisbehaviourRepository) *ConsensusQueryApi {
+	return &ConsensusQueryApi{
+		misbehaviourRepository: misbehaviourRepository,
+	}
+}
+
+func (c ConsensusQueryApi) GetMisbehaviours(offenderID string) []Misbehaviour {
+	if offenderID == "" {
+		return c.misbehaviourRepository.FindAll()
+	}
+
+	return c.misbehaviourRepository.FindByOffenderID(offenderID)
+}
+
+// GetStatus asks the consensus module of this node for its current status
+func (c ConsensusQueryApi) GetStatus() (pbft.Status, error) {
+	client := rpc.NewClient(conf.GetConfiguration().Engine.Amqp)
+	defer client.Close()
+
+	var status pbft.Status
+	var callBackErr error
+
+	err := client.Call("consensus.status", command.GetConsensusStatus{}, func(s pbft.Status, err rpc.Error) {
+		if !err.IsNil() {
+			callBackErr = errors.New(err.Message)
+			return
+		}
+
+		status = s
+	})
+
+	if err != nil {
+		return pbft.Status{}, err
+	}
+
+	return status, callBackErr
+}
+
+type MisbehaviourRepository struct {
+	sync.RWMutex
+	misbehaviours []Misbehaviour
+}
+
+func NewMisbehaviourRepository() *MisbehaviourRepository {
+	return &MisbehaviourRepository{
+		misbehaviours: make([]Misbehaviour, 0),
+		RWMutex:       sync.RWMutex{},
+	}
+}
+
+func (m *MisbehaviourRepository) Save(misbehaviour Misbehaviour) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.misbehaviours = append(m.misbehaviours, misbehaviour)
+	sort.SliceStable(m.misbehaviours, func(i, j int) bool {
+		return m.misbehaviours[i].Height < m.misbehaviours[j].Height
+	})
+}
+
+func (m *MisbehaviourRepository) FindAll() []Misbehaviour {
+	m.RLock()
+	defer m.RUnlock()
+
+	misbehaviourList := make([]Misbehaviour, len(m.misbehaviours))
+	copy(misbehaviourList, m.misbehaviours)
+
+	return misbehaviourList
+}
+
+func (m *MisbehaviourRepository) FindByOffenderID(offenderID string) []Misbehaviour {
+	m.RLock()
+	defer m.RUnlock()
+
+	misbehaviourList := make([]Misbehaviour, 0)
+	for _, misbehaviour := range m.misbehaviours {
+		if misbehaviour.OffenderID == offenderID {
+			misbehaviourList = append(misbehaviourList, misbehaviour)
+		}
+	}
+
+	return misbehaviourList
+}
+
+type MisbehaviourEventListener struct {
+	misbehaviourRepository *MisbehaviourRepository
+}
+
+func NewMisbehaviourEventListener(misbehaviourRepository *MisbehaviourRepository) *MisbehaviourEventListener {
+	return &MisbehaviourEventListener{
+		misbehaviourRepository: misbehaviourRepository,
+	}
+}
+
+func (m *MisbehaviourEventListener) HandleConsensusMisbehaviourEvent(event event.ConsensusMisbehaviour) {
+	m.misbehaviourRepository.Save(Misbehaviour{
+		Type:       event.Type,
+		OffenderID: event.OffenderID,
+		StateID:    event.StateID,
+		Height:     event.Height,
+		DetectedAt: event.DetectedAt,
+		Evidence:   event.Evidence,
+	})
+}
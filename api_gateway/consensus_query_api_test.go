@@ -0,0 +1,76 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api_gateway_test
+
+import (
+	"testing"
+
+	"github.com/it-chain/engine/api_gateway"
+	"github.com/it-chain/engine/common/event"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsensusQueryApi_GetMisbehaviours(t *testing.T) {
+	tests := map[string]struct {
+		input struct {
+			offenderID string
+		}
+		output []string
+	}{
+		"all misbehaviours ordered by height": {
+			input: struct {
+				offenderID string
+			}{offenderID: ""},
+			output: []string{"2", "1", "2"},
+		},
+		"misbehaviours of offender": {
+			input: struct {
+				offenderID string
+			}{offenderID: "2"},
+			output: []string{"2", "2"},
+		},
+		"unknown offender": {
+			input: struct {
+				offenderID string
+			}{offenderID: "3"},
+			output: []string{},
+		},
+	}
+
+	// given
+	repository := api_gateway.NewMisbehaviourRepository()
+	listener := api_gateway.NewMisbehaviourEventListener(repository)
+	listener.HandleConsensusMisbehaviourEvent(event.ConsensusMisbehaviour{Type: "double-prevote", OffenderID: "2", Height: 7})
+	listener.HandleConsensusMisbehaviourEvent(event.ConsensusMisbehaviour{Type: "double-propose", OffenderID: "2", Height: 3})
+	listener.HandleConsensusMisbehaviourEvent(event.ConsensusMisbehaviour{Type: "double-prevote", OffenderID: "1", Height: 5})
+
+	queryApi := api_gateway.NewConsensusQueryApi(repository)
+
+	for testName, test := range tests {
+		t.Logf("running test case %s", testName)
+
+		// when
+		misbehaviours := queryApi.GetMisbehaviours(test.input.offenderID)
+
+		// then
+		offenders := make([]string, 0)
+		for _, misbehaviour := range misbehaviours {
+			offenders = append(offenders, misbehaviour.OffenderID)
+		}
+		assert.Equal(t, test.output, offenders)
+	}
+}
@@ -18,6 +18,7 @@ package api_gateway
 
 import (
 	"context"
+	"time"
 
 	"github.com/go-kit/kit/endpoint"
 	"github.com/it-chain/iLogger"
@@ -32,12 +33,21 @@ type Endpoints struct {
 	FindPeerByIdEndpoint     endpoint.Endpoint
 	CreateConnectionEndpoint endpoint.Endpoint
 
-	GetIcodeListEndpoint  endpoint.Endpoint
-	DeployIcodeEndpoint   endpoint.Endpoint
-	UnDeployIcodeEndpoint endpoint.Endpoint
-
-	FindAllUncommittedTransactionEndpoint endpoint.Endpoint
-	CreateTransactionEndpoint             endpoint.Endpoint
+	GetIcodeListEndpoint     endpoint.Endpoint
+	DeployIcodeEndpoint      endpoint.Endpoint
+	UnDeployIcodeEndpoint    endpoint.Endpoint
+	GetIcodeVersionsEndpoint endpoint.Endpoint
+	GetExecutionEndpoint     endpoint.Endpoint
+	ResumeExecutionEndpoint  endpoint.Endpoint
+
+	FindAllTransactionEndpoint     endpoint.Endpoint
+	FindTransactionStatusEndpoint  endpoint.Endpoint
+	FindTransactionResultEndpoint  endpoint.Endpoint
+	CreateTransactionEndpoint      endpoint.Endpoint
+	CreateTransactionBatchEndpoint endpoint.Endpoint
+
+	FindConsensusStatusEndpoint endpoint.Endpoint
+	FindAllMisbehaviourEndpoint endpoint.Endpoint
 }
 
 /*
@@ -53,9 +63,12 @@ func MakeBlockchainEndpoints(b *BlockQueryApi) Endpoints {
 
 func MakeIcodeEndpoints(i *ICodeCommandApi, iqa *ICodeQueryApi) Endpoints {
 	return Endpoints{
-		GetIcodeListEndpoint:  makeFindAllICodeEndpoint(iqa),
-		DeployIcodeEndpoint:   makeDeployIcodeEndpoint(i),
-		UnDeployIcodeEndpoint: makeUnDeployIcodeEndpoint(i),
+		GetIcodeListEndpoint:     makeFindAllICodeEndpoint(iqa),
+		DeployIcodeEndpoint:      makeDeployIcodeEndpoint(i),
+		UnDeployIcodeEndpoint:    makeUnDeployIcodeEndpoint(i),
+		GetIcodeVersionsEndpoint: makeGetIcodeVersionsEndpoint(i),
+		GetExecutionEndpoint:     makeGetExecutionEndpoint(i),
+		ResumeExecutionEndpoint:  makeResumeExecutionEndpoint(i),
 	}
 }
 
@@ -66,10 +79,20 @@ func MakePeerEndpoints(p *PeerQueryApi, cca *ConnectionCommandApi) Endpoints {
 		CreateConnectionEndpoint: makeCreateConnectionEndpoint(cca),
 	}
 }
-func MakeTransactionEndpoints(i *ICodeCommandApi) Endpoints {
+func MakeTransactionEndpoints(i *ICodeCommandApi, t *TransactionQueryApi) Endpoints {
+	return Endpoints{
+		FindAllTransactionEndpoint:     makeFindAllTransactionEndpoint(t),
+		FindTransactionStatusEndpoint:  makeFindTransactionStatusEndpoint(t),
+		FindTransactionResultEndpoint:  makeFindTransactionResultEndpoint(t),
+		CreateTransactionEndpoint:      makeCreateTransactionEndpoint(i, t),
+		CreateTransactionBatchEndpoint: makeCreateTransactionBatchEndpoint(i),
+	}
+}
+
+func MakeConsensusEndpoints(c *ConsensusQueryApi) Endpoints {
 	return Endpoints{
-		FindAllUncommittedTransactionEndpoint: makeFindAllUncommittedTransactionEndpoint(),
-		CreateTransactionEndpoint:             makeCreateTransactionEndpoint(i),
+		FindConsensusStatusEndpoint: makeFindConsensusStatusEndpoint(c),
+		FindAllMisbehaviourEndpoint: makeFindAllMisbehaviourEndpoint(c),
 	}
 }
 
@@ -121,6 +144,15 @@ func makeFindAllICodeEndpoint(i *ICodeQueryApi) endpoint.Endpoint {
 func makeDeployIcodeEndpoint(i *ICodeCommandApi) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
 		req := request.(DeployIcodeRequest)
+		if req.Network {
+			txId, err := i.deployToNetwork(req.AmqpUrl, req.GitUrl, req.CommitHash, req.Version, req.ActivationHeight, req.MigrationFunction, req.Signature, req.IdempotencyKey)
+			if err != nil {
+				iLogger.Error(&iLogger.Fields{"err_message": err.Error()}, "error while deploy icode endpoint")
+				return nil, err
+			}
+			return txId, nil
+		}
+
 		icodeId, err := i.deploy(req.AmqpUrl, req.GitUrl, req.SshRaw, req.SshPassWord)
 		if err != nil {
 			iLogger.Error(&iLogger.Fields{"err_message": err.Error()}, "error while deploy icode endpoint")
@@ -142,25 +174,78 @@ func makeUnDeployIcodeEndpoint(i *ICodeCommandApi) endpoint.Endpoint {
 	}
 }
 
+func makeGetIcodeVersionsEndpoint(i *ICodeCommandApi) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(GetIcodeVersionsRequest)
+		versions, err := i.getVersions(req.AmqpUrl, req.Name)
+		if err != nil {
+			iLogger.Error(&iLogger.Fields{"err_message": err.Error()}, "error while get icode versions endpoint")
+			return nil, err
+		}
+		return versions, nil
+	}
+}
+
+func makeGetExecutionEndpoint(i *ICodeCommandApi) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(IvmRequest)
+		status, err := i.getExecutionStatus(req.AmqpUrl, false)
+		if err != nil {
+			iLogger.Error(&iLogger.Fields{"err_message": err.Error()}, "error while get icode execution endpoint")
+			return nil, err
+		}
+		return status, nil
+	}
+}
+
+func makeResumeExecutionEndpoint(i *ICodeCommandApi) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(IvmRequest)
+		status, err := i.getExecutionStatus(req.AmqpUrl, true)
+		if err != nil {
+			iLogger.Error(&iLogger.Fields{"err_message": err.Error()}, "error while resume icode execution endpoint")
+			return nil, err
+		}
+		return status, nil
+	}
+}
+
 //transaction
 
-//todo impl
-func makeFindAllUncommittedTransactionEndpoint() endpoint.Endpoint {
+func makeFindAllTransactionEndpoint(t *TransactionQueryApi) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
-		return nil, nil
+		req := request.(FindAllTransactionRequest)
+		return t.GetTransactions(req.Status)
+	}
+}
+
+func makeFindTransactionStatusEndpoint(t *TransactionQueryApi) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(FindTransactionStatusRequest)
+		return t.GetTransactionStatus(req.ID)
+	}
+}
+
+func makeFindTransactionResultEndpoint(t *TransactionQueryApi) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(FindTransactionResultRequest)
+		return t.WaitTransactionResult(req.ID, req.Timeout)
 	}
 }
 
-func makeCreateTransactionEndpoint(i *ICodeCommandApi) endpoint.Endpoint {
+func makeCreateTransactionEndpoint(i *ICodeCommandApi, t *TransactionQueryApi) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
 		req := request.(CreateTransactionRequest)
 		switch req.Type {
 		case "invoke":
-			txId, err := i.invoke(req.AmqpUrl, req.ICodeId, req.FuncName, req.Args)
+			txId, err := i.invoke(req.AmqpUrl, req.ICodeId, req.FuncName, req.Args, req.Signature, req.IdempotencyKey, req.Deadline, req.MaxHeight)
 			if err != nil {
 				iLogger.Error(&iLogger.Fields{"err_message": err.Error()}, "error while invoke icode endpoint")
 				return nil, err
 			}
+			if req.Wait {
+				return t.WaitTransactionResult(txId, req.Timeout)
+			}
 			return txId, err
 		case "query":
 			results, err := i.query(req.AmqpUrl, req.ICodeId, req.FuncName, req.Args)
@@ -176,6 +261,37 @@ func makeCreateTransactionEndpoint(i *ICodeCommandApi) endpoint.Endpoint {
 	}
 }
 
+func makeCreateTransactionBatchEndpoint(i *ICodeCommandApi) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(CreateTransactionBatchRequest)
+		results, err := i.invokeBatch(req.AmqpUrl, req.Transactions)
+		if err != nil {
+			iLogger.Error(&iLogger.Fields{"err_message": err.Error()}, "error while invoke icode batch endpoint")
+			return nil, err
+		}
+		return results, nil
+	}
+}
+
+//consensus
+func makeFindConsensusStatusEndpoint(c *ConsensusQueryApi) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		status, err := c.GetStatus()
+		if err != nil {
+			iLogger.Error(&iLogger.Fields{"err_message": err.Error()}, "error while find consensus status endpoint")
+			return nil, err
+		}
+		return status, nil
+	}
+}
+
+func makeFindAllMisbehaviourEndpoint(c *ConsensusQueryApi) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(FindMisbehaviourRequest)
+		return c.GetMisbehaviours(req.OffenderID), nil
+	}
+}
+
 //grpc gateway
 func makeFindAllPeerEndpoint(p *PeerQueryApi) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
@@ -263,6 +379,21 @@ type DeployIcodeRequest struct {
 	GitUrl      string
 	SshRaw      string
 	SshPassWord string
+	// deploy the commit on every node through a transaction, the response is the transaction id
+	Network    bool
+	CommitHash string
+	Version    string
+	// with Network, upgrade the icode of the same name at the height instead of deploying it
+	ActivationHeight  uint64
+	MigrationFunction string
+	// with Network, the signature of the client over the deployment transaction and its idempotency key, see TransactionRequest
+	Signature      []byte
+	IdempotencyKey string
+}
+
+type GetIcodeVersionsRequest struct {
+	IvmRequest
+	Name string
 }
 
 type UnDeployIcodeRequest struct {
@@ -272,10 +403,51 @@ type UnDeployIcodeRequest struct {
 
 type CreateTransactionRequest struct {
 	IvmRequest
-	Type     string
+	Type string
+	TransactionRequest
+	// set by the query of the request, an invoke waits until the transaction is committed and executed
+	Wait    bool          `json:"-"`
+	Timeout time.Duration `json:"-"`
+}
+
+// transactions of a batch are always invoked
+type CreateTransactionBatchRequest struct {
+	IvmRequest
+	Transactions []TransactionRequest
+}
+
+type TransactionRequest struct {
 	ICodeId  string
 	FuncName string
 	Args     []string
+	// json encoded common.Signature of the client over common.TransactionSigningData of the transaction,
+	// the signer is the submitter of the transaction
+	Signature []byte
+	// retrying an invoke with the same key does not execute it twice.
+	// Required with a signature, the key is part of the signed content
+	IdempotencyKey string
+	// optional, the transaction is dropped if it is not committed before the deadline or the max height
+	Deadline  time.Time
+	MaxHeight uint64
+}
+
+// transaction request struct
+type FindAllTransactionRequest struct {
+	Status string
+}
+
+type FindTransactionResultRequest struct {
+	ID      string
+	Timeout time.Duration
+}
+
+type FindTransactionStatusRequest struct {
+	ID string
+}
+
+// consensus request struct
+type FindMisbehaviourRequest struct {
+	OffenderID string
 }
 
 // grpc request struct
@@ -18,6 +18,7 @@ package api_gateway
 
 import (
 	"log"
+	"time"
 
 	"github.com/it-chain/engine/common/command"
 	"github.com/it-chain/engine/common/rabbitmq/rpc"
@@ -29,6 +30,8 @@ import (
 	"github.com/rs/xid"
 )
 
+var ErrEmptyBatch = errors.New("no transaction in the batch")
+
 type ICodeCommandApi struct {
 }
 
@@ -83,6 +86,97 @@ func (i *ICodeCommandApi) deploy(amqpUrl string, gitUrl string, rawSsh string, s
 	return callBackIcodeId, nil
 }
 
+// deployToNetwork submits a deployment transaction, every node deploys the commit when the transaction is committed.
+// With an activation height the transaction upgrades the icode of the same name at the height.
+// The deployment status of each node is the result of the transaction
+func (i *ICodeCommandApi) deployToNetwork(amqpUrl string, gitUrl string, commitHash string, version string, activationHeight uint64, migrationFunction string, signature []byte, idempotencyKey string) (string, error) {
+	deployment := ivm.Deployment{
+		GitUrl:            gitUrl,
+		CommitHash:        commitHash,
+		Version:           version,
+		ActivationHeight:  activationHeight,
+		MigrationFunction: migrationFunction,
+	}
+
+	return i.invoke(amqpUrl, ivm.DeploymentICodeID, deployment.Function(), deployment.Args(), signature, idempotencyKey, time.Time{}, 0)
+}
+
+func (i *ICodeCommandApi) getVersions(amqpUrl string, name string) ([]ivm.ICodeVersion, error) {
+	if amqpUrl == "" {
+		config := conf.GetConfiguration()
+		amqpUrl = config.Engine.Amqp
+	}
+
+	client := rpc.NewClient(amqpUrl)
+
+	defer client.Close()
+
+	var callBackVersions []ivm.ICodeVersion
+	var callBackErr error
+
+	err := client.Call("ivm.versions", command.GetICodeVersions{Name: name}, func(versionList command.ICodeVersionList, err rpc.Error) {
+		if !err.IsNil() {
+			callBackErr = errors.New(err.Message)
+			return
+		}
+
+		callBackVersions = versionList.Versions
+	})
+
+	if err != nil {
+		iLogger.Error(&iLogger.Fields{"err_msg": err.Error()}, "[Api_gateway] fatal err in versions cmd")
+		return nil, err
+	}
+
+	if callBackErr != nil {
+		return nil, callBackErr
+	}
+
+	return callBackVersions, nil
+}
+
+// getExecutionStatus tells whether the node halted executing the committed blocks, with resume it retries the halted block first
+func (i *ICodeCommandApi) getExecutionStatus(amqpUrl string, resume bool) (ivm.ExecutionStatus, error) {
+	if amqpUrl == "" {
+		config := conf.GetConfiguration()
+		amqpUrl = config.Engine.Amqp
+	}
+
+	client := rpc.NewClient(amqpUrl)
+
+	defer client.Close()
+
+	var callBackStatus ivm.ExecutionStatus
+	var callBackErr error
+
+	callBack := func(status ivm.ExecutionStatus, err rpc.Error) {
+		if !err.IsNil() {
+			callBackErr = errors.New(err.Message)
+			return
+		}
+
+		callBackStatus = status
+	}
+
+	var err error
+	if resume {
+		err = client.Call("ivm.resume", command.ResumeICodeExecution{}, callBack)
+	} else {
+		err = client.Call("ivm.status", command.GetICodeExecutionStatus{}, callBack)
+	}
+
+	if err != nil {
+		iLogger.Error(&iLogger.Fields{"err_msg": err.Error()}, "[Api_gateway] fatal err in execution status cmd")
+		return ivm.ExecutionStatus{}, err
+	}
+
+	if callBackErr != nil {
+		return ivm.ExecutionStatus{}, callBackErr
+	}
+
+	return callBackStatus, nil
+}
+
 func (i *ICodeCommandApi) unDeploy(amqpUrl string, icodeId string) error {
 	if amqpUrl == "" {
 		config := conf.GetConfiguration()
@@ -123,7 +217,7 @@ func (i *ICodeCommandApi) unDeploy(amqpUrl string, icodeId string) error {
 	return nil
 }
 
-func (i *ICodeCommandApi) invoke(amqpUrl string, id string, functionName string, args []string) (string, error) {
+func (i *ICodeCommandApi) invoke(amqpUrl string, id string, functionName string, args []string, signature []byte, idempotencyKey string, deadline time.Time, maxHeight uint64) (string, error) {
 	if amqpUrl == "" {
 		config := conf.GetConfiguration()
 		amqpUrl = config.Engine.Amqp
@@ -134,12 +228,16 @@ func (i *ICodeCommandApi) invoke(amqpUrl string, id string, functionName string,
 	defer client.Close()
 
 	invokeCommand := command.CreateTransaction{
-		TransactionId: xid.New().String(),
-		ICodeID:       id,
-		Jsonrpc:       "2.0",
-		Method:        "invoke",
-		Args:          args,
-		Function:      functionName,
+		TransactionId:  xid.New().String(),
+		ICodeID:        id,
+		Jsonrpc:        "2.0",
+		Method:         "invoke",
+		Args:           args,
+		Function:       functionName,
+		Signature:      signature,
+		IdempotencyKey: idempotencyKey,
+		Deadline:       deadline,
+		MaxHeight:      maxHeight,
 	}
 
 	iLogger.Infof(nil, "[Api_gateway] Invoke icode - icodeID: [%s]", id)
@@ -149,6 +247,18 @@ func (i *ICodeCommandApi) invoke(amqpUrl string, id string, functionName string,
 
 	err := client.Call("transaction.create", invokeCommand, func(transaction txpool.Transaction, err rpc.Error) {
 
+		if err.Code == txpool.ErrCodePoolFull {
+			iLogger.Errorf(nil, "[Api_gateway] Fail to invoke icode err: [%s]", err.Message)
+			callBackErr = txpool.ErrPoolFull
+			return
+		}
+
+		if err.Code == txpool.ErrCodeInvalidTransaction {
+			iLogger.Errorf(nil, "[Api_gateway] Fail to invoke icode err: [%s]", err.Message)
+			callBackErr = txpool.InvalidTransactionError{Err: errors.New(err.Message)}
+			return
+		}
+
 		if !err.IsNil() {
 			iLogger.Errorf(nil, "[Api_gateway] Fail to invoke icode err: [%s]", err.Message)
 			callBackErr = errors.New(err.Message)
@@ -173,6 +283,79 @@ func (i *ICodeCommandApi) invoke(amqpUrl string, id string, functionName string,
 	return callBackTransactionId, nil
 }
 
+// invokeBatch sends the transactions in one request, the result of each transaction is returned in order.
+// A batch larger than txpool.maxbatchtransactions is rejected before it is sent
+func (i *ICodeCommandApi) invokeBatch(amqpUrl string, requests []TransactionRequest) ([]command.CreateTransactionResult, error) {
+	if len(requests) == 0 {
+		return nil, ErrEmptyBatch
+	}
+
+	config := conf.GetConfiguration()
+	if amqpUrl == "" {
+		amqpUrl = config.Engine.Amqp
+	}
+
+	if max := config.Txpool.MaxBatchTransactions; max != 0 && len(requests) > max {
+		return nil, txpool.ErrBatchTooLarge
+	}
+
+	client := rpc.NewClient(amqpUrl)
+
+	defer client.Close()
+
+	batchCommand := command.CreateTransactions{
+		Transactions: make([]command.CreateTransaction, 0, len(requests)),
+	}
+
+	for _, request := range requests {
+		batchCommand.Transactions = append(batchCommand.Transactions, command.CreateTransaction{
+			TransactionId:  xid.New().String(),
+			ICodeID:        request.ICodeId,
+			Jsonrpc:        "2.0",
+			Method:         "invoke",
+			Args:           request.Args,
+			Function:       request.FuncName,
+			Signature:      request.Signature,
+			IdempotencyKey: request.IdempotencyKey,
+			Deadline:       request.Deadline,
+			MaxHeight:      request.MaxHeight,
+		})
+	}
+
+	iLogger.Infof(nil, "[Api_gateway] Invoke icode batch - count: [%d]", len(requests))
+
+	var callBackResults []command.CreateTransactionResult
+	var callBackErr error
+
+	err := client.Call("transaction.create.batch", batchCommand, func(result command.CreateTransactionsResult, err rpc.Error) {
+
+		if err.Code == txpool.ErrCodeBatchTooLarge {
+			iLogger.Errorf(nil, "[Api_gateway] Fail to invoke icode batch err: [%s]", err.Message)
+			callBackErr = txpool.ErrBatchTooLarge
+			return
+		}
+
+		if !err.IsNil() {
+			iLogger.Errorf(nil, "[Api_gateway] Fail to invoke icode batch err: [%s]", err.Message)
+			callBackErr = errors.New(err.Message)
+			return
+		}
+
+		callBackResults = result.Results
+	})
+
+	if err != nil {
+		iLogger.Error(&iLogger.Fields{"err_msg": err.Error()}, "[Api_gateway] fatal err in invoke batch cmd")
+		return nil, err
+	}
+
+	if callBackErr != nil {
+		return nil, callBackErr
+	}
+
+	return callBackResults, nil
+}
+
 func (i *ICodeCommandApi) query(amqpUrl string, id string, functionName string, args []string) (map[string]string, error) {
 	if amqpUrl == "" {
 		config := conf.GetConfiguration()
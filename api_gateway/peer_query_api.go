@@ -20,6 +20,7 @@ import (
 	"errors"
 	"sync"
 
+	"github.com/it-chain/engine/common"
 	"github.com/it-chain/engine/common/event"
 	"github.com/it-chain/iLogger"
 )
@@ -31,8 +32,9 @@ var ErrLeaderDoesNotExists = errors.New("leader does not exist")
 type Role string
 
 const (
-	Leader Role = "Leader"
-	Member Role = "Member"
+	Leader   Role = "Leader"
+	Member   Role = "Member"
+	Observer Role = "Observer"
 )
 
 type Peer struct {
@@ -143,8 +145,11 @@ func (p *PeerRepository) SetLeader(ID string) error {
 		return ErrPeerDoesNotExists
 	}
 
-	for _, peer := range p.peers {
-		peer.Role = Member
+	for id, peer := range p.peers {
+		if peer.Role == Leader {
+			peer.Role = Member
+			p.peers[id] = peer
+		}
 	}
 
 	newLeader.Role = Leader
@@ -177,11 +182,16 @@ func NewConnectionEventListener(peerRepository *PeerRepository) *ConnectionEvent
 }
 
 func (c *ConnectionEventHandler) HandleConnectionCreatedEvent(event event.ConnectionCreated) {
+	role := Member
+	if event.Role == common.ObserverRole {
+		role = Observer
+	}
+
 	peer := Peer{
 		ID:                 event.ConnectionID,
 		GrpcGatewayAddress: event.GrpcGatewayAddress,
 		ApiGatewayAddress:  event.ApiGatewayAddress,
-		Role:               Member,
+		Role:               role,
 	}
 
 	if err := c.peerRepository.Save(peer); err != nil {
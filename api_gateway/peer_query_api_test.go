@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	"github.com/it-chain/engine/api_gateway"
+	"github.com/it-chain/engine/common"
 	"github.com/it-chain/engine/common/event"
 	"github.com/stretchr/testify/assert"
 )
@@ -54,6 +55,30 @@ func TestPeerRepository_SetLeader_GetLeader(t *testing.T) {
 	assert.Equal(t, leader.ID, "123")
 }
 
+func TestPeerRepository_SetLeader_ChangeLeader(t *testing.T) {
+
+	//given
+	peerRepository := api_gateway.NewPeerRepository()
+	peerRepository.Save(api_gateway.Peer{ID: "1", Role: api_gateway.Leader})
+	peerRepository.Save(api_gateway.Peer{ID: "2", Role: api_gateway.Member})
+	peerRepository.Save(api_gateway.Peer{ID: "3", Role: api_gateway.Observer})
+
+	//when
+	err := peerRepository.SetLeader("2")
+	assert.NoError(t, err)
+
+	//then
+	leader, err := peerRepository.GetLeader()
+	assert.NoError(t, err)
+	assert.Equal(t, "2", leader.ID)
+
+	oldLeader, _ := peerRepository.FindById("1")
+	assert.Equal(t, api_gateway.Member, oldLeader.Role)
+
+	observer, _ := peerRepository.FindById("3")
+	assert.Equal(t, api_gateway.Observer, observer.Role)
+}
+
 func TestPeerRepository_FindAll(t *testing.T) {
 
 	//given
@@ -147,6 +172,23 @@ func TestConnectionEventListener_HandleConnectionCreatedEvent(t *testing.T) {
 	assert.Equal(t, peer.ApiGatewayAddress, "123")
 }
 
+func TestConnectionEventListener_HandleConnectionCreatedEvent_Observer(t *testing.T) {
+
+	//given
+	peerRepository := api_gateway.NewPeerRepository()
+	listener := api_gateway.NewConnectionEventListener(peerRepository)
+	listener.HandleConnectionCreatedEvent(event.ConnectionCreated{
+		ConnectionID: "0",
+		Role:         common.ObserverRole,
+	})
+
+	//when
+	peer := peerRepository.FindAll()[0]
+
+	//then
+	assert.Equal(t, api_gateway.Observer, peer.Role)
+}
+
 func TestConnectionEventListener_HandleConnectionClosedEvent(t *testing.T) {
 
 	//given
@@ -0,0 +1,444 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api_gateway
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/it-chain/engine/common/event"
+	"github.com/it-chain/engine/ivm"
+	"github.com/it-chain/engine/txpool"
+)
+
+var ErrTransactionNotFound = errors.New("transaction not found")
+var ErrUnknownTransactionStatus = errors.New("unknown transaction status")
+var ErrWaitTimeout = errors.New("timed out waiting for the transaction to be executed")
+
+// TransactionDroppedError is returned to the one waiting for a transaction which is dropped from the pool
+type TransactionDroppedError struct {
+	ID     string
+	Reason string
+}
+
+func (e TransactionDroppedError) Error() string {
+	return fmt.Sprintf("transaction [%s] is dropped: %s", e.ID, e.Reason)
+}
+
+// a transaction moves pending → forwarded → proposed → committed → executed or failed,
+// or is dropped from the pool before it is committed
+const (
+	TxPending   = "pending"
+	TxForwarded = "forwarded"
+	TxProposed  = "proposed"
+	TxCommitted = "committed"
+	TxExecuted  = "executed"
+	TxFailed    = "failed"
+	TxDropped   = "dropped"
+)
+
+var txStatusRank = map[string]int{
+	TxPending:   0,
+	TxDropped:   0,
+	TxForwarded: 1,
+	TxProposed:  2,
+	TxCommitted: 3,
+	TxExecuted:  4,
+	TxFailed:    4,
+}
+
+func IsTransactionStatus(status string) bool {
+	_, ok := txStatusRank[status]
+	return ok
+}
+
+type TransactionStatus struct {
+	ID     string
+	Status string
+	Height uint64
+	// position of the transaction in the block
+	TxIndex   int
+	Reason    string
+	Result    map[string]string
+	UpdatedAt time.Time
+}
+
+// a transaction is settled when it is dropped, or when it is executed and the block which contains it is known
+func (t TransactionStatus) IsSettled() bool {
+	switch t.Status {
+	case TxDropped:
+		return true
+	case TxExecuted, TxFailed:
+		return t.Height != 0
+	default:
+		return false
+	}
+}
+
+// InvokeResult is the outcome of a transaction which is committed and executed
+type InvokeResult struct {
+	TransactionId string
+	Height        uint64
+	TxIndex       int
+	Result        ivm.Result
+}
+
+// events of the components arrive through different queues, so a transition is applied only when it moves forward.
+// An in-flight transaction goes back to pending when its round fails, and a dropped one when it is submitted again
+func canTransit(from string, to string) bool {
+	if from == "" {
+		return true
+	}
+
+	switch to {
+	case TxPending:
+		return from == TxProposed || from == TxDropped
+	case TxDropped:
+		return txStatusRank[from] < txStatusRank[TxCommitted]
+	default:
+		return txStatusRank[to] > txStatusRank[from]
+	}
+}
+
+type TransactionQueryApi struct {
+	transactionStatusRepository *TransactionStatusRepository
+}
+
+func NewTransactionQueryApi(transactionStatusRepository *TransactionStatusRepository) *TransactionQueryApi {
+	return &TransactionQueryApi{
+		transactionStatusRepository: transactionStatusRepository,
+	}
+}
+
+func (t TransactionQueryApi) GetTransactionStatus(id string) (TransactionStatus, error) {
+	return t.transactionStatusRepository.FindById(id)
+}
+
+func (t TransactionQueryApi) GetTransactions(status string) ([]TransactionStatus, error) {
+	if status == "" {
+		return t.transactionStatusRepository.FindAll(), nil
+	}
+
+	if !IsTransactionStatus(status) {
+		return nil, ErrUnknownTransactionStatus
+	}
+
+	return t.transactionStatusRepository.FindByStatus(status), nil
+}
+
+// WaitTransactionResult blocks until the transaction is committed and executed, or the timeout passes
+func (t TransactionQueryApi) WaitTransactionResult(id string, timeout time.Duration) (InvokeResult, error) {
+	transactionStatus, err := t.transactionStatusRepository.WaitSettled(id, timeout)
+	if err != nil {
+		return InvokeResult{}, err
+	}
+
+	if transactionStatus.Status == TxDropped {
+		return InvokeResult{}, TransactionDroppedError{ID: id, Reason: transactionStatus.Reason}
+	}
+
+	result := ivm.Result{Data: transactionStatus.Result}
+	if transactionStatus.Status == TxFailed {
+		result.Err = transactionStatus.Reason
+	}
+
+	return InvokeResult{
+		TransactionId: id,
+		Height:        transactionStatus.Height,
+		TxIndex:       transactionStatus.TxIndex,
+		Result:        result,
+	}, nil
+}
+
+// TransactionStatusRepository keeps the status of the transactions seen by the node.
+// Settled transactions are evicted after the retention, so the repository does not grow with the history.
+// A retention of zero keeps them forever
+type TransactionStatusRepository struct {
+	sync.RWMutex
+	statuses map[string]TransactionStatus
+	// the transactions are listed in the order they were first seen
+	sequences    map[string]uint64
+	nextSequence uint64
+	byStatus     map[string]map[string]struct{}
+	// settled transactions in the order they were settled
+	settled   []settledTransaction
+	settledAt map[string]time.Time
+	retention time.Duration
+	waiters   map[string][]chan TransactionStatus
+}
+
+type settledTransaction struct {
+	id string
+	at time.Time
+}
+
+func NewTransactionStatusRepository(retention time.Duration) *TransactionStatusRepository {
+	return &TransactionStatusRepository{
+		statuses:  make(map[string]TransactionStatus),
+		sequences: make(map[string]uint64),
+		byStatus:  make(map[string]map[string]struct{}),
+		settled:   make([]settledTransaction, 0),
+		settledAt: make(map[string]time.Time),
+		retention: retention,
+		waiters:   make(map[string][]chan TransactionStatus),
+		RWMutex:   sync.RWMutex{},
+	}
+}
+
+// Update applies the change to the transaction when its status can move to the given one
+func (t *TransactionStatusRepository) Update(id string, status string, f func(*TransactionStatus)) {
+	t.Lock()
+	defer t.Unlock()
+
+	now := time.Now()
+	t.evictSettled(now)
+
+	transactionStatus, ok := t.statuses[id]
+	if !ok {
+		t.sequences[id] = t.nextSequence
+		t.nextSequence++
+		transactionStatus = TransactionStatus{ID: id}
+	}
+
+	wasSettled := transactionStatus.IsSettled()
+	previousStatus := transactionStatus.Status
+
+	if canTransit(transactionStatus.Status, status) {
+		transactionStatus.Status = status
+		transactionStatus.Reason = ""
+		transactionStatus.UpdatedAt = now
+	}
+
+	if f != nil {
+		f(&transactionStatus)
+	}
+
+	t.statuses[id] = transactionStatus
+	t.index(id, previousStatus, transactionStatus.Status)
+
+	if !transactionStatus.IsSettled() {
+		delete(t.settledAt, id)
+		return
+	}
+
+	if !wasSettled {
+		t.settledAt[id] = now
+		t.settled = append(t.settled, settledTransaction{id: id, at: now})
+	}
+
+	for _, waiter := range t.waiters[id] {
+		waiter <- transactionStatus
+	}
+	delete(t.waiters, id)
+}
+
+func (t *TransactionStatusRepository) index(id string, from string, to string) {
+	if from == to {
+		return
+	}
+
+	delete(t.byStatus[from], id)
+
+	if _, ok := t.byStatus[to]; !ok {
+		t.byStatus[to] = make(map[string]struct{})
+	}
+	t.byStatus[to][id] = struct{}{}
+}
+
+// evictSettled removes the transactions settled longer than the retention ago.
+// A transaction which left the settled state, or was settled again, has a stale entry which is skipped
+func (t *TransactionStatusRepository) evictSettled(now time.Time) {
+	if t.retention == 0 {
+		return
+	}
+
+	for len(t.settled) != 0 {
+		oldest := t.settled[0]
+		if settledAt, ok := t.settledAt[oldest.id]; ok && settledAt.Equal(oldest.at) {
+			if now.Sub(oldest.at) < t.retention {
+				return
+			}
+
+			delete(t.byStatus[t.statuses[oldest.id].Status], oldest.id)
+			delete(t.statuses, oldest.id)
+			delete(t.sequences, oldest.id)
+			delete(t.settledAt, oldest.id)
+		}
+
+		t.settled = t.settled[1:]
+	}
+}
+
+// WaitSettled returns the status of the transaction as soon as it is settled.
+// The transaction may not be known yet, its events can arrive after the caller got its ID
+func (t *TransactionStatusRepository) WaitSettled(id string, timeout time.Duration) (TransactionStatus, error) {
+	t.Lock()
+
+	if transactionStatus, ok := t.statuses[id]; ok && transactionStatus.IsSettled() {
+		t.Unlock()
+		return transactionStatus, nil
+	}
+
+	waiter := make(chan TransactionStatus, 1)
+	t.waiters[id] = append(t.waiters[id], waiter)
+	t.Unlock()
+
+	select {
+	case transactionStatus := <-waiter:
+		return transactionStatus, nil
+	case <-time.After(timeout):
+		t.removeWaiter(id, waiter)
+		return TransactionStatus{}, ErrWaitTimeout
+	}
+}
+
+func (t *TransactionStatusRepository) removeWaiter(id string, waiter chan TransactionStatus) {
+	t.Lock()
+	defer t.Unlock()
+
+	waiters := make([]chan TransactionStatus, 0)
+	for _, w := range t.waiters[id] {
+		if w != waiter {
+			waiters = append(waiters, w)
+		}
+	}
+
+	if len(waiters) == 0 {
+		delete(t.waiters, id)
+		return
+	}
+
+	t.waiters[id] = waiters
+}
+
+func (t *TransactionStatusRepository) FindById(id string) (TransactionStatus, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	transactionStatus, ok := t.statuses[id]
+	if !ok {
+		return TransactionStatus{}, ErrTransactionNotFound
+	}
+
+	return transactionStatus, nil
+}
+
+func (t *TransactionStatusRepository) FindAll() []TransactionStatus {
+	t.RLock()
+	defer t.RUnlock()
+
+	transactionStatusList := make([]TransactionStatus, 0, len(t.statuses))
+	for _, transactionStatus := range t.statuses {
+		transactionStatusList = append(transactionStatusList, transactionStatus)
+	}
+
+	return t.sortBySequence(transactionStatusList)
+}
+
+func (t *TransactionStatusRepository) FindByStatus(status string) []TransactionStatus {
+	t.RLock()
+	defer t.RUnlock()
+
+	transactionStatusList := make([]TransactionStatus, 0, len(t.byStatus[status]))
+	for id := range t.byStatus[status] {
+		transactionStatusList = append(transactionStatusList, t.statuses[id])
+	}
+
+	return t.sortBySequence(transactionStatusList)
+}
+
+func (t *TransactionStatusRepository) sortBySequence(transactionStatusList []TransactionStatus) []TransactionStatus {
+	sort.Slice(transactionStatusList, func(i, j int) bool {
+		return t.sequences[transactionStatusList[i].ID] < t.sequences[transactionStatusList[j].ID]
+	})
+
+	return transactionStatusList
+}
+
+// TransactionEventListener follows the transactions through the events of txpool, blockchain and ivm
+type TransactionEventListener struct {
+	transactionStatusRepository *TransactionStatusRepository
+}
+
+func NewTransactionEventListener(transactionStatusRepository *TransactionStatusRepository) *TransactionEventListener {
+	return &TransactionEventListener{
+		transactionStatusRepository: transactionStatusRepository,
+	}
+}
+
+func (t *TransactionEventListener) HandleTxPendingEvent(event event.TxPending) {
+	for _, id := range event.TransactionIds {
+		t.transactionStatusRepository.Update(id, TxPending, nil)
+	}
+}
+
+func (t *TransactionEventListener) HandleTxForwardedEvent(event event.TxForwarded) {
+	for _, id := range event.TransactionIds {
+		t.transactionStatusRepository.Update(id, TxForwarded, nil)
+	}
+}
+
+func (t *TransactionEventListener) HandleTxProposedEvent(event event.TxProposed) {
+	for _, id := range event.TransactionIds {
+		t.transactionStatusRepository.Update(id, TxProposed, nil)
+	}
+}
+
+func (t *TransactionEventListener) HandleTxDroppedEvent(event event.TxDropped) {
+	for _, id := range event.TransactionIds {
+		t.transactionStatusRepository.Update(id, TxDropped, func(transactionStatus *TransactionStatus) {
+			if transactionStatus.Status == TxDropped {
+				transactionStatus.Reason = event.Reason
+			}
+		})
+	}
+}
+
+func (t *TransactionEventListener) HandleTxExpiredEvent(event event.TxExpired) {
+	for _, id := range event.TransactionIds {
+		t.transactionStatusRepository.Update(id, TxDropped, func(transactionStatus *TransactionStatus) {
+			if transactionStatus.Status == TxDropped {
+				transactionStatus.Reason = txpool.DroppedByExpiry
+			}
+		})
+	}
+}
+
+// the height is kept even if the execution result arrived first
+func (t *TransactionEventListener) HandleBlockCommittedEvent(event event.BlockCommitted) {
+	for index, tx := range event.TxList {
+		t.transactionStatusRepository.Update(tx.ID, TxCommitted, func(transactionStatus *TransactionStatus) {
+			transactionStatus.Height = event.Height
+			transactionStatus.TxIndex = index
+		})
+	}
+}
+
+func (t *TransactionEventListener) HandleTxExecutedEvent(event event.TxExecuted) {
+	status := TxExecuted
+	if event.Err != "" {
+		status = TxFailed
+	}
+
+	t.transactionStatusRepository.Update(event.TransactionId, status, func(transactionStatus *TransactionStatus) {
+		transactionStatus.Result = event.Data
+		transactionStatus.Reason = event.Err
+	})
+}
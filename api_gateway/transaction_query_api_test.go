@@ -0,0 +1,196 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api_gateway_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/it-chain/engine/api_gateway"
+	"github.com/it-chain/engine/common/event"
+	"github.com/it-chain/engine/ivm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransactionQueryApi_GetTransactionStatus(t *testing.T) {
+
+	// given
+	repository := api_gateway.NewTransactionStatusRepository(time.Hour)
+	listener := api_gateway.NewTransactionEventListener(repository)
+	queryApi := api_gateway.NewTransactionQueryApi(repository)
+
+	listener.HandleTxPendingEvent(event.TxPending{TransactionIds: []string{"tx01", "tx02", "tx03"}})
+	listener.HandleTxProposedEvent(event.TxProposed{TransactionIds: []string{"tx01", "tx02"}})
+	// forwarded arrives late, the transaction is already proposed
+	listener.HandleTxForwardedEvent(event.TxForwarded{TransactionIds: []string{"tx01", "tx02", "tx03"}})
+	listener.HandleTxDroppedEvent(event.TxDropped{TransactionIds: []string{"tx03"}, Reason: "evicted"})
+	// execution result arrives before the commit
+	listener.HandleTxExecutedEvent(event.TxExecuted{TransactionId: "tx02", Err: "function not found"})
+	listener.HandleBlockCommittedEvent(event.BlockCommitted{Height: 3, TxList: []event.Tx{{ID: "tx01"}, {ID: "tx02"}}})
+	listener.HandleTxExecutedEvent(event.TxExecuted{TransactionId: "tx01", Data: map[string]string{"A": "1"}})
+
+	// when
+	tx01, err := queryApi.GetTransactionStatus("tx01")
+	assert.NoError(t, err)
+	tx02, err := queryApi.GetTransactionStatus("tx02")
+	assert.NoError(t, err)
+	tx03, err := queryApi.GetTransactionStatus("tx03")
+	assert.NoError(t, err)
+	_, err = queryApi.GetTransactionStatus("tx04")
+
+	// then
+	assert.Equal(t, api_gateway.TxExecuted, tx01.Status)
+	assert.Equal(t, uint64(3), tx01.Height)
+	assert.Equal(t, map[string]string{"A": "1"}, tx01.Result)
+
+	assert.Equal(t, api_gateway.TxFailed, tx02.Status)
+	assert.Equal(t, uint64(3), tx02.Height)
+	assert.Equal(t, "function not found", tx02.Reason)
+
+	assert.Equal(t, api_gateway.TxDropped, tx03.Status)
+	assert.Equal(t, "evicted", tx03.Reason)
+
+	assert.Equal(t, api_gateway.ErrTransactionNotFound, err)
+}
+
+func TestTransactionQueryApi_GetTransactions(t *testing.T) {
+	tests := map[string]struct {
+		input struct {
+			status string
+		}
+		output []string
+		err    error
+	}{
+		"all transactions": {
+			input: struct {
+				status string
+			}{status: ""},
+			output: []string{"tx01", "tx02", "tx03"},
+		},
+		"pending transactions": {
+			input: struct {
+				status string
+			}{status: api_gateway.TxPending},
+			output: []string{"tx01", "tx03"},
+		},
+		"unknown status": {
+			input: struct {
+				status string
+			}{status: "lost"},
+			output: nil,
+			err:    api_gateway.ErrUnknownTransactionStatus,
+		},
+	}
+
+	// given
+	repository := api_gateway.NewTransactionStatusRepository(time.Hour)
+	listener := api_gateway.NewTransactionEventListener(repository)
+	queryApi := api_gateway.NewTransactionQueryApi(repository)
+
+	listener.HandleTxPendingEvent(event.TxPending{TransactionIds: []string{"tx01", "tx02", "tx03"}})
+	listener.HandleTxProposedEvent(event.TxProposed{TransactionIds: []string{"tx01", "tx02"}})
+	// the round of tx01 failed
+	listener.HandleTxPendingEvent(event.TxPending{TransactionIds: []string{"tx01"}})
+
+	for testName, test := range tests {
+		t.Logf("running test case %s", testName)
+
+		// when
+		transactions, err := queryApi.GetTransactions(test.input.status)
+
+		// then
+		assert.Equal(t, test.err, err)
+		if test.output == nil {
+			continue
+		}
+
+		ids := make([]string, 0)
+		for _, tx := range transactions {
+			ids = append(ids, tx.ID)
+		}
+		assert.Equal(t, test.output, ids)
+	}
+}
+
+func TestTransactionQueryApi_WaitTransactionResult(t *testing.T) {
+
+	// given
+	repository := api_gateway.NewTransactionStatusRepository(time.Hour)
+	listener := api_gateway.NewTransactionEventListener(repository)
+	queryApi := api_gateway.NewTransactionQueryApi(repository)
+
+	listener.HandleTxPendingEvent(event.TxPending{TransactionIds: []string{"tx01", "tx02", "tx03"}})
+	listener.HandleTxDroppedEvent(event.TxDropped{TransactionIds: []string{"tx03"}, Reason: "evicted"})
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		listener.HandleTxExecutedEvent(event.TxExecuted{TransactionId: "tx02", Data: map[string]string{"A": "1"}})
+		listener.HandleBlockCommittedEvent(event.BlockCommitted{Height: 3, TxList: []event.Tx{{ID: "tx01"}, {ID: "tx02"}}})
+	}()
+
+	// when
+	result, err := queryApi.WaitTransactionResult("tx02", time.Second)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, api_gateway.InvokeResult{
+		TransactionId: "tx02",
+		Height:        3,
+		TxIndex:       1,
+		Result:        ivm.Result{Data: map[string]string{"A": "1"}},
+	}, result)
+
+	// when
+	_, err = queryApi.WaitTransactionResult("tx01", 50*time.Millisecond)
+
+	// then
+	assert.Equal(t, api_gateway.ErrWaitTimeout, err)
+
+	// when
+	_, err = queryApi.WaitTransactionResult("tx03", time.Second)
+
+	// then
+	assert.Equal(t, api_gateway.TransactionDroppedError{ID: "tx03", Reason: "evicted"}, err)
+}
+
+func TestTransactionStatusRepository_EvictSettled(t *testing.T) {
+
+	// given
+	repository := api_gateway.NewTransactionStatusRepository(50 * time.Millisecond)
+	listener := api_gateway.NewTransactionEventListener(repository)
+
+	listener.HandleTxPendingEvent(event.TxPending{TransactionIds: []string{"tx01", "tx02", "tx03"}})
+	listener.HandleTxDroppedEvent(event.TxDropped{TransactionIds: []string{"tx01", "tx03"}, Reason: "evicted"})
+	// tx03 is submitted again, it is not settled anymore
+	listener.HandleTxPendingEvent(event.TxPending{TransactionIds: []string{"tx03"}})
+
+	// when
+	time.Sleep(60 * time.Millisecond)
+	listener.HandleTxPendingEvent(event.TxPending{TransactionIds: []string{"tx04"}})
+
+	// then
+	_, err := repository.FindById("tx01")
+	assert.Equal(t, api_gateway.ErrTransactionNotFound, err)
+	assert.Empty(t, repository.FindByStatus(api_gateway.TxDropped))
+
+	ids := make([]string, 0)
+	for _, tx := range repository.FindByStatus(api_gateway.TxPending) {
+		ids = append(ids, tx.ID)
+	}
+	assert.Equal(t, []string{"tx02", "tx03", "tx04"}, ids)
+	assert.Len(t, repository.FindAll(), 3)
+}
@@ -21,31 +21,44 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
 	"strconv"
+	"time"
 
 	kitlog "github.com/go-kit/kit/log"
 	kithttp "github.com/go-kit/kit/transport/http"
 	"github.com/gorilla/mux"
+	"github.com/it-chain/engine/txpool"
 )
 
 var (
 	// ErrBadRouting is returned when an expected path variable is missing.
 	ErrBadRouting    = errors.New("inconsistent mapping between route and handler.")
 	ErrBadConversion = errors.New("Conversion failed: invalid argument in url endpoint.")
+	// ErrRequestTooLarge is returned when the body of a request is larger than the limit
+	ErrRequestTooLarge = errors.New("request body is too large")
+	// ErrInvalidTimeout is returned when the wait timeout is not a positive duration or is larger than the limit
+	ErrInvalidTimeout = errors.New("timeout must be a positive duration within the limit")
 )
 
-func NewApiHandler(bqa *BlockQueryApi, iqa *ICodeQueryApi, iha *ICodeCommandApi, p *PeerQueryApi, cca *ConnectionCommandApi, logger kitlog.Logger) http.Handler {
+const DefaultWaitTimeout = 30 * time.Second
+
+// the body of a POST request is limited to maxRequestBytes and the wait for a transaction result to maxWaitTimeout,
+// zero means no limit
+func NewApiHandler(bqa *BlockQueryApi, iqa *ICodeQueryApi, iha *ICodeCommandApi, p *PeerQueryApi, cca *ConnectionCommandApi, cqa *ConsensusQueryApi, tqa *TransactionQueryApi, logger kitlog.Logger, maxRequestBytes int64, maxWaitTimeout time.Duration) http.Handler {
 
 	r := mux.NewRouter()
 
 	be := MakeBlockchainEndpoints(bqa)
 	ie := MakeIcodeEndpoints(iha, iqa)
 	ce := MakePeerEndpoints(p, cca)
-	te := MakeTransactionEndpoints(iha)
+	te := MakeTransactionEndpoints(iha, tqa)
+	cse := MakeConsensusEndpoints(cqa)
 
 	opts := []kithttp.ServerOption{
 		kithttp.ServerErrorLogger(logger),
+		kithttp.ServerErrorEncoder(encodeError),
 	}
 
 	// GET     /blocks/						retrieves all blocks committed
@@ -70,6 +83,9 @@ func NewApiHandler(bqa *BlockQueryApi, iqa *ICodeQueryApi, iha *ICodeCommandApi,
 	// GET		/icodes?amqpUrl=:amqpUrl											retrieves all icodes deployed using particular amqp url not in config
 	// POST		/icodes																deploy icode. about post body information, see decodeDeployIcodeRequest
 	// DELETE	/icodes/{icodeId}													unDeploy icode that match icodeId
+	// GET		/icodes/{name}/versions												retrieves the versions deployed under the icode name and which one is active
+	// GET		/icodes/execution													retrieves whether this node halted executing the committed blocks and why
+	// POST		/icodes/execution/resume											retries the halted block execution right away and retrieves the status after it
 
 	r.Methods("GET").Path("/icodes").Handler(kithttp.NewServer(
 		ie.GetIcodeListEndpoint,
@@ -79,7 +95,7 @@ func NewApiHandler(bqa *BlockQueryApi, iqa *ICodeQueryApi, iha *ICodeCommandApi,
 
 	r.Methods("POST").Path("/icodes").Handler(kithttp.NewServer(
 		ie.DeployIcodeEndpoint,
-		decodeDeployIcodeRequest,
+		limitRequestBody(maxRequestBytes, decodeDeployIcodeRequest),
 		encodeResponse,
 		opts...))
 
@@ -89,11 +105,61 @@ func NewApiHandler(bqa *BlockQueryApi, iqa *ICodeQueryApi, iha *ICodeCommandApi,
 		encodeResponse,
 		opts...))
 
-	// GET		/transactions			get all uncommitted transactions
-	// POST 	/transactions			create transaction
+	r.Methods("GET").Path("/icodes/{name}/versions").Handler(kithttp.NewServer(
+		ie.GetIcodeVersionsEndpoint,
+		decodeGetIcodeVersionsRequest,
+		encodeResponse,
+		opts...))
+
+	r.Methods("GET").Path("/icodes/execution").Handler(kithttp.NewServer(
+		ie.GetExecutionEndpoint,
+		decodeIvmRequest,
+		encodeResponse,
+		opts...))
+
+	r.Methods("POST").Path("/icodes/execution/resume").Handler(kithttp.NewServer(
+		ie.ResumeExecutionEndpoint,
+		decodeIvmRequest,
+		encodeResponse,
+		opts...))
+
+	// GET		/transactions						retrieves the status of all transactions known to this node
+	// GET		/transactions?status=:status		retrieves transactions in particular status, e.g. pending
+	// GET		/transactions/{id}/status			retrieves the status of a transaction
+	// GET		/transactions/{id}/result?timeout=:duration	waits until the transaction is committed and executed, 504 on timeout,
+	// 											400 when the timeout is larger than apigateway.maxwaittimeoutms
+	// POST 	/transactions						create transaction, 400 when it is rejected by a validator and 429 when the transaction pool is full
+	// POST 	/transactions?wait=true&timeout=:duration		invoke and wait for the result like GET /transactions/{id}/result
+	// POST 	/transactions/batch					create transactions at once, the ID or the error of each transaction is returned in order,
+	// 											413 when the batch has more transactions than txpool.maxbatchtransactions
+	// every POST body is limited to apigateway.maxrequestbytes, 413 when it is larger
+	r.Methods("GET").Path("/transactions").Handler(kithttp.NewServer(
+		te.FindAllTransactionEndpoint,
+		decodeFindAllTransactionRequest,
+		encodeResponse,
+		opts...))
+
+	r.Methods("GET").Path("/transactions/{id}/status").Handler(kithttp.NewServer(
+		te.FindTransactionStatusEndpoint,
+		decodeFindTransactionStatusRequest,
+		encodeResponse,
+		opts...))
+
+	r.Methods("GET").Path("/transactions/{id}/result").Handler(kithttp.NewServer(
+		te.FindTransactionResultEndpoint,
+		decodeFindTransactionResultRequest(maxWaitTimeout),
+		encodeResponse,
+		opts...))
+
 	r.Methods("POST").Path("/transactions").Handler(kithttp.NewServer(
 		te.CreateTransactionEndpoint,
-		decodeCreateTransactionRequest,
+		limitRequestBody(maxRequestBytes, decodeCreateTransactionRequest(maxWaitTimeout)),
+		encodeResponse,
+		opts...))
+
+	r.Methods("POST").Path("/transactions/batch").Handler(kithttp.NewServer(
+		te.CreateTransactionBatchEndpoint,
+		limitRequestBody(maxRequestBytes, decodeCreateTransactionBatchRequest),
 		encodeResponse,
 		opts...))
 
@@ -116,23 +182,161 @@ func NewApiHandler(bqa *BlockQueryApi, iqa *ICodeQueryApi, iha *ICodeCommandApi,
 
 	r.Methods("POST").Path("/peers").Handler(kithttp.NewServer(
 		ce.CreateConnectionEndpoint,
-		decodeCreateConnectionRequest,
+		limitRequestBody(maxRequestBytes, decodeCreateConnectionRequest),
 		encodeResponse,
 		opts...))
 
+	// GET		/consensus									retrieves the consensus status of this node
+	r.Methods("GET").Path("/consensus").Handler(kithttp.NewServer(
+		cse.FindConsensusStatusEndpoint,
+		decodeFindConsensusStatusRequest,
+		encodeResponse,
+		opts...,
+	))
+
+	// GET		/consensus/misbehaviours					retrieves all misbehaviour evidences
+	// GET		/consensus/misbehaviours?offender=:id		retrieves misbehaviour evidences of particular offender
+	r.Methods("GET").Path("/consensus/misbehaviours").Handler(kithttp.NewServer(
+		cse.FindAllMisbehaviourEndpoint,
+		decodeFindMisbehaviourRequest,
+		encodeResponse,
+		opts...,
+	))
+
 	return r
 }
 
+// limitRequestBody rejects a request whose body is larger than maxBytes with ErrRequestTooLarge
+func limitRequestBody(maxBytes int64, decode kithttp.DecodeRequestFunc) kithttp.DecodeRequestFunc {
+	return func(ctx context.Context, r *http.Request) (interface{}, error) {
+		if maxBytes == 0 {
+			return decode(ctx, r)
+		}
+
+		body := &limitedBody{ReadCloser: r.Body, remaining: maxBytes}
+		r.Body = body
+
+		request, err := decode(ctx, r)
+		if body.exceeded {
+			return nil, ErrRequestTooLarge
+		}
+
+		return request, err
+	}
+}
+
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+	exceeded  bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining == 0 {
+		// the body may end right at the limit
+		n, err := b.ReadCloser.Read(make([]byte, 1))
+		if n != 0 {
+			b.exceeded = true
+			return 0, ErrRequestTooLarge
+		}
+
+		return 0, err
+	}
+
+	if int64(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+
+	return n, err
+}
+
 /*
 txpool
 */
-// this return nil because this request body is empty
-func decodeFindAllUncommittedTransactionsRequest(_ context.Context, r *http.Request) (interface{}, error) {
-	return nil, nil
+func decodeFindAllTransactionRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	return FindAllTransactionRequest{Status: r.URL.Query().Get("status")}, nil
+}
+
+func decodeFindTransactionStatusRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	vars := mux.Vars(r)
+
+	id, ok := vars["id"]
+	if !ok {
+		return nil, ErrBadRouting
+	}
+
+	return FindTransactionStatusRequest{ID: id}, nil
+}
+
+func decodeFindTransactionResultRequest(maxWaitTimeout time.Duration) kithttp.DecodeRequestFunc {
+	return func(_ context.Context, r *http.Request) (interface{}, error) {
+		vars := mux.Vars(r)
+
+		id, ok := vars["id"]
+		if !ok {
+			return nil, ErrBadRouting
+		}
+
+		timeout, err := decodeTimeout(r, maxWaitTimeout)
+		if err != nil {
+			return nil, err
+		}
+
+		return FindTransactionResultRequest{ID: id, Timeout: timeout}, nil
+	}
+}
+
+func decodeCreateTransactionRequest(maxWaitTimeout time.Duration) kithttp.DecodeRequestFunc {
+	return func(_ context.Context, r *http.Request) (interface{}, error) {
+		body := CreateTransactionRequest{}
+		err := json.NewDecoder(r.Body).Decode(&body)
+		if err != nil {
+			return nil, err
+		}
+
+		if r.URL.Query().Get("wait") == "true" {
+			timeout, err := decodeTimeout(r, maxWaitTimeout)
+			if err != nil {
+				return nil, err
+			}
+
+			body.Wait = true
+			body.Timeout = timeout
+		}
+
+		return body, nil
+	}
 }
 
-func decodeCreateTransactionRequest(_ context.Context, r *http.Request) (interface{}, error) {
-	body := CreateTransactionRequest{}
+// the timeout is a duration like 30s, DefaultWaitTimeout when it is not given.
+// A timeout larger than maxTimeout is rejected, and the default is cut to it. Zero maxTimeout means no limit
+func decodeTimeout(r *http.Request, maxTimeout time.Duration) (time.Duration, error) {
+	timeoutStr := r.URL.Query().Get("timeout")
+	if timeoutStr == "" {
+		if maxTimeout > 0 && DefaultWaitTimeout > maxTimeout {
+			return maxTimeout, nil
+		}
+
+		return DefaultWaitTimeout, nil
+	}
+
+	timeout, err := time.ParseDuration(timeoutStr)
+	if err != nil || timeout <= 0 {
+		return 0, ErrInvalidTimeout
+	}
+
+	if maxTimeout > 0 && timeout > maxTimeout {
+		return 0, ErrInvalidTimeout
+	}
+
+	return timeout, nil
+}
+
+func decodeCreateTransactionBatchRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	body := CreateTransactionBatchRequest{}
 	err := json.NewDecoder(r.Body).Decode(&body)
 	if err != nil {
 		return nil, err
@@ -215,6 +419,39 @@ func decodeUnDeployIcodeRequest(_ context.Context, r *http.Request) (interface{}
 	}, nil
 }
 
+// decodeIvmRequest decodes the requests which only choose the amqp url of the node
+func decodeIvmRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	return IvmRequest{AmqpUrl: r.FormValue("amqpUrl")}, nil
+}
+
+func decodeGetIcodeVersionsRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	vars := mux.Vars(r)
+
+	name, ok := vars["name"]
+	if !ok {
+		return nil, ErrBadConversion
+	}
+
+	return GetIcodeVersionsRequest{
+		IvmRequest: IvmRequest{
+			AmqpUrl: r.FormValue("amqpUrl"),
+		},
+		Name: name,
+	}, nil
+}
+
+/*
+consensus
+*/
+// this return nil because this request body is empty
+func decodeFindConsensusStatusRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	return nil, nil
+}
+
+func decodeFindMisbehaviourRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	return FindMisbehaviourRequest{OffenderID: r.URL.Query().Get("offender")}, nil
+}
+
 /*
 grpc gateway
 */
@@ -266,11 +503,31 @@ type errorer interface {
 func encodeError(_ context.Context, err error, w http.ResponseWriter) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	switch err {
+	case txpool.ErrPoolFull:
+		w.WriteHeader(http.StatusTooManyRequests)
+	case ErrTransactionNotFound:
+		w.WriteHeader(http.StatusNotFound)
+	case ErrUnknownTransactionStatus:
+		w.WriteHeader(http.StatusBadRequest)
+	case ErrEmptyBatch, ErrInvalidTimeout:
+		w.WriteHeader(http.StatusBadRequest)
+	case txpool.ErrBatchTooLarge, ErrRequestTooLarge:
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+	case ErrWaitTimeout:
+		w.WriteHeader(http.StatusGatewayTimeout)
 	//case cargo.ErrUnknown:
 	//	w.WriteHeader(http.StatusNotFound)
 	//case ErrInvalidArgument:
 	//	w.WriteHeader(http.StatusBadRequest)
 	default:
+		if _, ok := err.(txpool.InvalidTransactionError); ok {
+			w.WriteHeader(http.StatusBadRequest)
+			break
+		}
+		if _, ok := err.(TransactionDroppedError); ok {
+			w.WriteHeader(http.StatusConflict)
+			break
+		}
 		w.WriteHeader(http.StatusInternalServerError)
 	}
 	json.NewEncoder(w).Encode(map[string]interface{}{
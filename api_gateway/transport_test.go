@@ -0,0 +1,95 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api_gateway_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/it-chain/engine/api_gateway"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewApiHandler_MaxRequestBytes(t *testing.T) {
+
+	tests := map[string]struct {
+		body   string
+		status int
+	}{
+		"body at the limit": {
+			body:   `{"Transactions":[]}`,
+			status: http.StatusBadRequest,
+		},
+		"body over the limit": {
+			body:   `{"Transactions":[{}]}`,
+			status: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	// given
+	handler := api_gateway.NewApiHandler(nil, nil, api_gateway.NewICodeCommandApi(), nil, nil, nil, nil, kitlog.NewNopLogger(), int64(len(`{"Transactions":[]}`)), time.Minute)
+
+	for testName, test := range tests {
+		t.Logf("running test case %s", testName)
+
+		// when
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest("POST", "/transactions/batch", strings.NewReader(test.body)))
+
+		// then the batch at the limit is decoded and rejected as empty
+		assert.Equal(t, test.status, recorder.Code)
+	}
+}
+
+func TestNewApiHandler_MaxWaitTimeout(t *testing.T) {
+
+	tests := map[string]struct {
+		timeout string
+		status  int
+	}{
+		"timeout over the limit": {
+			timeout: "2m",
+			status:  http.StatusBadRequest,
+		},
+		"timeout not a duration": {
+			timeout: "forever",
+			status:  http.StatusBadRequest,
+		},
+		"negative timeout": {
+			timeout: "-1s",
+			status:  http.StatusBadRequest,
+		},
+	}
+
+	// given
+	handler := api_gateway.NewApiHandler(nil, nil, api_gateway.NewICodeCommandApi(), nil, nil, nil, nil, kitlog.NewNopLogger(), 0, time.Minute)
+
+	for testName, test := range tests {
+		t.Logf("running test case %s", testName)
+
+		// when
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/transactions/tx01/result?timeout="+test.timeout, nil))
+
+		// then
+		assert.Equal(t, test.status, recorder.Code)
+	}
+}
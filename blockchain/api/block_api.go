@@ -17,7 +17,10 @@
 package api
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/it-chain/engine/blockchain"
 	"github.com/it-chain/engine/blockchain/infra/mem"
@@ -46,34 +49,90 @@ func (bApi BlockApi) CheckAndSaveBlockFromPool(height blockchain.BlockHeight) er
 	return nil
 }
 
-func (api BlockApi) ConsentBlock(engineMode string, block blockchain.DefaultBlock) error {
+// ValidateProposedBody decodes the body of a block proposed to the consensus
+// and checks it was proposed with its own seal and height
+func (api BlockApi) ValidateProposedBody(seal []byte, height uint64, body []byte) error {
+	block := blockchain.DefaultBlock{}
+	if err := json.Unmarshal(body, &block); err != nil {
+		return err
+	}
 
-	iLogger.Info(nil, "[Blockchain] ConsentBlock")
+	if !bytes.Equal(block.GetSeal(), seal) {
+		return blockchain.ErrProposedSealMismatch
+	}
 
-	switch engineMode {
-	case "solo":
-		iLogger.Info(nil, "[Blockchain] ConsentBlock solo")
-		return api.CommitBlock(block)
+	if block.GetHeight() != height {
+		return blockchain.ErrProposedHeightMismatch
+	}
 
-	case "pbft":
+	return api.ValidateProposedBlock(block)
+}
 
-		iLogger.Info(nil, "[Blockchain] ConsentBlock pbft")
-		startConsensusCmd, err := createStartConsensusCommand(block)
-		if err != nil {
-			return err
-		}
+// GetLastBlockSeal returns the height and the seal of the last committed block
+func (api BlockApi) GetLastBlockSeal() (uint64, []byte, error) {
+	lastBlock, err := api.blockRepository.FindLast()
+	if err != nil {
+		return 0, nil, ErrGetLastBlock
+	}
+
+	return lastBlock.GetHeight(), lastBlock.GetSeal(), nil
+}
+
+// GetCommittedBlock returns the committed block of the height as it is published when committed
+func (api BlockApi) GetCommittedBlock(height uint64) (event.BlockCommitted, error) {
+	block, err := api.blockRepository.FindByHeight(height)
+	if err != nil {
+		return event.BlockCommitted{}, err
+	}
 
-		if err := api.eventService.Publish("block.consent", startConsensusCmd); err != nil {
-			return err
+	return createBlockCommittedEvent(block)
+}
+
+// ValidateProposedBlock is called by a member before it prevotes the block proposed by the leader
+func (api BlockApi) ValidateProposedBlock(block blockchain.DefaultBlock) error {
+	lastBlock, err := api.blockRepository.FindLast()
+	if err != nil {
+		return ErrGetLastBlock
+	}
+
+	if err := blockchain.ValidateProposedBlock(block, lastBlock); err != nil {
+		return err
+	}
+
+	for _, tx := range block.TxList {
+		committedBlock, err := api.blockRepository.FindByTxID(tx.ID)
+		if err == nil && !committedBlock.IsEmpty() {
+			return blockchain.ErrTransactionAlreadyCommitted
 		}
+	}
 
-		return nil
+	return nil
+}
 
-	default:
-		iLogger.Error(nil, fmt.Sprintf("[Blockchain] Undefined mode - engine mode:[%s]", engineMode))
+func (api BlockApi) ConsentBlock(engine blockchain.ConsensusEngine, block blockchain.DefaultBlock) error {
+
+	if engine == nil {
+		iLogger.Error(nil, "[Blockchain] Consensus engine is not set")
 		return ErrUndefinedConsensusType
 	}
 
+	iLogger.Infof(nil, "[Blockchain] ConsentBlock %s", engine.Mode())
+
+	startConsensusCmd, err := createStartConsensusCommand(block)
+	if err != nil {
+		return err
+	}
+
+	finalized, err := engine.ConsentBlock(startConsensusCmd)
+	if err != nil {
+		return err
+	}
+
+	if finalized {
+		return api.CommitBlock(block)
+	}
+
+	return nil
 }
 
 func (bApi BlockApi) CommitGenesisBlock(GenesisConfPath string) error {
@@ -107,7 +166,8 @@ func (bApi BlockApi) CommitGenesisBlock(GenesisConfPath string) error {
 	return bApi.eventService.Publish("block.committed", commitEvent)
 }
 
-/**
+/*
+*
 set state to 'committed'
 publish block committed event
 */
@@ -151,6 +211,12 @@ func (api BlockApi) CreateProposedBlock(txList []*blockchain.DefaultTransaction)
 	height := lastBlock.GetHeight() + 1
 	creator := api.publisherId
 
+	txList = api.filterCommittedTransactions(txList)
+	txList = filterExpiredTransactions(txList, time.Now(), height)
+	if len(txList) == 0 {
+		return blockchain.DefaultBlock{}, ErrNoTransaction
+	}
+
 	block, err := blockchain.CreateProposedBlock(prevSeal, height, txList, creator)
 	if err != nil {
 		return blockchain.DefaultBlock{}, err
@@ -159,6 +225,46 @@ func (api BlockApi) CreateProposedBlock(txList []*blockchain.DefaultTransaction)
 	return block, nil
 }
 
+// members reject a proposed block which contains a committed transaction,
+// so a resubmitted transaction is left out of the block instead of failing the whole round
+func (api BlockApi) filterCommittedTransactions(txList []*blockchain.DefaultTransaction) []*blockchain.DefaultTransaction {
+	filtered := make([]*blockchain.DefaultTransaction, 0)
+	txIds := make(map[string]bool)
+
+	for _, tx := range txList {
+		if txIds[tx.ID] {
+			continue
+		}
+		txIds[tx.ID] = true
+
+		committedBlock, err := api.blockRepository.FindByTxID(tx.ID)
+		if err == nil && !committedBlock.IsEmpty() {
+			iLogger.Infof(nil, "[Blockchain] Leave out committed transaction - ID: [%s]", tx.ID)
+			continue
+		}
+
+		filtered = append(filtered, tx)
+	}
+
+	return filtered
+}
+
+// members reject a block which contains an expired transaction, so they are left out of the proposal
+func filterExpiredTransactions(txList []*blockchain.DefaultTransaction, now time.Time, height uint64) []*blockchain.DefaultTransaction {
+	filtered := make([]*blockchain.DefaultTransaction, 0)
+
+	for _, tx := range txList {
+		if tx.IsExpired(now, height) {
+			iLogger.Infof(nil, "[Blockchain] Leave out expired transaction - ID: [%s]", tx.ID)
+			continue
+		}
+
+		filtered = append(filtered, tx)
+	}
+
+	return filtered
+}
+
 func createBlockCommittedEvent(block blockchain.DefaultBlock) (event.BlockCommitted, error) {
 
 	txList := blockchain.ConvBackFromTransactionList(block.TxList)
@@ -198,6 +198,9 @@ func TestBlockApi_CreateProposedBlock(t *testing.T) {
 	blockRepo.FindLastFunc = func() (blockchain.DefaultBlock, error) {
 		return *lastBlock, nil
 	}
+	blockRepo.FindByTxIDFunc = func(txID string) (blockchain.DefaultBlock, error) {
+		return blockchain.DefaultBlock{}, nil
+	}
 
 	eventService := mock.EventService{}
 	blockPool := mem.NewBlockPool()
@@ -216,6 +219,48 @@ func TestBlockApi_CreateProposedBlock(t *testing.T) {
 	assert.Equal(t, uint64(2), block.GetHeight())
 }
 
+func TestBlockApi_CreateProposedBlock_LeavesOutCommittedTransactions(t *testing.T) {
+	// given
+	publisherID := "zf"
+
+	lastBlock := mock.GetNewBlock([]byte("prevSeal"), 1)
+	txList := mock.GetTxList(time.Now())
+	committedTxID := txList[0].ID
+
+	blockRepo := mock.BlockRepository{}
+	blockRepo.FindLastFunc = func() (blockchain.DefaultBlock, error) {
+		return *lastBlock, nil
+	}
+	blockRepo.FindByTxIDFunc = func(txID string) (blockchain.DefaultBlock, error) {
+		if txID == committedTxID {
+			return *lastBlock, nil
+		}
+		return blockchain.DefaultBlock{}, nil
+	}
+
+	eventService := mock.EventService{}
+	blockPool := mem.NewBlockPool()
+
+	blockApi, err := api.NewBlockApi(publisherID, blockRepo, eventService, blockPool)
+	assert.NoError(t, err)
+
+	// when
+	block, err := blockApi.CreateProposedBlock(txList)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, len(txList)-1, len(block.GetTxList()))
+	for _, tx := range block.GetTxList() {
+		assert.NotEqual(t, committedTxID, tx.GetID())
+	}
+
+	// when
+	_, err = blockApi.CreateProposedBlock(txList[:1])
+
+	// then
+	assert.Equal(t, api.ErrNoTransaction, err)
+}
+
 func TestBlockApi_StageBlock(t *testing.T) {
 	// when
 	block := &blockchain.DefaultBlock{
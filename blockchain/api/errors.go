@@ -22,3 +22,4 @@ var ErrSaveBlock = errors.New("Error in saving block")
 var ErrCreateEvent = errors.New("Error in creating event")
 var ErrGetLastBlock = errors.New("Error in getting last block")
 var ErrUndefinedConsensusType = errors.New("Error in consensus type")
+var ErrNoTransaction = errors.New("No transaction to propose")
@@ -31,9 +31,10 @@ type SyncApi struct {
 	eventService        blockchain.EventService
 	queryService        blockchain.QueryService
 	blockPool           blockchain.BlockPool
+	finalityVerifier    blockchain.FinalityVerifier
 }
 
-func NewSyncApi(publisherId string, blockRepository blockchain.BlockRepository, syncStateRepository blockchain.SyncStateRepository, eventService blockchain.EventService, queryService blockchain.QueryService, blockPool blockchain.BlockPool) (SyncApi, error) {
+func NewSyncApi(publisherId string, blockRepository blockchain.BlockRepository, syncStateRepository blockchain.SyncStateRepository, eventService blockchain.EventService, queryService blockchain.QueryService, blockPool blockchain.BlockPool, finalityVerifier blockchain.FinalityVerifier) (SyncApi, error) {
 	return SyncApi{
 		publisherId:         publisherId,
 		blockRepository:     blockRepository,
@@ -41,6 +42,7 @@ func NewSyncApi(publisherId string, blockRepository blockchain.BlockRepository,
 		eventService:        eventService,
 		queryService:        queryService,
 		blockPool:           blockPool,
+		finalityVerifier:    finalityVerifier,
 	}, nil
 }
 
@@ -78,6 +80,16 @@ func (sApi SyncApi) Synchronize(peer blockchain.Peer) error {
 	return nil
 }
 
+// CatchUp synchronizes only when the peer is ahead.
+// An observer is not a recipient of the consensus, so it follows the chain of the leader this way
+func (sApi SyncApi) CatchUp(peer blockchain.Peer) error {
+	if sApi.isSynced(peer) {
+		return nil
+	}
+
+	return sApi.Synchronize(peer)
+}
+
 func (sApi SyncApi) isSynced(peer blockchain.Peer) bool {
 
 	// If nil peer is given(when i'm the first node of p2p network) : Synced
@@ -118,6 +130,10 @@ func (sApi SyncApi) syncWithPeer(peer blockchain.Peer) error {
 
 	lastHeight := lastBlock.GetHeight()
 
+	if err := sApi.replayCommittedBlocks(); err != nil {
+		return err
+	}
+
 	return sApi.construct(peer, standardHeight, lastHeight)
 
 }
@@ -132,6 +148,11 @@ func (sApi SyncApi) construct(peer blockchain.Peer, standardHeight blockchain.Bl
 			return err
 		}
 
+		if err := sApi.finalityVerifier.Verify(retrievedBlock); err != nil {
+			iLogger.Errorf(nil, "[Blockchain] Block is not finalized - Height: [%d], Err: [%s]", retrievedBlock.Height, err)
+			return err
+		}
+
 		err = sApi.commitBlock(retrievedBlock)
 		if err != nil {
 			return err
@@ -143,6 +164,20 @@ func (sApi SyncApi) construct(peer blockchain.Peer, standardHeight blockchain.Bl
 	return nil
 }
 
+// replayCommittedBlocks brings the finality verifier up to the validator set of the last committed block
+func (sApi SyncApi) replayCommittedBlocks() error {
+	blocks, err := sApi.blockRepository.FindAll()
+	if err != nil {
+		return err
+	}
+
+	for _, block := range blocks {
+		sApi.finalityVerifier.Apply(block)
+	}
+
+	return nil
+}
+
 func setTargetHeight(lastHeight blockchain.BlockHeight) blockchain.BlockHeight {
 	return lastHeight + 1
 }
@@ -159,6 +194,8 @@ func (sApi SyncApi) commitBlock(block blockchain.DefaultBlock) error {
 		return ErrSaveBlock
 	}
 
+	sApi.finalityVerifier.Apply(block)
+
 	// publish
 	commitEvent, err := createBlockCommittedEvent(block)
 	if err != nil {
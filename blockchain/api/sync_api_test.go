@@ -107,7 +107,7 @@ func TestSyncApi_Synchronize_NotSynced_BlockPool_Has_Shorter_Heights(t *testing.
 	blockPool.Add(*block1)
 	blockPool.Add(*block2)
 
-	sApi, err := api.NewSyncApi(publisherID, br, ssr, eventService, queryService, blockPool)
+	sApi, err := api.NewSyncApi(publisherID, br, ssr, eventService, queryService, blockPool, blockchain.NoFinalityVerifier{})
 	assert.NoError(t, err)
 
 	//when
@@ -125,6 +125,56 @@ func TestSyncApi_Synchronize_NotSynced_BlockPool_Has_Shorter_Heights(t *testing.
 	wg.Wait()
 }
 
+// with the default certificate finality a node syncs from genesis even when the genesis block has no validators
+func TestSyncApi_Synchronize_CertificateFinality_From_Genesis(t *testing.T) {
+	// given
+	committed := make([]event.BlockCommitted, 0)
+	eventService := mock.EventService{}
+	eventService.PublishFunc = func(topic string, e interface{}) error {
+		committed = append(committed, e.(event.BlockCommitted))
+		return nil
+	}
+
+	dbPath := "./.db"
+	br, err := repo.NewBlockRepository(dbPath)
+	assert.Equal(t, nil, err)
+
+	defer func() {
+		br.Close()
+		os.RemoveAll(dbPath)
+	}()
+
+	validatorSet := mock.ValidatorSet{}
+	validatorSet.GetValidatorsFunc = func() []string {
+		return nil
+	}
+	validatorSet.CountTransactionFunc = func(voter string, function string, args []string, height uint64) error {
+		return nil
+	}
+	validatorSet.AdvanceFunc = func(height uint64) {}
+
+	signatureVerifier := common.NewECDSAVerifier(func(pubKey []byte) (string, error) {
+		return "", nil
+	})
+	finalityVerifier := blockchain.NewCertificateVerifier(validatorSet, signatureVerifier)
+
+	br.AddBlock(block1)
+
+	sApi, err := api.NewSyncApi("junksound", br, mem.NewSyncStateRepository(), eventService, getQueryService(peerForSync), mem.NewBlockPool(), finalityVerifier)
+	assert.NoError(t, err)
+
+	// when
+	err = sApi.Synchronize(peerForSync)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(committed))
+
+	lastBlock, err := br.FindLast()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), lastBlock.Height)
+}
+
 func TestSyncApi_Synchronize_NotSynced_BlockPool_Has_Target_Heights_ThreeBlocks(t *testing.T) {
 	var wg sync.WaitGroup
 	wg.Add(5)
@@ -165,7 +215,7 @@ func TestSyncApi_Synchronize_NotSynced_BlockPool_Has_Target_Heights_ThreeBlocks(
 	blockPool.Add(*block5)
 	blockPool.Add(*block6)
 
-	sApi, err := api.NewSyncApi(publisherID, br, ssr, eventService, queryService, blockPool)
+	sApi, err := api.NewSyncApi(publisherID, br, ssr, eventService, queryService, blockPool, blockchain.NoFinalityVerifier{})
 	assert.NoError(t, err)
 
 	//when
@@ -223,7 +273,7 @@ func TestSyncApi_Synchronize_NotSynced_BlockPool_Has_Target_Heights_TwoBlocks_On
 	blockPool.Add(*block5)
 	blockPool.Add(*block7)
 
-	sApi, err := api.NewSyncApi(publisherID, br, ssr, eventService, queryService, blockPool)
+	sApi, err := api.NewSyncApi(publisherID, br, ssr, eventService, queryService, blockPool, blockchain.NoFinalityVerifier{})
 	assert.NoError(t, err)
 
 	//when
@@ -280,7 +330,7 @@ func TestSyncApi_Synchronize_NotSynced_BlockPool_Has_Higher_Heights(t *testing.T
 	blockPool.Add(*block6)
 	blockPool.Add(*block7)
 
-	sApi, err := api.NewSyncApi(publisherID, br, ssr, eventService, queryService, blockPool)
+	sApi, err := api.NewSyncApi(publisherID, br, ssr, eventService, queryService, blockPool, blockchain.NoFinalityVerifier{})
 	assert.NoError(t, err)
 
 	//when
@@ -340,7 +390,7 @@ func TestSyncApi_Synchronize_NotSynced_BlockPool_Has_Shorter_Target_Higher_Heigh
 	blockPool.Add(*block6)
 	blockPool.Add(*block7)
 
-	sApi, err := api.NewSyncApi(publisherID, br, ssr, eventService, queryService, blockPool)
+	sApi, err := api.NewSyncApi(publisherID, br, ssr, eventService, queryService, blockPool, blockchain.NoFinalityVerifier{})
 	assert.NoError(t, err)
 
 	//when
@@ -399,7 +449,7 @@ func TestSyncApi_Synchronize_Synced_BlockPool_Has_NoTarget_Heights(t *testing.T)
 	blockPool.Add(*block1)
 	blockPool.Add(*block2)
 
-	sApi, err := api.NewSyncApi(publisherID, br, ssr, eventService, queryService, blockPool)
+	sApi, err := api.NewSyncApi(publisherID, br, ssr, eventService, queryService, blockPool, blockchain.NoFinalityVerifier{})
 	assert.NoError(t, err)
 
 	//when
@@ -458,7 +508,7 @@ func TestSyncApi_Synchronize_Synced_BlockPool_Has_Target_Heights(t *testing.T) {
 	blockPool.Add(*block4)
 	blockPool.Add(*block5)
 
-	sApi, err := api.NewSyncApi(publisherID, br, ssr, eventService, queryService, blockPool)
+	sApi, err := api.NewSyncApi(publisherID, br, ssr, eventService, queryService, blockPool, blockchain.NoFinalityVerifier{})
 	assert.NoError(t, err)
 
 	//when
@@ -517,7 +567,7 @@ func TestSyncApi_Synchronize_When_NoPeer_In_Network(t *testing.T) {
 	blockPool.Add(*block4)
 	blockPool.Add(*block5)
 
-	sApi, err := api.NewSyncApi(publisherID, br, ssr, eventService, queryService, blockPool)
+	sApi, err := api.NewSyncApi(publisherID, br, ssr, eventService, queryService, blockPool, blockchain.NoFinalityVerifier{})
 	assert.NoError(t, err)
 
 	//when
@@ -567,7 +617,7 @@ func TestSyncApi_CommitStagedBlocks_Drop_Blocks_From_BlockPool(t *testing.T) {
 
 	ssr := mem.NewSyncStateRepository()
 
-	syncApi, err := api.NewSyncApi(publisherId, blockRepository, ssr, eventService, queryService, blockPool)
+	syncApi, err := api.NewSyncApi(publisherId, blockRepository, ssr, eventService, queryService, blockPool, blockchain.NoFinalityVerifier{})
 	assert.NoError(t, err)
 
 	// when
@@ -617,7 +667,7 @@ func TestSyncApi_CommitStagedBlocks_When_BlockPool_Has_Higher_Height_Block(t *te
 
 	ssr := mem.NewSyncStateRepository()
 
-	syncApi, err := api.NewSyncApi(publisherId, blockRepository, ssr, eventService, queryService, blockPool)
+	syncApi, err := api.NewSyncApi(publisherId, blockRepository, ssr, eventService, queryService, blockPool, blockchain.NoFinalityVerifier{})
 	assert.NoError(t, err)
 
 	// when
@@ -40,14 +40,15 @@ const (
 )
 
 type DefaultBlock struct {
-	Seal      []byte
-	PrevSeal  []byte
-	Height    uint64
-	TxList    []*DefaultTransaction
-	TxSeal    [][]byte
-	Timestamp time.Time
-	Creator   string
-	State     BlockState
+	Seal        []byte
+	PrevSeal    []byte
+	Height      uint64
+	TxList      []*DefaultTransaction
+	TxSeal      [][]byte
+	Timestamp   time.Time
+	Creator     string
+	State       BlockState
+	Certificate []byte
 }
 
 func (block *DefaultBlock) SetSeal(seal []byte) {
@@ -92,6 +93,10 @@ func (block *DefaultBlock) SetState(state BlockState) {
 	block.State = state
 }
 
+func (block *DefaultBlock) SetCertificate(certificate []byte) {
+	block.Certificate = certificate
+}
+
 func (block *DefaultBlock) GetSeal() []byte {
 	return block.Seal
 }
@@ -128,6 +133,10 @@ func (block *DefaultBlock) GetState() BlockState {
 	return block.State
 }
 
+func (block *DefaultBlock) GetCertificate() []byte {
+	return block.Certificate
+}
+
 // TODO: Write test case
 func (block *DefaultBlock) Serialize() ([]byte, error) {
 	data, err := json.Marshal(block)
@@ -172,6 +181,7 @@ type BlockRepository interface {
 	FindLast() (DefaultBlock, error)
 	FindByHeight(height BlockHeight) (DefaultBlock, error)
 	FindBySeal(seal []byte) (DefaultBlock, error)
+	FindByTxID(txID string) (DefaultBlock, error)
 	FindAll() ([]DefaultBlock, error)
 }
 
@@ -22,6 +22,8 @@ import (
 	"time"
 
 	"encoding/json"
+
+	"github.com/it-chain/engine/common"
 )
 
 func CreateGenesisBlock(genesisconfFilePath string) (DefaultBlock, error) {
@@ -89,6 +91,38 @@ func setBlockWithConfig(filePath string, block *DefaultBlock) error {
 	block.SetCreator(GenesisConfig.Creator)
 	block.SetState(Created)
 
+	return setGenesisValidators(block, GenesisConfig.Validators, timeStamp)
+}
+
+// genesis validators are recorded as add validator transactions,
+// so every node rebuilds the same validator set from the ledger
+func setGenesisValidators(block *DefaultBlock, validators []string, timeStamp time.Time) error {
+
+	if len(validators) == 0 {
+		return nil
+	}
+
+	validator := DefaultValidator{}
+
+	for _, id := range validators {
+		block.PutTx(&DefaultTransaction{
+			ID:        "genesis-validator-" + id,
+			ICodeID:   common.GovernanceICodeID,
+			PeerID:    block.GetCreator(),
+			Timestamp: timeStamp,
+			Function:  common.AddValidatorFunction,
+			Args:      []string{id, "0"},
+		})
+	}
+
+	txSeal, err := validator.BuildTxSeal(ConvertTxType(block.TxList))
+
+	if err != nil {
+		return err
+	}
+
+	block.SetTxSeal(txSeal)
+
 	return nil
 }
 
@@ -98,6 +132,7 @@ type GenesisConfig struct {
 	Height       int
 	TimeStamp    string
 	Creator      string
+	Validators   []string
 }
 
 func CreateProposedBlock(prevSeal []byte, height uint64, txList []*DefaultTransaction, Creator string) (DefaultBlock, error) {
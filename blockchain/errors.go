@@ -23,3 +23,16 @@ var ErrSetConfig = errors.New("error when get Config")
 var ErrDecodingEmptyBlock = errors.New("Empty Block decoding failed")
 var ErrBuildingTxSeal = errors.New("Error in building tx seal")
 var ErrBuildingSeal = errors.New("Error in building seal")
+var ErrEmptyCertificate = errors.New("Block has no commit certificate")
+var ErrDecodingCertificate = errors.New("Commit certificate decoding failed")
+var ErrInvalidHeight = errors.New("Height does not follow the last block")
+var ErrInvalidPrevSeal = errors.New("Previous seal does not match the last block")
+var ErrInvalidSeal = errors.New("Seal does not match the block")
+var ErrInvalidTxSeal = errors.New("Tx seal does not match the transactions")
+var ErrMalformedTransaction = errors.New("Transaction is malformed")
+var ErrDuplicatedTransaction = errors.New("Transaction is duplicated in the block")
+var ErrTransactionAlreadyCommitted = errors.New("Transaction is already committed")
+var ErrInvalidTimestamp = errors.New("Timestamp drifts from the local clock or goes before the last block")
+var ErrExpiredTransaction = errors.New("Transaction is expired")
+var ErrProposedSealMismatch = errors.New("Seal of proposed block does not match its body")
+var ErrProposedHeightMismatch = errors.New("Height of proposed block does not match its body")
@@ -0,0 +1,113 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package blockchain
+
+import (
+	"encoding/json"
+
+	"github.com/it-chain/engine/common"
+)
+
+// FinalityVerifier checks that a block downloaded from a peer was finalized by the validators,
+// so a syncing node does not have to trust the peer it syncs from
+type FinalityVerifier interface {
+	Verify(block DefaultBlock) error
+	Apply(block DefaultBlock)
+}
+
+// NoFinalityVerifier is used with the engines which do not issue commit certificates,
+// or when the node is configured to trust the peer it syncs from
+type NoFinalityVerifier struct{}
+
+func (NoFinalityVerifier) Verify(block DefaultBlock) error {
+	return nil
+}
+
+func (NoFinalityVerifier) Apply(block DefaultBlock) {}
+
+// ValidatorSet follows the validators voted by the governance transactions on the ledger.
+// It is provided by the consensus engine which issues the commit certificates
+type ValidatorSet interface {
+	GetValidators() []string
+
+	// CountTransaction counts a governance transaction committed in the block of the height
+	CountTransaction(voter string, function string, args []string, height uint64) error
+
+	// Advance applies the changes effective up to the height
+	Advance(height uint64)
+}
+
+// CertificateVerifier follows the validator set on the ledger
+// and checks the commit certificate of each block against it
+type CertificateVerifier struct {
+	validatorSet      ValidatorSet
+	signatureVerifier common.SignatureVerifier
+	appliedHeight     uint64
+	applied           bool
+}
+
+func NewCertificateVerifier(validatorSet ValidatorSet, signatureVerifier common.SignatureVerifier) *CertificateVerifier {
+	return &CertificateVerifier{
+		validatorSet:      validatorSet,
+		signatureVerifier: signatureVerifier,
+	}
+}
+
+// Verify checks the certificate against the validators of the block height.
+// The validator set is written in the genesis block, a chain without it has the parliament follow the connected peers.
+// Then there is no validator set to check against and the peer is trusted as with NoFinalityVerifier
+func (c *CertificateVerifier) Verify(block DefaultBlock) error {
+	validators := c.validatorSet.GetValidators()
+	if len(validators) == 0 {
+		return nil
+	}
+
+	if len(block.Certificate) == 0 {
+		return ErrEmptyCertificate
+	}
+
+	certificate := common.Certificate{}
+	if err := json.Unmarshal(block.Certificate, &certificate); err != nil {
+		return ErrDecodingCertificate
+	}
+
+	return certificate.Verify(block.Seal, block.Height, validators, c.signatureVerifier)
+}
+
+// Apply counts the governance transactions of a committed block, blocks already applied are skipped
+func (c *CertificateVerifier) Apply(block DefaultBlock) {
+	if c.applied && block.Height <= c.appliedHeight {
+		return
+	}
+
+	for _, tx := range block.TxList {
+		if !common.IsGovernanceTransaction(tx.ICodeID) {
+			continue
+		}
+
+		voter, err := common.GovernanceVoter(c.signatureVerifier, block.Height, tx.Signature, common.TransactionSigningData(tx.Jsonrpc, tx.ICodeID, tx.Function, tx.Args, tx.IdempotencyKey, FromDeadline(tx.Deadline), tx.MaxHeight))
+		if err != nil {
+			continue
+		}
+
+		c.validatorSet.CountTransaction(voter, tx.Function, tx.Args, block.Height)
+	}
+	c.validatorSet.Advance(block.Height + 1)
+
+	c.appliedHeight = block.Height
+	c.applied = true
+}
@@ -0,0 +1,137 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package blockchain_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/it-chain/engine/blockchain"
+	"github.com/it-chain/engine/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// validatorSet adds the validator of every governance transaction
+type validatorSet struct {
+	validators []string
+	voters     []string
+}
+
+func (v *validatorSet) GetValidators() []string {
+	return v.validators
+}
+
+func (v *validatorSet) CountTransaction(voter string, function string, args []string, height uint64) error {
+	v.validators = append(v.validators, args[0])
+	v.voters = append(v.voters, voter)
+	return nil
+}
+
+func (v *validatorSet) Advance(height uint64) {}
+
+func TestCertificateVerifier_Verify(t *testing.T) {
+	// given
+	signers := make(map[string]string)
+	signatureVerifier := common.NewECDSAVerifier(func(pubKey []byte) (string, error) {
+		return signers[string(pubKey)], nil
+	})
+	certificateVerifier := blockchain.NewCertificateVerifier(&validatorSet{}, signatureVerifier)
+
+	genesisBlock := blockchain.DefaultBlock{Height: 0}
+	for _, id := range []string{"1", "2", "3"} {
+		genesisBlock.PutTx(&blockchain.DefaultTransaction{
+			ID:       "genesis-validator-" + id,
+			ICodeID:  common.GovernanceICodeID,
+			PeerID:   "1",
+			Function: common.AddValidatorFunction,
+			Args:     []string{id, "0"},
+		})
+	}
+
+	block := blockchain.DefaultBlock{Seal: []byte("seal"), Height: 1}
+
+	// then: without validators in the genesis block the peer is trusted
+	assert.NoError(t, certificateVerifier.Verify(block))
+
+	// when
+	certificateVerifier.Apply(genesisBlock)
+
+	// then
+	assert.Equal(t, blockchain.ErrEmptyCertificate, certificateVerifier.Verify(block))
+
+	// when
+	signatures := make([]common.Signature, 0)
+	for _, id := range []string{"1", "2", "3"} {
+		priKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		signature, err := common.NewECDSASigner(id, priKey).Sign(common.VoteSigningData(common.PreCommitVote, "state1", block.Height, block.Seal))
+		assert.NoError(t, err)
+
+		signers[string(signature.PubKey)] = id
+		signatures = append(signatures, signature)
+	}
+
+	certificate, _ := common.Serialize(common.NewCertificate("state1", block.Height, block.Seal, signatures[:2]))
+	block.SetCertificate(certificate)
+
+	// then
+	assert.Equal(t, common.ErrNotEnoughSignatures, certificateVerifier.Verify(block))
+
+	// when
+	certificate, _ = common.Serialize(common.NewCertificate("state1", block.Height, block.Seal, signatures))
+	block.SetCertificate(certificate)
+
+	// then
+	assert.NoError(t, certificateVerifier.Verify(block))
+}
+
+func TestCertificateVerifier_Apply(t *testing.T) {
+	// given
+	priKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	signer := common.NewECDSASigner("1", priKey)
+	signatureVerifier := common.NewECDSAVerifier(func(pubKey []byte) (string, error) {
+		return "1", nil
+	})
+
+	validators := &validatorSet{}
+	certificateVerifier := blockchain.NewCertificateVerifier(validators, signatureVerifier)
+
+	unsigned := &blockchain.DefaultTransaction{
+		ID:       "tx01",
+		ICodeID:  common.GovernanceICodeID,
+		PeerID:   "2",
+		Jsonrpc:  "2.0",
+		Function: common.AddValidatorFunction,
+		Args:     []string{"3", "5"},
+	}
+
+	signed := *unsigned
+	signed.ID = "tx02"
+	signed.IdempotencyKey = "key02"
+	signed.Signature, _ = common.SignTransaction(signer, common.TransactionSigningData(signed.Jsonrpc, signed.ICodeID, signed.Function, signed.Args, signed.IdempotencyKey, blockchain.FromDeadline(signed.Deadline), signed.MaxHeight))
+
+	block := blockchain.DefaultBlock{Height: 1}
+	block.PutTx(unsigned)
+	block.PutTx(&signed)
+
+	// when
+	certificateVerifier.Apply(block)
+
+	// then the vote is counted for the signer, not for the peer which created the transaction
+	assert.Equal(t, []string{"1"}, validators.voters)
+}
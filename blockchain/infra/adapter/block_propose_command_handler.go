@@ -24,18 +24,18 @@ import (
 
 type BlockProposeApi interface {
 	CreateProposedBlock(txList []*blockchain.DefaultTransaction) (blockchain.DefaultBlock, error)
-	ConsentBlock(consensusType string, block blockchain.DefaultBlock) error
+	ConsentBlock(engine blockchain.ConsensusEngine, block blockchain.DefaultBlock) error
 }
 
 type BlockProposeCommandHandler struct {
-	blockApi   BlockProposeApi
-	engineMode string
+	blockApi BlockProposeApi
+	engine   blockchain.ConsensusEngine
 }
 
-func NewBlockProposeCommandHandler(blockApi BlockProposeApi, engineMode string) *BlockProposeCommandHandler {
+func NewBlockProposeCommandHandler(blockApi BlockProposeApi, engine blockchain.ConsensusEngine) *BlockProposeCommandHandler {
 	return &BlockProposeCommandHandler{
-		blockApi:   blockApi,
-		engineMode: engineMode,
+		blockApi: blockApi,
+		engine:   engine,
 	}
 }
 
@@ -55,7 +55,7 @@ func (h *BlockProposeCommandHandler) HandleProposeBlockCommand(command command.P
 		return err
 	}
 
-	if err := h.blockApi.ConsentBlock(h.engineMode, proposedBlock); err != nil {
+	if err := h.blockApi.ConsentBlock(h.engine, proposedBlock); err != nil {
 		return err
 	}
 
@@ -83,13 +83,16 @@ func getBackTxList(txList []command.Tx) []*blockchain.DefaultTransaction {
 
 func getBackTx(tx command.Tx) *blockchain.DefaultTransaction {
 	return &blockchain.DefaultTransaction{
-		ID:        tx.ID,
-		ICodeID:   tx.ICodeID,
-		PeerID:    tx.PeerID,
-		Timestamp: tx.TimeStamp,
-		Jsonrpc:   tx.Jsonrpc,
-		Function:  tx.Function,
-		Args:      tx.Args,
-		Signature: tx.Signature,
+		ID:             tx.ID,
+		ICodeID:        tx.ICodeID,
+		PeerID:         tx.PeerID,
+		Timestamp:      tx.TimeStamp,
+		Jsonrpc:        tx.Jsonrpc,
+		Function:       tx.Function,
+		Args:           tx.Args,
+		Signature:      tx.Signature,
+		Deadline:       blockchain.ToDeadline(tx.Deadline),
+		MaxHeight:      tx.MaxHeight,
+		IdempotencyKey: tx.IdempotencyKey,
 	}
 }
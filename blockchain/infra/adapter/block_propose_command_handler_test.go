@@ -35,6 +35,7 @@ import (
 	"github.com/it-chain/engine/common"
 	"github.com/it-chain/engine/common/event"
 	"github.com/it-chain/engine/common/rabbitmq/pubsub"
+	"github.com/it-chain/engine/consensus"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -78,7 +79,7 @@ func TestBlockProposeCommandHandler_HandleProposeBlockCommand_Solo(t *testing.T)
 	bApi, err := api.NewBlockApi(publisherID, br, eventService, blockPool)
 	assert.NoError(t, err)
 
-	commandHandler := adapter.NewBlockProposeCommandHandler(bApi, "solo")
+	commandHandler := adapter.NewBlockProposeCommandHandler(bApi, consensus.NewSoloEngine())
 
 	//when
 	err = commandHandler.HandleProposeBlockCommand(command.ProposeBlock{TxList: nil})
@@ -177,7 +178,7 @@ func TestBlockProposeCommandHandler_HandleProposeBlockCommand_PBFT_OneTransactio
 	api, err := api.NewBlockApi(publisherID, blockRepository, eventService, blockPool)
 	assert.NoError(t, err)
 
-	commandHandler := adapter.NewBlockProposeCommandHandler(api, "pbft")
+	commandHandler := adapter.NewBlockProposeCommandHandler(api, consensus.NewPbftEngine(eventService))
 
 	//when
 	err = commandHandler.HandleProposeBlockCommand(command.ProposeBlock{
@@ -235,7 +236,7 @@ func TestBlockProposeCommandHandler_HandleProposeBlockCommand_PBFT_TwoTransactio
 	api, err := api.NewBlockApi(publisherID, blockRepository, eventService, blockPool)
 	assert.NoError(t, err)
 
-	commandHandler := adapter.NewBlockProposeCommandHandler(api, "pbft")
+	commandHandler := adapter.NewBlockProposeCommandHandler(api, consensus.NewPbftEngine(eventService))
 
 	//when
 	err = commandHandler.HandleProposeBlockCommand(command.ProposeBlock{
@@ -290,7 +291,7 @@ func TestBlockProposeCommandHandler_HandleProposeBlockCommand_PBFT_ExceptionCase
 	api, err := api.NewBlockApi(publisherID, blockRepository, eventService, blockPool)
 	assert.NoError(t, err)
 
-	commandHandler := adapter.NewBlockProposeCommandHandler(api, "pbft")
+	commandHandler := adapter.NewBlockProposeCommandHandler(api, consensus.NewPbftEngine(eventService))
 
 	//when
 	err = commandHandler.HandleProposeBlockCommand(command.ProposeBlock{TxList: nil})
@@ -307,7 +308,7 @@ func TestBlockProposeCommandHandler_HandleProposeBlockCommand_PBFT_ExceptionCase
 	err = commandHandler.HandleProposeBlockCommand(command.ProposeBlock{
 		TxList: []command.Tx{
 			{
-				ID:        "tx01",
+				ID:        "proposedTx",
 				ICodeID:   "ICodeID",
 				PeerID:    "2",
 				TimeStamp: time.Now().Round(0),
@@ -52,6 +52,7 @@ func (c *ConsensusEventHandler) HandleConsensusFinishedEvent(event event.Consens
 	if receivedBlock.Seal == nil {
 		return ErrBlockSealNil
 	}
+	receivedBlock.SetCertificate(event.Certificate)
 
 	syncState := c.SyncStateRepository.Get()
 
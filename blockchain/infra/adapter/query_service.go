@@ -28,6 +28,7 @@ import (
 type PeerQueryApi interface {
 	GetAllPeerList() []api_gateway.Peer
 	GetPeerByID(connectionId string) (api_gateway.Peer, error)
+	GetLeader() (api_gateway.Peer, error)
 }
 
 type BlockAdapter interface {
@@ -62,6 +63,16 @@ func (s QuerySerivce) GetRandomPeer() (blockchain.Peer, error) {
 	return randomPeer, nil
 }
 
+func (s QuerySerivce) GetLeaderPeer() (blockchain.Peer, error) {
+
+	leader, err := s.peerQueryApi.GetLeader()
+	if err != nil {
+		return blockchain.Peer{}, err
+	}
+
+	return toPeerFromConnection(leader), nil
+}
+
 func (s QuerySerivce) GetLastBlockFromPeer(peer blockchain.Peer) (blockchain.DefaultBlock, error) {
 
 	block, err := s.blockAdapter.GetLastBlockFromPeer(peer)
@@ -110,3 +110,37 @@ func TestQuerySerivce_GetRandomPeer(t *testing.T) {
 	assert.Equal(t, _5thBlock, retrieved_5thBlock)
 
 }
+
+func TestQuerySerivce_GetLeaderPeer(t *testing.T) {
+
+	//given
+	peerQueryApi := mock.PeerQueryApi{}
+	peerQueryApi.GetLeaderFunc = func() (api_gateway.Peer, error) {
+		return api_gateway.Peer{
+			ID:                "leader",
+			ApiGatewayAddress: "address1",
+			Role:              api_gateway.Leader,
+		}, nil
+	}
+
+	queryService := adapter.NewQueryService(mock.BlockAdapter{}, peerQueryApi)
+
+	//when
+	leader, err := queryService.GetLeaderPeer()
+
+	//then
+	assert.NoError(t, err)
+	assert.Equal(t, blockchain.Peer{Id: "leader", ApiGatewayAddress: "address1"}, leader)
+
+	//given
+	peerQueryApi.GetLeaderFunc = func() (api_gateway.Peer, error) {
+		return api_gateway.Peer{}, api_gateway.ErrLeaderDoesNotExists
+	}
+	queryService = adapter.NewQueryService(mock.BlockAdapter{}, peerQueryApi)
+
+	//when
+	_, err = queryService.GetLeaderPeer()
+
+	//then
+	assert.Equal(t, api_gateway.ErrLeaderDoesNotExists, err)
+}
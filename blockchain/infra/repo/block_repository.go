@@ -97,6 +97,20 @@ func (br *BlockRepository) FindBySeal(seal []byte) (blockchain.DefaultBlock, err
 	return *block, nil
 }
 
+func (br *BlockRepository) FindByTxID(txID string) (blockchain.DefaultBlock, error) {
+	br.mux.Lock()
+	defer br.mux.Unlock()
+
+	block := &blockchain.DefaultBlock{}
+
+	err := br.BlockStorageManager.GetBlockByTxID(block, txID)
+	if err != nil {
+		return blockchain.DefaultBlock{}, ErrGetBlock
+	}
+
+	return *block, nil
+}
+
 func (br *BlockRepository) FindAll() ([]blockchain.DefaultBlock, error) {
 	br.mux.Lock()
 	defer br.mux.Unlock()
@@ -0,0 +1,109 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package blockchain
+
+import (
+	"bytes"
+	"time"
+)
+
+// MaxTimestampDrift is how far the timestamp of a proposed block may differ from the local clock.
+// Transaction expiry is checked against the block timestamp, so the leader must not be able to pick it freely.
+const MaxTimestampDrift = 30 * time.Second
+
+// ValidateProposedBlock checks that a block proposed by the leader follows the last block of the local chain
+// and that its seals are built from its own contents
+func ValidateProposedBlock(block DefaultBlock, lastBlock DefaultBlock) error {
+	if block.GetHeight() != lastBlock.GetHeight()+1 {
+		return ErrInvalidHeight
+	}
+
+	if !bytes.Equal(block.GetPrevSeal(), lastBlock.GetSeal()) {
+		return ErrInvalidPrevSeal
+	}
+
+	if !isValidTimestamp(block.GetTimestamp(), lastBlock.GetTimestamp(), time.Now()) {
+		return ErrInvalidTimestamp
+	}
+
+	txIds := make(map[string]bool)
+	for _, tx := range block.TxList {
+		if tx == nil || tx.ID == "" || tx.ICodeID == "" || tx.Function == "" {
+			return ErrMalformedTransaction
+		}
+
+		if txIds[tx.ID] {
+			return ErrDuplicatedTransaction
+		}
+		txIds[tx.ID] = true
+
+		if tx.IsExpired(block.GetTimestamp(), block.GetHeight()) {
+			return ErrExpiredTransaction
+		}
+	}
+
+	validator := DefaultValidator{}
+
+	txSeal, err := validator.BuildTxSeal(block.GetTxList())
+	if err != nil {
+		return ErrInvalidTxSeal
+	}
+
+	if !isSameTxSeal(txSeal, block.GetTxSeal()) {
+		return ErrInvalidTxSeal
+	}
+
+	isValidSeal, err := validator.ValidateSeal(block.GetSeal(), &block)
+	if err != nil || !isValidSeal {
+		return ErrInvalidSeal
+	}
+
+	return nil
+}
+
+// isValidTimestamp checks that the timestamp does not go before the parent block
+// and stays within MaxTimestampDrift of the local clock
+func isValidTimestamp(timestamp time.Time, parentTimestamp time.Time, now time.Time) bool {
+	if timestamp.IsZero() {
+		return false
+	}
+
+	if !parentTimestamp.IsZero() && timestamp.Before(parentTimestamp) {
+		return false
+	}
+
+	drift := timestamp.Sub(now)
+	if drift < 0 {
+		drift = -drift
+	}
+
+	return drift <= MaxTimestampDrift
+}
+
+func isSameTxSeal(txSeal [][]byte, comparisonTxSeal [][]byte) bool {
+	if len(txSeal) != len(comparisonTxSeal) {
+		return false
+	}
+
+	for i := range txSeal {
+		if !bytes.Equal(txSeal[i], comparisonTxSeal[i]) {
+			return false
+		}
+	}
+
+	return true
+}
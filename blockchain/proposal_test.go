@@ -0,0 +1,179 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package blockchain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/it-chain/engine/blockchain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateProposedBlock(t *testing.T) {
+	// given
+	lastBlock := blockchain.DefaultBlock{Seal: []byte("last seal"), Height: 3}
+
+	newTx := func(id string) *blockchain.DefaultTransaction {
+		return &blockchain.DefaultTransaction{
+			ID:        id,
+			ICodeID:   "ICodeID",
+			PeerID:    "junksound",
+			Timestamp: time.Now().Round(0),
+			Jsonrpc:   "jsonRPC",
+			Function:  "invoke",
+			Args:      []string{"a"},
+		}
+	}
+
+	createBlock := func(prevSeal []byte, height uint64, txList []*blockchain.DefaultTransaction) blockchain.DefaultBlock {
+		block, err := blockchain.CreateProposedBlock(prevSeal, height, txList, "junksound")
+		assert.NoError(t, err)
+		return block
+	}
+
+	tamperedTxBlock := createBlock(lastBlock.Seal, 4, []*blockchain.DefaultTransaction{newTx("tx01")})
+	tamperedTxBlock.TxList[0].Args = []string{"b"}
+
+	tamperedSealBlock := createBlock(lastBlock.Seal, 4, []*blockchain.DefaultTransaction{newTx("tx01")})
+	tamperedSealBlock.Seal = append([]byte{}, tamperedSealBlock.Seal...)
+	tamperedSealBlock.Seal[0] ^= 0xff
+
+	tamperedTimestampBlock := createBlock(lastBlock.Seal, 4, []*blockchain.DefaultTransaction{newTx("tx01")})
+	tamperedTimestampBlock.Timestamp = tamperedTimestampBlock.Timestamp.Add(time.Second)
+
+	heightExpiredTx := newTx("tx01")
+	heightExpiredTx.MaxHeight = 3
+
+	deadlineExpiredTx := newTx("tx01")
+	deadlineExpiredTx.Deadline = blockchain.ToDeadline(time.Now().Add(-time.Minute))
+
+	tests := map[string]struct {
+		input blockchain.DefaultBlock
+		err   error
+	}{
+		"valid block": {
+			input: createBlock(lastBlock.Seal, 4, []*blockchain.DefaultTransaction{newTx("tx01"), newTx("tx02")}),
+			err:   nil,
+		},
+		"height does not follow": {
+			input: createBlock(lastBlock.Seal, 5, []*blockchain.DefaultTransaction{newTx("tx01")}),
+			err:   blockchain.ErrInvalidHeight,
+		},
+		"prev seal does not match": {
+			input: createBlock([]byte("other seal"), 4, []*blockchain.DefaultTransaction{newTx("tx01")}),
+			err:   blockchain.ErrInvalidPrevSeal,
+		},
+		"malformed transaction": {
+			input: createBlock(lastBlock.Seal, 4, []*blockchain.DefaultTransaction{newTx("")}),
+			err:   blockchain.ErrMalformedTransaction,
+		},
+		"duplicated transaction": {
+			input: createBlock(lastBlock.Seal, 4, []*blockchain.DefaultTransaction{newTx("tx01"), newTx("tx01")}),
+			err:   blockchain.ErrDuplicatedTransaction,
+		},
+		"tampered transaction": {
+			input: tamperedTxBlock,
+			err:   blockchain.ErrInvalidTxSeal,
+		},
+		"tampered seal": {
+			input: tamperedSealBlock,
+			err:   blockchain.ErrInvalidSeal,
+		},
+		"tampered timestamp": {
+			input: tamperedTimestampBlock,
+			err:   blockchain.ErrInvalidSeal,
+		},
+		"transaction passed max height": {
+			input: createBlock(lastBlock.Seal, 4, []*blockchain.DefaultTransaction{heightExpiredTx}),
+			err:   blockchain.ErrExpiredTransaction,
+		},
+		"transaction passed deadline": {
+			input: createBlock(lastBlock.Seal, 4, []*blockchain.DefaultTransaction{deadlineExpiredTx}),
+			err:   blockchain.ErrExpiredTransaction,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Logf("running test case %s", testName)
+
+		// when
+		err := blockchain.ValidateProposedBlock(test.input, lastBlock)
+
+		// then
+		assert.Equal(t, test.err, err)
+	}
+}
+
+func TestValidateProposedBlock_Timestamp(t *testing.T) {
+	// given
+	lastBlock := blockchain.DefaultBlock{Seal: []byte("last seal"), Height: 3, Timestamp: time.Now().Add(10 * time.Second).Round(0)}
+
+	createBlockAt := func(timestamp time.Time) blockchain.DefaultBlock {
+		tx := &blockchain.DefaultTransaction{
+			ID:        "tx01",
+			ICodeID:   "ICodeID",
+			PeerID:    "junksound",
+			Timestamp: time.Now().Round(0),
+			Jsonrpc:   "jsonRPC",
+			Function:  "invoke",
+			Args:      []string{"a"},
+		}
+
+		block, err := blockchain.CreateProposedBlock(lastBlock.Seal, 4, []*blockchain.DefaultTransaction{tx}, "junksound")
+		assert.NoError(t, err)
+
+		validator := blockchain.DefaultValidator{}
+		block.Timestamp = timestamp
+		block.Seal, err = validator.BuildSeal(timestamp, block.PrevSeal, block.TxSeal, block.Creator)
+		assert.NoError(t, err)
+
+		return block
+	}
+
+	tests := map[string]struct {
+		input blockchain.DefaultBlock
+		err   error
+	}{
+		"timestamp within drift": {
+			input: createBlockAt(time.Now().Add(blockchain.MaxTimestampDrift / 2).Round(0)),
+			err:   nil,
+		},
+		"timestamp ahead of local clock": {
+			input: createBlockAt(time.Now().Add(blockchain.MaxTimestampDrift + time.Minute).Round(0)),
+			err:   blockchain.ErrInvalidTimestamp,
+		},
+		"timestamp behind local clock": {
+			input: createBlockAt(time.Now().Add(-blockchain.MaxTimestampDrift - time.Minute).Round(0)),
+			err:   blockchain.ErrInvalidTimestamp,
+		},
+		"timestamp before last block": {
+			input: createBlockAt(time.Now().Round(0)),
+			err:   blockchain.ErrInvalidTimestamp,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Logf("running test case %s", testName)
+
+		// when
+		err := blockchain.ValidateProposedBlock(test.input, lastBlock)
+
+		// then
+		assert.Equal(t, test.err, err)
+	}
+}
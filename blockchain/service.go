@@ -16,6 +16,8 @@
 
 package blockchain
 
+import "github.com/it-chain/engine/common/command"
+
 type QueryService interface {
 	GetLastBlockFromPeer(peer Peer) (DefaultBlock, error)
 	GetBlockByHeightFromPeer(height BlockHeight, peer Peer) (DefaultBlock, error)
@@ -24,3 +26,12 @@ type QueryService interface {
 type EventService interface {
 	Publish(topic string, event interface{}) error
 }
+
+// ConsensusEngine is the consensus algorithm a proposed block is handed over to
+type ConsensusEngine interface {
+	Mode() string
+
+	// ConsentBlock returns true when the block is final on return and can be committed right away,
+	// false when the decision arrives later as a 'block.confirm' event
+	ConsentBlock(block command.StartConsensus) (bool, error)
+}
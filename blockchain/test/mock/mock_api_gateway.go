@@ -21,6 +21,7 @@ import "github.com/it-chain/engine/api_gateway"
 type PeerQueryApi struct {
 	GetAllPeerListFunc  func() []api_gateway.Peer
 	GetPeerByIDByIdFunc func(peerId string) (api_gateway.Peer, error)
+	GetLeaderFunc       func() (api_gateway.Peer, error)
 }
 
 func (q PeerQueryApi) GetAllPeerList() []api_gateway.Peer {
@@ -30,3 +31,7 @@ func (q PeerQueryApi) GetAllPeerList() []api_gateway.Peer {
 func (q PeerQueryApi) GetPeerByID(connectionId string) (api_gateway.Peer, error) {
 	return q.GetPeerByIDByIdFunc(connectionId)
 }
+
+func (q PeerQueryApi) GetLeader() (api_gateway.Peer, error) {
+	return q.GetLeaderFunc()
+}
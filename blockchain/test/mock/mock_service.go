@@ -42,6 +42,7 @@ type BlockRepository struct {
 	FindLastFunc     func() (blockchain.DefaultBlock, error)
 	FindByHeightFunc func(height blockchain.BlockHeight) (blockchain.DefaultBlock, error)
 	FindBySealFunc   func(seal []byte) (blockchain.DefaultBlock, error)
+	FindByTxIDFunc   func(txID string) (blockchain.DefaultBlock, error)
 	FindAllFunc      func() ([]blockchain.DefaultBlock, error)
 }
 
@@ -61,6 +62,10 @@ func (r BlockRepository) FindBySeal(seal []byte) (blockchain.DefaultBlock, error
 	return r.FindBySealFunc(seal)
 }
 
+func (r BlockRepository) FindByTxID(txID string) (blockchain.DefaultBlock, error) {
+	return r.FindByTxIDFunc(txID)
+}
+
 func (r BlockRepository) FindAll() ([]blockchain.DefaultBlock, error) {
 	return r.FindAllFunc()
 }
@@ -98,3 +103,21 @@ func (s QueryService) GetLastBlockFromPeer(peer blockchain.Peer) (blockchain.Def
 func (s QueryService) GetBlockByHeightFromPeer(height blockchain.BlockHeight, peer blockchain.Peer) (blockchain.DefaultBlock, error) {
 	return s.GetBlockByHeightFromPeerFunc(height, peer)
 }
+
+type ValidatorSet struct {
+	GetValidatorsFunc    func() []string
+	CountTransactionFunc func(voter string, function string, args []string, height uint64) error
+	AdvanceFunc          func(height uint64)
+}
+
+func (v ValidatorSet) GetValidators() []string {
+	return v.GetValidatorsFunc()
+}
+
+func (v ValidatorSet) CountTransaction(voter string, function string, args []string, height uint64) error {
+	return v.CountTransactionFunc(voter, function, args, height)
+}
+
+func (v ValidatorSet) Advance(height uint64) {
+	v.AdvanceFunc(height)
+}
@@ -41,6 +41,21 @@ type DefaultTransaction struct {
 	Function  string
 	Args      []string
 	Signature []byte
+	// optional expiry, the transaction can not be included in a block after the deadline or above the max height.
+	// They are left out of the seal when not set
+	Deadline  *time.Time `json:",omitempty"`
+	MaxHeight uint64     `json:",omitempty"`
+	// the key the submitter signed with the transaction, left out of the seal when not set
+	IdempotencyKey string `json:",omitempty"`
+}
+
+// IsExpired tells whether the transaction can not be included in a block of the height created at the time
+func (t *DefaultTransaction) IsExpired(timestamp time.Time, height uint64) bool {
+	if t.Deadline != nil && timestamp.After(*t.Deadline) {
+		return true
+	}
+
+	return t.MaxHeight != 0 && height > t.MaxHeight
 }
 
 // GetID 함수는 Transaction의 ID 값을 반환한다.
@@ -121,14 +136,17 @@ func ConvertToTransactionList(txList []event.Tx) []*DefaultTransaction {
 
 func convertToTransaction(tx event.Tx) *DefaultTransaction {
 	return &DefaultTransaction{
-		ID:        tx.ID,
-		ICodeID:   tx.ICodeID,
-		PeerID:    tx.PeerID,
-		Timestamp: tx.TimeStamp,
-		Jsonrpc:   tx.Jsonrpc,
-		Function:  tx.Function,
-		Args:      tx.Args,
-		Signature: tx.Signature,
+		ID:             tx.ID,
+		ICodeID:        tx.ICodeID,
+		PeerID:         tx.PeerID,
+		Timestamp:      tx.TimeStamp,
+		Jsonrpc:        tx.Jsonrpc,
+		Function:       tx.Function,
+		Args:           tx.Args,
+		Signature:      tx.Signature,
+		Deadline:       ToDeadline(tx.Deadline),
+		MaxHeight:      tx.MaxHeight,
+		IdempotencyKey: tx.IdempotencyKey,
 	}
 }
 
@@ -145,14 +163,17 @@ func ConvToCommandTxList(defaultTxList []*DefaultTransaction) []command.Tx {
 
 func convToCommandTx(defaultTx *DefaultTransaction) command.Tx {
 	return command.Tx{
-		ID:        defaultTx.ID,
-		ICodeID:   defaultTx.ICodeID,
-		PeerID:    defaultTx.PeerID,
-		TimeStamp: defaultTx.Timestamp,
-		Jsonrpc:   defaultTx.Jsonrpc,
-		Function:  defaultTx.Function,
-		Args:      defaultTx.Args,
-		Signature: defaultTx.Signature,
+		ID:             defaultTx.ID,
+		ICodeID:        defaultTx.ICodeID,
+		PeerID:         defaultTx.PeerID,
+		TimeStamp:      defaultTx.Timestamp,
+		Jsonrpc:        defaultTx.Jsonrpc,
+		Function:       defaultTx.Function,
+		Args:           defaultTx.Args,
+		Signature:      defaultTx.Signature,
+		Deadline:       FromDeadline(defaultTx.Deadline),
+		MaxHeight:      defaultTx.MaxHeight,
+		IdempotencyKey: defaultTx.IdempotencyKey,
 	}
 }
 
@@ -169,15 +190,35 @@ func ConvBackFromTransactionList(defaultTxList []*DefaultTransaction) []event.Tx
 
 func convBackFromTransaction(defaultTx *DefaultTransaction) event.Tx {
 	return event.Tx{
-		ID:        defaultTx.ID,
-		ICodeID:   defaultTx.ICodeID,
-		PeerID:    defaultTx.PeerID,
-		TimeStamp: defaultTx.Timestamp,
-		Jsonrpc:   defaultTx.Jsonrpc,
-		Function:  defaultTx.Function,
-		Args:      defaultTx.Args,
-		Signature: defaultTx.Signature,
+		ID:             defaultTx.ID,
+		ICodeID:        defaultTx.ICodeID,
+		PeerID:         defaultTx.PeerID,
+		TimeStamp:      defaultTx.Timestamp,
+		Jsonrpc:        defaultTx.Jsonrpc,
+		Function:       defaultTx.Function,
+		Args:           defaultTx.Args,
+		Signature:      defaultTx.Signature,
+		Deadline:       FromDeadline(defaultTx.Deadline),
+		MaxHeight:      defaultTx.MaxHeight,
+		IdempotencyKey: defaultTx.IdempotencyKey,
+	}
+}
+
+// ToDeadline converts the deadline of a transaction, nil when it has no deadline
+func ToDeadline(deadline time.Time) *time.Time {
+	if deadline.IsZero() {
+		return nil
 	}
+
+	return &deadline
+}
+
+func FromDeadline(deadline *time.Time) time.Time {
+	if deadline == nil {
+		return time.Time{}
+	}
+
+	return *deadline
 }
 
 func ConvertTxType(txList []*DefaultTransaction) []Transaction {
@@ -0,0 +1,78 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package consensus
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/it-chain/engine/common/command"
+	"github.com/it-chain/engine/common/rabbitmq/rpc"
+	"github.com/it-chain/engine/conf"
+	"github.com/it-chain/engine/consensus/pbft"
+	"github.com/it-chain/iLogger"
+	"github.com/urfave/cli"
+)
+
+func Status() cli.Command {
+	return cli.Command{
+		Name:  "status",
+		Usage: "it-chain consensus status",
+		Action: func(c *cli.Context) error {
+			return status()
+		},
+	}
+}
+
+func status() error {
+
+	config := conf.GetConfiguration()
+	client := rpc.NewClient(config.Engine.Amqp)
+	defer client.Close()
+
+	err := client.Call("consensus.status", command.GetConsensusStatus{}, func(status pbft.Status, err rpc.Error) {
+
+		if !err.IsNil() {
+			iLogger.Fatalf(nil, "[Cmd] Fail to get consensus status")
+			return
+		}
+
+		fmt.Printf("Leader:\t\t\t [%s]\n", status.Leader)
+		fmt.Printf("Representatives:\t [%s]\n", strings.Join(status.Representatives, ", "))
+		fmt.Printf("Election:\t\t State: [%s], Term: [%d], VoteCount: [%d]\n", status.ElectionState, status.Term, status.VoteCount)
+
+		if status.State == nil {
+			fmt.Println("State:\t\t\t none")
+		} else {
+			fmt.Printf("State:\t\t\t ID: [%s], Stage: [%s], Height: [%d], Prevotes: [%d], PreCommits: [%d]\n",
+				status.State.StateID, status.State.Stage, status.State.BlockHeight, status.State.PrevoteCount, status.State.PreCommitCount)
+		}
+
+		if status.LastFinalizedAt.IsZero() {
+			fmt.Println("Last finalized:\t\t none")
+		} else {
+			fmt.Printf("Last finalized:\t\t Height: [%d], [%s] ago\n", status.LastFinalizedHeight, status.SinceLastFinalized.Round(time.Millisecond))
+		}
+	})
+
+	if err != nil {
+		iLogger.Fatal(nil, err.Error())
+	}
+
+	return nil
+}
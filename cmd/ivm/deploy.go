@@ -18,6 +18,7 @@ package ivm
 
 import (
 	"log"
+	"time"
 
 	"github.com/it-chain/engine/common"
 
@@ -33,9 +34,49 @@ func DeployCmd() cli.Command {
 	return cli.Command{
 		Name:  "deploy",
 		Usage: "it-chain ivm deploy [icode-git-url] [ssh-path] [password]",
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "network",
+				Usage: "deploy the commit on every node through a transaction instead of on this node",
+			},
+			cli.StringFlag{
+				Name:  "commit",
+				Usage: "full hash of the commit to deploy with --network",
+			},
+			cli.StringFlag{
+				Name:  "version",
+				Usage: "version of the icode deployed with --network",
+			},
+			cli.Uint64Flag{
+				Name:  "activation-height",
+				Usage: "with --network, upgrade the icode of the same name to the commit from the block height",
+			},
+			cli.StringFlag{
+				Name:  "migration",
+				Usage: "function of the new version run once when the upgrade is activated",
+			},
+			keyPathFlag(),
+		},
 		Action: func(c *cli.Context) error {
 
 			gitUrl := c.Args().Get(0)
+			if c.Bool("network") {
+				deployment := ivm.Deployment{
+					GitUrl:            gitUrl,
+					CommitHash:        c.String("commit"),
+					Version:           c.String("version"),
+					ActivationHeight:  c.Uint64("activation-height"),
+					MigrationFunction: c.String("migration"),
+				}
+
+				txId := invoke(c.String("key-path"), ivm.DeploymentICodeID, deployment.Function(), deployment.Args(), "", time.Time{}, 0)
+				if txId != "" {
+					iLogger.Infof(nil, "[Cmd] deployment transaction has created - txID: [%s], see the deployment status with the transaction result", txId)
+				}
+
+				return nil
+			}
+
 			sshPath := c.Args().Get(1)
 			password := c.Args().Get(2)
 			deploy(gitUrl, sshPath, password)
@@ -31,5 +31,6 @@ func IcodeCmd() cli.Command {
 	icodeCmd.Subcommands = append(icodeCmd.Subcommands, InvokeCmd())
 	icodeCmd.Subcommands = append(icodeCmd.Subcommands, QueryCmd())
 	icodeCmd.Subcommands = append(icodeCmd.Subcommands, ListCmd())
+	icodeCmd.Subcommands = append(icodeCmd.Subcommands, VersionsCmd())
 	return icodeCmd
 }
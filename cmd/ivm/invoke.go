@@ -18,7 +18,11 @@ package ivm
 
 import (
 	"errors"
+	"time"
 
+	"github.com/it-chain/engine/api_gateway"
+	"github.com/it-chain/engine/cmd/transaction"
+	"github.com/it-chain/engine/common"
 	"github.com/it-chain/engine/common/command"
 	"github.com/it-chain/engine/common/rabbitmq/rpc"
 	"github.com/it-chain/engine/conf"
@@ -32,6 +36,30 @@ func InvokeCmd() cli.Command {
 	return cli.Command{
 		Name:  "invoke",
 		Usage: "it-chain ivm invoke [icode-id] [function-name] [...args]",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "key",
+				Usage: "idempotency key, invoking again with the same key is rejected as a duplicate. A random key by default",
+			},
+			keyPathFlag(),
+			cli.DurationFlag{
+				Name:  "deadline",
+				Usage: "the transaction is dropped if it is not committed within the duration (e.g. 30s)",
+			},
+			cli.Uint64Flag{
+				Name:  "max-height",
+				Usage: "the transaction is dropped if it is not committed until the block height",
+			},
+			cli.BoolFlag{
+				Name:  "wait",
+				Usage: "wait until the transaction is committed and executed, and print its result",
+			},
+			cli.DurationFlag{
+				Name:  "timeout",
+				Value: api_gateway.DefaultWaitTimeout,
+				Usage: "how long to wait with --wait",
+			},
+		},
 		Action: func(c *cli.Context) error {
 			if c.NArg() < 2 {
 				return errors.New("not enough args")
@@ -45,32 +73,78 @@ func InvokeCmd() cli.Command {
 				args = append(args, c.Args().Get(i))
 			}
 
-			invoke(icodeId, functionName, args)
+			deadline := time.Time{}
+			if c.Duration("deadline") > 0 {
+				deadline = time.Now().Add(c.Duration("deadline"))
+			}
+
+			txId := invoke(c.String("key-path"), icodeId, functionName, args, c.String("key"), deadline, c.Uint64("max-height"))
+			if !c.Bool("wait") || txId == "" {
+				return nil
+			}
+
+			result, err := transaction.WaitResult(txId, c.Duration("timeout"))
+			if err != nil {
+				iLogger.Fatalf(nil, "[Cmd] Fail to wait for the transaction - ID: [%s], Err: [%s]", txId, err.Error())
+				return nil
+			}
+
+			transaction.PrintResult(result)
 
 			return nil
 		},
 	}
 }
 
-func invoke(id string, functionName string, args []string) {
+// keyPathFlag is the key which signs the transactions of the command, the submitter of a transaction is its signer
+func keyPathFlag() cli.Flag {
+	return cli.StringFlag{
+		Name:  "key-path",
+		Usage: "directory of the key which signs the transaction, the key of the node by default",
+	}
+}
+
+// invoke returns the ID of the created transaction, empty if it is rejected
+func invoke(keyPath string, id string, functionName string, args []string, idempotencyKey string, deadline time.Time, maxHeight uint64) string {
 
 	config := conf.GetConfiguration()
+	if keyPath == "" {
+		keyPath = config.Engine.KeyPath
+	}
+
+	// a signed transaction needs an idempotency key, a random one when it is not given
+	if idempotencyKey == "" {
+		idempotencyKey = xid.New().String()
+	}
+
+	signature, err := signTransaction(keyPath, "2.0", id, functionName, args, idempotencyKey, deadline, maxHeight)
+	if err != nil {
+		iLogger.Fatal(&iLogger.Fields{"err_msg": err.Error()}, "fail to sign the transaction")
+		return ""
+	}
+
 	client := rpc.NewClient(config.Engine.Amqp)
 
 	defer client.Close()
 
 	invokeCommand := command.CreateTransaction{
-		TransactionId: xid.New().String(),
-		ICodeID:       id,
-		Jsonrpc:       "2.0",
-		Method:        "invoke",
-		Args:          args,
-		Function:      functionName,
+		TransactionId:  xid.New().String(),
+		ICodeID:        id,
+		Jsonrpc:        "2.0",
+		Method:         "invoke",
+		Args:           args,
+		Function:       functionName,
+		Signature:      signature,
+		IdempotencyKey: idempotencyKey,
+		Deadline:       deadline,
+		MaxHeight:      maxHeight,
 	}
 
 	iLogger.Infof(nil, "[Cmd] Invoke icode - icodeID: [%s]", id)
 
-	err := client.Call("transaction.create", invokeCommand, func(transaction txpool.Transaction, err rpc.Error) {
+	var txId string
+
+	err = client.Call("transaction.create", invokeCommand, func(transaction txpool.Transaction, err rpc.Error) {
 
 		if !err.IsNil() {
 			iLogger.Errorf(nil, "[Cmd] Fail to invoke icode err: [%s]", err.Message)
@@ -78,9 +152,28 @@ func invoke(id string, functionName string, args []string) {
 		}
 
 		iLogger.Infof(nil, "[Cmd] Transactions are created - ID: [%s]", transaction.ID)
+		txId = transaction.ID
 	})
 
 	if err != nil {
 		iLogger.Fatal(&iLogger.Fields{"err_msg": err.Error()}, "fatal err in query cmd")
 	}
+
+	return txId
+}
+
+func signTransaction(keyPath string, jsonrpc string, id string, functionName string, args []string, idempotencyKey string, deadline time.Time, maxHeight uint64) ([]byte, error) {
+	priKey, _ := common.LoadKeyPair(keyPath, "ECDSA256")
+
+	pemData, err := priKey.ToPEM()
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := common.NewECDSASignerFromPEM(common.GetNodeID(keyPath, "ECDSA256"), pemData)
+	if err != nil {
+		return nil, err
+	}
+
+	return common.SignTransaction(signer, common.TransactionSigningData(jsonrpc, id, functionName, args, idempotencyKey, deadline, maxHeight))
 }
@@ -0,0 +1,74 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ivm
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/it-chain/engine/common/command"
+	"github.com/it-chain/engine/common/rabbitmq/rpc"
+	"github.com/it-chain/engine/conf"
+	"github.com/urfave/cli"
+)
+
+func VersionsCmd() cli.Command {
+	return cli.Command{
+		Name:  "versions",
+		Usage: "it-chain ivm versions [icode-name]",
+		Action: func(c *cli.Context) error {
+			if c.NArg() < 1 {
+				return errors.New("not enough args")
+			}
+
+			versions(c.Args().Get(0))
+			return nil
+		},
+	}
+}
+
+func versions(name string) {
+
+	config := conf.GetConfiguration()
+	client := rpc.NewClient(config.Engine.Amqp)
+	defer client.Close()
+
+	versionsCommand := command.GetICodeVersions{Name: name}
+	err := client.Call("ivm.versions", versionsCommand, func(versionList command.ICodeVersionList, err rpc.Error) {
+
+		if !err.IsNil() {
+			log.Printf("fail to get icode versions err: [%s]", err.Message)
+			return
+		}
+
+		fmt.Println("Active\t ID\t\t\t Version\t ActivationHeight")
+		for _, version := range versionList.Versions {
+			active := ""
+			if version.Active {
+				active = "*"
+			}
+
+			fmt.Printf("%s\t [%s]\t [%s]\t\t [%d]\n",
+				active, version.ICodeID, version.Version, version.ActivationHeight)
+		}
+	})
+
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+}
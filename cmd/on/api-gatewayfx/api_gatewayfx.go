@@ -20,6 +20,7 @@ import (
 	"context"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/it-chain/iLogger"
 
@@ -44,12 +45,18 @@ var Module = fx.Options(
 		NewBlockEventListener,
 		api_gateway.NewConnectionEventListener,
 		api_gateway.NewLeaderUpdateEventListener,
+		api_gateway.NewMisbehaviourRepository,
+		api_gateway.NewMisbehaviourEventListener,
+		api_gateway.NewConsensusQueryApi,
+		NewTransactionStatusRepository,
+		api_gateway.NewTransactionEventListener,
+		api_gateway.NewTransactionQueryApi,
 		NewICodeQueryApi,
 		NewICodeEventHandler,
 		api_gateway.NewPeerQueryApi,
 		api_gateway.NewICodeCommandApi,
 		api_gateway.NewConnectionCommandApi,
-		api_gateway.NewApiHandler,
+		NewApiHandler,
 		http.NewServeMux,
 	),
 	fx.Invoke(
@@ -64,6 +71,14 @@ func NewBlockRepository() (*api_gateway.BlockRepositoryImpl, error) {
 	return api_gateway.NewBlockRepositoryImpl(blockchainDB)
 }
 
+func NewTransactionStatusRepository(config *conf.Configuration) *api_gateway.TransactionStatusRepository {
+	return api_gateway.NewTransactionStatusRepository(time.Duration(config.ApiGateway.StatusRetentionMs) * time.Millisecond)
+}
+
+func NewApiHandler(config *conf.Configuration, bqa *api_gateway.BlockQueryApi, iqa *api_gateway.ICodeQueryApi, iha *api_gateway.ICodeCommandApi, p *api_gateway.PeerQueryApi, cca *api_gateway.ConnectionCommandApi, cqa *api_gateway.ConsensusQueryApi, tqa *api_gateway.TransactionQueryApi, logger kitlog.Logger) http.Handler {
+	return api_gateway.NewApiHandler(bqa, iqa, iha, p, cca, cqa, tqa, logger, config.ApiGateway.MaxRequestBytes, time.Duration(config.ApiGateway.MaxWaitTimeoutMs)*time.Millisecond)
+}
+
 func NewKitLogger() kitlog.Logger {
 	var kitLogger kitlog.Logger
 	kitLogger = kitlog.NewLogfmtLogger(kitlog.NewSyncWriter(os.Stderr))
@@ -97,7 +112,9 @@ func NewICodeRepository() *api_gateway.LevelDbICodeRepository {
 func NewPeerRepository(config *conf.Configuration) *api_gateway.PeerRepository {
 
 	var role api_gateway.Role
-	if config.Engine.BootstrapNodeAddress == "" {
+	if config.Engine.Role == common.ObserverRole {
+		role = api_gateway.Observer
+	} else if config.Engine.BootstrapNodeAddress == "" {
 		role = api_gateway.Leader
 	} else {
 		role = api_gateway.Member
@@ -115,7 +132,7 @@ func NewPeerRepository(config *conf.Configuration) *api_gateway.PeerRepository {
 	return peerRepository
 }
 
-func RegisterEvent(subscriber *pubsub.TopicSubscriber, blockEventListener *api_gateway.BlockEventListener, icodeEventListener *api_gateway.ICodeEventHandler, connectionEventhandler *api_gateway.ConnectionEventHandler, leaderUpdateEventlistener *api_gateway.LeaderUpdateEventListener) {
+func RegisterEvent(subscriber *pubsub.TopicSubscriber, blockEventListener *api_gateway.BlockEventListener, icodeEventListener *api_gateway.ICodeEventHandler, connectionEventhandler *api_gateway.ConnectionEventHandler, leaderUpdateEventlistener *api_gateway.LeaderUpdateEventListener, misbehaviourEventListener *api_gateway.MisbehaviourEventListener, transactionEventListener *api_gateway.TransactionEventListener) {
 	if err := subscriber.SubscribeTopic("block.*", blockEventListener); err != nil {
 		panic(err)
 	}
@@ -128,6 +145,15 @@ func RegisterEvent(subscriber *pubsub.TopicSubscriber, blockEventListener *api_g
 	if err := subscriber.SubscribeTopic("leader.updated", leaderUpdateEventlistener); err != nil {
 		panic(err)
 	}
+	if err := subscriber.SubscribeTopic("consensus.misbehaviour", misbehaviourEventListener); err != nil {
+		panic(err)
+	}
+	if err := subscriber.SubscribeTopic("tx.*", transactionEventListener); err != nil {
+		panic(err)
+	}
+	if err := subscriber.SubscribeTopic("block.committed", transactionEventListener); err != nil {
+		panic(err)
+	}
 }
 
 func RegisterHandlers(mux *http.ServeMux) {
@@ -18,8 +18,11 @@ package blockchainfx
 
 import (
 	"context"
+	"errors"
 	"os"
+	"time"
 
+	"github.com/it-chain/engine/blockchain"
 	"github.com/it-chain/engine/blockchain/api"
 	"github.com/it-chain/engine/blockchain/infra/adapter"
 	"github.com/it-chain/engine/blockchain/infra/mem"
@@ -29,12 +32,22 @@ import (
 	"github.com/it-chain/engine/common"
 	"github.com/it-chain/engine/common/rabbitmq/pubsub"
 	"github.com/it-chain/engine/conf"
+	"github.com/it-chain/engine/consensus"
+	"github.com/it-chain/engine/consensus/pbft"
 	"github.com/it-chain/iLogger"
 	"go.uber.org/fx"
 )
 
 const publisherID = "publisher.1"
 const BbPath = "./db"
+const ObserverSyncInterval = 3 * time.Second
+
+const (
+	CertificateFinality = "certificate"
+	TrustPeerFinality   = "trustpeer"
+)
+
+var ErrUnknownFinality = errors.New("unknown blockchain finality")
 
 var Module = fx.Options(
 	fx.Provide(
@@ -44,6 +57,7 @@ var Module = fx.Options(
 		NewBlockAdapter,
 		NewQueryService,
 		NewBlockApi,
+		NewFinalityVerifier,
 		NewSyncApi,
 		NewConnectionEventHandler,
 		NewBlockProposeHandler,
@@ -53,6 +67,7 @@ var Module = fx.Options(
 		RegisterPubsubHandlers,
 		RegisterTearDown,
 		CreateGenesisBlock,
+		RunObserverSync,
 	),
 )
 
@@ -79,14 +94,32 @@ func NewBlockApi(config *conf.Configuration, blockRepository *repo.BlockReposito
 	return api.NewBlockApi(NodeId, blockRepository, service, blockPool)
 }
 
-func NewSyncApi(config *conf.Configuration, blockRepository *repo.BlockRepository, syncStateRepository *mem.SyncStateRepository, eventService common.EventService, queryService *adapter.QuerySerivce, blockPool *mem.BlockPool) (*api.SyncApi, error) {
+// only pbft issues commit certificates. They are checked against the validators of the genesis block,
+// a network without them trusts the peer a node syncs from until a validator set exists
+func NewFinalityVerifier(config *conf.Configuration, signatureVerifier *common.ECDSAVerifier) (blockchain.FinalityVerifier, error) {
+	if config.Engine.Mode != consensus.PbftMode {
+		return blockchain.NoFinalityVerifier{}, nil
+	}
+
+	switch config.Blockchain.Finality {
+	case CertificateFinality:
+		validatorSet := pbft.NewValidatorSet()
+		return blockchain.NewCertificateVerifier(&validatorSet, signatureVerifier), nil
+	case TrustPeerFinality:
+		return blockchain.NoFinalityVerifier{}, nil
+	default:
+		return nil, ErrUnknownFinality
+	}
+}
+
+func NewSyncApi(config *conf.Configuration, blockRepository *repo.BlockRepository, syncStateRepository *mem.SyncStateRepository, eventService common.EventService, queryService *adapter.QuerySerivce, blockPool *mem.BlockPool, finalityVerifier blockchain.FinalityVerifier) (*api.SyncApi, error) {
 	NodeId := common.GetNodeID(config.Engine.KeyPath, "ECDSA256")
-	api, err := api.NewSyncApi(NodeId, blockRepository, syncStateRepository, eventService, queryService, blockPool)
+	api, err := api.NewSyncApi(NodeId, blockRepository, syncStateRepository, eventService, queryService, blockPool, finalityVerifier)
 	return &api, err
 }
 
-func NewBlockProposeHandler(blockApi *api.BlockApi, config *conf.Configuration) *adapter.BlockProposeCommandHandler {
-	return adapter.NewBlockProposeCommandHandler(blockApi, config.Engine.Mode)
+func NewBlockProposeHandler(blockApi *api.BlockApi, engine consensus.ConsensusEngine) *adapter.BlockProposeCommandHandler {
+	return adapter.NewBlockProposeCommandHandler(blockApi, engine)
 }
 
 func NewConnectionEventHandler(syncApi *api.SyncApi) *adapter.NetworkEventHandler {
@@ -121,6 +154,45 @@ func RegisterPubsubHandlers(subscriber *pubsub.TopicSubscriber, networkEventHand
 
 }
 
+// an observer does not receive the consensus messages, so it keeps up with the leader by syncing periodically
+func RunObserverSync(lifecycle fx.Lifecycle, config *conf.Configuration, syncApi *api.SyncApi, queryService *adapter.QuerySerivce) {
+	if config.Engine.Role != common.ObserverRole {
+		return
+	}
+
+	quit := make(chan struct{})
+
+	lifecycle.Append(fx.Hook{
+		OnStart: func(context context.Context) error {
+			go func() {
+				ticker := time.NewTicker(ObserverSyncInterval)
+				defer ticker.Stop()
+
+				for {
+					select {
+					case <-ticker.C:
+						leader, err := queryService.GetLeaderPeer()
+						if err != nil {
+							continue
+						}
+
+						if err := syncApi.CatchUp(leader); err != nil {
+							iLogger.Errorf(nil, "[Blockchain] Fail to catch up with leader - Err: [%s]", err.Error())
+						}
+					case <-quit:
+						return
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(context context.Context) error {
+			close(quit)
+			return nil
+		},
+	})
+}
+
 func RegisterTearDown(lifecycle fx.Lifecycle) {
 
 	lifecycle.Append(fx.Hook{
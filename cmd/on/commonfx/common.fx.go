@@ -23,6 +23,7 @@ import (
 	"github.com/it-chain/engine/common/rabbitmq/pubsub"
 	"github.com/it-chain/engine/common/rabbitmq/rpc"
 	"github.com/it-chain/engine/conf"
+	"github.com/it-chain/engine/consensus"
 	"go.uber.org/fx"
 )
 
@@ -34,6 +35,9 @@ var Module = fx.Options(
 		NewPubsubServer,
 		NewEventService,
 		NewPubsubClient,
+		NewConsensusEngine,
+		NewSigner,
+		NewSignatureVerifier,
 	),
 	fx.Invoke(
 		RegisterTearDown,
@@ -64,6 +68,30 @@ func NewEventService(config *conf.Configuration) common.EventService {
 	return common.NewEventService(config.Engine.Amqp, "Event")
 }
 
+func NewConsensusEngine(config *conf.Configuration, eventService common.EventService) (consensus.ConsensusEngine, error) {
+	return consensus.NewConsensusEngine(config.Engine.Mode, eventService)
+}
+
+// the key of the node signs consensus messages and the governance transactions of the node
+func NewSigner(config *conf.Configuration) (*common.ECDSASigner, error) {
+	NodeId := common.GetNodeID(config.Engine.KeyPath, "ECDSA256")
+	priKey, _ := common.LoadKeyPair(config.Engine.KeyPath, "ECDSA256")
+
+	pemData, err := priKey.ToPEM()
+	if err != nil {
+		return nil, err
+	}
+
+	return common.NewECDSASignerFromPEM(NodeId, pemData)
+}
+
+// node ids are derived from the public keys, so a signature is verified against the id of its signer
+func NewSignatureVerifier() *common.ECDSAVerifier {
+	return common.NewECDSAVerifier(func(pubKey []byte) (string, error) {
+		return common.GetNodeIDFromPubKey(pubKey, "ECDSA256")
+	})
+}
+
 func RegisterTearDown(lifecycle fx.Lifecycle, rpcServer *rpc.Server, subscriber *pubsub.TopicSubscriber, eventService common.EventService) {
 	lifecycle.Append(fx.Hook{
 		OnStart: func(context context.Context) error {
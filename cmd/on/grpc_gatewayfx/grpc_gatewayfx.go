@@ -50,6 +50,7 @@ func NewGrpcHostService(conf *conf.Configuration, publisher *pubsub.TopicPublish
 	hostService := infra.NewGrpcHostService(priKey, pubKey, publisher.Publish, infra.HostInfo{
 		ApiGatewayAddress:  conf.ApiGateway.Address + ":" + conf.ApiGateway.Port,
 		GrpcGatewayAddress: conf.GrpcGateway.Address + ":" + conf.GrpcGateway.Port,
+		Role:               conf.Engine.Role,
 	})
 	return hostService
 }
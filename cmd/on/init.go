@@ -26,7 +26,10 @@ import (
 	"github.com/it-chain/engine/cmd/on/grpc_gatewayfx"
 	"github.com/it-chain/engine/cmd/on/ivmfx"
 	"github.com/it-chain/engine/cmd/on/pbftfx"
+	"github.com/it-chain/engine/cmd/on/raftfx"
 	"github.com/it-chain/engine/cmd/on/txpoolfx"
+	"github.com/it-chain/engine/conf"
+	"github.com/it-chain/engine/consensus"
 	"github.com/it-chain/iLogger"
 	"github.com/urfave/cli"
 	"go.uber.org/fx"
@@ -70,10 +73,20 @@ func start() error {
 		grpc_gatewayfx.Module,
 		ivmfx.Module,
 		txpoolfx.Module,
-		blockchainfx.Module,
 		pbftfx.Module,
+		raftModule(conf.GetConfiguration()),
+		blockchainfx.Module,
 		fx.NopLogger,
 	)
 	app.Run()
 	return nil
 }
+
+// the raft log is only replicated when raft is the consensus engine
+func raftModule(config *conf.Configuration) fx.Option {
+	if config.Engine.Mode != consensus.RaftMode {
+		return fx.Options()
+	}
+
+	return raftfx.Module
+}
@@ -18,15 +18,23 @@ package ivmfx
 
 import (
 	"context"
+	"errors"
+	"time"
 
+	"github.com/it-chain/engine/common"
 	"github.com/it-chain/engine/common/rabbitmq/pubsub"
 	"github.com/it-chain/engine/common/rabbitmq/rpc"
+	"github.com/it-chain/engine/conf"
+	"github.com/it-chain/engine/consensus/pbft"
 	"github.com/it-chain/engine/ivm"
 	"github.com/it-chain/engine/ivm/api"
 	"github.com/it-chain/engine/ivm/infra/adapter"
 	"github.com/it-chain/engine/ivm/infra/git"
+	"github.com/it-chain/engine/ivm/infra/native"
+	"github.com/it-chain/engine/ivm/infra/repo"
 	"github.com/it-chain/engine/ivm/infra/tesseract"
 	"github.com/it-chain/iLogger"
+	"github.com/it-chain/sdk"
 	"go.uber.org/fx"
 )
 
@@ -34,14 +42,19 @@ var Module = fx.Options(
 	fx.Provide(
 		NewGitReposutoryService,
 		NewContainerService,
+		NewExecutionService,
+		NewVersionRegistry,
+		NewDeployerSet,
 		api.NewICodeApi,
 		adapter.NewDeployCommandHandler,
 		adapter.NewUnDeployCommandHandler,
 		adapter.NewIcodeExecuteCommandHandler,
 		adapter.NewListCommandHandler,
-		adapter.NewBlockCommittedEventHandler,
+		adapter.NewExecutionCommandHandler,
+		NewBlockCommittedEventHandler,
 	),
 	fx.Invoke(
+		RestoreVersions,
 		RegisterRpcHandlers,
 		RegisterPubsubHandlers,
 		RegisterTearDown,
@@ -52,8 +65,94 @@ func NewGitReposutoryService() ivm.GitService {
 	return git.NewRepositoryService()
 }
 
-func NewContainerService() ivm.ContainerService {
-	return tesseract.NewContainerService()
+const ICodeStateDbPath = "./icode-state-db"
+const ICodeVersionDbPath = "./icode-version-db"
+
+const (
+	DockerRuntime = "docker"
+	NativeRuntime = "native"
+)
+
+var ErrUnknownRuntime = errors.New("unknown icode runtime")
+
+// HandlerParams collects the icode handlers applications register with RegisterHandler
+type HandlerParams struct {
+	fx.In
+	Handlers []sdk.TransactionHandler `group:"icode_handlers"`
+}
+
+type HandlerResult struct {
+	fx.Out
+	Handler sdk.TransactionHandler `group:"icode_handlers"`
+}
+
+// RegisterHandler adds an icode handler to the native runtime, an icode whose repository name is the name of the handler runs it.
+// Pass it to fx.New with the modules of the node
+func RegisterHandler(handler sdk.TransactionHandler) fx.Option {
+	return fx.Provide(func() HandlerResult {
+		return HandlerResult{Handler: handler}
+	})
+}
+
+func NewContainerService(lifecycle fx.Lifecycle, config *conf.Configuration, params HandlerParams) (ivm.ContainerService, error) {
+	switch config.Icode.Runtime {
+	case DockerRuntime:
+		return tesseract.NewContainerService(), nil
+
+	case NativeRuntime:
+		containerService := native.NewContainerService(ICodeStateDbPath, params.Handlers...)
+		lifecycle.Append(fx.Hook{
+			OnStop: func(context context.Context) error {
+				containerService.Close()
+				return nil
+			},
+		})
+		iLogger.Infof(nil, "[IVM] Run icodes in process - handlers: [%d]", len(params.Handlers))
+
+		return containerService, nil
+
+	default:
+		return nil, ErrUnknownRuntime
+	}
+}
+
+func NewExecutionService(containerService ivm.ContainerService, config *conf.Configuration) *ivm.ExecutionService {
+	circuitBreaker := ivm.NewCircuitBreaker(config.Icode.CircuitBreakerThreshold, time.Duration(config.Icode.CircuitBreakerCooldownMs)*time.Millisecond)
+
+	return ivm.NewExecutionService(
+		containerService,
+		time.Duration(config.Icode.ExecutionTimeoutMs)*time.Millisecond,
+		time.Duration(config.Icode.BlockExecutionBudgetMs)*time.Millisecond,
+		circuitBreaker,
+	)
+}
+
+// the versions deployed through transactions are kept on disk, so they are started again when the node restarts
+func NewVersionRegistry(lifecycle fx.Lifecycle) (*ivm.VersionRegistry, error) {
+	versionRepository := repo.NewVersionRepository(ICodeVersionDbPath)
+	lifecycle.Append(fx.Hook{
+		OnStop: func(context context.Context) error {
+			versionRepository.Close()
+			return nil
+		},
+	})
+
+	return ivm.NewPersistentVersionRegistry(versionRepository)
+}
+
+// the validators on the ledger are followed with the pbft validator set, a network without them has to configure the deployers
+func NewDeployerSet(config *conf.Configuration) *ivm.DeployerSet {
+	validatorSet := pbft.NewValidatorSet()
+	return ivm.NewDeployerSet(config.Icode.Deployers, &validatorSet)
+}
+
+func NewBlockCommittedEventHandler(icodeApi api.ICodeApi, config *conf.Configuration, signatureVerifier *common.ECDSAVerifier) *adapter.BlockCommittedEventHandler {
+	return adapter.NewBlockCommittedEventHandler(
+		icodeApi,
+		signatureVerifier,
+		time.Duration(config.Icode.DeploymentFetchTimeoutMs)*time.Millisecond,
+		config.Icode.DeploymentFetchAttempts,
+	)
 }
 
 func RegisterRpcHandlers(
@@ -62,6 +161,7 @@ func RegisterRpcHandlers(
 	listCommandHandler *adapter.ListCommandHandler,
 	deployCommandHandler *adapter.DeployCommandHandler,
 	unDeployCommandHandler *adapter.UnDeployCommandHandler,
+	executionCommandHandler *adapter.ExecutionCommandHandler,
 ) {
 
 	if err := server.Register("ivm.execute", executeCommandHandler.HandleTransactionExecuteCommandHandler); err != nil {
@@ -76,6 +176,15 @@ func RegisterRpcHandlers(
 	if err := server.Register("ivm.list", listCommandHandler.HandleListCommand); err != nil {
 		panic(err)
 	}
+	if err := server.Register("ivm.versions", listCommandHandler.HandleVersionsCommand); err != nil {
+		panic(err)
+	}
+	if err := server.Register("ivm.status", executionCommandHandler.HandleStatusCommand); err != nil {
+		panic(err)
+	}
+	if err := server.Register("ivm.resume", executionCommandHandler.HandleResumeCommand); err != nil {
+		panic(err)
+	}
 }
 
 func RegisterPubsubHandlers(subscriber *pubsub.TopicSubscriber, handler *adapter.BlockCommittedEventHandler) {
@@ -85,6 +194,11 @@ func RegisterPubsubHandlers(subscriber *pubsub.TopicSubscriber, handler *adapter
 	}
 }
 
+// the restored versions are started before the handler subscribes to the committed blocks
+func RestoreVersions(handler *adapter.BlockCommittedEventHandler) {
+	handler.RestoreVersions()
+}
+
 func RegisterTearDown(lifecycle fx.Lifecycle, containerService ivm.ContainerService) {
 	lifecycle.Append(fx.Hook{
 		OnStart: func(context context.Context) error {
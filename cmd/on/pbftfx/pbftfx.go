@@ -17,23 +17,36 @@
 package pbftfx
 
 import (
+	"context"
+
+	blockchainApi "github.com/it-chain/engine/blockchain/api"
 	"github.com/it-chain/engine/common"
 	"github.com/it-chain/engine/common/rabbitmq/pubsub"
+	"github.com/it-chain/engine/common/rabbitmq/rpc"
 	"github.com/it-chain/engine/conf"
+	"github.com/it-chain/engine/consensus"
 	"github.com/it-chain/engine/consensus/pbft"
 	"github.com/it-chain/engine/consensus/pbft/api"
 	"github.com/it-chain/engine/consensus/pbft/infra/adapter"
 	"github.com/it-chain/engine/consensus/pbft/infra/mem"
+	"github.com/it-chain/engine/consensus/pbft/infra/repo"
 	"github.com/it-chain/iLogger"
 	"go.uber.org/fx"
 )
 
+const ElectionDbPath = "./election-db"
+
 var Module = fx.Options(
 	fx.Provide(
 		NewParliamentRepository,
+		NewElectionTermRepository,
 		mem.NewStateRepository,
 		NewElectionService,
 		NewPropagateService,
+		NewProposedBlockValidator,
+		NewChainState,
+		mem.NewEvidenceRepository,
+		NewEvidenceApi,
 		NewElectionApi,
 		NewParliamentApi,
 		NewStateApi,
@@ -43,9 +56,19 @@ var Module = fx.Options(
 		adapter.NewLeaderEventHandler,
 		NewStartConsensusCommandHandler,
 		NewPbftMsgHandler,
+		NewRotationApi,
+		mem.NewValidatorSetRepository,
+		NewGovernanceApi,
+		NewBlockCommittedEventHandler,
+		NewStatusApi,
+		NewStatusQueryHandler,
 	),
 	fx.Invoke(
+		RestoreValidatorSet,
 		RegisterPubsubHandlers,
+		RegisterRpcHandlers,
+		RunElection,
+		RunRoundTimer,
 	),
 )
 
@@ -58,6 +81,12 @@ func NewParliamentRepository(config *conf.Configuration) *mem.ParliamentReposito
 
 	NodeId := common.GetNodeID(config.Engine.KeyPath, "ECDSA256")
 	parliament := pbft.NewParliament()
+
+	// an observer is not a representative even of its own parliament
+	if config.Engine.Role == common.ObserverRole {
+		return mem.NewParliamentRepositoryWithParliament(parliament)
+	}
+
 	parliament.AddRepresentative(pbft.NewRepresentative(NodeId))
 
 	if config.Engine.BootstrapNodeAddress == "" {
@@ -71,14 +100,35 @@ func NewPropagateService(service common.EventService) *pbft.PropagateService {
 	return pbft.NewPropagateService(service)
 }
 
-func NewStateApi(config *conf.Configuration, propagateService *pbft.PropagateService, service common.EventService, paliamentrepository *mem.ParliamentRepository, stateRepository *mem.StateRepository) *api.StateApi {
+func NewProposedBlockValidator(blockApi *blockchainApi.BlockApi) *adapter.ProposedBlockValidator {
+	return adapter.NewProposedBlockValidator(blockApi)
+}
+
+func NewEvidenceApi(evidenceRepository *mem.EvidenceRepository, verifier *common.ECDSAVerifier, eventService common.EventService) *api.EvidenceApi {
+	return api.NewEvidenceApi(evidenceRepository, verifier, eventService)
+}
+
+func NewStateApi(config *conf.Configuration, propagateService *pbft.PropagateService, service common.EventService, signer *common.ECDSASigner, verifier *common.ECDSAVerifier, blockValidator *adapter.ProposedBlockValidator, evidenceApi *api.EvidenceApi, paliamentrepository *mem.ParliamentRepository, stateRepository *mem.StateRepository) *api.StateApi {
 	PublisherId := common.GetNodeID(config.Engine.KeyPath, "ECDSA256")
 
-	return api.NewStateApi(PublisherId, propagateService, service, paliamentrepository, stateRepository)
+	stateApi := api.NewStateApi(PublisherId, propagateService, service, signer, verifier, blockValidator, evidenceApi, paliamentrepository, stateRepository)
+	if config.Consensus.RoundTimeoutMs > 0 {
+		stateApi.SetRoundTimeout(config.Consensus.RoundTimeoutMs)
+	}
+
+	return stateApi
 }
 
-func NewElectionApi(electionService *pbft.ElectionService, parliamentRepository *mem.ParliamentRepository, eventService common.EventService) *api.ElectionApi {
-	return api.NewElectionApi(electionService, parliamentRepository, eventService)
+func NewElectionTermRepository() *repo.ElectionTermRepository {
+	return repo.NewElectionTermRepository(ElectionDbPath)
+}
+
+func NewChainState(blockApi *blockchainApi.BlockApi) *adapter.ChainState {
+	return adapter.NewChainState(blockApi)
+}
+
+func NewElectionApi(electionService *pbft.ElectionService, parliamentRepository *mem.ParliamentRepository, electionTermRepository *repo.ElectionTermRepository, chainState *adapter.ChainState, signer *common.ECDSASigner, verifier *common.ECDSAVerifier, eventService common.EventService) *api.ElectionApi {
+	return api.NewElectionApi(electionService, parliamentRepository, electionTermRepository, chainState, signer, verifier, eventService)
 }
 
 func NewParliamentApi(config *conf.Configuration, parliamentRepository *mem.ParliamentRepository, eventService common.EventService) *api.ParliamentApi {
@@ -87,6 +137,40 @@ func NewParliamentApi(config *conf.Configuration, parliamentRepository *mem.Parl
 	return api.NewParliamentApi(NodeId, parliamentRepository, eventService)
 }
 
+// raft moves the leader only through an election in a new term, so the leader is never rotated in raft mode
+func NewRotationApi(config *conf.Configuration, parliamentRepository *mem.ParliamentRepository, eventService common.EventService) (*api.RotationApi, error) {
+	if config.Engine.Mode == consensus.RaftMode {
+		if config.Consensus.LeaderRotation != "" && config.Consensus.LeaderRotation != pbft.NoRotation {
+			iLogger.Infof(nil, "[Main] Leader rotation is disabled in raft mode - Rotation: [%s]", config.Consensus.LeaderRotation)
+		}
+
+		return api.NewRotationApi(parliamentRepository, pbft.NoRotationPolicy{}, eventService), nil
+	}
+
+	rotationPolicy, err := pbft.NewRotationPolicy(config.Consensus.LeaderRotation, config.Consensus.RotationInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	return api.NewRotationApi(parliamentRepository, rotationPolicy, eventService), nil
+}
+
+func NewGovernanceApi(parliamentRepository *mem.ParliamentRepository, validatorSetRepository *mem.ValidatorSetRepository, eventService common.EventService) *api.GovernanceApi {
+	return api.NewGovernanceApi(parliamentRepository, validatorSetRepository, eventService)
+}
+
+func NewStatusApi(parliamentRepository *mem.ParliamentRepository, electionService *pbft.ElectionService, stateRepository *mem.StateRepository) *api.StatusApi {
+	return api.NewStatusApi(parliamentRepository, electionService, stateRepository)
+}
+
+func NewStatusQueryHandler(statusApi *api.StatusApi) *adapter.StatusQueryHandler {
+	return adapter.NewStatusQueryHandler(statusApi)
+}
+
+func NewBlockCommittedEventHandler(rotationApi *api.RotationApi, governanceApi *api.GovernanceApi, statusApi *api.StatusApi, verifier *common.ECDSAVerifier) *adapter.BlockCommittedEventHandler {
+	return adapter.NewBlockCommittedEventHandler(rotationApi, governanceApi, statusApi, verifier)
+}
+
 func NewStartConsensusCommandHandler(stateApi *api.StateApi) *adapter.StartConsensusCommandHandler {
 	return adapter.NewStartConsensusCommandHandler(stateApi)
 }
@@ -95,7 +179,13 @@ func NewPbftMsgHandler(stateApi *api.StateApi) *adapter.PbftMsgHandler {
 	return adapter.NewPbftMsgHandler(stateApi)
 }
 
-func RegisterPubsubHandlers(subscriber *pubsub.TopicSubscriber, pbftMsgHandler *adapter.PbftMsgHandler, electionCommandHandler *adapter.ElectionCommandHandler, connectionEventHandler *adapter.ConnectionEventHandler, leaderCommandHandler *adapter.LeaderCommandHandler, leaderEventHandler *adapter.LeaderEventHandler, startConsensusHandler *adapter.StartConsensusCommandHandler) {
+func RestoreValidatorSet(blockCommittedEventHandler *adapter.BlockCommittedEventHandler, blockApi *blockchainApi.BlockApi) {
+	if err := blockCommittedEventHandler.RestoreValidatorSet(blockApi); err != nil {
+		panic(err)
+	}
+}
+
+func RegisterPubsubHandlers(subscriber *pubsub.TopicSubscriber, pbftMsgHandler *adapter.PbftMsgHandler, electionCommandHandler *adapter.ElectionCommandHandler, connectionEventHandler *adapter.ConnectionEventHandler, leaderCommandHandler *adapter.LeaderCommandHandler, leaderEventHandler *adapter.LeaderEventHandler, startConsensusHandler *adapter.StartConsensusCommandHandler, blockCommittedEventHandler *adapter.BlockCommittedEventHandler) {
 	iLogger.Infof(nil, "[Main] Consensus is starting")
 
 	if err := subscriber.SubscribeTopic("message.receive", electionCommandHandler); err != nil {
@@ -121,4 +211,43 @@ func RegisterPubsubHandlers(subscriber *pubsub.TopicSubscriber, pbftMsgHandler *
 	if err := subscriber.SubscribeTopic("message.receive", pbftMsgHandler); err != nil {
 		panic(err)
 	}
+
+	if err := subscriber.SubscribeTopic("block.committed", blockCommittedEventHandler); err != nil {
+		panic(err)
+	}
+}
+
+func RegisterRpcHandlers(server *rpc.Server, statusQueryHandler *adapter.StatusQueryHandler) {
+	if err := server.Register("consensus.status", statusQueryHandler.HandleGetConsensusStatusQuery); err != nil {
+		panic(err)
+	}
+}
+
+func RunElection(lifecycle fx.Lifecycle, electionApi *api.ElectionApi, electionTermRepository *repo.ElectionTermRepository) {
+
+	lifecycle.Append(fx.Hook{
+		OnStart: func(context context.Context) error {
+			go electionApi.ElectLeaderWithRaft()
+			return nil
+		},
+		OnStop: func(context context.Context) error {
+			electionApi.EndRaft()
+			electionTermRepository.Close()
+			return nil
+		},
+	})
+}
+
+func RunRoundTimer(lifecycle fx.Lifecycle, stateApi *api.StateApi) {
+
+	lifecycle.Append(fx.Hook{
+		OnStart: func(context context.Context) error {
+			go stateApi.RunRoundTimer()
+			return nil
+		},
+		OnStop: func(context context.Context) error {
+			stateApi.StopRoundTimer()
+			return nil
+		},
+	})
 }
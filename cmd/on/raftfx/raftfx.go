@@ -0,0 +1,85 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package raftfx
+
+import (
+	"github.com/it-chain/engine/common"
+	"github.com/it-chain/engine/common/rabbitmq/pubsub"
+	"github.com/it-chain/engine/conf"
+	"github.com/it-chain/engine/consensus/pbft"
+	pbftApi "github.com/it-chain/engine/consensus/pbft/api"
+	pbftMem "github.com/it-chain/engine/consensus/pbft/infra/mem"
+	"github.com/it-chain/engine/consensus/raft/api"
+	"github.com/it-chain/engine/consensus/raft/infra/adapter"
+	"github.com/it-chain/engine/consensus/raft/infra/repo"
+	"github.com/it-chain/iLogger"
+	"go.uber.org/fx"
+)
+
+const RaftDbPath = "./raft-db"
+
+var Module = fx.Options(
+	fx.Provide(
+		NewLogRepository,
+		NewParliamentCluster,
+		NewReplicationApi,
+		NewReplicationMsgHandler,
+		NewReplicateCommandHandler,
+	),
+	fx.Invoke(
+		UseReplicatedLogInElection,
+		RegisterPubsubHandlers,
+	),
+)
+
+func NewLogRepository() (*repo.LogRepository, error) {
+	return repo.NewLogRepository(RaftDbPath)
+}
+
+func NewParliamentCluster(parliamentRepository *pbftMem.ParliamentRepository, electionService *pbft.ElectionService) *adapter.ParliamentCluster {
+	return adapter.NewParliamentCluster(parliamentRepository, electionService)
+}
+
+func NewReplicationApi(config *conf.Configuration, cluster *adapter.ParliamentCluster, logRepository *repo.LogRepository, eventService common.EventService) *api.ReplicationApi {
+	NodeId := common.GetNodeID(config.Engine.KeyPath, "ECDSA256")
+	return api.NewReplicationApi(NodeId, cluster, logRepository, eventService)
+}
+
+// a vote is granted only to a candidate whose raft log is at least as up to date
+func UseReplicatedLogInElection(electionApi *pbftApi.ElectionApi, logRepository *repo.LogRepository) {
+	electionApi.SetReplicatedLog(logRepository)
+}
+
+func NewReplicationMsgHandler(replicationApi *api.ReplicationApi) *adapter.ReplicationMsgHandler {
+	return adapter.NewReplicationMsgHandler(replicationApi)
+}
+
+func NewReplicateCommandHandler(replicationApi *api.ReplicationApi) *adapter.ReplicateCommandHandler {
+	return adapter.NewReplicateCommandHandler(replicationApi)
+}
+
+func RegisterPubsubHandlers(subscriber *pubsub.TopicSubscriber, replicationMsgHandler *adapter.ReplicationMsgHandler, replicateCommandHandler *adapter.ReplicateCommandHandler) {
+	iLogger.Infof(nil, "[Main] Raft is starting")
+
+	if err := subscriber.SubscribeTopic("message.receive", replicationMsgHandler); err != nil {
+		panic(err)
+	}
+
+	if err := subscriber.SubscribeTopic("block.replicate", replicateCommandHandler); err != nil {
+		panic(err)
+	}
+}
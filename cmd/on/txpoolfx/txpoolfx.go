@@ -18,6 +18,7 @@ package txpoolfx
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/it-chain/engine/common"
@@ -25,32 +26,107 @@ import (
 	"github.com/it-chain/engine/common/rabbitmq/pubsub"
 	"github.com/it-chain/engine/common/rabbitmq/rpc"
 	"github.com/it-chain/engine/conf"
+	"github.com/it-chain/engine/consensus"
+	"github.com/it-chain/engine/ivm"
+	ivmApi "github.com/it-chain/engine/ivm/api"
 	"github.com/it-chain/engine/txpool"
 	"github.com/it-chain/engine/txpool/api"
 	"github.com/it-chain/engine/txpool/infra/adapter"
 	"github.com/it-chain/engine/txpool/infra/mem"
+	"github.com/it-chain/engine/txpool/infra/repo"
 	"github.com/it-chain/iLogger"
 	"go.uber.org/fx"
 )
 
+const TxpoolDbPath = "./txpool-db"
+const TxpoolCommittedDbPath = "./txpool-committed-db"
+
+const (
+	MemoryRepository  = "memory"
+	LevelDbRepository = "leveldb"
+)
+
+var ErrUnknownRepository = errors.New("unknown txpool repository")
+
 var Module = fx.Options(
 	fx.Provide(
-		mem.NewTransactionRepository,
+		NewTransactionRepository,
+		NewCommittedTransactionRepository,
 		NewLeaderRepository,
 		NewBlockProposalService,
-		NewTransferService,
+		NewPeerRepository,
+		NewGossipService,
+		NewInFlightService,
+		NewCapacityService,
+		NewExpiryService,
+		NewICodeRepository,
+		NewTxValidator,
 		NewTxpoolApi,
 		NewGrpcMessageHandler,
 		NewLeaderEventHandler,
-		adapter.NewTxCommandHandler,
+		NewConnectionEventHandler,
+		NewBlockCommittedEventHandler,
+		NewICodeEventHandler,
+		NewTxCommandHandler,
+		NewMisbehaviourEventHandler,
 	),
 	fx.Invoke(
 		RunBatcher,
 		RegisterRpcHandlers,
 		RegisterPubsubHandlers,
+		RestoreICodes,
 	),
 )
 
+// pending transactions saved in leveldb are replayed on boot, they are proposed or sent to the leader again
+func NewTransactionRepository(lifecycle fx.Lifecycle, config *conf.Configuration) (txpool.TransactionRepository, error) {
+	switch config.Txpool.Repository {
+	case MemoryRepository:
+		return mem.NewTransactionRepository(), nil
+
+	case LevelDbRepository:
+		transactionRepository := repo.NewTransactionRepository(TxpoolDbPath)
+		lifecycle.Append(fx.Hook{
+			OnStop: func(context context.Context) error {
+				transactionRepository.Close()
+				return nil
+			},
+		})
+
+		transactions, err := transactionRepository.FindAll()
+		if err != nil {
+			return nil, err
+		}
+		iLogger.Infof(nil, "[Txpool] Replay pending transactions - count: [%d]", len(transactions))
+
+		return transactionRepository, nil
+
+	default:
+		return nil, ErrUnknownRepository
+	}
+}
+
+func NewCommittedTransactionRepository(lifecycle fx.Lifecycle, config *conf.Configuration) (txpool.CommittedTransactionRepository, error) {
+	switch config.Txpool.Repository {
+	case MemoryRepository:
+		return mem.NewCommittedTransactionRepository(), nil
+
+	case LevelDbRepository:
+		committedTransactionRepository := repo.NewCommittedTransactionRepository(TxpoolCommittedDbPath)
+		lifecycle.Append(fx.Hook{
+			OnStop: func(context context.Context) error {
+				committedTransactionRepository.Close()
+				return nil
+			},
+		})
+
+		return committedTransactionRepository, nil
+
+	default:
+		return nil, ErrUnknownRepository
+	}
+}
+
 func NewLeaderRepository(config *conf.Configuration) *mem.LeaderRepository {
 	NodeId := common.GetNodeID(config.Engine.KeyPath, "ECDSA256")
 	repo := mem.NewLeaderRepository()
@@ -61,46 +137,159 @@ func NewLeaderRepository(config *conf.Configuration) *mem.LeaderRepository {
 	return repo
 }
 
-func NewBlockProposalService(repository *mem.TransactionRepository, eventService common.EventService) *txpool.BlockProposalService {
-	return txpool.NewBlockProposalService(repository, eventService)
+func NewBlockProposalService(config *conf.Configuration, repository txpool.TransactionRepository, eventService common.EventService) (*txpool.BlockProposalService, error) {
+	orderingPolicy, err := txpool.NewOrderingPolicy(config.Txpool.Ordering, config.Txpool.PriorityLanes)
+	if err != nil {
+		return nil, err
+	}
+
+	return txpool.NewBlockProposalService(repository, eventService, orderingPolicy), nil
+}
+
+func NewPeerRepository() *mem.PeerRepository {
+	return mem.NewPeerRepository()
+}
+
+func NewGossipService(config *conf.Configuration, transactionRepository txpool.TransactionRepository, peerRepository *mem.PeerRepository, eventService common.EventService) *txpool.GossipService {
+	return txpool.NewGossipService(transactionRepository, peerRepository, eventService, time.Duration(config.Txpool.GossipResendMs)*time.Millisecond, config.Txpool.MaxGossipBytes)
+}
+
+func NewInFlightService(config *conf.Configuration, transactionRepository txpool.TransactionRepository, eventService common.EventService) *txpool.InFlightService {
+	return txpool.NewInFlightService(transactionRepository, eventService, time.Duration(config.Txpool.InFlightTimeoutMs)*time.Millisecond)
+}
+
+func NewCapacityService(config *conf.Configuration, transactionRepository txpool.TransactionRepository, committedTransactionRepository txpool.CommittedTransactionRepository, eventService common.EventService) (*txpool.CapacityService, error) {
+	limit, err := txpool.NewPoolLimit(
+		config.Txpool.MaxPendingTransactions,
+		config.Txpool.MaxPendingBytes,
+		config.Txpool.MaxPendingTransactionsPerSubmitter,
+		config.Txpool.MaxPendingBytesPerSubmitter,
+		config.Txpool.Eviction,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return txpool.NewCapacityService(transactionRepository, committedTransactionRepository, eventService, limit), nil
+}
+
+func NewExpiryService(transactionRepository txpool.TransactionRepository, eventService common.EventService) *txpool.ExpiryService {
+	return txpool.NewExpiryService(transactionRepository, eventService)
+}
+
+func NewICodeRepository() *mem.ICodeRepository {
+	return mem.NewICodeRepository()
+}
+
+// TxValidatorParams collects the validators applications register with RegisterTxValidator
+type TxValidatorParams struct {
+	fx.In
+	Validators []txpool.TxValidator `group:"tx_validators"`
+}
+
+type TxValidatorResult struct {
+	fx.Out
+	Validator txpool.TxValidator `group:"tx_validators"`
+}
+
+// RegisterTxValidator adds a validator to the admission of the txpool, it runs after the built-in ones.
+// Pass it to fx.New with the modules of the node
+func RegisterTxValidator(validator txpool.TxValidator) fx.Option {
+	return fx.Provide(func() TxValidatorResult {
+		return TxValidatorResult{Validator: validator}
+	})
 }
 
-func NewTransferService(transactionRepository *mem.TransactionRepository, leaderRepository *mem.LeaderRepository, eventService common.EventService) *txpool.TransferService {
-	return txpool.NewTransferService(transactionRepository, leaderRepository, eventService)
+func NewTxValidator(config *conf.Configuration, icodeRepository *mem.ICodeRepository, expiryService *txpool.ExpiryService, signatureVerifier *common.ECDSAVerifier, params TxValidatorParams) txpool.TxValidator {
+	// a deployment is checked to be well formed since its icode does not exist yet
+	reserved := map[string]txpool.TxValidator{
+		ivm.DeploymentICodeID: txpool.TxValidatorFunc(func(transaction txpool.Transaction) error {
+			_, err := ivm.NewDeployment(transaction.Function, transaction.Args)
+			return err
+		}),
+	}
+
+	validators := []txpool.TxValidator{
+		txpool.JsonrpcValidator{},
+		txpool.NewICodeValidator(icodeRepository, reserved),
+		txpool.FunctionValidator{},
+		txpool.ArgsValidator{MaxArgs: config.Txpool.MaxArgs, MaxArgBytes: config.Txpool.MaxArgBytes},
+		txpool.NewSignatureValidator(signatureVerifier, config.Txpool.RequireSignature),
+		txpool.NewExpiryValidator(expiryService),
+	}
+
+	return txpool.NewTxValidatorChain(append(validators, params.Validators...)...)
 }
 
-func NewTxpoolApi(config *conf.Configuration, transactionRepository *mem.TransactionRepository, leaderRepository *mem.LeaderRepository, transferService *txpool.TransferService, blockProposalService *txpool.BlockProposalService) *api.TransactionApi {
+func NewTxpoolApi(config *conf.Configuration, transactionRepository txpool.TransactionRepository, committedTransactionRepository txpool.CommittedTransactionRepository, leaderRepository *mem.LeaderRepository, gossipService *txpool.GossipService, blockProposalService *txpool.BlockProposalService, inFlightService *txpool.InFlightService, capacityService *txpool.CapacityService, expiryService *txpool.ExpiryService, txValidator txpool.TxValidator) *api.TransactionApi {
 	NodeId := common.GetNodeID(config.Engine.KeyPath, "ECDSA256")
-	return api.NewTransactionApi(NodeId, transactionRepository, leaderRepository, transferService, blockProposalService)
+	return api.NewTransactionApi(NodeId, transactionRepository, committedTransactionRepository, leaderRepository, gossipService, blockProposalService, inFlightService, capacityService, expiryService, txValidator)
 }
 
-func NewLeaderEventHandler(leaderRepository *mem.LeaderRepository) *adapter.LeaderEventHandler {
+func NewLeaderEventHandler(leaderRepository *mem.LeaderRepository, txPoolApi *api.TransactionApi) *adapter.LeaderEventHandler {
+
+	return adapter.NewLeaderEventHandler(leaderRepository, txPoolApi)
+}
+
+func NewConnectionEventHandler(peerRepository *mem.PeerRepository) *adapter.ConnectionEventHandler {
+	return adapter.NewConnectionEventHandler(peerRepository)
+}
 
-	return adapter.NewLeaderEventHandler(leaderRepository)
+func NewBlockCommittedEventHandler(txPoolApi *api.TransactionApi) *adapter.BlockCommittedEventHandler {
+	return adapter.NewBlockCommittedEventHandler(txPoolApi)
+}
+
+func NewICodeEventHandler(icodeRepository *mem.ICodeRepository) *adapter.ICodeEventHandler {
+	return adapter.NewICodeEventHandler(icodeRepository)
+}
+
+// the icodes are started by ivm before txpool subscribes to their events, so the registry is filled from ivm once subscribed
+func RestoreICodes(icodeEventHandler *adapter.ICodeEventHandler, icodeApi ivmApi.ICodeApi) {
+	icodeEventHandler.Restore(icodeApi.GetRunningICodeEvents())
+}
+
+func NewTxCommandHandler(config *conf.Configuration, txPoolApi *api.TransactionApi) *adapter.TxCommandHandler {
+	return adapter.NewTxCommandHandler(txPoolApi, config.Txpool.MaxBatchTransactions)
+}
+
+func NewMisbehaviourEventHandler(txPoolApi *api.TransactionApi, signer *common.ECDSASigner) *adapter.MisbehaviourEventHandler {
+	return adapter.NewMisbehaviourEventHandler(txPoolApi, signer)
 }
 
 func NewGrpcMessageHandler(txPoolApi *api.TransactionApi) *adapter.GrpcMessageHandler {
 	return adapter.NewGrpcMessageHandler(txPoolApi)
 }
 
-func RunBatcher(lifecycle fx.Lifecycle, txPoolApi *api.TransactionApi, config *conf.Configuration) {
+func RunBatcher(lifecycle fx.Lifecycle, txPoolApi *api.TransactionApi, engine consensus.ConsensusEngine, config *conf.Configuration) {
 
 	var proposeBlockQuit chan struct{}
-	var sendTransactionQuit chan struct{}
+	var gossipTransactionQuit chan struct{}
+	var releaseTransactionQuit chan struct{}
+	var dropExpiredTransactionQuit chan struct{}
 	lifecycle.Append(fx.Hook{
 		OnStart: func(context context.Context) error {
 			proposeBlockQuit = batch.GetTimeOutBatcherInstance().Run(func() error {
-				return txPoolApi.ProposeBlock(config.Engine.Mode)
+				return txPoolApi.ProposeBlock(engine)
+			}, (time.Duration(config.Txpool.TimeoutMs) * time.Millisecond))
+
+			gossipTransactionQuit = batch.GetTimeOutBatcherInstance().Run(func() error {
+				return txPoolApi.GossipTransactions()
+			}, (time.Duration(config.Txpool.TimeoutMs) * time.Millisecond))
+
+			releaseTransactionQuit = batch.GetTimeOutBatcherInstance().Run(func() error {
+				return txPoolApi.ReleaseExpiredTransactions()
 			}, (time.Duration(config.Txpool.TimeoutMs) * time.Millisecond))
 
-			sendTransactionQuit = batch.GetTimeOutBatcherInstance().Run(func() error {
-				return txPoolApi.SendLeaderTransaction(config.Engine.Mode)
+			dropExpiredTransactionQuit = batch.GetTimeOutBatcherInstance().Run(func() error {
+				return txPoolApi.DropExpiredTransactions()
 			}, (time.Duration(config.Txpool.TimeoutMs) * time.Millisecond))
 			return nil
 		},
 		OnStop: func(context context.Context) error {
 			proposeBlockQuit <- struct{}{}
-			sendTransactionQuit <- struct{}{}
+			gossipTransactionQuit <- struct{}{}
+			releaseTransactionQuit <- struct{}{}
+			dropExpiredTransactionQuit <- struct{}{}
 			return nil
 		},
 	})
@@ -111,16 +300,41 @@ func RegisterRpcHandlers(server *rpc.Server, handler *adapter.TxCommandHandler)
 	if err := server.Register("transaction.create", handler.HandleTxCreateCommand); err != nil {
 		panic(err)
 	}
+	if err := server.Register("transaction.create.batch", handler.HandleTxCreateBatchCommand); err != nil {
+		panic(err)
+	}
 }
 
-func RegisterPubsubHandlers(subscriber *pubsub.TopicSubscriber, leaderEventHandler *adapter.LeaderEventHandler, grpcMessageHandler *adapter.GrpcMessageHandler) {
+func RegisterPubsubHandlers(config *conf.Configuration, subscriber *pubsub.TopicSubscriber, leaderEventHandler *adapter.LeaderEventHandler, grpcMessageHandler *adapter.GrpcMessageHandler, misbehaviourEventHandler *adapter.MisbehaviourEventHandler, blockCommittedEventHandler *adapter.BlockCommittedEventHandler, connectionEventHandler *adapter.ConnectionEventHandler, icodeEventHandler *adapter.ICodeEventHandler) {
 
 	if err := subscriber.SubscribeTopic("leader.updated", leaderEventHandler); err != nil {
 		panic(err)
 	}
 
+	if err := subscriber.SubscribeTopic("leader.deleted", leaderEventHandler); err != nil {
+		panic(err)
+	}
+
+	if err := subscriber.SubscribeTopic("block.committed", blockCommittedEventHandler); err != nil {
+		panic(err)
+	}
+
+	if err := subscriber.SubscribeTopic("connection.*", connectionEventHandler); err != nil {
+		panic(err)
+	}
+
 	if err := subscriber.SubscribeTopic("message.receive", grpcMessageHandler); err != nil {
 		panic(err)
 	}
 
+	if err := subscriber.SubscribeTopic("icode.*", icodeEventHandler); err != nil {
+		panic(err)
+	}
+
+	if config.Consensus.RemoveMisbehaving {
+		if err := subscriber.SubscribeTopic("consensus.misbehaviour", misbehaviourEventHandler); err != nil {
+			panic(err)
+		}
+	}
+
 }
@@ -0,0 +1,64 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transaction
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/it-chain/engine/api_gateway"
+	"github.com/it-chain/iLogger"
+	"github.com/urfave/cli"
+)
+
+func List() cli.Command {
+	return cli.Command{
+		Name:  "list",
+		Usage: "it-chain transaction list [--status pending]",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "status",
+				Value: "",
+				Usage: "pending, forwarded, proposed, committed, executed, failed or dropped",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			return list(c.String("status"))
+		},
+	}
+}
+
+func list(status string) error {
+
+	path := "/transactions"
+	if status != "" {
+		path = path + "?status=" + url.QueryEscape(status)
+	}
+
+	transactions := make([]api_gateway.TransactionStatus, 0)
+	if err := get(path, &transactions); err != nil {
+		iLogger.Fatalf(nil, "[Cmd] Fail to get transaction list - Err: [%s]", err.Error())
+		return nil
+	}
+
+	fmt.Println("Index\t ID\t\t\t\t\t\t\t\t\t Status\t Height\t Reason")
+	for index, tx := range transactions {
+		fmt.Printf("[%d]\t [%s]\t [%s]\t [%d]\t [%s]\n", index, tx.ID, tx.Status, tx.Height, tx.Reason)
+	}
+
+	return nil
+}
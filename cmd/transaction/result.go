@@ -0,0 +1,79 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transaction
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/it-chain/engine/api_gateway"
+	"github.com/it-chain/iLogger"
+	"github.com/urfave/cli"
+)
+
+func Result() cli.Command {
+	return cli.Command{
+		Name:  "result",
+		Usage: "it-chain transaction result [transaction id]",
+		Flags: []cli.Flag{
+			cli.DurationFlag{
+				Name:  "timeout",
+				Value: api_gateway.DefaultWaitTimeout,
+				Usage: "how long to wait for the transaction to be committed and executed",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.NArg() != 1 {
+				return errors.New("transaction id is needed")
+			}
+
+			result, err := WaitResult(c.Args().Get(0), c.Duration("timeout"))
+			if err != nil {
+				iLogger.Fatalf(nil, "[Cmd] Fail to get transaction result - Err: [%s]", err.Error())
+				return nil
+			}
+
+			PrintResult(result)
+
+			return nil
+		},
+	}
+}
+
+// WaitResult asks the api gateway for the result of the transaction, which answers once it is committed and executed
+func WaitResult(id string, timeout time.Duration) (api_gateway.InvokeResult, error) {
+
+	result := api_gateway.InvokeResult{}
+	if err := get(fmt.Sprintf("/transactions/%s/result?timeout=%s", id, timeout), &result); err != nil {
+		return api_gateway.InvokeResult{}, err
+	}
+
+	return result, nil
+}
+
+func PrintResult(result api_gateway.InvokeResult) {
+	fmt.Printf("ID:\t\t [%s]\n", result.TransactionId)
+	fmt.Printf("Height:\t\t [%d]\n", result.Height)
+	fmt.Printf("Tx index:\t [%d]\n", result.TxIndex)
+	if result.Result.Err != "" {
+		fmt.Printf("Err:\t\t [%s]\n", result.Result.Err)
+	}
+	if len(result.Result.Data) != 0 {
+		fmt.Printf("Result:\t\t %v\n", result.Result.Data)
+	}
+}
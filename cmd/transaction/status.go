@@ -0,0 +1,64 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transaction
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/it-chain/engine/api_gateway"
+	"github.com/it-chain/iLogger"
+	"github.com/urfave/cli"
+)
+
+func Status() cli.Command {
+	return cli.Command{
+		Name:  "status",
+		Usage: "it-chain transaction status [transaction id]",
+		Action: func(c *cli.Context) error {
+			if c.NArg() != 1 {
+				return errors.New("transaction id is needed")
+			}
+
+			return status(c.Args().Get(0))
+		},
+	}
+}
+
+func status(id string) error {
+
+	tx := api_gateway.TransactionStatus{}
+	if err := get(fmt.Sprintf("/transactions/%s/status", id), &tx); err != nil {
+		iLogger.Fatalf(nil, "[Cmd] Fail to get transaction status - Err: [%s]", err.Error())
+		return nil
+	}
+
+	fmt.Printf("ID:\t\t [%s]\n", tx.ID)
+	fmt.Printf("Status:\t\t [%s]\n", tx.Status)
+	if tx.Height != 0 {
+		fmt.Printf("Height:\t\t [%d]\n", tx.Height)
+	}
+	if tx.Reason != "" {
+		fmt.Printf("Reason:\t\t [%s]\n", tx.Reason)
+	}
+	if len(tx.Result) != 0 {
+		fmt.Printf("Result:\t\t %v\n", tx.Result)
+	}
+	fmt.Printf("Updated at:\t [%s]\n", tx.UpdatedAt)
+
+	return nil
+}
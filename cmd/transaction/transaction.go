@@ -0,0 +1,63 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transaction
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/it-chain/engine/conf"
+	"github.com/urfave/cli"
+)
+
+var transactionCmd = cli.Command{
+	Name:        "transaction",
+	Aliases:     []string{"tx"},
+	Usage:       "options for transaction",
+	Subcommands: []cli.Command{},
+}
+
+func Cmd() cli.Command {
+	transactionCmd.Subcommands = append(transactionCmd.Subcommands, Status())
+	transactionCmd.Subcommands = append(transactionCmd.Subcommands, List())
+	transactionCmd.Subcommands = append(transactionCmd.Subcommands, Result())
+
+	return transactionCmd
+}
+
+// the status of transactions is kept by the api gateway, so it is asked through its rest api
+func get(path string, v interface{}) error {
+
+	config := conf.GetConfiguration()
+	url := fmt.Sprintf("http://%s:%s%s", config.ApiGateway.Address, config.ApiGateway.Port, path)
+
+	resp, err := http.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body := make(map[string]interface{})
+		json.NewDecoder(resp.Body).Decode(&body)
+		return errors.New(fmt.Sprintf("[%d] %v", resp.StatusCode, body["error"]))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
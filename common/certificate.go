@@ -0,0 +1,122 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+var ErrCertificateSealMismatch = errors.New("certificate does not belong to the block")
+var ErrNotEnoughSignatures = errors.New("certificate has not enough signatures of validators")
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// VoteType tells which step of the consensus a signature was made for
+type VoteType string
+
+const (
+	ProposeVote   VoteType = "propose"
+	PrevoteVote   VoteType = "prevote"
+	PreCommitVote VoteType = "precommit"
+)
+
+// Signature is made with the key of a node, such as the precommit of a validator over the seal of a block
+type Signature struct {
+	SignerID string
+	PubKey   []byte
+	Value    []byte
+}
+
+type Signer interface {
+	Sign(data []byte) (Signature, error)
+}
+
+// SignatureVerifier checks the value of the signature and that the public key belongs to the signer
+type SignatureVerifier interface {
+	Verify(signature Signature, data []byte) error
+}
+
+// VoteSigningData is what a representative signs for a vote. It binds the block hash to the round
+// and the height, so a signature can not be replayed in another round or for another step
+func VoteSigningData(voteType VoteType, stateID string, height uint64, blockHash []byte) []byte {
+	data, _ := json.Marshal(struct {
+		Type      VoteType
+		StateID   string
+		Height    uint64
+		BlockHash []byte
+	}{voteType, stateID, height, blockHash})
+
+	return data
+}
+
+// Certificate proves the finality of a block with the precommits which reached the quorum
+type Certificate struct {
+	StateID    string
+	Height     uint64
+	Seal       []byte
+	Signatures []Signature
+}
+
+func NewCertificate(stateID string, height uint64, seal []byte, signatures []Signature) Certificate {
+	return Certificate{
+		StateID:    stateID,
+		Height:     height,
+		Seal:       seal,
+		Signatures: signatures,
+	}
+}
+
+// Quorum is the number of precommits which finalizes a block among n representatives.
+// Two quorums share more than a third of the representatives, so they can not both be reached
+// for conflicting blocks while less than a third are faulty
+func Quorum(n int) int {
+	return 2*n/3 + 1
+}
+
+// Verify checks that the certificate has a quorum of valid precommits of the validators for the block
+func (c Certificate) Verify(seal []byte, height uint64, validators []string, verifier SignatureVerifier) error {
+	if !bytes.Equal(c.Seal, seal) || c.Height != height {
+		return ErrCertificateSealMismatch
+	}
+
+	signingData := VoteSigningData(PreCommitVote, c.StateID, c.Height, c.Seal)
+
+	isValidator := make(map[string]bool)
+	for _, id := range validators {
+		isValidator[id] = true
+	}
+
+	signers := make(map[string]bool)
+	for _, signature := range c.Signatures {
+		if !isValidator[signature.SignerID] || signers[signature.SignerID] {
+			continue
+		}
+
+		if err := verifier.Verify(signature, signingData); err != nil {
+			continue
+		}
+
+		signers[signature.SignerID] = true
+	}
+
+	if len(signers) < Quorum(len(validators)) {
+		return ErrNotEnoughSignatures
+	}
+
+	return nil
+}
@@ -0,0 +1,155 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/it-chain/engine/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSigner(t *testing.T, id string) *common.ECDSASigner {
+	priKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	return common.NewECDSASigner(id, priKey)
+}
+
+// the test verifier trusts the signer id written in the signature
+func newVerifier(signers map[string]string) *common.ECDSAVerifier {
+	return common.NewECDSAVerifier(func(pubKey []byte) (string, error) {
+		return signers[string(pubKey)], nil
+	})
+}
+
+func TestECDSAVerifier_Verify(t *testing.T) {
+	// given
+	signer := newSigner(t, "1")
+	signature, err := signer.Sign([]byte("seal"))
+	assert.NoError(t, err)
+
+	verifier := newVerifier(map[string]string{string(signature.PubKey): "1"})
+
+	// then
+	assert.NoError(t, verifier.Verify(signature, []byte("seal")))
+	assert.Equal(t, common.ErrInvalidSignature, verifier.Verify(signature, []byte("other seal")))
+
+	// when
+	signature.SignerID = "2"
+
+	// then
+	assert.Equal(t, common.ErrInvalidSignature, verifier.Verify(signature, []byte("seal")))
+}
+
+func TestCertificate_Verify(t *testing.T) {
+	seal := []byte("seal")
+	signers := make(map[string]string)
+	signatures := make([]common.Signature, 0)
+	prevotes := make([]common.Signature, 0)
+
+	for _, id := range []string{"1", "2", "3"} {
+		signer := newSigner(t, id)
+		signature, err := signer.Sign(common.VoteSigningData(common.PreCommitVote, "state1", 4, seal))
+		assert.NoError(t, err)
+
+		prevote, err := signer.Sign(common.VoteSigningData(common.PrevoteVote, "state1", 4, seal))
+		assert.NoError(t, err)
+
+		signers[string(signature.PubKey)] = id
+		signatures = append(signatures, signature)
+		prevotes = append(prevotes, prevote)
+	}
+
+	tests := map[string]struct {
+		input struct {
+			certificate common.Certificate
+			validators  []string
+		}
+		err error
+	}{
+		"quorum of validators": {
+			input: struct {
+				certificate common.Certificate
+				validators  []string
+			}{certificate: common.NewCertificate("state1", 4, seal, signatures), validators: []string{"1", "2", "3", "4"}},
+			err: nil,
+		},
+		"less than two thirds of validators": {
+			input: struct {
+				certificate common.Certificate
+				validators  []string
+			}{certificate: common.NewCertificate("state1", 4, seal, signatures[:2]), validators: []string{"1", "2", "3", "4"}},
+			err: common.ErrNotEnoughSignatures,
+		},
+		"seal mismatch": {
+			input: struct {
+				certificate common.Certificate
+				validators  []string
+			}{certificate: common.NewCertificate("state1", 4, []byte("other seal"), signatures), validators: []string{"1", "2", "3", "4"}},
+			err: common.ErrCertificateSealMismatch,
+		},
+		"height mismatch": {
+			input: struct {
+				certificate common.Certificate
+				validators  []string
+			}{certificate: common.NewCertificate("state1", 5, seal, signatures), validators: []string{"1", "2", "3", "4"}},
+			err: common.ErrCertificateSealMismatch,
+		},
+		"precommits of another round": {
+			input: struct {
+				certificate common.Certificate
+				validators  []string
+			}{certificate: common.NewCertificate("state2", 4, seal, signatures), validators: []string{"1", "2", "3", "4"}},
+			err: common.ErrNotEnoughSignatures,
+		},
+		"prevotes instead of precommits": {
+			input: struct {
+				certificate common.Certificate
+				validators  []string
+			}{certificate: common.NewCertificate("state1", 4, seal, prevotes), validators: []string{"1", "2", "3", "4"}},
+			err: common.ErrNotEnoughSignatures,
+		},
+		"duplicated signer": {
+			input: struct {
+				certificate common.Certificate
+				validators  []string
+			}{certificate: common.NewCertificate("state1", 4, seal, []common.Signature{signatures[0], signatures[0]}), validators: []string{"1", "2", "3", "4"}},
+			err: common.ErrNotEnoughSignatures,
+		},
+		"signer is not a validator": {
+			input: struct {
+				certificate common.Certificate
+				validators  []string
+			}{certificate: common.NewCertificate("state1", 4, seal, signatures[1:]), validators: []string{"1", "2", "4", "5"}},
+			err: common.ErrNotEnoughSignatures,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Logf("running test case %s", testName)
+
+		// when
+		err := test.input.certificate.Verify(seal, 4, test.input.validators, newVerifier(signers))
+
+		// then
+		assert.Equal(t, test.err, err)
+	}
+}
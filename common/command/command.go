@@ -39,6 +39,9 @@ type StartConsensus struct {
 	State     string
 }
 
+type GetConsensusStatus struct {
+}
+
 /*
  * grpc-gateway
  */
@@ -109,6 +112,22 @@ type ICodeList struct {
 	ICodes []ivm.ICode
 }
 
+type GetICodeVersions struct {
+	Name string
+}
+
+type ICodeVersionList struct {
+	Versions []ivm.ICodeVersion
+}
+
+// answered with ivm.ExecutionStatus
+type GetICodeExecutionStatus struct {
+}
+
+// retries the halted block execution right away, answered with ivm.ExecutionStatus
+type ResumeICodeExecution struct {
+}
+
 /*
  * blockchain
  */
@@ -157,16 +176,19 @@ type ProposeBlock struct {
 }
 
 type Tx struct {
-	ID        string
-	ICodeID   string
-	Status    int
-	PeerID    string
-	TimeStamp time.Time
-	Jsonrpc   string
-	Method    string
-	Function  string
-	Args      []string
-	Signature []byte
+	ID             string
+	ICodeID        string
+	Status         int
+	PeerID         string
+	TimeStamp      time.Time
+	Jsonrpc        string
+	Method         string
+	Function       string
+	Args           []string
+	Signature      []byte
+	Deadline       time.Time
+	MaxHeight      uint64
+	IdempotencyKey string
 }
 
 /*
@@ -174,11 +196,32 @@ type Tx struct {
  */
 
 type CreateTransaction struct {
+	TransactionId  string
+	Jsonrpc        string
+	Method         string
+	ICodeID        string
+	Function       string
+	Args           []string
+	Signature      []byte
+	IdempotencyKey string
+	Deadline       time.Time
+	MaxHeight      uint64
+}
+
+// a batch of CreateTransaction, each transaction is validated and accepted on its own
+type CreateTransactions struct {
+	Transactions []CreateTransaction
+}
+
+// results are in the order of the transactions in the batch
+type CreateTransactionsResult struct {
+	Results []CreateTransactionResult
+}
+
+// Error is empty when the transaction is accepted,
+// Code tells the kind of the rejection in the same way as rpc.Error of transaction.create
+type CreateTransactionResult struct {
 	TransactionId string
-	Jsonrpc       string
-	Method        string
-	ICodeID       string
-	Function      string
-	Args          []string
-	Signature     []byte
+	Error         string
+	Code          string
 }
@@ -0,0 +1,136 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"math/big"
+)
+
+var ErrInvalidKey = errors.New("invalid ecdsa key")
+
+// NodeIDFunc derives the node id from the DER encoded public key
+type NodeIDFunc func(pubKey []byte) (string, error)
+
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+type ECDSASigner struct {
+	nodeId string
+	priKey *ecdsa.PrivateKey
+}
+
+func NewECDSASigner(nodeId string, priKey *ecdsa.PrivateKey) *ECDSASigner {
+	return &ECDSASigner{
+		nodeId: nodeId,
+		priKey: priKey,
+	}
+}
+
+func NewECDSASignerFromPEM(nodeId string, pemData []byte) (*ECDSASigner, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, ErrInvalidKey
+	}
+
+	if priKey, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return NewECDSASigner(nodeId, priKey), nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+
+	priKey, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, ErrInvalidKey
+	}
+
+	return NewECDSASigner(nodeId, priKey), nil
+}
+
+func (s *ECDSASigner) Sign(data []byte) (Signature, error) {
+	digest := sha256.Sum256(data)
+
+	r, sv, err := ecdsa.Sign(rand.Reader, s.priKey, digest[:])
+	if err != nil {
+		return Signature{}, err
+	}
+
+	value, err := asn1.Marshal(ecdsaSignature{R: r, S: sv})
+	if err != nil {
+		return Signature{}, err
+	}
+
+	pubKey, err := x509.MarshalPKIXPublicKey(&s.priKey.PublicKey)
+	if err != nil {
+		return Signature{}, err
+	}
+
+	return Signature{
+		SignerID: s.nodeId,
+		PubKey:   pubKey,
+		Value:    value,
+	}, nil
+}
+
+type ECDSAVerifier struct {
+	nodeIdFunc NodeIDFunc
+}
+
+func NewECDSAVerifier(nodeIdFunc NodeIDFunc) *ECDSAVerifier {
+	return &ECDSAVerifier{
+		nodeIdFunc: nodeIdFunc,
+	}
+}
+
+func (v *ECDSAVerifier) Verify(signature Signature, data []byte) error {
+	nodeId, err := v.nodeIdFunc(signature.PubKey)
+	if err != nil || nodeId != signature.SignerID {
+		return ErrInvalidSignature
+	}
+
+	parsed, err := x509.ParsePKIXPublicKey(signature.PubKey)
+	if err != nil {
+		return ErrInvalidKey
+	}
+
+	pubKey, ok := parsed.(*ecdsa.PublicKey)
+	if !ok {
+		return ErrInvalidKey
+	}
+
+	value := ecdsaSignature{}
+	if _, err := asn1.Unmarshal(signature.Value, &value); err != nil {
+		return ErrInvalidSignature
+	}
+
+	digest := sha256.Sum256(data)
+	if !ecdsa.Verify(pubKey, digest[:], value.R, value.S) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
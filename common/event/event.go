@@ -28,8 +28,19 @@ import (
 // consensus가 끝났다는 event
 // true면 블록 저장, false면 블록 저장 안함
 type ConsensusFinished struct {
-	Seal []byte
-	Body []byte
+	Seal        []byte
+	Body        []byte
+	Certificate []byte
+}
+
+// a representative signed two different blocks in the same round
+type ConsensusMisbehaviour struct {
+	Type       string
+	OffenderID string
+	StateID    string
+	Height     uint64
+	DetectedAt time.Time
+	Evidence   []byte
 }
 
 /*
@@ -44,6 +55,8 @@ type ICodeCreated struct {
 	Path           string
 	CommitHash     string
 	Version        string
+	// set when the icode is a version deployed through a transaction, transactions can be sent to the name
+	Name string
 }
 
 // ivm meta deleted
@@ -51,6 +64,14 @@ type ICodeDeleted struct {
 	ICodeID string
 }
 
+// icode execution result of a committed transaction
+type TxExecuted struct {
+	TransactionId string
+	ICodeID       string
+	Data          map[string]string
+	Err           string
+}
+
 /*
  * blockChain
  */
@@ -74,14 +95,17 @@ type BlockStaged struct {
 }
 
 type Tx struct {
-	ID        string
-	ICodeID   string
-	PeerID    string
-	TimeStamp time.Time
-	Jsonrpc   string
-	Function  string
-	Args      []string
-	Signature []byte
+	ID             string
+	ICodeID        string
+	PeerID         string
+	TimeStamp      time.Time
+	Jsonrpc        string
+	Function       string
+	Args           []string
+	Signature      []byte
+	Deadline       time.Time
+	MaxHeight      uint64
+	IdempotencyKey string
 }
 
 /*
@@ -105,6 +129,32 @@ type TxDeleted struct {
 	TransactionId string
 }
 
+// transactions are added to the pool, or are back to pending from in flight
+type TxPending struct {
+	TransactionIds []string
+}
+
+// transactions are gossiped to the other validators
+type TxForwarded struct {
+	TransactionIds []string
+}
+
+// transactions are proposed in a block
+type TxProposed struct {
+	TransactionIds []string
+}
+
+// transactions are removed from the pool without being committed
+type TxDropped struct {
+	TransactionIds []string
+	Reason         string
+}
+
+// transactions are removed from the pool because their deadline or max height has passed
+type TxExpired struct {
+	TransactionIds []string
+}
+
 /*
  * p2p
  */
@@ -137,6 +187,7 @@ type ConnectionCreated struct {
 	ConnectionID       string
 	GrpcGatewayAddress string
 	ApiGatewayAddress  string
+	Role               string
 }
 
 type ConnectionSaved struct {
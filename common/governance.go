@@ -0,0 +1,47 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+// Validator set changes are ordinary transactions addressed to a reserved icode id,
+// so they are ordered and recorded on the ledger like any other transaction.
+// Args of a governance transaction are [validator id, effective block height, proposal id],
+// and it is signed with the node key of the voting validator.
+// A vote is counted for its proposal only, and not again once the proposal is decided.
+const (
+	GovernanceICodeID       = "validator-governance"
+	AddValidatorFunction    = "addValidator"
+	RemoveValidatorFunction = "removeValidator"
+)
+
+// MisbehaviourRemovalDelay is the number of blocks between the height a misbehaviour was
+// detected at and the effective height of the removal voted for it. Every detecting node
+// derives the same effective height, so their votes are counted for the same change.
+const MisbehaviourRemovalDelay uint64 = 10
+
+func IsGovernanceTransaction(icodeId string) bool {
+	return icodeId == GovernanceICodeID
+}
+
+// GovernanceVoter returns the validator who voted with a governance transaction, the verified signer of the transaction.
+// The genesis block lists the validators of the configuration, its transactions need no signature
+func GovernanceVoter(verifier SignatureVerifier, height uint64, signature []byte, signingData []byte) (string, error) {
+	if height == 0 {
+		return "", nil
+	}
+
+	return VerifyTransactionSigner(verifier, signature, signingData)
+}
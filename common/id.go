@@ -17,6 +17,7 @@
 package common
 
 import (
+	"encoding/pem"
 	"log"
 
 	"github.com/it-chain/bifrost"
@@ -29,6 +30,18 @@ func GetNodeID(keyPath string, keyType string) string {
 	return bifrost.FromPriKey(pri)
 }
 
+// GetNodeIDFromPubKey derives the node id of the DER encoded public key
+func GetNodeIDFromPubKey(pubKey []byte, keyType string) (string, error) {
+	pemData := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubKey})
+
+	pub, err := key.PEMToPublicKey(pemData, ConvertToKeyGenOpts(keyType))
+	if err != nil {
+		return "", err
+	}
+
+	return bifrost.FromPubKey(pub), nil
+}
+
 func LoadKeyPair(keyPath string, keyType string) (key.PriKey, key.PubKey) {
 
 	km, err := key.NewKeyManager(keyPath)
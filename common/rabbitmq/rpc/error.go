@@ -18,6 +18,8 @@ package rpc
 
 type Error struct {
 	Message string
+	// optional code of the error, so the caller can tell the errors apart without parsing the message
+	Code string
 }
 
 func (e *Error) NewError(message string) {
@@ -0,0 +1,93 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var ErrMissingSignature = errors.New("transaction is not signed")
+
+// TransactionSigningData is the content of a transaction its submitter signs, the json encoding of its jsonrpc version,
+// icode ID, function, arguments, idempotency key and expiry. It does not depend on the node which creates the transaction.
+// The idempotency key derives the ID of a signed transaction, so a signature can not be replayed under another ID,
+// and the expiry can not be stripped or extended without invalidating the signature.
+// The deadline is signed as unix nanoseconds, zero when there is no deadline, so it does not depend on its time zone
+func TransactionSigningData(jsonrpc string, icodeId string, function string, args []string, idempotencyKey string, deadline time.Time, maxHeight uint64) []byte {
+	if args == nil {
+		args = []string{}
+	}
+
+	var deadlineNano int64
+	if !deadline.IsZero() {
+		deadlineNano = deadline.UnixNano()
+	}
+
+	data, _ := json.Marshal(struct {
+		Jsonrpc        string
+		ICodeID        string
+		Function       string
+		Args           []string
+		IdempotencyKey string
+		Deadline       int64
+		MaxHeight      uint64
+	}{jsonrpc, icodeId, function, args, idempotencyKey, deadlineNano, maxHeight})
+
+	return data
+}
+
+// VerifyTransactionSigner verifies the json encoded signature of a transaction over its signing data
+// and returns the id of the signer
+func VerifyTransactionSigner(verifier SignatureVerifier, encodedSignature []byte, signingData []byte) (string, error) {
+	if len(encodedSignature) == 0 {
+		return "", ErrMissingSignature
+	}
+
+	signature := Signature{}
+	if err := json.Unmarshal(encodedSignature, &signature); err != nil {
+		return "", ErrInvalidSignature
+	}
+
+	if err := verifier.Verify(signature, signingData); err != nil {
+		return "", ErrInvalidSignature
+	}
+
+	return signature.SignerID, nil
+}
+
+// TransactionSigner returns the id of the signer which the json encoded signature of a transaction claims,
+// without verifying it. It is empty for an unsigned transaction or a signature which can not be decoded
+func TransactionSigner(encodedSignature []byte) string {
+	signature := Signature{}
+	if err := json.Unmarshal(encodedSignature, &signature); err != nil {
+		return ""
+	}
+
+	return signature.SignerID
+}
+
+// SignTransaction signs the signing data of a transaction and json encodes the signature
+func SignTransaction(signer Signer, signingData []byte) ([]byte, error) {
+	signature, err := signer.Sign(signingData)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(signature)
+}
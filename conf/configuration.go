@@ -50,6 +50,7 @@ var modeConsts = [...]string{
 	"solo",
 	"test",
 	"pbft",
+	"raft",
 }
 
 // GOPATH 설정유무 확인, conf package 호출 시 최초 실행되는 func
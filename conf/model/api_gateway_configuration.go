@@ -19,11 +19,20 @@ package model
 type ApiGatewayConfiguration struct {
 	Address string
 	Port    string
+	// the status of a settled transaction is kept for this time, zero keeps it forever
+	StatusRetentionMs int64
+	// requests with a larger body are rejected with 413, zero means no limit
+	MaxRequestBytes int64
+	// the longest wait for a transaction result, larger timeouts are rejected with 400. Zero means no limit
+	MaxWaitTimeoutMs int64
 }
 
 func NewApiGatewayConfiguration() ApiGatewayConfiguration {
 	return ApiGatewayConfiguration{
-		Address: "127.0.0.1",
-		Port:    "4444",
+		Address:           "127.0.0.1",
+		Port:              "4444",
+		StatusRetentionMs: 600000,
+		MaxRequestBytes:   1048576,
+		MaxWaitTimeoutMs:  300000,
 	}
 }
@@ -18,10 +18,13 @@ package model
 
 type BlockChainConfiguration struct {
 	GenesisConfPath string
+	// how the finality of a synced block is checked with pbft, "certificate" or "trustpeer"
+	Finality string
 }
 
 func NewBlockChainConfiguration() BlockChainConfiguration {
 	return BlockChainConfiguration{
 		GenesisConfPath: "./Genesis.conf",
+		Finality:        "certificate",
 	}
 }
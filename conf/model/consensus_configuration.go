@@ -19,11 +19,23 @@ package model
 type ConsensusConfiguration struct {
 	BatchTime       int
 	MaxTransactions int
+	// leader rotation policy, one of "none" and "round-robin"
+	LeaderRotation string
+	// number of blocks proposed by a leader before the round-robin moves on
+	RotationInterval uint64
+	// vote for removing a validator from the validator set when its misbehaviour is detected
+	RemoveMisbehaving bool
+	// time in millisecond a representative waits for a round of pbft to finish before it gives the round up
+	RoundTimeoutMs int
 }
 
 func NewConsensusConfiguration() ConsensusConfiguration {
 	return ConsensusConfiguration{
-		BatchTime:       3,
-		MaxTransactions: 100,
+		BatchTime:         3,
+		MaxTransactions:   100,
+		LeaderRotation:    "none",
+		RotationInterval:  1,
+		RemoveMisbehaving: false,
+		RoundTimeoutMs:    5000,
 	}
 }
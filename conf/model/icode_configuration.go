@@ -18,10 +18,36 @@ package model
 
 type ICodeConfiguration struct {
 	RepositoryPath string
+	// where icodes run, one of "docker" and "native".
+	// "native" runs the handlers compiled into the engine without a container runtime
+	Runtime string
+	// limits on the execution of icodes, zero means no limit.
+	// A request is given up after ExecutionTimeoutMs and the requests of a block after BlockExecutionBudgetMs.
+	// A request of a committed block exceeding a limit stops the execution of blocks on the node
+	ExecutionTimeoutMs     int64
+	BlockExecutionBudgetMs int64
+	// the code of a deployment transaction is fetched at most DeploymentFetchAttempts times, each given up after DeploymentFetchTimeoutMs.
+	// A node which can not fetch it stops executing blocks
+	DeploymentFetchTimeoutMs int64
+	DeploymentFetchAttempts  int
+	// an icode which fails this many times in a row is disabled for CircuitBreakerCooldownMs, zero never disables it
+	CircuitBreakerThreshold  int
+	CircuitBreakerCooldownMs int64
+	// node IDs allowed to deploy and upgrade icodes through transactions, the same on every node.
+	// Empty allows the validators on the ledger
+	Deployers []string
 }
 
 func NewIcodeConfiguration() ICodeConfiguration {
 	return ICodeConfiguration{
-		RepositoryPath: "empty",
+		RepositoryPath:           "empty",
+		Runtime:                  "docker",
+		ExecutionTimeoutMs:       3000,
+		BlockExecutionBudgetMs:   30000,
+		DeploymentFetchTimeoutMs: 60000,
+		DeploymentFetchAttempts:  3,
+		CircuitBreakerThreshold:  5,
+		CircuitBreakerCooldownMs: 60000,
+		Deployers:                []string{},
 	}
 }
@@ -19,11 +19,54 @@ package model
 type TxpoolConfiguration struct {
 	TimeoutMs          int64
 	MaxTransactionByte int
+	// where pending transactions are kept, one of "memory" and "leveldb".
+	// Transactions in leveldb survive a restart
+	Repository string
+	// proposed transactions which are not committed in this time go back to pending
+	InFlightTimeoutMs int64
+	// order of the transactions in a proposed block, one of "fifo", "fair" and "priority"
+	Ordering string
+	// icode IDs proposed first by the "priority" ordering, from the highest priority
+	PriorityLanes []string
+	// caps on the pending transactions, zero means no limit
+	MaxPendingTransactions             int
+	MaxPendingBytes                    int
+	MaxPendingTransactionsPerSubmitter int
+	MaxPendingBytesPerSubmitter        int
+	// what to do when the pool is full, one of "reject" and "oldest"
+	Eviction string
+	// a transaction still in the pool is gossiped to the same peer again after this time
+	GossipResendMs int64
+	// the most bytes of transactions in a gossip message, more transactions are split into several messages.
+	// Zero means no limit
+	MaxGossipBytes int
+	// limits on the arguments of a transaction, zero means no limit
+	MaxArgs     int
+	MaxArgBytes int
+	// reject transactions which are not signed by the client
+	RequireSignature bool
+	// the most transactions of a batch submission, zero means no limit
+	MaxBatchTransactions int
 }
 
 func NewTxpoolConfiguration() TxpoolConfiguration {
 	return TxpoolConfiguration{
-		TimeoutMs:          1000,
-		MaxTransactionByte: 1024,
+		TimeoutMs:                          1000,
+		MaxTransactionByte:                 1024,
+		Repository:                         "leveldb",
+		InFlightTimeoutMs:                  10000,
+		Ordering:                           "fifo",
+		PriorityLanes:                      []string{},
+		MaxPendingTransactions:             10000,
+		MaxPendingBytes:                    10485760,
+		MaxPendingTransactionsPerSubmitter: 0,
+		MaxPendingBytesPerSubmitter:        0,
+		Eviction:                           "reject",
+		GossipResendMs:                     10000,
+		MaxGossipBytes:                     1048576,
+		MaxArgs:                            32,
+		MaxArgBytes:                        4096,
+		RequireSignature:                   true,
+		MaxBatchTransactions:               100,
 	}
 }
@@ -0,0 +1,83 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package consensus
+
+import (
+	"errors"
+
+	"github.com/it-chain/engine/common/command"
+)
+
+const (
+	SoloMode = "solo"
+	PbftMode = "pbft"
+	RaftMode = "raft"
+)
+
+var ErrUnsupportedMode = errors.New("unsupported consensus mode")
+
+type EventService interface {
+	Publish(topic string, event interface{}) error
+}
+
+// ConsensusEngine is the contract between the blockchain component and a consensus algorithm.
+// Adding a new mode means implementing this interface and registering its constructor.
+type ConsensusEngine interface {
+	// Mode returns the name used for the engine mode in the configuration
+	Mode() string
+
+	// IsLeaderBased reports whether only the leader proposes blocks.
+	// Other nodes forward their pending transactions to the leader
+	IsLeaderBased() bool
+
+	// ConsentBlock hands a proposed block over to the consensus.
+	// It returns true when the block is final on return and can be committed right away,
+	// false when the decision arrives later as a 'block.confirm' event
+	ConsentBlock(block command.StartConsensus) (bool, error)
+}
+
+type EngineConstructor func(eventService EventService) ConsensusEngine
+
+var engineConstructors = map[string]EngineConstructor{
+	SoloMode: func(eventService EventService) ConsensusEngine {
+		return NewSoloEngine()
+	},
+	PbftMode: func(eventService EventService) ConsensusEngine {
+		return NewPbftEngine(eventService)
+	},
+	RaftMode: func(eventService EventService) ConsensusEngine {
+		return NewRaftEngine(eventService)
+	},
+}
+
+func RegisterEngine(mode string, constructor EngineConstructor) {
+	engineConstructors[mode] = constructor
+}
+
+func IsSupportedMode(mode string) bool {
+	_, ok := engineConstructors[mode]
+	return ok
+}
+
+func NewConsensusEngine(mode string, eventService EventService) (ConsensusEngine, error) {
+	constructor, ok := engineConstructors[mode]
+	if !ok {
+		return nil, ErrUnsupportedMode
+	}
+
+	return constructor(eventService), nil
+}
@@ -0,0 +1,113 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package consensus_test
+
+import (
+	"testing"
+
+	"github.com/it-chain/engine/common/command"
+	"github.com/it-chain/engine/consensus"
+	"github.com/stretchr/testify/assert"
+)
+
+type EventService struct {
+	PublishFunc func(topic string, event interface{}) error
+}
+
+func (s EventService) Publish(topic string, event interface{}) error {
+	return s.PublishFunc(topic, event)
+}
+
+func TestNewConsensusEngine(t *testing.T) {
+
+	tests := map[string]struct {
+		input struct {
+			mode string
+		}
+		output struct {
+			leaderBased bool
+			topic       string
+			finalized   bool
+		}
+		err error
+	}{
+		"solo": {
+			input: struct{ mode string }{mode: "solo"},
+			output: struct {
+				leaderBased bool
+				topic       string
+				finalized   bool
+			}{leaderBased: false, topic: "", finalized: true},
+			err: nil,
+		},
+		"pbft": {
+			input: struct{ mode string }{mode: "pbft"},
+			output: struct {
+				leaderBased bool
+				topic       string
+				finalized   bool
+			}{leaderBased: true, topic: "block.consent", finalized: false},
+			err: nil,
+		},
+		"raft": {
+			input: struct{ mode string }{mode: "raft"},
+			output: struct {
+				leaderBased bool
+				topic       string
+				finalized   bool
+			}{leaderBased: true, topic: "block.replicate", finalized: false},
+			err: nil,
+		},
+		"unsupported": {
+			input: struct{ mode string }{mode: "unknown"},
+			err:   consensus.ErrUnsupportedMode,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Logf("running test case %s", testName)
+
+		// given
+		publishedTopic := ""
+		eventService := EventService{
+			PublishFunc: func(topic string, event interface{}) error {
+				publishedTopic = topic
+				return nil
+			},
+		}
+
+		// when
+		engine, err := consensus.NewConsensusEngine(test.input.mode, eventService)
+
+		// then
+		assert.Equal(t, test.err, err)
+		if err != nil {
+			continue
+		}
+
+		assert.Equal(t, test.input.mode, engine.Mode())
+		assert.Equal(t, test.output.leaderBased, engine.IsLeaderBased())
+
+		// when
+		finalized, err := engine.ConsentBlock(command.StartConsensus{Seal: []byte("seal")})
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, test.output.finalized, finalized)
+		assert.Equal(t, test.output.topic, publishedTopic)
+	}
+}
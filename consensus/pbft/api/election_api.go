@@ -17,7 +17,9 @@
 package api
 
 import (
+	"errors"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/it-chain/engine/common"
@@ -28,24 +30,56 @@ import (
 	"github.com/rs/xid"
 )
 
+const HeartbeatInterval = 50 // in millisecond
+
 type ElectionApi struct {
-	ElectionService      *pbft.ElectionService
-	parliamentRepository pbft.ParliamentRepository
-	eventService         common.EventService
-	quit                 chan struct{}
+	ElectionService        *pbft.ElectionService
+	parliamentRepository   pbft.ParliamentRepository
+	electionTermRepository pbft.ElectionTermRepository
+	chainState             pbft.ChainState
+	signer                 common.Signer
+	signatureVerifier      common.SignatureVerifier
+	eventService           common.EventService
+	// set in raft mode, a vote then compares the replicated logs instead of the chains
+	replicatedLog pbft.ReplicatedLog
+	running       int32
+	quit          chan struct{}
 }
 
-func NewElectionApi(electionService *pbft.ElectionService, parliamentRepository pbft.ParliamentRepository, eventService common.EventService) *ElectionApi {
+var ErrUnknownVoter = errors.New("Voter is not a representative")
+
+func NewElectionApi(electionService *pbft.ElectionService, parliamentRepository pbft.ParliamentRepository, electionTermRepository pbft.ElectionTermRepository, chainState pbft.ChainState, signer common.Signer, signatureVerifier common.SignatureVerifier, eventService common.EventService) *ElectionApi {
+
+	electionTerm, err := electionTermRepository.Load()
+	if err != nil {
+		iLogger.Errorf(nil, "[PBFT] Cannot load election term - Error: [%s]", err.Error())
+	}
+	electionService.SetElectionTerm(electionTerm)
 
 	return &ElectionApi{
-		ElectionService:      electionService,
-		parliamentRepository: parliamentRepository,
-		eventService:         eventService,
-		quit:                 make(chan struct{}, 1),
+		ElectionService:        electionService,
+		parliamentRepository:   parliamentRepository,
+		electionTermRepository: electionTermRepository,
+		chainState:             chainState,
+		signer:                 signer,
+		signatureVerifier:      signatureVerifier,
+		eventService:           eventService,
+		running:                0,
+		quit:                   make(chan struct{}, 1),
 	}
 }
 
+// grant the vote of this node in the current term to the candidate
 func (e *ElectionApi) Vote(connectionId string) error {
+	return e.vote(connectionId, e.ElectionService.GetTerm())
+}
+
+// SetReplicatedLog makes the election compare the raft logs of the candidates, it is used in raft mode
+func (e *ElectionApi) SetReplicatedLog(replicatedLog pbft.ReplicatedLog) {
+	e.replicatedLog = replicatedLog
+}
+
+func (e *ElectionApi) vote(connectionId string, term int) error {
 
 	parliament := e.parliamentRepository.Load()
 
@@ -55,25 +89,183 @@ func (e *ElectionApi) Vote(connectionId string) error {
 		return err
 	}
 
+	if !e.ElectionService.GrantVote(term, connectionId) {
+		iLogger.Infof(nil, "[PBFT] Already voted to %s - Term: [%d]", e.ElectionService.GetVotedFor(), term)
+		return e.sendVote(connectionId, false)
+	}
+
 	e.ElectionService.SetCandidate(representative)
+	if err := e.saveElectionTerm(); err != nil {
+		return err
+	}
+
 	e.ElectionService.ResetLeftTime()
 
-	voteLeaderMessage := pbft.VoteMessage{}
-	grpcDeliverCommand, _ := CreateGrpcDeliverCommand("VoteLeaderProtocol", voteLeaderMessage)
-	grpcDeliverCommand.RecipientList = append(grpcDeliverCommand.RecipientList, connectionId)
+	iLogger.Infof(nil, "[PBFT] Vote to %s - Term: [%d]", connectionId, term)
+
+	return e.sendVote(connectionId, true)
+}
+
+func (e *ElectionApi) sendVote(connectionId string, granted bool) error {
+
+	voteLeaderMessage := pbft.VoteMessage{
+		Term:        e.ElectionService.GetTerm(),
+		CandidateID: connectionId,
+		VoteGranted: granted,
+	}
+	signature, err := e.signer.Sign(voteLeaderMessage.SigningData())
+	if err != nil {
+		return err
+	}
+	voteLeaderMessage.Signature = signature
 
-	iLogger.Infof(nil, "[PBFT] Vote to %s", connectionId)
-	e.ElectionService.SetVoted(true)
+	grpcDeliverCommand, err := CreateGrpcDeliverCommand("VoteLeaderProtocol", voteLeaderMessage)
+	if err != nil {
+		return err
+	}
+	grpcDeliverCommand.RecipientList = append(grpcDeliverCommand.RecipientList, connectionId)
 
 	return e.eventService.Publish("message.deliver", grpcDeliverCommand)
 }
 
+// grant the vote only once per term, and only to a candidate whose chain is at least as up to date.
+// A node which is not a validator never votes
+func (e *ElectionApi) HandleRequestVote(connectionId string, msg pbft.RequestVoteMessage) error {
+
+	if !e.isRepresentative() {
+		iLogger.Debugf(nil, "[PBFT] Ignore vote request as a non voter - Candidate: [%s]", connectionId)
+		return nil
+	}
+
+	if msg.Term > e.ElectionService.GetTerm() {
+		if err := e.stepDown(msg.Term); err != nil {
+			return err
+		}
+	}
+
+	if msg.Term < e.ElectionService.GetTerm() {
+		iLogger.Infof(nil, "[PBFT] Reject vote request of stale term - Candidate: [%s], Term: [%d]", connectionId, msg.Term)
+		return e.sendVote(connectionId, false)
+	}
+
+	if !e.isUpToDate(msg) {
+		iLogger.Infof(nil, "[PBFT] Reject vote request of outdated chain - Candidate: [%s]", connectionId)
+		return e.sendVote(connectionId, false)
+	}
+
+	return e.vote(connectionId, msg.Term)
+}
+
+// in raft mode the candidate log has to hold every entry of the local log, the chain only follows the committed entries
+func (e *ElectionApi) isUpToDate(msg pbft.RequestVoteMessage) bool {
+	if e.replicatedLog != nil {
+		return pbft.IsLogUpToDate(e.replicatedLog, msg.LastLogTerm, msg.LastLogIndex)
+	}
+
+	return pbft.IsChainUpToDate(e.chainState, msg.LastHeight, msg.LastSeal)
+}
+
+// count a granted vote of the current term once for the representative who signed it
+func (e *ElectionApi) HandleVote(msg pbft.VoteMessage) error {
+
+	if err := e.signatureVerifier.Verify(msg.Signature, msg.SigningData()); err != nil {
+		return err
+	}
+
+	parliament := e.parliamentRepository.Load()
+	if _, err := parliament.FindRepresentativeByID(msg.Signature.SignerID); err != nil {
+		return ErrUnknownVoter
+	}
+
+	if msg.Term > e.ElectionService.GetTerm() {
+		return e.stepDown(msg.Term)
+	}
+
+	if !msg.VoteGranted || msg.Term != e.ElectionService.GetTerm() || msg.CandidateID != e.ElectionService.NodeId {
+		return nil
+	}
+
+	if !e.ElectionService.CountVote(msg.Signature.SignerID) {
+		iLogger.Debugf(nil, "[PBFT] Vote is already counted - Voter: [%s], Term: [%d]", msg.Signature.SignerID, msg.Term)
+		return nil
+	}
+
+	return e.DecideToBeLeader()
+}
+
+// follow the leader which sends heartbeats of the current or a newer term
+func (e *ElectionApi) HandleHeartbeat(msg pbft.HeartbeatMessage) error {
+
+	if msg.Term < e.ElectionService.GetTerm() {
+		return nil
+	}
+
+	return e.followLeader(msg.LeaderID, msg.Term)
+}
+
+func (e *ElectionApi) HandleUpdateLeader(msg pbft.UpdateLeaderMessage) error {
+
+	if msg.Term < e.ElectionService.GetTerm() {
+		iLogger.Infof(nil, "[PBFT] Ignore leader of stale term - Leader: [%s], Term: [%d]", msg.Representative.ID, msg.Term)
+		return nil
+	}
+
+	return e.followLeader(msg.Representative.ID, msg.Term)
+}
+
+func (e *ElectionApi) followLeader(leaderId string, term int) error {
+
+	if term > e.ElectionService.GetTerm() {
+		e.ElectionService.SetTerm(term)
+		e.ElectionService.SetVotedFor("")
+		if err := e.saveElectionTerm(); err != nil {
+			return err
+		}
+	}
+
+	if e.ElectionService.GetState() != pbft.NORMAL {
+		e.ElectionService.SetState(pbft.TICKING)
+	}
+	e.ElectionService.ResetLeftTime()
+
+	parliament := e.parliamentRepository.Load()
+	if parliament.GetLeader().GetID() != leaderId {
+		iLogger.Infof(nil, "[PBFT] Follow leader - ID: [%s], Term: [%d]", leaderId, term)
+		e.SetLeader(leaderId)
+	}
+
+	return nil
+}
+
+// a newer term is seen, turn back into a follower
+func (e *ElectionApi) stepDown(term int) error {
+
+	iLogger.Infof(nil, "[PBFT] Step down - Term: [%d]", term)
+
+	e.ElectionService.SetTerm(term)
+	e.ElectionService.SetVotedFor("")
+	e.ElectionService.ResetVoteCount()
+
+	if e.ElectionService.GetState() != pbft.NORMAL {
+		e.ElectionService.SetState(pbft.TICKING)
+	}
+
+	parliament := e.parliamentRepository.Load()
+	if parliament.GetLeader().GetID() == e.ElectionService.NodeId {
+		parliament.RemoveLeader()
+		e.parliamentRepository.Save(parliament)
+	}
+
+	return e.saveElectionTerm()
+}
+
 // broadcast leader to other peers
 func (e *ElectionApi) broadcastLeader(rep pbft.Representative) error {
 	iLogger.Infof(nil, "[PBFT] Broadcast leader - ID: [%s]", rep.ID)
 
 	updateLeaderMessage := pbft.UpdateLeaderMessage{
 		Representative: rep,
+		Term:           e.ElectionService.GetTerm(),
 	}
 	grpcDeliverCommand, err := CreateGrpcDeliverCommand("UpdateLeaderProtocol", updateLeaderMessage)
 	if err != nil {
@@ -81,26 +273,43 @@ func (e *ElectionApi) broadcastLeader(rep pbft.Representative) error {
 		return err
 	}
 
-	parliament := e.parliamentRepository.Load()
-	for _, r := range parliament.GetRepresentatives() {
-		grpcDeliverCommand.RecipientList = append(grpcDeliverCommand.RecipientList, r.ID)
+	grpcDeliverCommand.RecipientList = append(grpcDeliverCommand.RecipientList, e.getPeerIds()...)
+	grpcDeliverCommand.RecipientList = append(grpcDeliverCommand.RecipientList, e.getObserverIds()...)
+
+	return e.eventService.Publish("message.deliver", grpcDeliverCommand)
+}
+
+func (e *ElectionApi) broadcastHeartbeat() error {
+
+	heartbeatMessage := pbft.HeartbeatMessage{
+		Term:     e.ElectionService.GetTerm(),
+		LeaderID: e.ElectionService.NodeId,
+	}
+	grpcDeliverCommand, err := CreateGrpcDeliverCommand("HeartbeatProtocol", heartbeatMessage)
+	if err != nil {
+		return err
+	}
+
+	peerIds := append(e.getPeerIds(), e.getObserverIds()...)
+	if len(peerIds) == 0 {
+		return nil
 	}
 
+	grpcDeliverCommand.RecipientList = append(grpcDeliverCommand.RecipientList, peerIds...)
+
 	return e.eventService.Publish("message.deliver", grpcDeliverCommand)
 }
 
-//broadcast leader when voted fully
+//broadcast leader when voted by majority
 func (e *ElectionApi) DecideToBeLeader() error {
 	if e.ElectionService.GetState() != pbft.CANDIDATE {
 		return nil
 	}
 
-	e.ElectionService.CountUpVoteCount()
+	if e.hasMajorityVotes() {
+		iLogger.Infof(nil, "[PBFT] Leader has voted by majority - Term: [%d]", e.ElectionService.GetTerm())
 
-	if e.isFullyVoted() {
-		iLogger.Infof(nil, "[PBFT] Leader has fully voted")
-
-		e.EndRaft()
+		e.ElectionService.SetState(pbft.LEADER)
 		representative := pbft.Representative{
 			ID: e.ElectionService.NodeId,
 		}
@@ -121,79 +330,119 @@ func (e *ElectionApi) DecideToBeLeader() error {
 	return nil
 }
 
-func (e *ElectionApi) isFullyVoted() bool {
+// vote count includes the vote of the candidate itself
+func (e *ElectionApi) hasMajorityVotes() bool {
 	parliament := e.parliamentRepository.Load()
 	numOfPeers := len(parliament.Representatives)
-	if e.ElectionService.GetVoteCount() == numOfPeers-1 {
-		return true
-	}
 
-	return false
+	return e.ElectionService.GetVoteCount() >= pbft.Majority(numOfPeers)
 }
 
 //1. Start random timeout
 //2. timed out! alter state to 'candidate'
 //3. while ticking, count down leader repo left time
 //4. Send message having 'RequestVoteProtocol' to other node
+//5. while being a leader, send heartbeats instead
 func (e *ElectionApi) ElectLeaderWithRaft() {
 
+	if !atomic.CompareAndSwapInt32(&e.running, 0, 1) {
+		return
+	}
+	defer atomic.StoreInt32(&e.running, 0)
+
 	e.ElectionService.SetState(pbft.TICKING)
 	e.ElectionService.InitLeftTime()
 
 	tick := time.Tick(1 * time.Millisecond)
-	timeout := time.After(time.Second * 10)
+	heartbeat := time.Tick(HeartbeatInterval * time.Millisecond)
 
 	for {
 		select {
 		case <-tick:
-			e.ElectionService.CountDownLeftTimeBy(1)
-			if e.ElectionService.GetLeftTime() == 0 {
-				e.HandleRaftTimeout()
-			}
+			e.Tick()
+		case <-heartbeat:
+			e.Heartbeat()
 		case <-e.quit:
 			iLogger.Infof(nil, "[PBFT] Raft has end")
 			return
-		case <-timeout:
-			iLogger.Errorf(nil, "[PBFT] Raft Time out")
-			return
 		}
 	}
 }
 
+// Tick counts down the election timeout by a millisecond, and starts an election when it runs out
+func (e *ElectionApi) Tick() {
+	if e.isLeader() || !e.hasPeers() || !e.isRepresentative() {
+		return
+	}
+
+	e.ElectionService.CountDownLeftTimeBy(1)
+	if e.ElectionService.GetLeftTime() == 0 {
+		e.HandleRaftTimeout()
+	}
+}
+
+// Heartbeat lets the followers know the leader is alive, it is only sent by the leader
+func (e *ElectionApi) Heartbeat() {
+	if !e.isLeader() {
+		return
+	}
+
+	if err := e.broadcastHeartbeat(); err != nil {
+		iLogger.Errorf(nil, "[PBFT] Cannot broadcast heartbeat - Error: [%s]", err.Error())
+	}
+}
+
 func (e *ElectionApi) EndRaft() {
 	e.ElectionService.SetState(pbft.NORMAL)
-	e.quit <- struct{}{}
+
+	if atomic.LoadInt32(&e.running) == 1 {
+		e.quit <- struct{}{}
+	}
 }
 
+// start a new election in a new term, the candidate votes for itself
 func (e *ElectionApi) HandleRaftTimeout() error {
-	if e.ElectionService.GetState() == pbft.TICKING {
-		e.ElectionService.SetState(pbft.CANDIDATE)
-		e.ElectionService.ResetLeftTime()
-		connectionIds := make([]string, 0)
-		parliament := e.parliamentRepository.Load()
-		for _, r := range parliament.GetRepresentatives() {
-			if r.ID != e.ElectionService.NodeId {
-				connectionIds = append(connectionIds, r.ID)
+	if e.isLeader() {
+		return nil
+	}
 
-			}
-		}
-		e.RequestVote(connectionIds)
-	} else if e.ElectionService.GetState() == pbft.CANDIDATE {
-		//reset time and state chane candidate -> ticking when timed in candidate state
-		e.ElectionService.ResetLeftTime()
-		e.ElectionService.SetState(pbft.TICKING)
+	e.ElectionService.IncreaseTerm()
+	e.ElectionService.SetVotedFor(e.ElectionService.NodeId)
+	e.ElectionService.ResetVoteCount()
+	e.ElectionService.CountVote(e.ElectionService.NodeId)
+	if err := e.saveElectionTerm(); err != nil {
+		return err
 	}
 
-	return nil
+	e.ElectionService.SetState(pbft.CANDIDATE)
+	e.ElectionService.ResetLeftTime()
+
+	if e.hasMajorityVotes() {
+		return e.DecideToBeLeader()
+	}
+
+	return e.RequestVote(e.getPeerIds())
 }
 
 func (e *ElectionApi) RequestVote(peerIds []string) error {
 
 	iLogger.Infof(nil, "[PBFT] Request Vote - Peers:[%s]", strings.Join(peerIds, ", "))
+	lastHeight, lastSeal, err := e.chainState.LastBlock()
+	if err != nil {
+		return err
+	}
+
 	// 1. create request vote message
 	// 2. send message
 	requestVoteMessage := pbft.RequestVoteMessage{
-		Term: e.ElectionService.GetTerm(),
+		Term:        e.ElectionService.GetTerm(),
+		CandidateID: e.ElectionService.NodeId,
+		LastHeight:  lastHeight,
+		LastSeal:    lastSeal,
+	}
+	if e.replicatedLog != nil {
+		requestVoteMessage.LastLogTerm = e.replicatedLog.LastTerm()
+		requestVoteMessage.LastLogIndex = e.replicatedLog.LastIndex()
 	}
 	grpcDeliverCommand, _ := CreateGrpcDeliverCommand("RequestVoteProtocol", requestVoteMessage)
 
@@ -203,6 +452,54 @@ func (e *ElectionApi) RequestVote(peerIds []string) error {
 	return e.eventService.Publish("message.deliver", grpcDeliverCommand)
 }
 
+func (e *ElectionApi) saveElectionTerm() error {
+	if err := e.electionTermRepository.Save(e.ElectionService.GetElectionTerm()); err != nil {
+		iLogger.Errorf(nil, "[PBFT] Cannot save election term - Error: [%s]", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (e *ElectionApi) isLeader() bool {
+	parliament := e.parliamentRepository.Load()
+	return parliament.GetLeader().GetID() == e.ElectionService.NodeId
+}
+
+// a node which is not a validator follows the leader but never runs for it
+func (e *ElectionApi) isRepresentative() bool {
+	parliament := e.parliamentRepository.Load()
+	_, err := parliament.FindRepresentativeByID(e.ElectionService.NodeId)
+	return err == nil
+}
+
+func (e *ElectionApi) hasPeers() bool {
+	return len(e.getPeerIds()) > 0
+}
+
+func (e *ElectionApi) getPeerIds() []string {
+	peerIds := make([]string, 0)
+	parliament := e.parliamentRepository.Load()
+	for _, r := range parliament.GetRepresentatives() {
+		if r.ID != e.ElectionService.NodeId {
+			peerIds = append(peerIds, r.ID)
+		}
+	}
+
+	return peerIds
+}
+
+// observers are told who the leader is, but never asked for a vote
+func (e *ElectionApi) getObserverIds() []string {
+	observerIds := make([]string, 0)
+	parliament := e.parliamentRepository.Load()
+	for _, o := range parliament.GetObservers() {
+		observerIds = append(observerIds, o.ID)
+	}
+
+	return observerIds
+}
+
 func (e *ElectionApi) GetCandidate() pbft.Representative {
 	return e.ElectionService.GetCandidate()
 }
@@ -21,6 +21,8 @@ import (
 
 	"time"
 
+	"github.com/it-chain/engine/common"
+	"github.com/it-chain/engine/common/command"
 	"github.com/it-chain/engine/consensus/pbft"
 	"github.com/it-chain/engine/consensus/pbft/api"
 	"github.com/it-chain/engine/consensus/pbft/infra/mem"
@@ -86,7 +88,7 @@ func TestElectionApi_DecideToBeLeader(t *testing.T) {
 				election: pbft.NewElectionService("this.should.not.broadcast", 30, pbft.CANDIDATE, 0),
 			},
 			output: struct{ voteCount int }{
-				voteCount: 1,
+				voteCount: 0,
 			},
 		},
 		"when election is ticking state, vote count reached majority": {
@@ -99,7 +101,7 @@ func TestElectionApi_DecideToBeLeader(t *testing.T) {
 		},
 		"when election is candidate state, vote count reached majority": {
 			input: struct{ election *pbft.ElectionService }{
-				election: pbft.NewElectionService("this.is.input.address", 30, pbft.CANDIDATE, 1),
+				election: pbft.NewElectionService("this.is.input.address", 30, pbft.CANDIDATE, 2),
 			},
 			output: struct{ voteCount int }{
 				voteCount: 2,
@@ -249,6 +251,424 @@ func setElectionApi() *api.ElectionApi {
 	parliamentRepository.Save(parliament)
 
 	eventService := &mock.EventService{}
-	api := api.NewElectionApi(electionService, parliamentRepository, eventService)
+	api := api.NewElectionApi(electionService, parliamentRepository, mem.NewElectionTermRepository(), newChainState(0, nil), newSigner("1"), acceptAllVerifier, eventService)
 	return api
 }
+
+func TestElectionApi_HandleRequestVote(t *testing.T) {
+	tests := map[string]struct {
+		input struct {
+			term     int
+			votedFor string
+			msg      pbft.RequestVoteMessage
+		}
+		output struct {
+			granted  bool
+			term     int
+			votedFor string
+		}
+	}{
+		"grant vote of new term": {
+			input: struct {
+				term     int
+				votedFor string
+				msg      pbft.RequestVoteMessage
+			}{term: 1, votedFor: "1", msg: pbft.RequestVoteMessage{Term: 2, CandidateID: "2"}},
+			output: struct {
+				granted  bool
+				term     int
+				votedFor string
+			}{granted: true, term: 2, votedFor: "2"},
+		},
+		"reject stale term": {
+			input: struct {
+				term     int
+				votedFor string
+				msg      pbft.RequestVoteMessage
+			}{term: 3, votedFor: "", msg: pbft.RequestVoteMessage{Term: 2, CandidateID: "2"}},
+			output: struct {
+				granted  bool
+				term     int
+				votedFor string
+			}{granted: false, term: 3, votedFor: ""},
+		},
+		"reject when already voted in the term": {
+			input: struct {
+				term     int
+				votedFor string
+				msg      pbft.RequestVoteMessage
+			}{term: 2, votedFor: "3", msg: pbft.RequestVoteMessage{Term: 2, CandidateID: "2"}},
+			output: struct {
+				granted  bool
+				term     int
+				votedFor string
+			}{granted: false, term: 2, votedFor: "3"},
+		},
+		"reject outdated chain": {
+			input: struct {
+				term     int
+				votedFor string
+				msg      pbft.RequestVoteMessage
+			}{term: 1, votedFor: "", msg: pbft.RequestVoteMessage{Term: 2, CandidateID: "2", LastHeight: 0}},
+			output: struct {
+				granted  bool
+				term     int
+				votedFor string
+			}{granted: false, term: 2, votedFor: ""},
+		},
+		"reject diverged chain": {
+			input: struct {
+				term     int
+				votedFor string
+				msg      pbft.RequestVoteMessage
+			}{term: 1, votedFor: "", msg: pbft.RequestVoteMessage{Term: 2, CandidateID: "2", LastHeight: 1, LastSeal: []byte("seal2")}},
+			output: struct {
+				granted  bool
+				term     int
+				votedFor string
+			}{granted: false, term: 2, votedFor: ""},
+		},
+	}
+
+	for testName, test := range tests {
+		t.Logf("running test case %s", testName)
+
+		// given
+		var vote pbft.VoteMessage
+		eventService := &mock.EventService{}
+		eventService.PublishFunc = func(topic string, event interface{}) error {
+			deliver := event.(command.DeliverGrpc)
+			assert.Equal(t, "VoteLeaderProtocol", deliver.Protocol)
+			common.Deserialize(deliver.Body, &vote)
+			return nil
+		}
+
+		chainState := newChainState(0, nil)
+		if testName == "reject outdated chain" || testName == "reject diverged chain" {
+			chainState = newChainState(1, []byte("seal1"))
+		}
+
+		electionTermRepository := mem.NewElectionTermRepository()
+		electionTermRepository.Save(pbft.ElectionTerm{Term: test.input.term, VotedFor: test.input.votedFor})
+
+		electionApi := api.NewElectionApi(pbft.NewElectionService("1", 30, pbft.TICKING, 0), setParliamentRepository(), electionTermRepository, chainState, newSigner("1"), acceptAllVerifier, eventService)
+
+		// when
+		err := electionApi.HandleRequestVote("2", test.input.msg)
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, test.output.granted, vote.VoteGranted)
+		assert.Equal(t, test.output.term, vote.Term)
+
+		electionTerm, _ := electionTermRepository.Load()
+		assert.Equal(t, test.output.term, electionTerm.Term)
+		assert.Equal(t, test.output.votedFor, electionTerm.VotedFor)
+	}
+}
+
+// replicatedLog is a raft log ending with the term and the index
+type replicatedLog struct {
+	term  int
+	index uint64
+}
+
+func (l replicatedLog) LastIndex() uint64 { return l.index }
+
+func (l replicatedLog) LastTerm() int { return l.term }
+
+func TestElectionApi_HandleRequestVote_Raft(t *testing.T) {
+
+	tests := map[string]struct {
+		msg     pbft.RequestVoteMessage
+		granted bool
+	}{
+		"grant longer log of the same term": {
+			msg:     pbft.RequestVoteMessage{Term: 3, LastLogTerm: 2, LastLogIndex: 6},
+			granted: true,
+		},
+		"grant later last term": {
+			msg:     pbft.RequestVoteMessage{Term: 3, LastLogTerm: 3, LastLogIndex: 1},
+			granted: true,
+		},
+		// the chain of the candidate is at the same height, but its log misses an entry a majority may have committed
+		"reject shorter log at the same chain height": {
+			msg:     pbft.RequestVoteMessage{Term: 3, LastLogTerm: 2, LastLogIndex: 4, LastHeight: 3, LastSeal: []byte("seal3")},
+			granted: false,
+		},
+		"reject earlier last term": {
+			msg:     pbft.RequestVoteMessage{Term: 3, LastLogTerm: 1, LastLogIndex: 9},
+			granted: false,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Logf("running test case %s", testName)
+
+		// given
+		var vote pbft.VoteMessage
+		eventService := &mock.EventService{}
+		eventService.PublishFunc = func(topic string, event interface{}) error {
+			deliver := event.(command.DeliverGrpc)
+			common.Deserialize(deliver.Body, &vote)
+			return nil
+		}
+
+		electionTermRepository := mem.NewElectionTermRepository()
+		electionTermRepository.Save(pbft.ElectionTerm{Term: 2})
+
+		electionApi := api.NewElectionApi(pbft.NewElectionService("1", 30, pbft.TICKING, 0), setParliamentRepository(), electionTermRepository, newChainState(3, []byte("seal3")), newSigner("1"), acceptAllVerifier, eventService)
+		electionApi.SetReplicatedLog(replicatedLog{term: 2, index: 5})
+
+		// when
+		err := electionApi.HandleRequestVote("2", test.msg)
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, test.granted, vote.VoteGranted)
+	}
+}
+
+func TestElectionApi_HandleRaftTimeout(t *testing.T) {
+	// given
+	var requestVote pbft.RequestVoteMessage
+	recipients := make([]string, 0)
+	eventService := &mock.EventService{}
+	eventService.PublishFunc = func(topic string, event interface{}) error {
+		deliver := event.(command.DeliverGrpc)
+		assert.Equal(t, "RequestVoteProtocol", deliver.Protocol)
+		recipients = deliver.RecipientList
+		common.Deserialize(deliver.Body, &requestVote)
+		return nil
+	}
+
+	electionTermRepository := mem.NewElectionTermRepository()
+	electionApi := api.NewElectionApi(pbft.NewElectionService("1", 30, pbft.TICKING, 0), setParliamentRepository(), electionTermRepository, newChainState(0, nil), newSigner("1"), acceptAllVerifier, eventService)
+
+	// when
+	err := electionApi.HandleRaftTimeout()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, pbft.CANDIDATE, electionApi.GetState())
+	assert.Equal(t, 1, electionApi.GetVoteCount())
+	assert.Equal(t, 1, requestVote.Term)
+	assert.ElementsMatch(t, []string{"2", "3"}, recipients)
+
+	electionTerm, _ := electionTermRepository.Load()
+	assert.Equal(t, pbft.ElectionTerm{Term: 1, VotedFor: "1"}, electionTerm)
+}
+
+func TestElectionApi_HandleVote(t *testing.T) {
+	// given
+	eventService := &mock.EventService{}
+	eventService.PublishFunc = func(topic string, event interface{}) error {
+		return nil
+	}
+
+	parliamentRepository := setParliamentRepository()
+	parliament := parliamentRepository.Load()
+	parliament.AddRepresentative(pbft.NewRepresentative("4"))
+	parliament.AddRepresentative(pbft.NewRepresentative("5"))
+	parliamentRepository.Save(parliament)
+
+	electionTermRepository := mem.NewElectionTermRepository()
+	electionApi := api.NewElectionApi(pbft.NewElectionService("1", 30, pbft.TICKING, 0), parliamentRepository, electionTermRepository, newChainState(0, nil), newSigner("1"), acceptAllVerifier, eventService)
+	electionApi.HandleRaftTimeout()
+
+	vote := func(voterId string, term int, candidateId string, granted bool) pbft.VoteMessage {
+		return pbft.VoteMessage{Term: term, CandidateID: candidateId, VoteGranted: granted, Signature: common.Signature{SignerID: voterId}}
+	}
+
+	// when: vote of a stale term
+	err := electionApi.HandleVote(vote("2", 0, "1", true))
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, 1, electionApi.GetVoteCount())
+
+	// when: vote of a node which is not a representative
+	err = electionApi.HandleVote(vote("6", 1, "1", true))
+
+	// then
+	assert.Equal(t, api.ErrUnknownVoter, err)
+	assert.Equal(t, 1, electionApi.GetVoteCount())
+
+	// when: vote for another candidate
+	err = electionApi.HandleVote(vote("2", 1, "3", true))
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, 1, electionApi.GetVoteCount())
+
+	// when: the same voter votes twice
+	assert.NoError(t, electionApi.HandleVote(vote("2", 1, "1", true)))
+	assert.NoError(t, electionApi.HandleVote(vote("2", 1, "1", true)))
+
+	// then
+	assert.Equal(t, 2, electionApi.GetVoteCount())
+	assert.Equal(t, pbft.CANDIDATE, electionApi.GetState())
+
+	// when: majority of five representatives
+	err = electionApi.HandleVote(vote("3", 1, "1", true))
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, pbft.LEADER, electionApi.GetState())
+	assert.Equal(t, "1", electionApi.GetParliament().GetLeader().GetID())
+
+	// when: newer term is seen
+	err = electionApi.HandleVote(vote("2", 2, "2", false))
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, pbft.TICKING, electionApi.GetState())
+	assert.Equal(t, "", electionApi.GetParliament().GetLeader().GetID())
+}
+
+func TestElectionApi_HandleVote_InvalidSignature(t *testing.T) {
+	// given
+	eventService := &mock.EventService{}
+	eventService.PublishFunc = func(topic string, event interface{}) error {
+		return nil
+	}
+
+	rejectAllVerifier := mock.SignatureVerifier{}
+	rejectAllVerifier.VerifyFunc = func(signature common.Signature, data []byte) error {
+		return common.ErrInvalidSignature
+	}
+
+	electionApi := api.NewElectionApi(pbft.NewElectionService("1", 30, pbft.TICKING, 0), setParliamentRepository(), mem.NewElectionTermRepository(), newChainState(0, nil), newSigner("1"), rejectAllVerifier, eventService)
+	electionApi.HandleRaftTimeout()
+
+	// when
+	err := electionApi.HandleVote(pbft.VoteMessage{Term: 1, CandidateID: "1", VoteGranted: true, Signature: common.Signature{SignerID: "2"}})
+
+	// then
+	assert.Equal(t, common.ErrInvalidSignature, err)
+	assert.Equal(t, pbft.CANDIDATE, electionApi.GetState())
+	assert.Equal(t, 1, electionApi.GetVoteCount())
+}
+
+func TestElectionApi_HandleHeartbeat(t *testing.T) {
+	// given
+	eventService := &mock.EventService{}
+	eventService.PublishFunc = func(topic string, event interface{}) error {
+		return nil
+	}
+
+	electionTermRepository := mem.NewElectionTermRepository()
+	electionTermRepository.Save(pbft.ElectionTerm{Term: 2})
+	electionApi := api.NewElectionApi(pbft.NewElectionService("1", 30, pbft.CANDIDATE, 0), setParliamentRepository(), electionTermRepository, newChainState(0, nil), newSigner("1"), acceptAllVerifier, eventService)
+
+	// when: heartbeat of a stale term
+	err := electionApi.HandleHeartbeat(pbft.HeartbeatMessage{Term: 1, LeaderID: "2"})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, pbft.CANDIDATE, electionApi.GetState())
+	assert.Equal(t, "", electionApi.GetParliament().GetLeader().GetID())
+
+	// when
+	err = electionApi.HandleHeartbeat(pbft.HeartbeatMessage{Term: 3, LeaderID: "2"})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, pbft.TICKING, electionApi.GetState())
+	assert.Equal(t, "2", electionApi.GetParliament().GetLeader().GetID())
+
+	electionTerm, _ := electionTermRepository.Load()
+	assert.Equal(t, 3, electionTerm.Term)
+}
+
+func TestElectionApi_Observer(t *testing.T) {
+	// given
+	published := false
+	eventService := &mock.EventService{}
+	eventService.PublishFunc = func(topic string, event interface{}) error {
+		published = published || topic == "message.deliver"
+		return nil
+	}
+
+	electionTermRepository := mem.NewElectionTermRepository()
+	electionApi := api.NewElectionApi(pbft.NewElectionService("4", 30, pbft.TICKING, 0), setParliamentRepository(), electionTermRepository, newChainState(0, nil), newSigner("4"), acceptAllVerifier, eventService)
+
+	// when
+	err := electionApi.HandleRequestVote("2", pbft.RequestVoteMessage{Term: 1, CandidateID: "2"})
+
+	// then
+	assert.NoError(t, err)
+	assert.False(t, published)
+
+	electionTerm, _ := electionTermRepository.Load()
+	assert.Equal(t, "", electionTerm.VotedFor)
+
+	// when
+	err = electionApi.HandleHeartbeat(pbft.HeartbeatMessage{Term: 1, LeaderID: "2"})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, "2", electionApi.GetParliament().GetLeader().GetID())
+}
+
+func TestElectionApi_Heartbeat_ToObservers(t *testing.T) {
+	// given
+	recipients := make([]string, 0)
+	eventService := &mock.EventService{}
+	eventService.PublishFunc = func(topic string, event interface{}) error {
+		deliver := event.(command.DeliverGrpc)
+		assert.Equal(t, "HeartbeatProtocol", deliver.Protocol)
+		recipients = deliver.RecipientList
+		return nil
+	}
+
+	parliamentRepository := setParliamentRepository()
+	parliament := parliamentRepository.Load()
+	parliament.SetLeader("1")
+	parliament.AddObserver(pbft.NewObserver("4"))
+	parliamentRepository.Save(parliament)
+
+	electionApi := api.NewElectionApi(pbft.NewElectionService("1", 30, pbft.LEADER, 0), parliamentRepository, mem.NewElectionTermRepository(), newChainState(0, nil), newSigner("1"), acceptAllVerifier, eventService)
+
+	// when
+	electionApi.Heartbeat()
+
+	// then
+	assert.ElementsMatch(t, []string{"2", "3", "4"}, recipients)
+}
+
+func setParliamentRepository() *mem.ParliamentRepository {
+	parliament := pbft.NewParliament()
+	parliament.AddRepresentative(pbft.NewRepresentative("1"))
+	parliament.AddRepresentative(pbft.NewRepresentative("2"))
+	parliament.AddRepresentative(pbft.NewRepresentative("3"))
+
+	parliamentRepository := mem.NewParliamentRepository()
+	parliamentRepository.Save(parliament)
+
+	return parliamentRepository
+}
+
+func newChainState(height uint64, seal []byte) mock.ChainState {
+	chainState := mock.ChainState{}
+	chainState.LastBlockFunc = func() (uint64, []byte, error) {
+		return height, seal, nil
+	}
+
+	return chainState
+}
+
+func newSigner(id string) mock.Signer {
+	signer := mock.Signer{}
+	signer.SignFunc = func(data []byte) (common.Signature, error) {
+		return common.Signature{SignerID: id, Value: data}, nil
+	}
+
+	return signer
+}
+
+var acceptAllVerifier = mock.SignatureVerifier{
+	VerifyFunc: func(signature common.Signature, data []byte) error {
+		return nil
+	},
+}
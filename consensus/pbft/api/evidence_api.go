@@ -0,0 +1,71 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"github.com/it-chain/engine/common"
+	"github.com/it-chain/engine/common/event"
+	"github.com/it-chain/engine/consensus/pbft"
+	"github.com/it-chain/iLogger"
+)
+
+type EvidenceApi struct {
+	evidenceRepository pbft.EvidenceRepository
+	signatureVerifier  common.SignatureVerifier
+	eventService       common.EventService
+}
+
+func NewEvidenceApi(evidenceRepository pbft.EvidenceRepository, signatureVerifier common.SignatureVerifier, eventService common.EventService) *EvidenceApi {
+	return &EvidenceApi{
+		evidenceRepository: evidenceRepository,
+		signatureVerifier:  signatureVerifier,
+		eventService:       eventService,
+	}
+}
+
+// Report keeps the evidence only when the offender signed both messages,
+// so a forged message can not frame another representative
+func (e *EvidenceApi) Report(evidence pbft.Evidence) error {
+	if err := evidence.Verify(e.signatureVerifier); err != nil {
+		iLogger.Errorf(nil, "[PBFT] Invalid misbehaviour evidence - Offender: [%s], Err: [%s]", evidence.OffenderID, err.Error())
+		return err
+	}
+
+	if err := e.evidenceRepository.Save(evidence); err != nil {
+		return err
+	}
+
+	iLogger.Errorf(nil, "[PBFT] Misbehaviour detected - Type: [%s], Offender: [%s], StateID: [%s]", evidence.Type, evidence.OffenderID, evidence.StateID.ID)
+
+	serializedEvidence, err := common.Serialize(evidence)
+	if err != nil {
+		return err
+	}
+
+	return e.eventService.Publish("consensus.misbehaviour", event.ConsensusMisbehaviour{
+		Type:       string(evidence.Type),
+		OffenderID: evidence.OffenderID,
+		StateID:    evidence.StateID.ID,
+		Height:     evidence.Height,
+		DetectedAt: evidence.DetectedAt,
+		Evidence:   serializedEvidence,
+	})
+}
+
+func (e *EvidenceApi) GetEvidences() []pbft.Evidence {
+	return e.evidenceRepository.FindAll()
+}
@@ -0,0 +1,121 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"sync"
+
+	"github.com/it-chain/engine/common"
+	"github.com/it-chain/engine/common/event"
+	"github.com/it-chain/engine/consensus/pbft"
+	"github.com/it-chain/iLogger"
+)
+
+type GovernanceApi struct {
+	parliamentRepository   pbft.ParliamentRepository
+	validatorSetRepository pbft.ValidatorSetRepository
+	eventService           common.EventService
+	appliedHeight          uint64
+	applied                bool
+	mux                    sync.Mutex
+}
+
+func NewGovernanceApi(parliamentRepository pbft.ParliamentRepository, validatorSetRepository pbft.ValidatorSetRepository, eventService common.EventService) *GovernanceApi {
+	return &GovernanceApi{
+		parliamentRepository:   parliamentRepository,
+		validatorSetRepository: validatorSetRepository,
+		eventService:           eventService,
+		mux:                    sync.Mutex{},
+	}
+}
+
+// ApplyBlock counts the governance votes of a committed block and applies the changes
+// which become effective for the next block. The validators of the genesis block need no votes.
+// Blocks already applied are skipped, so a block replayed from the ledger is not counted twice
+func (g *GovernanceApi) ApplyBlock(height uint64, votes []pbft.ValidatorVote) error {
+	g.mux.Lock()
+	defer g.mux.Unlock()
+
+	if g.applied && height <= g.appliedHeight {
+		return nil
+	}
+
+	validatorSet := g.validatorSetRepository.Load()
+
+	for _, vote := range votes {
+		if err := validatorSet.CountVote(vote, height); err != nil {
+			iLogger.Errorf(nil, "[PBFT] Invalid validator vote - Voter: [%s], Validator: [%s], Err: [%s]", vote.Voter, vote.ValidatorID, err.Error())
+		}
+	}
+
+	changes := validatorSet.ApplyScheduled(height + 1)
+	g.validatorSetRepository.Save(validatorSet)
+
+	g.appliedHeight = height
+	g.applied = true
+
+	for _, change := range changes {
+		iLogger.Infof(nil, "[PBFT] Validator set changed - Action: [%s], Validator: [%s], Height: [%d]", change.Action, change.ValidatorID, change.EffectiveHeight)
+	}
+
+	if !validatorSet.IsEnabled() || (height != 0 && len(changes) == 0) {
+		return nil
+	}
+
+	return g.rebuildParliament(validatorSet)
+}
+
+// IsManaged reports whether the parliament follows the validator set on the ledger
+// instead of the connected peers
+func (g *GovernanceApi) IsManaged() bool {
+	g.mux.Lock()
+	defer g.mux.Unlock()
+
+	return g.validatorSetRepository.Load().IsEnabled()
+}
+
+func (g *GovernanceApi) GetValidators() []string {
+	g.mux.Lock()
+	defer g.mux.Unlock()
+
+	return g.validatorSetRepository.Load().GetValidators()
+}
+
+func (g *GovernanceApi) rebuildParliament(validatorSet pbft.ValidatorSet) error {
+	parliament := g.parliamentRepository.Load()
+	leaderId := parliament.GetLeader().GetID()
+
+	rebuilt := pbft.NewParliament()
+	for _, id := range validatorSet.GetValidators() {
+		rebuilt.AddRepresentative(pbft.NewRepresentative(id))
+	}
+	for _, observer := range parliament.GetObservers() {
+		rebuilt.AddObserver(observer)
+	}
+
+	if validatorSet.IsValidator(leaderId) {
+		rebuilt.SetLeader(leaderId)
+	}
+	g.parliamentRepository.Save(rebuilt)
+
+	if leaderId != "" && !validatorSet.IsValidator(leaderId) {
+		iLogger.Infof(nil, "[PBFT] Leader is no longer a validator - ID: [%s]", leaderId)
+		return g.eventService.Publish("leader.deleted", event.LeaderDeleted{})
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api_test
+
+import (
+	"testing"
+
+	"github.com/it-chain/engine/consensus/pbft"
+	"github.com/it-chain/engine/consensus/pbft/api"
+	"github.com/it-chain/engine/consensus/pbft/infra/mem"
+	"github.com/it-chain/engine/consensus/pbft/test/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGovernanceApi_ApplyBlock(t *testing.T) {
+	// given
+	parliamentRepository := mem.NewParliamentRepository()
+	parliament := pbft.NewParliament()
+	parliament.AddRepresentative(pbft.NewRepresentative("1"))
+	parliament.AddRepresentative(pbft.NewRepresentative("5"))
+	parliament.SetLeader("1")
+	parliamentRepository.Save(parliament)
+
+	published := make([]string, 0)
+	eventService := &mock.EventService{}
+	eventService.PublishFunc = func(topic string, event interface{}) error {
+		published = append(published, topic)
+		return nil
+	}
+
+	governanceApi := api.NewGovernanceApi(parliamentRepository, mem.NewValidatorSetRepository(), eventService)
+
+	// then
+	assert.False(t, governanceApi.IsManaged())
+
+	// when
+	err := governanceApi.ApplyBlock(0, []pbft.ValidatorVote{
+		{Voter: "1", Action: pbft.AddValidator, ValidatorID: "1"},
+		{Voter: "1", Action: pbft.AddValidator, ValidatorID: "2"},
+		{Voter: "1", Action: pbft.AddValidator, ValidatorID: "3"},
+	})
+
+	// then
+	assert.NoError(t, err)
+	assert.True(t, governanceApi.IsManaged())
+	assert.Equal(t, 3, len(parliamentRepository.Load().GetRepresentatives()))
+	assert.Equal(t, "1", parliamentRepository.Load().GetLeader().GetID())
+
+	// when
+	err = governanceApi.ApplyBlock(1, []pbft.ValidatorVote{
+		{Voter: "2", Action: pbft.RemoveValidator, ValidatorID: "1", EffectiveHeight: 3, ProposalID: "remove-1"},
+		{Voter: "3", Action: pbft.RemoveValidator, ValidatorID: "1", EffectiveHeight: 3, ProposalID: "remove-1"},
+	})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1", "2", "3"}, governanceApi.GetValidators())
+
+	// when
+	err = governanceApi.ApplyBlock(2, []pbft.ValidatorVote{})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2", "3"}, governanceApi.GetValidators())
+	assert.Equal(t, 2, len(parliamentRepository.Load().GetRepresentatives()))
+	assert.Equal(t, "", parliamentRepository.Load().GetLeader().GetID())
+	assert.Equal(t, []string{"leader.deleted"}, published)
+
+	// when
+	err = governanceApi.ApplyBlock(1, []pbft.ValidatorVote{
+		{Voter: "2", Action: pbft.RemoveValidator, ValidatorID: "3", EffectiveHeight: 3, ProposalID: "remove-3"},
+		{Voter: "3", Action: pbft.RemoveValidator, ValidatorID: "3", EffectiveHeight: 3, ProposalID: "remove-3"},
+	})
+	governanceApi.ApplyBlock(3, []pbft.ValidatorVote{})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2", "3"}, governanceApi.GetValidators())
+}
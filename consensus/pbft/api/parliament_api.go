@@ -53,6 +53,20 @@ func (p *ParliamentApi) AddRepresentative(representativeId string) {
 	p.parliamentRepository.Save(parliament)
 }
 
+func (p *ParliamentApi) AddObserver(observerId string) {
+	parliament := p.parliamentRepository.Load()
+	parliament.AddObserver(pbft.NewObserver(observerId))
+
+	p.parliamentRepository.Save(parliament)
+}
+
+func (p *ParliamentApi) RemoveObserver(observerId string) {
+	parliament := p.parliamentRepository.Load()
+	parliament.RemoveObserver(observerId)
+
+	p.parliamentRepository.Save(parliament)
+}
+
 func (p *ParliamentApi) RemoveRepresentative(representativeId string) {
 	iLogger.Infof(nil, "[PBFT] Remove Representative - ID: [%s]", representativeId)
 
@@ -104,10 +118,14 @@ func (p *ParliamentApi) RequestLeader(connectionId string) {
 	p.eventService.Publish("message.deliver", msg)
 }
 
+// an observer only passes on the leader it follows, it never appoints itself
 func (p *ParliamentApi) DeliverLeader(connectionId string) {
 	parliament := p.parliamentRepository.Load()
 	leader := parliament.GetLeader()
 	if leader.LeaderId == "" {
+		if _, err := parliament.FindRepresentativeByID(p.nodeId); err != nil {
+			return
+		}
 		p.UpdateLeader(p.nodeId)
 	}
 
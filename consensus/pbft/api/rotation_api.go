@@ -0,0 +1,60 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"github.com/it-chain/engine/common"
+	"github.com/it-chain/engine/common/event"
+	"github.com/it-chain/engine/consensus/pbft"
+	"github.com/it-chain/iLogger"
+)
+
+type RotationApi struct {
+	parliamentRepository pbft.ParliamentRepository
+	rotationPolicy       pbft.RotationPolicy
+	eventService         common.EventService
+}
+
+func NewRotationApi(parliamentRepository pbft.ParliamentRepository, rotationPolicy pbft.RotationPolicy, eventService common.EventService) *RotationApi {
+	return &RotationApi{
+		parliamentRepository: parliamentRepository,
+		rotationPolicy:       rotationPolicy,
+		eventService:         eventService,
+	}
+}
+
+// RotateLeader hands the leadership to the proposer of the block following the committed height.
+// Every node runs it on the same committed block, so no message is needed to agree on the leader
+func (r *RotationApi) RotateLeader(committedHeight uint64) error {
+	parliament := r.parliamentRepository.Load()
+
+	nextLeaderId := r.rotationPolicy.NextLeader(parliament, committedHeight+1)
+	if nextLeaderId == "" || nextLeaderId == parliament.GetLeader().GetID() {
+		return nil
+	}
+
+	if err := parliament.SetLeader(nextLeaderId); err != nil {
+		return err
+	}
+	r.parliamentRepository.Save(parliament)
+
+	iLogger.Infof(nil, "[PBFT] Rotate leader - ID: [%s], Height: [%d]", nextLeaderId, committedHeight+1)
+
+	return r.eventService.Publish("leader.updated", event.LeaderUpdated{
+		LeaderId: nextLeaderId,
+	})
+}
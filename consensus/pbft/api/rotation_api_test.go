@@ -0,0 +1,100 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api_test
+
+import (
+	"testing"
+
+	"github.com/it-chain/engine/common/event"
+	"github.com/it-chain/engine/consensus/pbft"
+	"github.com/it-chain/engine/consensus/pbft/api"
+	"github.com/it-chain/engine/consensus/pbft/test/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRotationApi_RotateLeader(t *testing.T) {
+	tests := map[string]struct {
+		input struct {
+			policy          pbft.RotationPolicy
+			committedHeight uint64
+		}
+		output struct {
+			leaderId  string
+			published bool
+		}
+	}{
+		"no rotation": {
+			input: struct {
+				policy          pbft.RotationPolicy
+				committedHeight uint64
+			}{policy: pbft.NoRotationPolicy{}, committedHeight: 1},
+			output: struct {
+				leaderId  string
+				published bool
+			}{leaderId: "1", published: false},
+		},
+		"round-robin to next representative": {
+			input: struct {
+				policy          pbft.RotationPolicy
+				committedHeight uint64
+			}{policy: pbft.RoundRobinPolicy{Interval: 1}, committedHeight: 1},
+			output: struct {
+				leaderId  string
+				published bool
+			}{leaderId: "3", published: true},
+		},
+		"round-robin to current leader": {
+			input: struct {
+				policy          pbft.RotationPolicy
+				committedHeight uint64
+			}{policy: pbft.RoundRobinPolicy{Interval: 1}, committedHeight: 2},
+			output: struct {
+				leaderId  string
+				published bool
+			}{leaderId: "1", published: false},
+		},
+	}
+
+	for testName, test := range tests {
+		t.Logf("running test case %s", testName)
+
+		// given
+		published := false
+		eventService := &mock.EventService{}
+		eventService.PublishFunc = func(topic string, e interface{}) error {
+			assert.Equal(t, "leader.updated", topic)
+			assert.Equal(t, test.output.leaderId, e.(event.LeaderUpdated).LeaderId)
+			published = true
+			return nil
+		}
+
+		parliamentRepository := setParliamentRepository()
+		parliament := parliamentRepository.Load()
+		parliament.SetLeader("1")
+		parliamentRepository.Save(parliament)
+
+		rotationApi := api.NewRotationApi(parliamentRepository, test.input.policy, eventService)
+
+		// when
+		err := rotationApi.RotateLeader(test.input.committedHeight)
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, test.output.leaderId, parliamentRepository.Load().GetLeader().GetID())
+		assert.Equal(t, test.output.published, published)
+	}
+}
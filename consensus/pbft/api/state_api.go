@@ -17,7 +17,10 @@
 package api
 
 import (
+	"bytes"
 	"errors"
+	"sync"
+	"time"
 
 	"github.com/it-chain/engine/common"
 	"github.com/it-chain/engine/common/event"
@@ -30,28 +33,67 @@ type StateApi struct {
 	publisherID          string
 	propagateService     *pbft.PropagateService
 	eventService         common.EventService
+	signer               common.Signer
+	verifier             common.SignatureVerifier
+	blockValidator       pbft.BlockValidator
+	reporter             pbft.MisbehaviourReporter
 	parliamentRepository pbft.ParliamentRepository
 	repo                 pbft.StateRepository
 	tempPrevoteMsgPool   pbft.PrevoteMsgPool
 	tempPreCommitMsgPool pbft.PreCommitMsgPool
+	roundTimeout         int
+	roundLeftTime        int
+	// the block the representative precommitted, it prevotes no other block at the height until it commits one
+	locked pbft.ProposedBlock
+	// the block the representative proposed as the leader, it is proposed again when the round is given up
+	proposed pbft.ProposedBlock
+	// the rounds finished or given up lately, their late messages are dropped instead of buffered
+	closedStateIDs []string
+	quit           chan struct{}
+	mux            sync.Mutex
 }
 
+// DefaultRoundTimeout is the time in millisecond a representative waits for a round to finish
+const DefaultRoundTimeout = 5000
+
+const closedStateLimit = 16
+
 var ConsensusCreateError = errors.New("Consensus can't be created")
+var ErrConflictingProposal = errors.New("Leader proposed another block in the same round")
+var ErrConflictingPrevote = errors.New("Representative prevoted another block in the same round")
+var ErrLockedOnAnotherBlock = errors.New("Representative precommitted another block at the height")
 
 func NewStateApi(publisherID string, propagateService *pbft.PropagateService,
-	eventService common.EventService, parliamentRepository pbft.ParliamentRepository, repo pbft.StateRepository) *StateApi {
+	eventService common.EventService, signer common.Signer, verifier common.SignatureVerifier, blockValidator pbft.BlockValidator, reporter pbft.MisbehaviourReporter, parliamentRepository pbft.ParliamentRepository, repo pbft.StateRepository) *StateApi {
 	return &StateApi{
 		publisherID:          publisherID,
 		propagateService:     propagateService,
 		eventService:         eventService,
+		signer:               signer,
+		verifier:             verifier,
+		blockValidator:       blockValidator,
+		reporter:             reporter,
 		parliamentRepository: parliamentRepository,
 		repo:                 repo,
 		tempPrevoteMsgPool:   pbft.NewPrevoteMsgPool(),
 		tempPreCommitMsgPool: pbft.NewPreCommitMsgPool(),
+		roundTimeout:         DefaultRoundTimeout,
+		closedStateIDs:       make([]string, 0),
+		quit:                 make(chan struct{}, 1),
 	}
 }
 
+// SetRoundTimeout replaces the time in millisecond a representative waits for a round to finish
+func (sApi *StateApi) SetRoundTimeout(timeout int) {
+	sApi.mux.Lock()
+	defer sApi.mux.Unlock()
+
+	sApi.roundTimeout = timeout
+}
+
 func (sApi *StateApi) StartConsensus(proposedBlock pbft.ProposedBlock) error {
+	sApi.mux.Lock()
+	defer sApi.mux.Unlock()
 
 	parliament := sApi.parliamentRepository.Load()
 	if !parliament.IsNeedConsensus() {
@@ -59,20 +101,21 @@ func (sApi *StateApi) StartConsensus(proposedBlock pbft.ProposedBlock) error {
 		return ConsensusCreateError
 	}
 
+	proposedBlock = sApi.blockToPropose(proposedBlock)
+
 	createdState, err := pbft.NewState(parliament.GetRepresentatives(), proposedBlock)
 	if err != nil {
 		return err
 	}
 
 	createdProposeMsg := pbft.NewProposeMsg(createdState, sApi.publisherID)
-
-	receipients := make([]pbft.Representative, 0)
-
-	for _, rep := range createdState.Representatives {
-		if rep.ID != sApi.publisherID {
-			receipients = append(receipients, rep)
-		}
+	signature, err := sApi.signer.Sign(common.VoteSigningData(common.ProposeVote, createdState.StateID.ID, proposedBlock.Height, proposedBlock.Seal))
+	if err != nil {
+		return err
 	}
+	createdProposeMsg.Signature = signature
+
+	receipients := sApi.receipients(createdState)
 
 	iLogger.Infof(nil, "[PBFT] Leader broadcasts ProposeMsg to %v", receipients)
 	if err := sApi.propagateService.BroadcastProposeMsg(*createdProposeMsg, receipients); err != nil {
@@ -82,14 +125,26 @@ func (sApi *StateApi) StartConsensus(proposedBlock pbft.ProposedBlock) error {
 	createdState.Start()
 	iLogger.Infof(nil, "[PBFT] Consensus starts - Stage: [%s]", createdState.CurrentStage)
 
+	// the leader is a representative too, its prevote counts for the quorum
+	if err := sApi.prevote(createdState, receipients); err != nil {
+		return err
+	}
+
 	if err := sApi.repo.Save(*createdState); err != nil {
 		return err
 	}
 
+	sApi.proposed = proposedBlock
+	sApi.roundLeftTime = sApi.roundTimeout
+
 	return nil
 }
 
+// HandleProposeMsg prevotes the proposal of the leader. A proposal of another round replaces the round
+// the representative is in, the leader only starts a new round when the former one is finished or given up
 func (sApi *StateApi) HandleProposeMsg(msg pbft.ProposeMsg) error {
+	sApi.mux.Lock()
+	defer sApi.mux.Unlock()
 
 	parliament := sApi.parliamentRepository.Load()
 
@@ -98,117 +153,376 @@ func (sApi *StateApi) HandleProposeMsg(msg pbft.ProposeMsg) error {
 		return pbft.InvalidLeaderIdError
 	}
 
-	builtState := pbft.BuildState(msg)
+	if err := pbft.VerifyProposeMsg(msg, sApi.verifier); err != nil {
+		iLogger.Errorf(nil, "[PBFT] Rejected unverifiable proposal - Sender: [%s], Reason: [%s]", msg.SenderID, err.Error())
+		return err
+	}
 
-	receipients := make([]pbft.Representative, 0)
-	for _, rep := range builtState.Representatives {
-		if rep.ID != sApi.publisherID {
-			receipients = append(receipients, rep)
+	// a late proposal of a round the representative left
+	if sApi.isClosed(msg.StateID.ID) {
+		return nil
+	}
+
+	loadedState, err := sApi.repo.Load()
+	hasState := err == nil
+	if hasState && loadedState.StateID.ID == msg.StateID.ID {
+		if evidence, ok := pbft.DetectDoublePropose(loadedState, msg); ok {
+			sApi.report(evidence)
+			return ErrConflictingProposal
 		}
+
+		// the proposal of the round is already prevoted
+		return nil
 	}
 
-	iLogger.Debugf(nil, "[PBFT] Representative broadcasts PreVoteMsg to %v", receipients)
-	prevoteMsg := pbft.NewPrevoteMsg(builtState, sApi.publisherID)
-	if err := sApi.propagateService.BroadcastPrevoteMsg(*prevoteMsg, receipients); err != nil {
+	if sApi.isLockedOnAnotherBlock(msg.ProposedBlock) {
+		iLogger.Infof(nil, "[PBFT] Rejected proposal of another block at the locked height - Sender: [%s], Height: [%d]", msg.SenderID, msg.ProposedBlock.Height)
+		return ErrLockedOnAnotherBlock
+	}
+
+	// an invalid proposal gets no vote
+	if err := sApi.blockValidator.ValidateProposedBlock(msg.ProposedBlock); err != nil {
+		iLogger.Errorf(nil, "[PBFT] Rejected proposed block - Sender: [%s], Reason: [%s]", msg.SenderID, err.Error())
 		return err
 	}
 
-	builtState.ToPrevoteStage()
-	logger.Infof(nil, "[PBFT] Prevoted - Stage: [%s]", builtState.CurrentStage)
+	if hasState {
+		iLogger.Infof(nil, "[PBFT] Round is replaced by a new proposal - StateID: [%s]", loadedState.StateID.ID)
+		sApi.closeRound(loadedState.StateID.ID)
+	}
+
+	builtState := pbft.BuildState(msg)
+	receipients := sApi.receipients(builtState)
 
-	if err := sApi.repo.Save(*builtState); err != nil {
+	if err := sApi.prevote(builtState, receipients); err != nil {
 		return err
 	}
 
-	return nil
+	// votes which arrived before the proposal may already satisfy the conditions
+	sApi.restoreBufferedMsgs(builtState)
+	sApi.roundLeftTime = sApi.roundTimeout
+
+	return sApi.advance(builtState, receipients)
 }
 
-func (sApi *StateApi) HandlePrevoteMsg(msg pbft.PrevoteMsg) (returnErr error) {
+func (sApi *StateApi) HandlePrevoteMsg(msg pbft.PrevoteMsg) error {
+	sApi.mux.Lock()
+	defer sApi.mux.Unlock()
 
 	loadedState, err := sApi.repo.Load()
-	if err != nil {
-		sApi.tempPrevoteMsgPool.Save(&msg)
-		iLogger.Debugf(nil, "[PBFT] %s while handling PreVote message", err)
+	if err != nil || loadedState.StateID.ID != msg.StateID.ID {
+		sApi.bufferPrevoteMsg(msg)
 		return nil
 	}
 
-	receipients := make([]pbft.Representative, 0)
-	for _, rep := range loadedState.Representatives {
-		if rep.ID != sApi.publisherID {
-			receipients = append(receipients, rep)
-		}
+	sApi.restoreBufferedMsgs(&loadedState)
+
+	if err := sApi.savePrevote(&loadedState, msg); err != nil {
+		return err
 	}
 
-	tempPrevoteMsgPool := sApi.tempPrevoteMsgPool.Get()
-	for i := 0; i < len(tempPrevoteMsgPool); i++ {
-		loadedState.PrevoteMsgPool.Save(&tempPrevoteMsgPool[i])
+	return sApi.advance(&loadedState, sApi.receipients(&loadedState))
+}
+
+func (sApi *StateApi) HandlePreCommitMsg(msg pbft.PreCommitMsg) error {
+	sApi.mux.Lock()
+	defer sApi.mux.Unlock()
+
+	loadedState, err := sApi.repo.Load()
+	if err != nil || loadedState.StateID.ID != msg.StateID.ID {
+		sApi.bufferPreCommitMsg(msg)
+		return nil
 	}
-	sApi.tempPrevoteMsgPool.RemoveAllMsgs()
 
-	if err := loadedState.SavePrevoteMsg(&msg); err != nil {
+	sApi.restoreBufferedMsgs(&loadedState)
+
+	if err := sApi.savePreCommit(&loadedState, msg); err != nil {
 		return err
 	}
 
-	defer func() {
-		if err := sApi.repo.Save(loadedState); err != nil {
-			returnErr = err
-		}
-	}()
-
-	if loadedState.CheckPrevoteCondition() {
-		if loadedState.CurrentStage == pbft.PREVOTE_STAGE {
-			iLogger.Infof(nil, "[PBFT] Representative broadcasts PreCommitMsg to %v", receipients)
-			newCommitMsg := pbft.NewPreCommitMsg(&loadedState, sApi.publisherID)
-			if err := sApi.propagateService.BroadcastPreCommitMsg(*newCommitMsg, receipients); err != nil {
-				return err
-			}
-
-			loadedState.ToPreCommitStage()
-			iLogger.Infof(nil, "[PBFT] PreCommitted - Stage: [%s]", loadedState.CurrentStage)
+	return sApi.advance(&loadedState, sApi.receipients(&loadedState))
+}
+
+// Tick counts down the round timeout by a millisecond. A round which does not finish in time is given up,
+// so a lost message can not hold the representative in it. The leader then proposes its block again in a new round
+func (sApi *StateApi) Tick() {
+	sApi.mux.Lock()
+	defer sApi.mux.Unlock()
+
+	state, err := sApi.repo.Load()
+	if err != nil {
+		return
+	}
+
+	sApi.roundLeftTime--
+	if sApi.roundLeftTime > 0 {
+		return
+	}
+
+	iLogger.Infof(nil, "[PBFT] Round timed out - StateID: [%s], Stage: [%s]", state.StateID.ID, state.CurrentStage)
+	sApi.closeRound(state.StateID.ID)
+}
+
+// RunRoundTimer ticks the round timeout every millisecond until StopRoundTimer is called
+func (sApi *StateApi) RunRoundTimer() {
+	tick := time.Tick(1 * time.Millisecond)
+
+	for {
+		select {
+		case <-tick:
+			sApi.Tick()
+		case <-sApi.quit:
+			return
 		}
 	}
+}
 
-	return returnErr
+func (sApi *StateApi) StopRoundTimer() {
+	sApi.quit <- struct{}{}
 }
 
-func (sApi *StateApi) HandlePreCommitMsg(msg pbft.PreCommitMsg) error {
+// advance moves the state on as far as its votes allow, the own precommit of the representative
+// may be the one which completes the precommit quorum
+func (sApi *StateApi) advance(state *pbft.State, receipients []pbft.Representative) error {
+	if state.CheckPrevoteCondition() {
+		if err := sApi.preCommit(state, receipients); err != nil {
+			return err
+		}
+	}
 
-	loadedState, err := sApi.repo.Load()
+	if state.CheckPreCommitCondition() {
+		return sApi.finish(state, receipients)
+	}
+
+	return sApi.repo.Save(*state)
+}
+
+// broadcast the prevote of the state and count it, a quorum includes the vote of the representative itself
+func (sApi *StateApi) prevote(state *pbft.State, receipients []pbft.Representative) error {
+	iLogger.Debugf(nil, "[PBFT] Representative broadcasts PreVoteMsg to %v", receipients)
+	prevoteMsg := pbft.NewPrevoteMsg(state, sApi.publisherID)
+	signature, err := sApi.signer.Sign(common.VoteSigningData(common.PrevoteVote, state.StateID.ID, state.Block.Height, prevoteMsg.BlockHash))
 	if err != nil {
-		sApi.tempPreCommitMsgPool.Save(&msg)
-		iLogger.Debug(nil, "[PBFT] State repository is empty when handling PreCommit message")
+		return err
+	}
+	prevoteMsg.Signature = signature
+
+	if err := sApi.propagateService.BroadcastPrevoteMsg(*prevoteMsg, receipients); err != nil {
+		return err
+	}
+
+	if err := state.SavePrevoteMsg(prevoteMsg); err != nil {
+		return err
+	}
+
+	state.ToPrevoteStage()
+	logger.Infof(nil, "[PBFT] Prevoted - Stage: [%s]", state.CurrentStage)
+
+	return nil
+}
+
+// broadcast the precommit of the state once, when the representative has prevoted
+func (sApi *StateApi) preCommit(state *pbft.State, receipients []pbft.Representative) error {
+	if state.CurrentStage != pbft.PREVOTE_STAGE {
 		return nil
 	}
 
-	tempPreCommitMsgPool := sApi.tempPreCommitMsgPool.Get()
-	for i := 0; i < len(tempPreCommitMsgPool); i++ {
-		loadedState.PreCommitMsgPool.Save(&tempPreCommitMsgPool[i])
+	iLogger.Infof(nil, "[PBFT] Representative broadcasts PreCommitMsg to %v", receipients)
+	newCommitMsg := pbft.NewPreCommitMsg(state, sApi.publisherID)
+	signature, err := sApi.signer.Sign(common.VoteSigningData(common.PreCommitVote, state.StateID.ID, state.Block.Height, state.Block.Seal))
+	if err != nil {
+		return err
+	}
+	newCommitMsg.Signature = signature
+
+	if err := sApi.propagateService.BroadcastPreCommitMsg(*newCommitMsg, receipients); err != nil {
+		return err
+	}
+
+	if err := state.SavePreCommitMsg(newCommitMsg); err != nil {
+		return err
+	}
+
+	state.ToPreCommitStage()
+	sApi.locked = state.Block
+	iLogger.Infof(nil, "[PBFT] PreCommitted - Stage: [%s]", state.CurrentStage)
+
+	return nil
+}
+
+// a representative which has not precommitted yet does it before finishing,
+// the ones behind it may still need its precommit to reach the condition
+func (sApi *StateApi) finish(state *pbft.State, receipients []pbft.Representative) error {
+	if err := sApi.preCommit(state, receipients); err != nil {
+		return err
+	}
+
+	certificate, err := common.Serialize(state.BuildCertificate())
+	if err != nil {
+		return err
+	}
+
+	e := event.ConsensusFinished{
+		Seal:        state.Block.Seal,
+		Body:        state.Block.Body,
+		Certificate: certificate,
 	}
-	sApi.tempPreCommitMsgPool.RemoveAllMsgs()
 
-	if err := loadedState.SavePreCommitMsg(&msg); err != nil {
+	if err := sApi.eventService.Publish("block.confirm", e); err != nil {
 		return err
 	}
+	iLogger.Debug(nil, "[PBFT] Published block confirm event")
+
+	if sApi.locked.Height <= state.Block.Height {
+		sApi.locked = pbft.ProposedBlock{}
+	}
+	if sApi.proposed.Height <= state.Block.Height {
+		sApi.proposed = pbft.ProposedBlock{}
+	}
+
+	sApi.closeRound(state.StateID.ID)
+	logger.Infof(nil, "[PBFT] Consensus is finished.")
+	return nil
+}
+
+// closeRound leaves the round, the messages which arrive for it later are dropped
+func (sApi *StateApi) closeRound(stateID string) {
+	sApi.closedStateIDs = append(sApi.closedStateIDs, stateID)
+	if len(sApi.closedStateIDs) > closedStateLimit {
+		sApi.closedStateIDs = sApi.closedStateIDs[1:]
+	}
 
-	if loadedState.CheckPreCommitCondition() {
-		e := event.ConsensusFinished{
-			Seal: loadedState.Block.Seal,
-			Body: loadedState.Block.Body,
+	sApi.repo.Remove()
+}
+
+func (sApi *StateApi) isClosed(stateID string) bool {
+	for _, id := range sApi.closedStateIDs {
+		if id == stateID {
+			return true
 		}
+	}
 
-		if err := sApi.eventService.Publish("block.confirm", e); err != nil {
-			return err
+	return false
+}
+
+// a representative locked on a block prevotes no other block at its height, so a block precommitted
+// by a quorum in a round which some representatives gave up can not be replaced in a later round
+func (sApi *StateApi) isLockedOnAnotherBlock(block pbft.ProposedBlock) bool {
+	return sApi.locked.Seal != nil && sApi.locked.Height == block.Height && !bytes.Equal(sApi.locked.Seal, block.Seal)
+}
+
+// a leader proposes the block it precommitted or proposed before at the height again,
+// the representatives locked on it would not prevote another one
+func (sApi *StateApi) blockToPropose(block pbft.ProposedBlock) pbft.ProposedBlock {
+	if sApi.locked.Seal != nil && sApi.locked.Height == block.Height {
+		return sApi.locked
+	}
+
+	if sApi.proposed.Seal != nil && sApi.proposed.Height == block.Height {
+		return sApi.proposed
+	}
+
+	return block
+}
+
+func (sApi *StateApi) receipients(state *pbft.State) []pbft.Representative {
+	receipients := make([]pbft.Representative, 0)
+	for _, rep := range state.Representatives {
+		if rep.ID != sApi.publisherID {
+			receipients = append(receipients, rep)
 		}
-		iLogger.Debug(nil, "[PBFT] Published block confirm event")
+	}
 
-		sApi.repo.Remove()
-		logger.Infof(nil, "[PBFT] Consensus is finished.")
-		return nil
+	return receipients
+}
+
+// savePrevote counts the prevote of a representative for the proposed block.
+// The signature is verified before the vote is compared with the earlier vote of the sender,
+// so a forged vote can neither be counted nor make the vote of an honest representative look conflicting
+func (sApi *StateApi) savePrevote(state *pbft.State, msg pbft.PrevoteMsg) error {
+	if err := state.VerifyPrevoteMsg(msg, sApi.verifier); err != nil {
+		iLogger.Debugf(nil, "[PBFT] Dropped unverifiable prevote - Sender: [%s], Reason: [%s]", msg.SenderID, err.Error())
+		return err
 	}
 
-	if err := sApi.repo.Save(loadedState); err != nil {
+	if evidence, ok := pbft.DetectDoublePrevote(*state, msg); ok {
+		sApi.report(evidence)
+		return ErrConflictingPrevote
+	}
+
+	if !bytes.Equal(msg.BlockHash, state.Block.Seal) {
+		return pbft.ErrBlockHashMismatch
+	}
+
+	return state.SavePrevoteMsg(&msg)
+}
+
+// savePreCommit counts the precommit of a representative, its signature goes into the commit certificate
+func (sApi *StateApi) savePreCommit(state *pbft.State, msg pbft.PreCommitMsg) error {
+	if err := state.VerifyPreCommitMsg(msg, sApi.verifier); err != nil {
+		iLogger.Debugf(nil, "[PBFT] Dropped unverifiable precommit - Sender: [%s], Reason: [%s]", msg.SenderID, err.Error())
 		return err
 	}
 
-	return nil
+	return state.SavePreCommitMsg(&msg)
+}
+
+// move the messages buffered for the state into it.
+// messages of another round stay buffered, they may belong to the round which comes next
+func (sApi *StateApi) restoreBufferedMsgs(state *pbft.State) {
+	prevoteMsgs := sApi.tempPrevoteMsgPool.Get()
+	sApi.tempPrevoteMsgPool.RemoveAllMsgs()
+	for _, msg := range prevoteMsgs {
+		if msg.StateID.ID != state.StateID.ID {
+			sApi.tempPrevoteMsgPool.Save(&msg)
+			continue
+		}
+
+		sApi.savePrevote(state, msg)
+	}
+
+	preCommitMsgs := sApi.tempPreCommitMsgPool.Get()
+	sApi.tempPreCommitMsgPool.RemoveAllMsgs()
+	for _, msg := range preCommitMsgs {
+		if msg.StateID.ID != state.StateID.ID {
+			sApi.tempPreCommitMsgPool.Save(&msg)
+			continue
+		}
+
+		sApi.savePreCommit(state, msg)
+	}
+}
+
+// the buffer only keeps the messages of the latest round seen, so a stale message can not hold
+// the place of its sender in the next round. Messages of a closed round are dropped
+func (sApi *StateApi) bufferPrevoteMsg(msg pbft.PrevoteMsg) {
+	if sApi.isClosed(msg.StateID.ID) {
+		return
+	}
+
+	buffered := sApi.tempPrevoteMsgPool.Get()
+	if len(buffered) != 0 && buffered[0].StateID.ID != msg.StateID.ID {
+		sApi.tempPrevoteMsgPool.RemoveAllMsgs()
+	}
+
+	sApi.tempPrevoteMsgPool.Save(&msg)
+}
+
+func (sApi *StateApi) bufferPreCommitMsg(msg pbft.PreCommitMsg) {
+	if sApi.isClosed(msg.StateID.ID) {
+		return
+	}
+
+	buffered := sApi.tempPreCommitMsgPool.Get()
+	if len(buffered) != 0 && buffered[0].StateID.ID != msg.StateID.ID {
+		sApi.tempPreCommitMsgPool.RemoveAllMsgs()
+	}
+
+	sApi.tempPreCommitMsgPool.Save(&msg)
+}
+
+func (sApi *StateApi) report(evidence pbft.Evidence) {
+	if err := sApi.reporter.Report(evidence); err != nil {
+		iLogger.Errorf(nil, "[PBFT] Cannot report misbehaviour - Offender: [%s], Err: [%s]", evidence.OffenderID, err.Error())
+	}
 }
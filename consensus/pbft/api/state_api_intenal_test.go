@@ -17,9 +17,12 @@
 package api
 
 import (
+	"bytes"
+	"errors"
 	"strconv"
 	"testing"
 
+	"github.com/it-chain/engine/common"
 	"github.com/it-chain/engine/consensus/pbft"
 	"github.com/it-chain/engine/consensus/pbft/infra/mem"
 	"github.com/it-chain/engine/consensus/pbft/test/mock"
@@ -56,7 +59,7 @@ func TestStateApi_StartConsensus_State(t *testing.T) {
 				isPreCommitConditionSatisfied bool
 			}{normalBlock, 5, false, false},
 			err:   nil,
-			stage: pbft.PROPOSE_STAGE,
+			stage: pbft.PREVOTE_STAGE,
 		},
 	}
 
@@ -71,15 +74,16 @@ func TestStateApi_StartConsensus_State(t *testing.T) {
 
 func TestStateApi_HandleProposeMsg_CheckState(t *testing.T) {
 
-	var validLeaderProposeMsg = pbft.ProposeMsg{
+	reps := []pbft.Representative{{ID: "user0"}, {ID: "user1"}, {ID: "user2"}, {ID: "user3"}, {ID: "user4"}}
+	var validLeaderProposeMsg = signProposeMsg(pbft.ProposeMsg{
 		StateID:        pbft.StateID{ID: "state1"},
 		SenderID:       "user0",
-		Representative: nil,
+		Representative: reps,
 		ProposedBlock: pbft.ProposedBlock{
 			Seal: make([]byte, 0),
 			Body: make([]byte, 0),
 		},
-	}
+	})
 
 	tests := map[string]struct {
 		input struct {
@@ -111,11 +115,39 @@ func TestStateApi_HandleProposeMsg_CheckState(t *testing.T) {
 	}
 }
 
+func TestStateApi_HandleProposeMsg_InvalidBlock(t *testing.T) {
+	// given
+	errInvalidBlock := errors.New("invalid block")
+	proposeMsg := signProposeMsg(pbft.ProposeMsg{
+		StateID:  pbft.StateID{ID: "state1"},
+		SenderID: "user0",
+		ProposedBlock: pbft.ProposedBlock{
+			Seal: make([]byte, 0),
+			Body: make([]byte, 0),
+		},
+	})
+
+	cApi := setUpApiCondition(5, true, false, false)
+	blockValidator := mock.BlockValidator{}
+	blockValidator.ValidateProposedBlockFunc = func(block pbft.ProposedBlock) error {
+		return errInvalidBlock
+	}
+	cApi.blockValidator = blockValidator
+
+	// when
+	err := cApi.HandleProposeMsg(proposeMsg)
+
+	// then
+	assert.Equal(t, errInvalidBlock, err)
+	_, err = cApi.repo.Load()
+	assert.Equal(t, pbft.ErrEmptyRepo, err)
+}
+
 func TestStateApi_RepositoryClone(t *testing.T) {
 	// stateApi1 에는 setUpApiCondition에 의해 repo가 set된 상황
 	stateApi1 := setUpApiCondition(5, true, false, false)
 	// stateApi2 에는 stateApi1의 Repo가 주입된 상황
-	stateApi2 := NewStateApi("publish2", &pbft.PropagateService{}, nil, nil, stateApi1.repo)
+	stateApi2 := NewStateApi("publish2", &pbft.PropagateService{}, nil, nil, nil, nil, nil, nil, stateApi1.repo)
 
 	stateApi1.repo.Remove()
 	_, err := stateApi2.repo.Load()
@@ -142,27 +174,18 @@ func TestStateApi_Reflect_TemporaryPrevoteMsgPool(t *testing.T) {
 	reps := make([]pbft.Representative, 0)
 	for i := 0; i < 5; i++ {
 		reps = append(reps, pbft.Representative{
-			ID: "user",
+			ID: "user" + strconv.Itoa(i),
 		})
 	}
-	var tempProposeMsg = pbft.ProposeMsg{
+	var tempProposeMsg = signProposeMsg(pbft.ProposeMsg{
 		StateID:        pbft.StateID{"state1"},
 		SenderID:       "user0",
 		Representative: reps,
 		ProposedBlock:  normalBlock,
-	}
+	})
 
-	var tempPrevoteMsg = pbft.PrevoteMsg{
-		StateID:   pbft.StateID{"state1"},
-		SenderID:  "user1",
-		BlockHash: []byte{1, 2, 3, 5},
-	}
-
-	var tempPrevoteMsg2 = pbft.PrevoteMsg{
-		StateID:   pbft.StateID{"state1"},
-		SenderID:  "user2",
-		BlockHash: []byte{1, 2, 3, 5},
-	}
+	var tempPrevoteMsg = signPrevoteMsg("state1", "user1", normalBlock.Seal)
+	var tempPrevoteMsg2 = signPrevoteMsg("state1", "user2", normalBlock.Seal)
 
 	//When Propose Msg를 받지못해 Repo에 State가 없음 then sApi의 tempPool이 저장 후 State가 생겼을 때 추가
 	stateApi := setUpApiCondition(4, true, false, false)
@@ -175,9 +198,266 @@ func TestStateApi_Reflect_TemporaryPrevoteMsgPool(t *testing.T) {
 	stateApi.HandleProposeMsg(tempProposeMsg)
 	stateApi.HandlePrevoteMsg(tempPrevoteMsg2)
 
+	// the prevote of the representative itself is counted too
 	state, _ := stateApi.repo.Load()
+	assert.Equal(t, 3, len(state.PrevoteMsgPool.Get()))
+
+}
+
+func TestStateApi_HandleProposeMsg_BufferedMsgs(t *testing.T) {
+	// given
+	reps := []pbft.Representative{{ID: "user0"}, {ID: "user1"}, {ID: "user2"}, {ID: "user3"}}
+	proposeMsg := signProposeMsg(pbft.ProposeMsg{
+		StateID:        pbft.StateID{"state1"},
+		SenderID:       "user0",
+		Representative: reps,
+		ProposedBlock:  normalBlock,
+	})
+
+	stateApi := setUpApiCondition(4, true, false, false)
+	stateApi.repo.Remove()
+
+	// a stale prevote of the former state is dropped when the votes of the new state arrive
+	stateApi.HandlePrevoteMsg(signPrevoteMsg("state0", "user2", []byte{1, 2, 3, 3}))
+	stateApi.HandlePrevoteMsg(signPrevoteMsg("state1", "user2", normalBlock.Seal))
+	stateApi.HandlePrevoteMsg(signPrevoteMsg("state1", "user3", normalBlock.Seal))
+
+	// when
+	err := stateApi.HandleProposeMsg(proposeMsg)
+
+	// then
+	assert.NoError(t, err)
+	state, _ := stateApi.repo.Load()
+	assert.Equal(t, pbft.PRECOMMIT_STAGE, state.CurrentStage)
+	assert.Equal(t, 3, len(state.PrevoteMsgPool.Get()))
+	assert.Equal(t, 0, len(stateApi.tempPrevoteMsgPool.Get()))
+}
+
+func TestStateApi_HandleProposeMsg_UnverifiableProposal(t *testing.T) {
+	// given
+	proposeMsg := signProposeMsg(pbft.ProposeMsg{
+		StateID:        pbft.StateID{"state1"},
+		SenderID:       "user0",
+		Representative: []pbft.Representative{{ID: "user0"}, {ID: "user1"}, {ID: "user2"}, {ID: "user3"}},
+		ProposedBlock:  normalBlock,
+	})
+	proposeMsg.ProposedBlock.Height = 1
+
+	stateApi := setUpApiCondition(4, true, false, false)
+	stateApi.repo.Remove()
+
+	// when
+	err := stateApi.HandleProposeMsg(proposeMsg)
+
+	// then
+	assert.Equal(t, common.ErrInvalidSignature, err)
+	_, err = stateApi.repo.Load()
+	assert.Equal(t, pbft.ErrEmptyRepo, err)
+}
+
+func TestStateApi_HandlePrevoteMsg_Verification(t *testing.T) {
+	// given
+	reps := []pbft.Representative{{ID: "user0"}, {ID: "user1"}, {ID: "user2"}, {ID: "user3"}, {ID: "user4"}}
+	proposeMsg := signProposeMsg(pbft.ProposeMsg{
+		StateID:        pbft.StateID{"state1"},
+		SenderID:       "user0",
+		Representative: reps,
+		ProposedBlock:  normalBlock,
+	})
+
+	forged := signPrevoteMsg("state1", "user2", []byte{9, 9, 9, 9})
+	forged.Signature.Value = []byte("forged")
+
+	tests := map[string]struct {
+		msg pbft.PrevoteMsg
+		err error
+	}{
+		"forged signature":     {msg: forged, err: common.ErrInvalidSignature},
+		"not a representative": {msg: signPrevoteMsg("state1", "user9", normalBlock.Seal), err: pbft.ErrNotRepresentative},
+		"signed by another":    {msg: pbft.PrevoteMsg{StateID: pbft.StateID{"state1"}, SenderID: "user2", BlockHash: normalBlock.Seal, Signature: signPrevoteMsg("state1", "user3", normalBlock.Seal).Signature}, err: pbft.ErrSenderNotSigner},
+		"another block":        {msg: signPrevoteMsg("state1", "user2", []byte{1, 2, 3, 3}), err: pbft.ErrBlockHashMismatch},
+	}
+
+	for testName, test := range tests {
+		t.Logf("running test case %s", testName)
+
+		stateApi := setUpApiCondition(5, true, false, false)
+		stateApi.repo.Remove()
+		assert.NoError(t, stateApi.HandleProposeMsg(proposeMsg))
+
+		// when
+		err := stateApi.HandlePrevoteMsg(test.msg)
+
+		// then: the vote is not counted, and the honest vote of the sender is still taken
+		assert.Equal(t, test.err, err)
+		assert.NoError(t, stateApi.HandlePrevoteMsg(signPrevoteMsg("state1", "user2", normalBlock.Seal)))
+
+		state, _ := stateApi.repo.Load()
+		assert.Equal(t, 2, len(state.PrevoteMsgPool.Get()))
+	}
+}
+
+func TestStateApi_HandlePreCommitMsg_Verification(t *testing.T) {
+	// given
+	reps := []pbft.Representative{{ID: "user0"}, {ID: "user1"}, {ID: "user2"}, {ID: "user3"}, {ID: "user4"}}
+	proposeMsg := signProposeMsg(pbft.ProposeMsg{
+		StateID:        pbft.StateID{"state1"},
+		SenderID:       "user0",
+		Representative: reps,
+		ProposedBlock:  normalBlock,
+	})
+
+	stateApi := setUpApiCondition(5, true, false, false)
+	stateApi.repo.Remove()
+	assert.NoError(t, stateApi.HandleProposeMsg(proposeMsg))
+
+	// a precommit signed for another block
+	forged := signPreCommitMsg("state1", "user2", []byte{1, 2, 3, 3})
+
+	// when
+	err := stateApi.HandlePreCommitMsg(forged)
+
+	// then
+	assert.Equal(t, common.ErrInvalidSignature, err)
+
+	// when
+	err = stateApi.HandlePreCommitMsg(signPreCommitMsg("state1", "user2", normalBlock.Seal))
+
+	// then
+	assert.NoError(t, err)
+	state, _ := stateApi.repo.Load()
+	assert.Equal(t, 1, len(state.PreCommitMsgPool.Get()))
+	assert.Equal(t, 1, len(state.BuildCertificate().Signatures))
+}
+
+func TestStateApi_HandlePrevoteMsg_OwnPreCommitFinishes(t *testing.T) {
+	// given
+	reps := []pbft.Representative{{ID: "my"}, {ID: "user0"}, {ID: "user1"}, {ID: "user2"}}
+	stateApi := setUpApiCondition(4, true, false, false)
+	stateApi.repo.Remove()
+
+	assert.NoError(t, stateApi.HandleProposeMsg(signProposeMsg(pbft.ProposeMsg{StateID: pbft.StateID{"state1"}, SenderID: "user0", Representative: reps, ProposedBlock: normalBlock})))
+	assert.NoError(t, stateApi.HandlePreCommitMsg(signPreCommitMsg("state1", "user1", normalBlock.Seal)))
+	assert.NoError(t, stateApi.HandlePreCommitMsg(signPreCommitMsg("state1", "user2", normalBlock.Seal)))
+	assert.NoError(t, stateApi.HandlePrevoteMsg(signPrevoteMsg("state1", "user1", normalBlock.Seal)))
+
+	// when: the prevote quorum makes the representative precommit, which completes the precommit quorum
+	err := stateApi.HandlePrevoteMsg(signPrevoteMsg("state1", "user2", normalBlock.Seal))
+
+	// then
+	assert.NoError(t, err)
+	_, err = stateApi.repo.Load()
+	assert.Equal(t, pbft.ErrEmptyRepo, err)
+}
+
+func TestStateApi_HandlePrevoteMsg_NextRound(t *testing.T) {
+	// given
+	reps := []pbft.Representative{{ID: "my"}, {ID: "user0"}, {ID: "user1"}, {ID: "user2"}}
+	stateApi := setUpApiCondition(4, true, false, false)
+	stateApi.repo.Remove()
+	assert.NoError(t, stateApi.HandleProposeMsg(signProposeMsg(pbft.ProposeMsg{StateID: pbft.StateID{"state1"}, SenderID: "user0", Representative: reps, ProposedBlock: normalBlock})))
+
+	// when: a vote of the next round arrives before the representative left the round
+	err := stateApi.HandlePrevoteMsg(signPrevoteMsg("state2", "user1", normalBlock.Seal))
+
+	// then: it is kept for the next round
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(stateApi.tempPrevoteMsgPool.Get()))
+
+	// when
+	err = stateApi.HandleProposeMsg(signProposeMsg(pbft.ProposeMsg{StateID: pbft.StateID{"state2"}, SenderID: "user0", Representative: reps, ProposedBlock: normalBlock}))
+
+	// then: the proposal of the next round replaces the round and takes the buffered vote
+	assert.NoError(t, err)
+	state, _ := stateApi.repo.Load()
+	assert.Equal(t, "state2", state.StateID.ID)
 	assert.Equal(t, 2, len(state.PrevoteMsgPool.Get()))
 
+	// when: a late vote of the left round
+	err = stateApi.HandlePrevoteMsg(signPrevoteMsg("state1", "user2", normalBlock.Seal))
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(stateApi.tempPrevoteMsgPool.Get()))
+}
+
+func TestStateApi_Tick(t *testing.T) {
+	// given
+	reps := []pbft.Representative{{ID: "my"}, {ID: "user0"}, {ID: "user1"}, {ID: "user2"}}
+	stateApi := setUpApiCondition(4, true, false, false)
+	stateApi.repo.Remove()
+	stateApi.SetRoundTimeout(2)
+	assert.NoError(t, stateApi.HandleProposeMsg(signProposeMsg(pbft.ProposeMsg{StateID: pbft.StateID{"state1"}, SenderID: "user0", Representative: reps, ProposedBlock: normalBlock})))
+
+	// when
+	stateApi.Tick()
+
+	// then
+	_, err := stateApi.repo.Load()
+	assert.NoError(t, err)
+
+	// when
+	stateApi.Tick()
+
+	// then: the round is given up and its proposal is not taken again
+	_, err = stateApi.repo.Load()
+	assert.Equal(t, pbft.ErrEmptyRepo, err)
+
+	assert.NoError(t, stateApi.HandleProposeMsg(signProposeMsg(pbft.ProposeMsg{StateID: pbft.StateID{"state1"}, SenderID: "user0", Representative: reps, ProposedBlock: normalBlock})))
+	_, err = stateApi.repo.Load()
+	assert.Equal(t, pbft.ErrEmptyRepo, err)
+}
+
+func TestStateApi_HandleProposeMsg_Locked(t *testing.T) {
+	// given: the representative precommitted the block in a round which it gave up
+	reps := []pbft.Representative{{ID: "my"}, {ID: "user0"}, {ID: "user1"}, {ID: "user2"}}
+	stateApi := setUpApiCondition(4, true, false, false)
+	stateApi.repo.Remove()
+	stateApi.SetRoundTimeout(1)
+
+	assert.NoError(t, stateApi.HandleProposeMsg(signProposeMsg(pbft.ProposeMsg{StateID: pbft.StateID{"state1"}, SenderID: "user0", Representative: reps, ProposedBlock: normalBlock})))
+	assert.NoError(t, stateApi.HandlePrevoteMsg(signPrevoteMsg("state1", "user1", normalBlock.Seal)))
+	assert.NoError(t, stateApi.HandlePrevoteMsg(signPrevoteMsg("state1", "user2", normalBlock.Seal)))
+	state, _ := stateApi.repo.Load()
+	assert.Equal(t, pbft.PRECOMMIT_STAGE, state.CurrentStage)
+	stateApi.Tick()
+
+	anotherBlock := pbft.ProposedBlock{Seal: []byte{4, 3, 2, 1}, Height: normalBlock.Height, Body: []byte{1}}
+
+	// when
+	err := stateApi.HandleProposeMsg(signProposeMsg(pbft.ProposeMsg{StateID: pbft.StateID{"state2"}, SenderID: "user0", Representative: reps, ProposedBlock: anotherBlock}))
+
+	// then
+	assert.Equal(t, ErrLockedOnAnotherBlock, err)
+
+	// when
+	err = stateApi.HandleProposeMsg(signProposeMsg(pbft.ProposeMsg{StateID: pbft.StateID{"state3"}, SenderID: "user0", Representative: reps, ProposedBlock: normalBlock}))
+
+	// then
+	assert.NoError(t, err)
+}
+
+// the signatures of the tests carry the signed data as their value, see setUpApiCondition
+func signProposeMsg(msg pbft.ProposeMsg) pbft.ProposeMsg {
+	msg.Signature = common.Signature{SignerID: msg.SenderID, Value: common.VoteSigningData(common.ProposeVote, msg.StateID.ID, msg.ProposedBlock.Height, msg.ProposedBlock.Seal)}
+	return msg
+}
+
+func signPrevoteMsg(stateID string, senderID string, blockHash []byte) pbft.PrevoteMsg {
+	return pbft.PrevoteMsg{
+		StateID:   pbft.StateID{stateID},
+		SenderID:  senderID,
+		BlockHash: blockHash,
+		Signature: common.Signature{SignerID: senderID, Value: common.VoteSigningData(common.PrevoteVote, stateID, normalBlock.Height, blockHash)},
+	}
+}
+
+func signPreCommitMsg(stateID string, senderID string, blockHash []byte) pbft.PreCommitMsg {
+	return pbft.PreCommitMsg{
+		StateID:   pbft.StateID{stateID},
+		SenderID:  senderID,
+		Signature: common.Signature{SignerID: senderID, Value: common.VoteSigningData(common.PreCommitVote, stateID, normalBlock.Height, blockHash)},
+	}
 }
 
 // todo
@@ -300,7 +580,30 @@ func setUpApiCondition(peerNum int, isNormalBlock bool,
 		}
 		repo.Save(savedConsensus)
 	}
-	cApi := NewStateApi("my", propagateService, eventService, parliamentRepository, repo)
+	signer := mock.Signer{}
+	signer.SignFunc = func(data []byte) (common.Signature, error) {
+		return common.Signature{SignerID: "my", Value: data}, nil
+	}
+
+	blockValidator := mock.BlockValidator{}
+	blockValidator.ValidateProposedBlockFunc = func(block pbft.ProposedBlock) error {
+		return nil
+	}
+
+	verifier := mock.SignatureVerifier{}
+	verifier.VerifyFunc = func(signature common.Signature, data []byte) error {
+		if !bytes.Equal(signature.Value, data) {
+			return common.ErrInvalidSignature
+		}
+		return nil
+	}
+
+	reporter := mock.MisbehaviourReporter{}
+	reporter.ReportFunc = func(evidence pbft.Evidence) error {
+		return nil
+	}
+
+	cApi := NewStateApi("my", propagateService, eventService, signer, verifier, blockValidator, reporter, parliamentRepository, repo)
 
 	return cApi
 }
@@ -23,6 +23,7 @@ import (
 
 	"time"
 
+	"github.com/it-chain/engine/common"
 	"github.com/it-chain/engine/consensus/pbft"
 	"github.com/it-chain/engine/consensus/pbft/api"
 	"github.com/it-chain/engine/consensus/pbft/infra/mem"
@@ -136,16 +137,19 @@ func TestStateApi_StartConsensus_NodeTest(t *testing.T) {
 
 func TestStateApi_HandleProposeMsg(t *testing.T) {
 
+	// the own prevote alone does not finish the consensus of five representatives
+	reps := []pbft.Representative{{ID: "user0"}, {ID: "user1"}, {ID: "user2"}, {ID: "user3"}, {ID: "user4"}}
 	var validLeaderProposeMsg = pbft.ProposeMsg{
 		StateID: pbft.StateID{
 			ID: "state1",
 		},
 		SenderID:       "user0",
-		Representative: nil,
+		Representative: reps,
 		ProposedBlock: pbft.ProposedBlock{
 			Seal: make([]byte, 0),
 			Body: make([]byte, 0),
 		},
+		Signature: common.Signature{SignerID: "user0", Value: common.VoteSigningData(common.ProposeVote, "state1", 0, make([]byte, 0))},
 	}
 	var invalidLeaderProposeMsg = pbft.ProposeMsg{
 		StateID: pbft.StateID{
@@ -171,13 +175,13 @@ func TestStateApi_HandleProposeMsg(t *testing.T) {
 			}{validLeaderProposeMsg, 5, false},
 			err: nil,
 		},
-		"Case 2 PrePrepareMsg의 Sender id와 Request된 Leader id가 일치하며, repo가 차있는 경우": {
+		"Case 2 PrePrepareMsg의 Sender id와 Request된 Leader id가 일치하며, repo가 차있는 경우 (새 round로 교체)": {
 			input: struct {
 				proposeMsg pbft.ProposeMsg
 				peerNum    int
 				isRepoFull bool
 			}{validLeaderProposeMsg, 5, true},
-			err: pbft.ErrInvalidSave,
+			err: nil,
 		},
 		"Case 3 PrePrepareMsg의 Sender id와 Request된 Leader id가 일치하지 않을 경우": {
 			input: struct {
@@ -378,6 +382,26 @@ func setUpApiCondition(peerNum int, isRepoFull bool, isNormalBlock bool) *api.St
 		repo.Save(savedConsensus)
 	}
 
-	cApi := api.NewStateApi("my", propagateService, eventService, parliamentRepository, repo)
+	signer := mock.Signer{}
+	signer.SignFunc = func(data []byte) (common.Signature, error) {
+		return common.Signature{SignerID: "my", Value: data}, nil
+	}
+
+	blockValidator := mock.BlockValidator{}
+	blockValidator.ValidateProposedBlockFunc = func(block pbft.ProposedBlock) error {
+		return nil
+	}
+
+	verifier := mock.SignatureVerifier{}
+	verifier.VerifyFunc = func(signature common.Signature, data []byte) error {
+		return nil
+	}
+
+	reporter := mock.MisbehaviourReporter{}
+	reporter.ReportFunc = func(evidence pbft.Evidence) error {
+		return nil
+	}
+
+	cApi := api.NewStateApi("my", propagateService, eventService, signer, verifier, blockValidator, reporter, parliamentRepository, repo)
 	return cApi
 }
@@ -0,0 +1,89 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/it-chain/engine/consensus/pbft"
+)
+
+type StatusApi struct {
+	parliamentRepository pbft.ParliamentRepository
+	electionService      *pbft.ElectionService
+	stateRepository      pbft.StateRepository
+	lastFinalizedHeight  uint64
+	lastFinalizedAt      time.Time
+	mux                  sync.RWMutex
+}
+
+func NewStatusApi(parliamentRepository pbft.ParliamentRepository, electionService *pbft.ElectionService, stateRepository pbft.StateRepository) *StatusApi {
+	return &StatusApi{
+		parliamentRepository: parliamentRepository,
+		electionService:      electionService,
+		stateRepository:      stateRepository,
+		mux:                  sync.RWMutex{},
+	}
+}
+
+// RecordFinalizedBlock remembers the latest block committed to the chain
+func (s *StatusApi) RecordFinalizedBlock(height uint64, finalizedAt time.Time) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if height < s.lastFinalizedHeight {
+		return
+	}
+
+	s.lastFinalizedHeight = height
+	s.lastFinalizedAt = finalizedAt
+}
+
+func (s *StatusApi) GetStatus() pbft.Status {
+	parliament := s.parliamentRepository.Load()
+
+	representatives := make([]string, 0)
+	for _, representative := range parliament.GetRepresentatives() {
+		representatives = append(representatives, representative.GetID())
+	}
+	sort.Strings(representatives)
+
+	status := pbft.Status{
+		Leader:          parliament.GetLeader().GetID(),
+		Representatives: representatives,
+		ElectionState:   s.electionService.GetState(),
+		Term:            s.electionService.GetTerm(),
+		VoteCount:       s.electionService.GetVoteCount(),
+	}
+
+	if state, err := s.stateRepository.Load(); err == nil {
+		status.State = pbft.NewStateStatus(state)
+	}
+
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+
+	status.LastFinalizedHeight = s.lastFinalizedHeight
+	status.LastFinalizedAt = s.lastFinalizedAt
+	if !s.lastFinalizedAt.IsZero() {
+		status.SinceLastFinalized = time.Since(s.lastFinalizedAt)
+	}
+
+	return status
+}
@@ -0,0 +1,79 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/it-chain/engine/consensus/pbft"
+	"github.com/it-chain/engine/consensus/pbft/api"
+	"github.com/it-chain/engine/consensus/pbft/infra/mem"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusApi_GetStatus(t *testing.T) {
+	// given
+	parliamentRepository := setParliamentRepository()
+	parliament := parliamentRepository.Load()
+	parliament.SetLeader("1")
+	parliamentRepository.Save(parliament)
+
+	electionService := pbft.NewElectionService("1", 30, pbft.LEADER, 2)
+	electionService.SetTerm(3)
+
+	stateRepository := mem.NewStateRepository()
+	statusApi := api.NewStatusApi(parliamentRepository, electionService, stateRepository)
+
+	// when
+	status := statusApi.GetStatus()
+
+	// then
+	assert.Equal(t, "1", status.Leader)
+	assert.Equal(t, []string{"1", "2", "3"}, status.Representatives)
+	assert.Equal(t, pbft.LEADER, status.ElectionState)
+	assert.Equal(t, 3, status.Term)
+	assert.Equal(t, 2, status.VoteCount)
+	assert.Nil(t, status.State)
+	assert.Equal(t, time.Duration(0), status.SinceLastFinalized)
+
+	// given
+	state := pbft.State{
+		StateID:          pbft.NewStateID("state1"),
+		Block:            pbft.ProposedBlock{Seal: []byte("seal"), Height: 5},
+		CurrentStage:     pbft.PREVOTE_STAGE,
+		PrevoteMsgPool:   pbft.NewPrevoteMsgPool(),
+		PreCommitMsgPool: pbft.NewPreCommitMsgPool(),
+	}
+	state.SavePrevoteMsg(pbft.NewPrevoteMsg(&state, "2"))
+	stateRepository.Save(state)
+	statusApi.RecordFinalizedBlock(4, time.Now().Add(-time.Minute))
+
+	// when
+	status = statusApi.GetStatus()
+
+	// then
+	assert.Equal(t, &pbft.StateStatus{
+		StateID:        "state1",
+		Stage:          pbft.PREVOTE_STAGE,
+		BlockHeight:    5,
+		PrevoteCount:   1,
+		PreCommitCount: 0,
+	}, status.State)
+	assert.Equal(t, uint64(4), status.LastFinalizedHeight)
+	assert.True(t, status.SinceLastFinalized >= time.Minute)
+}
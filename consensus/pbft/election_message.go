@@ -16,8 +16,15 @@
 
 package pbft
 
+import (
+	"encoding/json"
+
+	"github.com/it-chain/engine/common"
+)
+
 type UpdateLeaderMessage struct {
 	Representative Representative
+	Term           int
 }
 
 type ParliamentMessage struct {
@@ -25,8 +32,34 @@ type ParliamentMessage struct {
 }
 
 type RequestVoteMessage struct {
-	Term int
+	Term        int
+	CandidateID string
+	LastHeight  uint64
+	LastSeal    []byte
+	// the position of the raft log of the candidate, set only in raft mode
+	LastLogTerm  int
+	LastLogIndex uint64
 }
 
+// VoteMessage is signed by the voter, so a vote is counted for a verified representative
 type VoteMessage struct {
+	Term        int
+	CandidateID string
+	VoteGranted bool
+	Signature   common.Signature
+}
+
+func (v VoteMessage) SigningData() []byte {
+	data, _ := json.Marshal(struct {
+		Term        int
+		CandidateID string
+		VoteGranted bool
+	}{v.Term, v.CandidateID, v.VoteGranted})
+
+	return data
+}
+
+type HeartbeatMessage struct {
+	Term     int
+	LeaderID string
 }
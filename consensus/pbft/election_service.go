@@ -26,6 +26,7 @@ const (
 	CANDIDATE ElectionState = "CANDIDATE"
 	TICKING   ElectionState = "TICKING"
 	NORMAL    ElectionState = "NORMAL"
+	LEADER    ElectionState = "LEADER"
 )
 
 type ElectionState string
@@ -35,10 +36,12 @@ type ElectionService struct {
 	candidate Representative // candidate peer to be leader later
 	leftTime  int            //left time in millisecond
 	state     ElectionState
-	Voted     bool
+	votedFor  string // candidate voted for in the current term
 	voteCount int
+	voters    map[string]bool // representatives whose vote is counted in the current term
 	mux       sync.Mutex
 	term      int
+	randomize func(min, max int) int // picks the election timeout
 }
 
 func NewElectionService(id string, leftTime int, state ElectionState, voteCount int) *ElectionService {
@@ -48,15 +51,26 @@ func NewElectionService(id string, leftTime int, state ElectionState, voteCount
 		candidate: Representative{
 			ID: "",
 		},
-		Voted:     false,
+		votedFor:  "",
 		leftTime:  leftTime,
 		state:     state,
 		voteCount: voteCount,
+		voters:    make(map[string]bool),
 		mux:       sync.Mutex{},
 		term:      0,
+		randomize: GenRandomInRange,
 	}
 }
 
+// SetRandomizer replaces the random source of the election timeout, a seeded one makes elections reproducible
+func (e *ElectionService) SetRandomizer(randomize func(min, max int) int) {
+
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	e.randomize = randomize
+}
+
 func (e *ElectionService) SetLeftTime(time int) error {
 
 	e.mux.Lock()
@@ -71,6 +85,7 @@ func (e *ElectionService) SetVoteCount(count int) error {
 	defer e.mux.Unlock()
 
 	e.voteCount = count
+	e.voters = make(map[string]bool)
 	return nil
 }
 
@@ -79,7 +94,7 @@ func (e *ElectionService) InitLeftTime() {
 	e.mux.Lock()
 	defer e.mux.Unlock()
 
-	e.leftTime = GenRandomInRange(150, 300)
+	e.leftTime = e.randomize(150, 300)
 }
 
 func (e *ElectionService) ResetLeftTime() {
@@ -87,9 +102,7 @@ func (e *ElectionService) ResetLeftTime() {
 	e.mux.Lock()
 	defer e.mux.Unlock()
 
-	e.Voted = false
-
-	e.leftTime = GenRandomInRange(150, 300)
+	e.leftTime = e.randomize(150, 300)
 }
 
 //count down left time by tick millisecond  until 0
@@ -145,6 +158,7 @@ func (e *ElectionService) ResetVoteCount() {
 	defer e.mux.Unlock()
 
 	e.voteCount = 0
+	e.voters = make(map[string]bool)
 }
 
 func (e *ElectionService) CountUpVoteCount() {
@@ -155,6 +169,21 @@ func (e *ElectionService) CountUpVoteCount() {
 	e.voteCount = e.voteCount + 1
 }
 
+// CountVote counts the vote of the voter once in the term, it returns false when the vote is already counted
+func (e *ElectionService) CountVote(voterId string) bool {
+
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	if e.voters[voterId] {
+		return false
+	}
+
+	e.voters[voterId] = true
+	e.voteCount = e.voteCount + 1
+	return true
+}
+
 func (e *ElectionService) SetCandidate(representative Representative) {
 	e.mux.Lock()
 	defer e.mux.Unlock()
@@ -185,9 +214,59 @@ func (e *ElectionService) GetTerm() int {
 	return e.term
 }
 
-func (e *ElectionService) SetVoted(index bool) {
+func (e *ElectionService) SetTerm(term int) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	e.term = term
+}
+
+// GrantVote records the vote for the candidate when the term is the current one and no other candidate got the vote in it.
+// Checking and recording the vote in one step keeps two candidates from getting the vote of the same term
+func (e *ElectionService) GrantVote(term int, candidateId string) bool {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	if e.term != term {
+		return false
+	}
+
+	if e.votedFor != "" && e.votedFor != candidateId {
+		return false
+	}
+
+	e.votedFor = candidateId
+	return true
+}
+
+func (e *ElectionService) GetVotedFor() string {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	return e.votedFor
+}
+
+func (e *ElectionService) SetVotedFor(candidateId string) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	e.votedFor = candidateId
+}
+
+func (e *ElectionService) GetElectionTerm() ElectionTerm {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	return ElectionTerm{
+		Term:     e.term,
+		VotedFor: e.votedFor,
+	}
+}
+
+func (e *ElectionService) SetElectionTerm(electionTerm ElectionTerm) {
 	e.mux.Lock()
 	defer e.mux.Unlock()
 
-	e.Voted = index
+	e.term = electionTerm.Term
+	e.votedFor = electionTerm.VotedFor
 }
@@ -17,6 +17,7 @@
 package pbft_test
 
 import (
+	"sync"
 	"testing"
 
 	"github.com/it-chain/engine/consensus/pbft"
@@ -119,6 +120,37 @@ func TestElectionService_SetVoteCount(t *testing.T) {
 	assert.Equal(t, e.GetVoteCount(), 100)
 }
 
+func TestElectionService_GrantVote(t *testing.T) {
+	e := pbft.NewElectionService("1", 30, pbft.TICKING, 0)
+	e.SetTerm(2)
+
+	// candidates racing for the vote of the term, only one of them gets it
+	granted := make(chan string, 10)
+	wg := sync.WaitGroup{}
+	for _, candidate := range []string{"2", "3", "4", "5", "6", "7", "8", "9", "10", "11"} {
+		wg.Add(1)
+		go func(candidate string) {
+			defer wg.Done()
+			if e.GrantVote(2, candidate) {
+				granted <- candidate
+			}
+		}(candidate)
+	}
+	wg.Wait()
+	close(granted)
+
+	winners := make([]string, 0)
+	for candidate := range granted {
+		winners = append(winners, candidate)
+	}
+	assert.Equal(t, len(winners), 1)
+	assert.Equal(t, e.GetVotedFor(), winners[0])
+
+	// the same candidate is granted again, a stale term is not
+	assert.Equal(t, e.GrantVote(2, winners[0]), true)
+	assert.Equal(t, e.GrantVote(1, winners[0]), false)
+}
+
 func SetElectionService() *pbft.ElectionService {
 	return pbft.NewElectionService("1", 30, pbft.CANDIDATE, 0)
 }
@@ -0,0 +1,29 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pbft
+
+// ElectionTerm is the part of the election state which must survive a restart,
+// otherwise a node could vote twice in the same term
+type ElectionTerm struct {
+	Term     int
+	VotedFor string
+}
+
+type ElectionTermRepository interface {
+	Save(electionTerm ElectionTerm) error
+	Load() (ElectionTerm, error)
+}
@@ -0,0 +1,142 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pbft
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/it-chain/engine/common"
+)
+
+const (
+	DoublePropose MisbehaviourType = "double-propose"
+	DoublePrevote MisbehaviourType = "double-prevote"
+)
+
+var ErrNotConflicting = errors.New("messages do not conflict")
+var ErrOffenderNotSigner = errors.New("offender did not sign the message")
+var ErrEvidenceAlreadyExist = errors.New("evidence already exist")
+var ErrUnknownMisbehaviour = errors.New("unknown misbehaviour type")
+
+// the step of the consensus whose signatures make up the evidence of the misbehaviour
+var voteTypes = map[MisbehaviourType]common.VoteType{
+	DoublePropose: common.ProposeVote,
+	DoublePrevote: common.PrevoteVote,
+}
+
+type MisbehaviourType string
+
+// SignedBlockHash is a block hash with the signature of the representative over it
+type SignedBlockHash struct {
+	BlockHash []byte
+	Signature common.Signature
+}
+
+// Evidence proves that a representative signed two different blocks in the same round
+type Evidence struct {
+	Type       MisbehaviourType
+	OffenderID string
+	StateID    StateID
+	Height     uint64
+	First      SignedBlockHash
+	Second     SignedBlockHash
+	DetectedAt time.Time
+}
+
+func (e Evidence) GetID() string {
+	return fmt.Sprintf("%s/%s/%s", e.Type, e.OffenderID, e.StateID.ID)
+}
+
+// Verify checks that the offender signed both of the conflicting block hashes in the round and at the height of the evidence
+func (e Evidence) Verify(verifier common.SignatureVerifier) error {
+	if bytes.Equal(e.First.BlockHash, e.Second.BlockHash) {
+		return ErrNotConflicting
+	}
+
+	voteType, ok := voteTypes[e.Type]
+	if !ok {
+		return ErrUnknownMisbehaviour
+	}
+
+	for _, signed := range []SignedBlockHash{e.First, e.Second} {
+		if signed.Signature.SignerID != e.OffenderID {
+			return ErrOffenderNotSigner
+		}
+
+		if err := verifier.Verify(signed.Signature, common.VoteSigningData(voteType, e.StateID.ID, e.Height, signed.BlockHash)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DetectDoublePropose compares a proposal with the one the state was built from
+func DetectDoublePropose(state State, msg ProposeMsg) (Evidence, bool) {
+	if state.StateID.ID != msg.StateID.ID || state.ProposerID != msg.SenderID || state.Block.Height != msg.ProposedBlock.Height {
+		return Evidence{}, false
+	}
+
+	if bytes.Equal(state.Block.Seal, msg.ProposedBlock.Seal) {
+		return Evidence{}, false
+	}
+
+	return Evidence{
+		Type:       DoublePropose,
+		OffenderID: msg.SenderID,
+		StateID:    msg.StateID,
+		Height:     msg.ProposedBlock.Height,
+		First:      SignedBlockHash{BlockHash: state.Block.Seal, Signature: state.ProposalSignature},
+		Second:     SignedBlockHash{BlockHash: msg.ProposedBlock.Seal, Signature: msg.Signature},
+		DetectedAt: time.Now(),
+	}, true
+}
+
+// DetectDoublePrevote compares a prevote with the one already received from the same sender
+func DetectDoublePrevote(state State, msg PrevoteMsg) (Evidence, bool) {
+	if state.StateID.ID != msg.StateID.ID {
+		return Evidence{}, false
+	}
+
+	prevote, ok := state.PrevoteMsgPool.FindBySenderID(msg.SenderID)
+	if !ok || bytes.Equal(prevote.BlockHash, msg.BlockHash) {
+		return Evidence{}, false
+	}
+
+	return Evidence{
+		Type:       DoublePrevote,
+		OffenderID: msg.SenderID,
+		StateID:    msg.StateID,
+		Height:     state.Block.Height,
+		First:      SignedBlockHash{BlockHash: prevote.BlockHash, Signature: prevote.Signature},
+		Second:     SignedBlockHash{BlockHash: msg.BlockHash, Signature: msg.Signature},
+		DetectedAt: time.Now(),
+	}, true
+}
+
+type EvidenceRepository interface {
+	Save(evidence Evidence) error
+	FindAll() []Evidence
+}
+
+// MisbehaviourReporter keeps the evidence of a misbehaving representative
+type MisbehaviourReporter interface {
+	Report(evidence Evidence) error
+}
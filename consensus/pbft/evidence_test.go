@@ -0,0 +1,255 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pbft_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/it-chain/engine/common"
+	"github.com/it-chain/engine/consensus/pbft"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSigner(t *testing.T, id string) (*common.ECDSASigner, common.SignatureVerifier) {
+	priKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	signer := common.NewECDSASigner(id, priKey)
+	signature, err := signer.Sign([]byte(id))
+	assert.NoError(t, err)
+
+	verifier := common.NewECDSAVerifier(func(pubKey []byte) (string, error) {
+		if string(pubKey) == string(signature.PubKey) {
+			return id, nil
+		}
+		return "", common.ErrInvalidKey
+	})
+
+	return signer, verifier
+}
+
+func sign(t *testing.T, signer common.Signer, data []byte) common.Signature {
+	signature, err := signer.Sign(data)
+	assert.NoError(t, err)
+
+	return signature
+}
+
+func vote(t *testing.T, signer common.Signer, voteType common.VoteType, stateID string, height uint64, blockHash string) common.Signature {
+	return sign(t, signer, common.VoteSigningData(voteType, stateID, height, []byte(blockHash)))
+}
+
+func TestDetectDoublePropose(t *testing.T) {
+	signer, verifier := newTestSigner(t, "leader")
+
+	state := pbft.State{
+		StateID:           pbft.StateID{"state1"},
+		Block:             pbft.ProposedBlock{Seal: []byte("seal1"), Height: 3},
+		ProposerID:        "leader",
+		ProposalSignature: vote(t, signer, common.ProposeVote, "state1", 3, "seal1"),
+	}
+
+	tests := map[string]struct {
+		input struct {
+			msg pbft.ProposeMsg
+		}
+		detected bool
+	}{
+		"same proposal": {
+			input: struct {
+				msg pbft.ProposeMsg
+			}{msg: pbft.ProposeMsg{
+				StateID:       pbft.StateID{"state1"},
+				SenderID:      "leader",
+				ProposedBlock: pbft.ProposedBlock{Seal: []byte("seal1"), Height: 3},
+				Signature:     vote(t, signer, common.ProposeVote, "state1", 3, "seal1"),
+			}},
+			detected: false,
+		},
+		"conflicting proposal": {
+			input: struct {
+				msg pbft.ProposeMsg
+			}{msg: pbft.ProposeMsg{
+				StateID:       pbft.StateID{"state1"},
+				SenderID:      "leader",
+				ProposedBlock: pbft.ProposedBlock{Seal: []byte("seal2"), Height: 3},
+				Signature:     vote(t, signer, common.ProposeVote, "state1", 3, "seal2"),
+			}},
+			detected: true,
+		},
+		"proposal of other state": {
+			input: struct {
+				msg pbft.ProposeMsg
+			}{msg: pbft.ProposeMsg{
+				StateID:       pbft.StateID{"state2"},
+				SenderID:      "leader",
+				ProposedBlock: pbft.ProposedBlock{Seal: []byte("seal2"), Height: 4},
+				Signature:     vote(t, signer, common.ProposeVote, "state1", 3, "seal2"),
+			}},
+			detected: false,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Logf("running test case %s", testName)
+
+		// when
+		evidence, detected := pbft.DetectDoublePropose(state, test.input.msg)
+
+		// then
+		assert.Equal(t, test.detected, detected)
+		if detected {
+			assert.Equal(t, pbft.DoublePropose, evidence.Type)
+			assert.Equal(t, "leader", evidence.OffenderID)
+			assert.Equal(t, uint64(3), evidence.Height)
+			assert.NoError(t, evidence.Verify(verifier))
+		}
+	}
+}
+
+func TestDetectDoublePrevote(t *testing.T) {
+	// given
+	signer, verifier := newTestSigner(t, "member")
+
+	state := pbft.State{
+		StateID:        pbft.StateID{"state1"},
+		Block:          pbft.ProposedBlock{Seal: []byte("seal1"), Height: 3},
+		PrevoteMsgPool: pbft.NewPrevoteMsgPool(),
+	}
+	state.PrevoteMsgPool.Save(&pbft.PrevoteMsg{
+		StateID:   pbft.StateID{"state1"},
+		SenderID:  "member",
+		BlockHash: []byte("seal1"),
+		Signature: vote(t, signer, common.PrevoteVote, "state1", 3, "seal1"),
+	})
+
+	// when
+	evidence, detected := pbft.DetectDoublePrevote(state, pbft.PrevoteMsg{
+		StateID:   pbft.StateID{"state1"},
+		SenderID:  "member",
+		BlockHash: []byte("seal2"),
+		Signature: vote(t, signer, common.PrevoteVote, "state1", 3, "seal2"),
+	})
+
+	// then
+	assert.True(t, detected)
+	assert.Equal(t, pbft.DoublePrevote, evidence.Type)
+	assert.NoError(t, evidence.Verify(verifier))
+
+	// when
+	_, detected = pbft.DetectDoublePrevote(state, pbft.PrevoteMsg{
+		StateID:   pbft.StateID{"state1"},
+		SenderID:  "member",
+		BlockHash: []byte("seal1"),
+		Signature: vote(t, signer, common.PrevoteVote, "state1", 3, "seal1"),
+	})
+
+	// then
+	assert.False(t, detected)
+}
+
+func TestEvidence_Verify(t *testing.T) {
+	// given
+	signer, verifier := newTestSigner(t, "member")
+	forger, _ := newTestSigner(t, "member")
+
+	tests := map[string]struct {
+		input struct {
+			evidence pbft.Evidence
+		}
+		err error
+	}{
+		"same block hash": {
+			input: struct {
+				evidence pbft.Evidence
+			}{evidence: pbft.Evidence{
+				Type:       pbft.DoublePrevote,
+				OffenderID: "member",
+				StateID:    pbft.StateID{"state1"},
+				Height:     3,
+				First:      pbft.SignedBlockHash{BlockHash: []byte("seal1"), Signature: vote(t, signer, common.PrevoteVote, "state1", 3, "seal1")},
+				Second:     pbft.SignedBlockHash{BlockHash: []byte("seal1"), Signature: vote(t, signer, common.PrevoteVote, "state1", 3, "seal1")},
+			}},
+			err: pbft.ErrNotConflicting,
+		},
+		"signed by other member": {
+			input: struct {
+				evidence pbft.Evidence
+			}{evidence: pbft.Evidence{
+				Type:       pbft.DoublePrevote,
+				OffenderID: "other",
+				StateID:    pbft.StateID{"state1"},
+				Height:     3,
+				First:      pbft.SignedBlockHash{BlockHash: []byte("seal1"), Signature: vote(t, signer, common.PrevoteVote, "state1", 3, "seal1")},
+				Second:     pbft.SignedBlockHash{BlockHash: []byte("seal2"), Signature: vote(t, signer, common.PrevoteVote, "state1", 3, "seal2")},
+			}},
+			err: pbft.ErrOffenderNotSigner,
+		},
+		"forged signature": {
+			input: struct {
+				evidence pbft.Evidence
+			}{evidence: pbft.Evidence{
+				Type:       pbft.DoublePrevote,
+				OffenderID: "member",
+				StateID:    pbft.StateID{"state1"},
+				Height:     3,
+				First:      pbft.SignedBlockHash{BlockHash: []byte("seal1"), Signature: vote(t, signer, common.PrevoteVote, "state1", 3, "seal1")},
+				Second:     pbft.SignedBlockHash{BlockHash: []byte("seal2"), Signature: vote(t, forger, common.PrevoteVote, "state1", 3, "seal2")},
+			}},
+			err: common.ErrInvalidSignature,
+		},
+		"signature of another round": {
+			input: struct {
+				evidence pbft.Evidence
+			}{evidence: pbft.Evidence{
+				Type:       pbft.DoublePrevote,
+				OffenderID: "member",
+				StateID:    pbft.StateID{"state1"},
+				Height:     3,
+				First:      pbft.SignedBlockHash{BlockHash: []byte("seal1"), Signature: vote(t, signer, common.PrevoteVote, "state1", 3, "seal1")},
+				Second:     pbft.SignedBlockHash{BlockHash: []byte("seal2"), Signature: vote(t, signer, common.PrevoteVote, "state0", 3, "seal2")},
+			}},
+			err: common.ErrInvalidSignature,
+		},
+		"proposal replayed as prevote": {
+			input: struct {
+				evidence pbft.Evidence
+			}{evidence: pbft.Evidence{
+				Type:       pbft.DoublePrevote,
+				OffenderID: "member",
+				StateID:    pbft.StateID{"state1"},
+				Height:     3,
+				First:      pbft.SignedBlockHash{BlockHash: []byte("seal1"), Signature: vote(t, signer, common.PrevoteVote, "state1", 3, "seal1")},
+				Second:     pbft.SignedBlockHash{BlockHash: []byte("seal2"), Signature: vote(t, signer, common.ProposeVote, "state1", 3, "seal2")},
+			}},
+			err: common.ErrInvalidSignature,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Logf("running test case %s", testName)
+
+		// when
+		err := test.input.evidence.Verify(verifier)
+
+		// then
+		assert.Equal(t, test.err, err)
+	}
+}
@@ -0,0 +1,131 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"time"
+
+	"github.com/it-chain/engine/common"
+	"github.com/it-chain/engine/common/event"
+	"github.com/it-chain/engine/consensus/pbft"
+	"github.com/it-chain/iLogger"
+)
+
+type LeaderRotationApi interface {
+	RotateLeader(committedHeight uint64) error
+}
+
+type ValidatorGovernanceApi interface {
+	ApplyBlock(height uint64, votes []pbft.ValidatorVote) error
+}
+
+// CommittedBlockQueryApi returns the blocks of the local chain,
+// it is provided by the blockchain component
+type CommittedBlockQueryApi interface {
+	GetLastBlockSeal() (uint64, []byte, error)
+	GetCommittedBlock(height uint64) (event.BlockCommitted, error)
+}
+
+type FinalityRecorder interface {
+	RecordFinalizedBlock(height uint64, finalizedAt time.Time)
+}
+
+type BlockCommittedEventHandler struct {
+	rotationApi       LeaderRotationApi
+	governanceApi     ValidatorGovernanceApi
+	finalityRecorder  FinalityRecorder
+	signatureVerifier common.SignatureVerifier
+}
+
+func NewBlockCommittedEventHandler(rotationApi LeaderRotationApi, governanceApi ValidatorGovernanceApi, finalityRecorder FinalityRecorder, signatureVerifier common.SignatureVerifier) *BlockCommittedEventHandler {
+	return &BlockCommittedEventHandler{
+		rotationApi:       rotationApi,
+		governanceApi:     governanceApi,
+		finalityRecorder:  finalityRecorder,
+		signatureVerifier: signatureVerifier,
+	}
+}
+
+// validator set changes are applied before the leader rotates,
+// so the next leader is chosen among the validators of the next block
+func (b *BlockCommittedEventHandler) HandleBlockCommittedEvent(event event.BlockCommitted) {
+	b.finalityRecorder.RecordFinalizedBlock(event.Height, time.Now())
+
+	if err := b.governanceApi.ApplyBlock(event.Height, b.extractValidatorVotes(event.Height, event.TxList)); err != nil {
+		iLogger.Errorf(nil, "[PBFT] Cannot apply validator set changes - Error: [%s]", err.Error())
+	}
+
+	if err := b.rotationApi.RotateLeader(event.Height); err != nil {
+		iLogger.Errorf(nil, "[PBFT] Cannot rotate leader - Error: [%s]", err.Error())
+	}
+}
+
+// RestoreValidatorSet replays the governance transactions of the committed blocks,
+// so a restarted node follows the same validator set as the rest of the network
+func (b *BlockCommittedEventHandler) RestoreValidatorSet(blockQueryApi CommittedBlockQueryApi) error {
+	lastHeight, lastSeal, err := blockQueryApi.GetLastBlockSeal()
+	if err != nil {
+		return err
+	}
+
+	// nothing is committed before the genesis block
+	if len(lastSeal) == 0 {
+		return nil
+	}
+
+	for height := uint64(0); height <= lastHeight; height++ {
+		block, err := blockQueryApi.GetCommittedBlock(height)
+		if err != nil {
+			return err
+		}
+
+		if err := b.governanceApi.ApplyBlock(height, b.extractValidatorVotes(height, block.TxList)); err != nil {
+			return err
+		}
+	}
+
+	iLogger.Infof(nil, "[PBFT] Validator set is restored from the ledger - Height: [%d]", lastHeight)
+
+	return nil
+}
+
+// the voter of a governance transaction is the validator who signed it
+func (b *BlockCommittedEventHandler) extractValidatorVotes(height uint64, txList []event.Tx) []pbft.ValidatorVote {
+	votes := make([]pbft.ValidatorVote, 0)
+
+	for _, tx := range txList {
+		if !common.IsGovernanceTransaction(tx.ICodeID) {
+			continue
+		}
+
+		voter, err := common.GovernanceVoter(b.signatureVerifier, height, tx.Signature, common.TransactionSigningData(tx.Jsonrpc, tx.ICodeID, tx.Function, tx.Args, tx.IdempotencyKey, tx.Deadline, tx.MaxHeight))
+		if err != nil {
+			iLogger.Errorf(nil, "[PBFT] Unauthenticated governance transaction - ID: [%s], Err: [%s]", tx.ID, err.Error())
+			continue
+		}
+
+		vote, err := pbft.NewValidatorVote(voter, tx.Function, tx.Args)
+		if err != nil {
+			iLogger.Errorf(nil, "[PBFT] Invalid governance transaction - ID: [%s], Err: [%s]", tx.ID, err.Error())
+			continue
+		}
+
+		votes = append(votes, vote)
+	}
+
+	return votes
+}
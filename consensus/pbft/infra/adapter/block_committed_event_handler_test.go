@@ -0,0 +1,82 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/it-chain/engine/common"
+	"github.com/it-chain/engine/common/event"
+	"github.com/it-chain/engine/consensus/pbft/api"
+	"github.com/it-chain/engine/consensus/pbft/infra/adapter"
+	"github.com/it-chain/engine/consensus/pbft/infra/mem"
+	"github.com/it-chain/engine/consensus/pbft/test/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+type blockQueryApi struct {
+	blocks []event.BlockCommitted
+}
+
+func (b blockQueryApi) GetLastBlockSeal() (uint64, []byte, error) {
+	if len(b.blocks) == 0 {
+		return 0, nil, nil
+	}
+
+	last := b.blocks[len(b.blocks)-1]
+	return last.Height, last.Seal, nil
+}
+
+func (b blockQueryApi) GetCommittedBlock(height uint64) (event.BlockCommitted, error) {
+	if height >= uint64(len(b.blocks)) {
+		return event.BlockCommitted{}, errors.New("block not found")
+	}
+
+	return b.blocks[height], nil
+}
+
+func TestBlockCommittedEventHandler_RestoreValidatorSet(t *testing.T) {
+	// given
+	genesis := event.BlockCommitted{
+		Seal:   []byte("genesis"),
+		Height: 0,
+		TxList: []event.Tx{
+			{ID: "genesis-validator-1", ICodeID: common.GovernanceICodeID, Function: common.AddValidatorFunction, Args: []string{"1", "0"}},
+			{ID: "genesis-validator-2", ICodeID: common.GovernanceICodeID, Function: common.AddValidatorFunction, Args: []string{"2", "0"}},
+		},
+	}
+	block := event.BlockCommitted{Seal: []byte("block1"), Height: 1}
+
+	governanceApi := api.NewGovernanceApi(mem.NewParliamentRepository(), mem.NewValidatorSetRepository(), &mock.EventService{})
+	handler := adapter.NewBlockCommittedEventHandler(nil, governanceApi, nil, nil)
+
+	// when
+	err := handler.RestoreValidatorSet(blockQueryApi{})
+
+	// then
+	assert.NoError(t, err)
+	assert.False(t, governanceApi.IsManaged())
+
+	// when
+	err = handler.RestoreValidatorSet(blockQueryApi{blocks: []event.BlockCommitted{genesis, block}})
+
+	// then
+	assert.NoError(t, err)
+	assert.True(t, governanceApi.IsManaged())
+	assert.Equal(t, []string{"1", "2"}, governanceApi.GetValidators())
+}
@@ -0,0 +1,38 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+// LastBlockQueryApi returns the height and the seal of the last committed block,
+// it is provided by the blockchain component
+type LastBlockQueryApi interface {
+	GetLastBlockSeal() (uint64, []byte, error)
+}
+
+// ChainState reports the last block of the local chain to the election
+type ChainState struct {
+	blockApi LastBlockQueryApi
+}
+
+func NewChainState(blockApi LastBlockQueryApi) *ChainState {
+	return &ChainState{
+		blockApi: blockApi,
+	}
+}
+
+func (c *ChainState) LastBlock() (uint64, []byte, error) {
+	return c.blockApi.GetLastBlockSeal()
+}
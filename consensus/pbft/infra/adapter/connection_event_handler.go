@@ -17,6 +17,7 @@
 package adapter
 
 import (
+	"github.com/it-chain/engine/common"
 	"github.com/it-chain/engine/common/event"
 	"github.com/it-chain/engine/consensus/pbft/api"
 	"github.com/it-chain/iLogger"
@@ -25,24 +26,42 @@ import (
 type ConnectionEventHandler struct {
 	electionApi   *api.ElectionApi
 	parliamentApi *api.ParliamentApi
+	governanceApi *api.GovernanceApi
 }
 
-func NewConnectionEventHandler(electionApi *api.ElectionApi, parliamentApi *api.ParliamentApi) *ConnectionEventHandler {
+func NewConnectionEventHandler(electionApi *api.ElectionApi, parliamentApi *api.ParliamentApi, governanceApi *api.GovernanceApi) *ConnectionEventHandler {
 
 	return &ConnectionEventHandler{
 		electionApi:   electionApi,
 		parliamentApi: parliamentApi,
+		governanceApi: governanceApi,
 	}
 }
 
+// when the validator set is managed on chain, a new connection is not a voter
+// until an add validator transaction is committed.
+// An observer is never a voter, it only receives the leader
 func (c *ConnectionEventHandler) HandleConnectionCreatedEvent(event event.ConnectionCreated) {
 
-	c.parliamentApi.AddRepresentative(event.ConnectionID)
-	iLogger.Debugf(nil, "[PBFT] Added new representative - ConnectionID : [%s]", event.ConnectionID)
+	if event.Role == common.ObserverRole {
+		c.parliamentApi.AddObserver(event.ConnectionID)
+		iLogger.Debugf(nil, "[PBFT] Added new observer - ConnectionID : [%s]", event.ConnectionID)
+		return
+	}
+
+	if !c.governanceApi.IsManaged() {
+		c.parliamentApi.AddRepresentative(event.ConnectionID)
+		iLogger.Debugf(nil, "[PBFT] Added new representative - ConnectionID : [%s]", event.ConnectionID)
+	}
 	c.parliamentApi.RequestLeader(event.ConnectionID)
 }
 
 func (c *ConnectionEventHandler) HandleConnectionClosedEvent(event event.ConnectionClosed) {
 
+	c.parliamentApi.RemoveObserver(event.ConnectionID)
+
+	if c.governanceApi.IsManaged() {
+		return
+	}
 	c.parliamentApi.RemoveRepresentative(event.ConnectionID)
 }
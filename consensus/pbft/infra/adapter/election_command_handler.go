@@ -54,46 +54,45 @@ func (e *ElectionCommandHandler) HandleMessageReceive(command command.ReceiveGrp
 			return deserializeErr
 		}
 
-		if e.electionApi.ElectionService.GetState() == "NORMAL" {
-			iLogger.Infof(nil, "[PBFT] Elect Leader With RAFT is not in progress, Do Not Receive Request Vote")
-			return nil
-		}
-
-		if e.electionApi.ElectionService.Voted {
-			iLogger.Info(nil, "[PBFT] Already voted!")
-			return nil
-		}
-
-		err := e.electionApi.Vote(command.ConnectionID)
-
-		if err != nil {
+		if err := e.electionApi.HandleRequestVote(command.ConnectionID, *message); err != nil {
+			iLogger.Errorf(nil, "[PBFT] Cannot handle request vote - Error: [%s]", err.Error())
 			return err
 		}
 
 	case "VoteLeaderProtocol":
 		iLogger.Infof(nil, "[PBFT] Receive VoteLeaderProtocol")
 
-		if e.electionApi.ElectionService.GetState() == "NORMAL" {
-			iLogger.Infof(nil, "[PBFT] Elect Leader With RAFT is not in progress, Do Not Receive Vote")
-			return nil
+		message := &pbft.VoteMessage{}
+		if err := common.Deserialize(command.Body, message); err != nil {
+			return err
 		}
 
-		if err := e.electionApi.DecideToBeLeader(); err != nil {
+		if err := e.electionApi.HandleVote(*message); err != nil {
 			iLogger.Errorf(nil, "[PBFT] Cannot decide to be leader - Error: [%s]", err.Error())
 		}
 
-	case "UpdateLeaderProtocol":
-		if e.electionApi.GetCandidate().ID != command.ConnectionID {
-			return nil
+	case "HeartbeatProtocol":
+		message := &pbft.HeartbeatMessage{}
+		if err := common.Deserialize(command.Body, message); err != nil {
+			return err
 		}
 
-		e.electionApi.EndRaft()
+		if err := e.electionApi.HandleHeartbeat(*message); err != nil {
+			iLogger.Errorf(nil, "[PBFT] Cannot handle heartbeat - Error: [%s]", err.Error())
+		}
+
+	case "UpdateLeaderProtocol":
 		toBeLeader := &pbft.UpdateLeaderMessage{}
 		if err := common.Deserialize(command.Body, toBeLeader); err != nil {
 			iLogger.Errorf(nil, "[PBFT] Cannot deserialize update leader msg - Error: [%s]", err.Error())
+			return err
+		}
+
+		if toBeLeader.Representative.ID != command.ConnectionID {
+			return nil
 		}
 
-		if err := e.parliamentApi.UpdateLeader(toBeLeader.Representative.ID); err != nil {
+		if err := e.electionApi.HandleUpdateLeader(*toBeLeader); err != nil {
 			iLogger.Errorf(nil, "[PBFT] Cannot update leader - Error: [%s]", err.Error())
 		}
 	}
@@ -0,0 +1,40 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import "github.com/it-chain/engine/consensus/pbft"
+
+// ProposalValidationApi validates the encoded body of a proposed block with the local chain,
+// it is provided by the blockchain component
+type ProposalValidationApi interface {
+	ValidateProposedBody(seal []byte, height uint64, body []byte) error
+}
+
+// ProposedBlockValidator validates the body of a proposed block with the local chain
+type ProposedBlockValidator struct {
+	blockApi ProposalValidationApi
+}
+
+func NewProposedBlockValidator(blockApi ProposalValidationApi) *ProposedBlockValidator {
+	return &ProposedBlockValidator{
+		blockApi: blockApi,
+	}
+}
+
+func (v *ProposedBlockValidator) ValidateProposedBlock(proposedBlock pbft.ProposedBlock) error {
+	return v.blockApi.ValidateProposedBody(proposedBlock.Seal, proposedBlock.Height, proposedBlock.Body)
+}
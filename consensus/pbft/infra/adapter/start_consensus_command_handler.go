@@ -68,7 +68,8 @@ func extractProposedBlock(command command.StartConsensus) (pbft.ProposedBlock, e
 	}
 
 	return pbft.ProposedBlock{
-		Seal: command.Seal,
-		Body: body,
+		Seal:   command.Seal,
+		Height: command.Height,
+		Body:   body,
 	}, nil
 }
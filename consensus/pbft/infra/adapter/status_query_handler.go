@@ -0,0 +1,41 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"github.com/it-chain/engine/common/command"
+	"github.com/it-chain/engine/common/rabbitmq/rpc"
+	"github.com/it-chain/engine/consensus/pbft"
+)
+
+type ConsensusStatusApi interface {
+	GetStatus() pbft.Status
+}
+
+type StatusQueryHandler struct {
+	statusApi ConsensusStatusApi
+}
+
+func NewStatusQueryHandler(statusApi ConsensusStatusApi) *StatusQueryHandler {
+	return &StatusQueryHandler{
+		statusApi: statusApi,
+	}
+}
+
+func (s *StatusQueryHandler) HandleGetConsensusStatusQuery(query command.GetConsensusStatus) (pbft.Status, rpc.Error) {
+	return s.statusApi.GetStatus(), rpc.Error{}
+}
@@ -0,0 +1,51 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"sync"
+
+	"github.com/it-chain/engine/consensus/pbft"
+)
+
+type ElectionTermRepository struct {
+	electionTerm pbft.ElectionTerm
+	sync.RWMutex
+}
+
+func NewElectionTermRepository() *ElectionTermRepository {
+	return &ElectionTermRepository{
+		electionTerm: pbft.ElectionTerm{},
+		RWMutex:      sync.RWMutex{},
+	}
+}
+
+func (repo *ElectionTermRepository) Save(electionTerm pbft.ElectionTerm) error {
+	repo.Lock()
+	defer repo.Unlock()
+
+	repo.electionTerm = electionTerm
+
+	return nil
+}
+
+func (repo *ElectionTermRepository) Load() (pbft.ElectionTerm, error) {
+	repo.RLock()
+	defer repo.RUnlock()
+
+	return repo.electionTerm, nil
+}
@@ -0,0 +1,59 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"sync"
+
+	"github.com/it-chain/engine/consensus/pbft"
+)
+
+type EvidenceRepository struct {
+	evidences map[string]pbft.Evidence
+	sync.RWMutex
+}
+
+func NewEvidenceRepository() *EvidenceRepository {
+	return &EvidenceRepository{
+		evidences: make(map[string]pbft.Evidence),
+		RWMutex:   sync.RWMutex{},
+	}
+}
+
+func (e *EvidenceRepository) Save(evidence pbft.Evidence) error {
+	e.Lock()
+	defer e.Unlock()
+
+	if _, ok := e.evidences[evidence.GetID()]; ok {
+		return pbft.ErrEvidenceAlreadyExist
+	}
+
+	e.evidences[evidence.GetID()] = evidence
+	return nil
+}
+
+func (e *EvidenceRepository) FindAll() []pbft.Evidence {
+	e.RLock()
+	defer e.RUnlock()
+
+	evidences := make([]pbft.Evidence, 0)
+	for _, evidence := range e.evidences {
+		evidences = append(evidences, evidence)
+	}
+
+	return evidences
+}
@@ -0,0 +1,51 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"sync"
+
+	"github.com/it-chain/engine/consensus/pbft"
+)
+
+// ValidatorSetRepository keeps a copy of the validator set,
+// so a loaded validator set can be changed without a lock until it is saved
+type ValidatorSetRepository struct {
+	validatorSet pbft.ValidatorSet
+	sync.RWMutex
+}
+
+func NewValidatorSetRepository() *ValidatorSetRepository {
+	return &ValidatorSetRepository{
+		validatorSet: pbft.NewValidatorSet(),
+		RWMutex:      sync.RWMutex{},
+	}
+}
+
+func (v *ValidatorSetRepository) Save(validatorSet pbft.ValidatorSet) {
+	v.Lock()
+	defer v.Unlock()
+
+	v.validatorSet = validatorSet.Copy()
+}
+
+func (v *ValidatorSetRepository) Load() pbft.ValidatorSet {
+	v.RLock()
+	defer v.RUnlock()
+
+	return v.validatorSet.Copy()
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package repo
+
+import (
+	"github.com/it-chain/engine/common"
+	"github.com/it-chain/engine/consensus/pbft"
+	"github.com/it-chain/leveldb-wrapper"
+)
+
+var electionTermKey = []byte("election_term")
+
+// ElectionTermRepository keeps the current term and the vote of the node on disk
+type ElectionTermRepository struct {
+	leveldb *leveldbwrapper.DB
+}
+
+func NewElectionTermRepository(path string) *ElectionTermRepository {
+	db := leveldbwrapper.CreateNewDB(path)
+	db.Open()
+
+	return &ElectionTermRepository{
+		leveldb: db,
+	}
+}
+
+func (r *ElectionTermRepository) Save(electionTerm pbft.ElectionTerm) error {
+	b, err := common.Serialize(electionTerm)
+	if err != nil {
+		return err
+	}
+
+	return r.leveldb.Put(electionTermKey, b, true)
+}
+
+// Load returns an empty election term when nothing was saved yet
+func (r *ElectionTermRepository) Load() (pbft.ElectionTerm, error) {
+	b, err := r.leveldb.Get(electionTermKey)
+	if err != nil {
+		return pbft.ElectionTerm{}, err
+	}
+
+	if len(b) == 0 {
+		return pbft.ElectionTerm{}, nil
+	}
+
+	electionTerm := pbft.ElectionTerm{}
+	if err := common.Deserialize(b, &electionTerm); err != nil {
+		return pbft.ElectionTerm{}, err
+	}
+
+	return electionTerm, nil
+}
+
+func (r *ElectionTermRepository) Close() {
+	r.leveldb.Close()
+}
@@ -28,12 +28,15 @@ var ErrRepresentativeAlreadyExist = errors.New("Representative already exist")
 type Parliament struct {
 	Leader          Leader
 	Representatives map[string]Representative
+	// observers follow the leader, but are never counted in elections and quorums
+	Observers map[string]Observer
 }
 
 func NewParliament() Parliament {
 	return Parliament{
 		Leader:          Leader{},
 		Representatives: make(map[string]Representative),
+		Observers:       make(map[string]Observer),
 	}
 }
 
@@ -99,6 +102,27 @@ func (p *Parliament) RemoveRepresentative(representativeId string) {
 	delete(p.Representatives, representativeId)
 }
 
+func (p *Parliament) AddObserver(observer Observer) {
+	if p.Observers == nil {
+		p.Observers = make(map[string]Observer)
+	}
+
+	p.Observers[observer.ID] = observer
+}
+
+func (p *Parliament) RemoveObserver(observerId string) {
+	delete(p.Observers, observerId)
+}
+
+func (p Parliament) GetObservers() []Observer {
+	observerList := make([]Observer, 0)
+	for _, observer := range p.Observers {
+		observerList = append(observerList, observer)
+	}
+
+	return observerList
+}
+
 type ParliamentRepository interface {
 	Save(parliament Parliament)
 	Load() Parliament
@@ -123,3 +147,15 @@ func (r Representative) GetID() string {
 func NewRepresentative(ID string) Representative {
 	return Representative{ID: ID}
 }
+
+type Observer struct {
+	ID string
+}
+
+func (o Observer) GetID() string {
+	return o.ID
+}
+
+func NewObserver(ID string) Observer {
+	return Observer{ID: ID}
+}
@@ -0,0 +1,83 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pbft
+
+import (
+	"errors"
+	"sort"
+)
+
+const (
+	NoRotation         = "none"
+	RoundRobinRotation = "round-robin"
+)
+
+var ErrUnknownRotationPolicy = errors.New("unknown leader rotation policy")
+var ErrInvalidRotationInterval = errors.New("rotation interval must be greater than zero")
+
+// RotationPolicy decides which representative proposes the block of the height.
+// Every node computes it from the same parliament, so the result must not depend on local state
+type RotationPolicy interface {
+	NextLeader(parliament Parliament, height uint64) string
+}
+
+// NoRotationPolicy keeps the current leader until it leaves the network
+type NoRotationPolicy struct{}
+
+func (p NoRotationPolicy) NextLeader(parliament Parliament, height uint64) string {
+	return parliament.GetLeader().GetID()
+}
+
+// RoundRobinPolicy hands the leadership to the next representative in the order of their IDs,
+// changing the leader every Interval blocks
+type RoundRobinPolicy struct {
+	Interval uint64
+}
+
+func (p RoundRobinPolicy) NextLeader(parliament Parliament, height uint64) string {
+	representatives := parliament.GetRepresentatives()
+	if len(representatives) == 0 {
+		return ""
+	}
+
+	ids := make([]string, 0)
+	for _, r := range representatives {
+		ids = append(ids, r.GetID())
+	}
+	sort.Strings(ids)
+
+	round := height / p.Interval
+
+	return ids[round%uint64(len(ids))]
+}
+
+func NewRotationPolicy(policy string, interval uint64) (RotationPolicy, error) {
+	switch policy {
+	case "", NoRotation:
+		return NoRotationPolicy{}, nil
+
+	case RoundRobinRotation:
+		if interval == 0 {
+			return nil, ErrInvalidRotationInterval
+		}
+
+		return RoundRobinPolicy{Interval: interval}, nil
+
+	default:
+		return nil, ErrUnknownRotationPolicy
+	}
+}
@@ -0,0 +1,108 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pbft_test
+
+import (
+	"testing"
+
+	"github.com/it-chain/engine/consensus/pbft"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRotationPolicy(t *testing.T) {
+	tests := map[string]struct {
+		input struct {
+			policy   string
+			interval uint64
+		}
+		err error
+	}{
+		"none": {
+			input: struct {
+				policy   string
+				interval uint64
+			}{policy: "none", interval: 0},
+			err: nil,
+		},
+		"round-robin": {
+			input: struct {
+				policy   string
+				interval uint64
+			}{policy: "round-robin", interval: 1},
+			err: nil,
+		},
+		"round-robin with zero interval": {
+			input: struct {
+				policy   string
+				interval uint64
+			}{policy: "round-robin", interval: 0},
+			err: pbft.ErrInvalidRotationInterval,
+		},
+		"unknown": {
+			input: struct {
+				policy   string
+				interval uint64
+			}{policy: "random", interval: 1},
+			err: pbft.ErrUnknownRotationPolicy,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Logf("running test case %s", testName)
+
+		_, err := pbft.NewRotationPolicy(test.input.policy, test.input.interval)
+		assert.Equal(t, test.err, err)
+	}
+}
+
+func TestNoRotationPolicy_NextLeader(t *testing.T) {
+	// given
+	parliament := pbft.NewParliament()
+	parliament.AddRepresentative(pbft.NewRepresentative("2"))
+	parliament.AddRepresentative(pbft.NewRepresentative("1"))
+	parliament.SetLeader("2")
+
+	// when, then
+	assert.Equal(t, "2", pbft.NoRotationPolicy{}.NextLeader(parliament, 1))
+	assert.Equal(t, "2", pbft.NoRotationPolicy{}.NextLeader(parliament, 2))
+}
+
+func TestRoundRobinPolicy_NextLeader(t *testing.T) {
+	// given
+	parliament := pbft.NewParliament()
+	parliament.AddRepresentative(pbft.NewRepresentative("c"))
+	parliament.AddRepresentative(pbft.NewRepresentative("a"))
+	parliament.AddRepresentative(pbft.NewRepresentative("b"))
+
+	// when: leader changes every block
+	policy := pbft.RoundRobinPolicy{Interval: 1}
+
+	// then
+	assert.Equal(t, "a", policy.NextLeader(parliament, 0))
+	assert.Equal(t, "b", policy.NextLeader(parliament, 1))
+	assert.Equal(t, "c", policy.NextLeader(parliament, 2))
+	assert.Equal(t, "a", policy.NextLeader(parliament, 3))
+
+	// when: leader changes every 2 blocks
+	policy = pbft.RoundRobinPolicy{Interval: 2}
+
+	// then
+	assert.Equal(t, "a", policy.NextLeader(parliament, 1))
+	assert.Equal(t, "b", policy.NextLeader(parliament, 2))
+	assert.Equal(t, "b", policy.NextLeader(parliament, 3))
+	assert.Equal(t, "c", policy.NextLeader(parliament, 4))
+}
@@ -17,6 +17,7 @@
 package pbft
 
 import (
+	"bytes"
 	"errors"
 	"math/rand"
 	"time"
@@ -37,3 +38,51 @@ func GenRandomInRange(min, max int) int {
 	rand.Seed(time.Now().UnixNano())
 	return rand.Intn(max-min) + min
 }
+
+// number of members needed for a quorum among n members
+func Majority(n int) int {
+	return n/2 + 1
+}
+
+// ChainState reports the last block committed to the local chain.
+// A vote is only granted to a candidate whose chain is at least as up to date
+type ChainState interface {
+	LastBlock() (height uint64, seal []byte, err error)
+}
+
+// IsChainUpToDate tells whether a candidate with the last block of the height and the seal is not behind the local chain.
+// A candidate at the same height with another seal is on a diverged chain
+func IsChainUpToDate(local ChainState, lastHeight uint64, lastSeal []byte) bool {
+	height, seal, err := local.LastBlock()
+	if err != nil {
+		return false
+	}
+
+	if lastHeight != height {
+		return lastHeight > height
+	}
+
+	return bytes.Equal(lastSeal, seal)
+}
+
+// ReplicatedLog is the position of the raft log. In raft mode a vote is granted only to a candidate
+// whose log is at least as up to date, so a new leader holds every entry committed by a majority
+type ReplicatedLog interface {
+	LastIndex() uint64
+	LastTerm() int
+}
+
+// IsLogUpToDate tells whether a candidate log ending with the term and the index is at least as up to date as the local log.
+// The log with the later last term is more up to date, logs ending with the same term compare their lengths
+func IsLogUpToDate(local ReplicatedLog, lastLogTerm int, lastLogIndex uint64) bool {
+	if lastLogTerm != local.LastTerm() {
+		return lastLogTerm > local.LastTerm()
+	}
+
+	return lastLogIndex >= local.LastIndex()
+}
+
+// BlockValidator checks a proposed block before the member prevotes it
+type BlockValidator interface {
+	ValidateProposedBlock(block ProposedBlock) error
+}
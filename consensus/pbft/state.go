@@ -21,6 +21,8 @@ import (
 	"fmt"
 
 	"encoding/json"
+
+	"github.com/it-chain/engine/common"
 )
 
 type Stage string
@@ -37,10 +39,14 @@ var ErrPrevoteMsgNil = errors.New("Prevote msg is nil")
 var ErrBlockHashNil = errors.New("Block hash is nil")
 var ErrPreCommitMsgNil = errors.New("PreCommit msg is nil")
 var ErrStateIdNotSame = errors.New("State ID is not same")
+var ErrNotRepresentative = errors.New("Sender is not a representative of the state")
+var ErrSenderNotSigner = errors.New("Sender did not sign the message")
+var ErrBlockHashMismatch = errors.New("Block hash does not match the proposed block")
 
 type ProposedBlock struct {
-	Seal []byte
-	Body []byte
+	Seal   []byte
+	Height uint64
+	Body   []byte
 }
 
 func (block *ProposedBlock) Serialize() ([]byte, error) {
@@ -75,6 +81,7 @@ type ProposeMsg struct {
 	SenderID       string
 	Representative []Representative
 	ProposedBlock  ProposedBlock
+	Signature      common.Signature
 }
 
 func NewProposeMsg(s *State, senderID string) *ProposeMsg {
@@ -98,6 +105,7 @@ type PrevoteMsg struct {
 	StateID   StateID
 	SenderID  string
 	BlockHash []byte
+	Signature common.Signature
 }
 
 func NewPrevoteMsg(s *State, senderID string) *PrevoteMsg {
@@ -117,8 +125,9 @@ func (p PrevoteMsg) ToByte() ([]byte, error) {
 }
 
 type PreCommitMsg struct {
-	StateID  StateID
-	SenderID string
+	StateID   StateID
+	SenderID  string
+	Signature common.Signature
 }
 
 func NewPreCommitMsg(s *State, senderID string) *PreCommitMsg {
@@ -177,6 +186,15 @@ func (p *PrevoteMsgPool) Get() []PrevoteMsg {
 	return p.messages
 }
 
+func (p *PrevoteMsgPool) FindBySenderID(senderID string) (PrevoteMsg, bool) {
+	index := p.findIndexOfPrevoteMsg(senderID)
+	if index == -1 {
+		return PrevoteMsg{}, false
+	}
+
+	return p.messages[index], true
+}
+
 func (p *PrevoteMsgPool) findIndexOfPrevoteMsg(senderID string) int {
 	for i, msg := range p.messages {
 		if msg.SenderID == senderID {
@@ -243,12 +261,14 @@ func NewStateID(id string) StateID {
 }
 
 type State struct {
-	StateID          StateID
-	Representatives  []Representative
-	Block            ProposedBlock
-	CurrentStage     Stage
-	PrevoteMsgPool   PrevoteMsgPool
-	PreCommitMsgPool PreCommitMsgPool
+	StateID           StateID
+	Representatives   []Representative
+	Block             ProposedBlock
+	ProposerID        string
+	ProposalSignature common.Signature
+	CurrentStage      Stage
+	PrevoteMsgPool    PrevoteMsgPool
+	PreCommitMsgPool  PreCommitMsgPool
 }
 
 func (s *State) GetID() string {
@@ -302,25 +322,72 @@ func (s *State) SavePreCommitMsg(precommitMsg *PreCommitMsg) error {
 
 	return s.PreCommitMsgPool.Save(precommitMsg)
 }
-func (s *State) CheckPrevoteCondition() bool {
-	representativeNum := len(s.Representatives)
-	commitMsgNum := len(s.PrevoteMsgPool.Get())
-	satisfyNum := representativeNum / 3
-
-	if commitMsgNum >= (satisfyNum + 1) {
-		return true
+func (s *State) IsRepresentative(id string) bool {
+	for _, rep := range s.Representatives {
+		if rep.ID == id {
+			return true
+		}
 	}
+
 	return false
 }
+
+// VerifyPrevoteMsg checks that a representative of the state signed the prevote in the round of the state
+func (s *State) VerifyPrevoteMsg(msg PrevoteMsg, verifier common.SignatureVerifier) error {
+	if s.StateID.ID != msg.StateID.ID {
+		return ErrStateIdNotSame
+	}
+
+	return s.verifyVote(msg.SenderID, msg.Signature, common.VoteSigningData(common.PrevoteVote, s.StateID.ID, s.Block.Height, msg.BlockHash), verifier)
+}
+
+// VerifyPreCommitMsg checks that a representative of the state signed the precommit of the proposed block
+func (s *State) VerifyPreCommitMsg(msg PreCommitMsg, verifier common.SignatureVerifier) error {
+	if s.StateID.ID != msg.StateID.ID {
+		return ErrStateIdNotSame
+	}
+
+	return s.verifyVote(msg.SenderID, msg.Signature, common.VoteSigningData(common.PreCommitVote, s.StateID.ID, s.Block.Height, s.Block.Seal), verifier)
+}
+
+func (s *State) verifyVote(senderID string, signature common.Signature, signingData []byte, verifier common.SignatureVerifier) error {
+	if !s.IsRepresentative(senderID) {
+		return ErrNotRepresentative
+	}
+
+	if signature.SignerID != senderID {
+		return ErrSenderNotSigner
+	}
+
+	return verifier.Verify(signature, signingData)
+}
+
+// VerifyProposeMsg checks that the sender signed the proposal for the round and the height of the block
+func VerifyProposeMsg(msg ProposeMsg, verifier common.SignatureVerifier) error {
+	if msg.Signature.SignerID != msg.SenderID {
+		return ErrSenderNotSigner
+	}
+
+	return verifier.Verify(msg.Signature, common.VoteSigningData(common.ProposeVote, msg.StateID.ID, msg.ProposedBlock.Height, msg.ProposedBlock.Seal))
+}
+
+func (s *State) CheckPrevoteCondition() bool {
+	return len(s.PrevoteMsgPool.Get()) >= common.Quorum(len(s.Representatives))
+}
 func (s *State) CheckPreCommitCondition() bool {
-	representativeNum := len(s.Representatives)
-	commitMsgNum := len(s.PreCommitMsgPool.Get())
-	satisfyNum := representativeNum / 3
+	return len(s.PreCommitMsgPool.Get()) >= common.Quorum(len(s.Representatives))
+}
 
-	if commitMsgNum >= (satisfyNum + 1) {
-		return true
+// BuildCertificate bundles the signed precommits of the state into the commit certificate of the block
+func (s *State) BuildCertificate() common.Certificate {
+	signatures := make([]common.Signature, 0)
+	for _, msg := range s.PreCommitMsgPool.Get() {
+		if msg.Signature.SignerID == msg.SenderID {
+			signatures = append(signatures, msg.Signature)
+		}
 	}
-	return false
+
+	return common.NewCertificate(s.StateID.ID, s.Block.Height, s.Block.Seal, signatures)
 }
 
 type StateRepository interface {
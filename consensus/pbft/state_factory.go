@@ -42,12 +42,14 @@ func NewState(parliament []Representative, block ProposedBlock) (*State, error)
 // member
 func BuildState(msg ProposeMsg) *State {
 	newState := &State{
-		StateID:          msg.StateID,
-		Representatives:  msg.Representative,
-		Block:            msg.ProposedBlock,
-		CurrentStage:     IDLE_STAGE,
-		PrevoteMsgPool:   NewPrevoteMsgPool(),
-		PreCommitMsgPool: NewPreCommitMsgPool(),
+		StateID:           msg.StateID,
+		Representatives:   msg.Representative,
+		Block:             msg.ProposedBlock,
+		ProposerID:        msg.SenderID,
+		ProposalSignature: msg.Signature,
+		CurrentStage:      IDLE_STAGE,
+		PrevoteMsgPool:    NewPrevoteMsgPool(),
+		PreCommitMsgPool:  NewPreCommitMsgPool(),
 	}
 
 	return newState
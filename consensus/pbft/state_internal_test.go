@@ -6,12 +6,12 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-// When Representative Number : 6, prevoteMsg Num : 4 -> then true
+// When Representative Number : 6, prevoteMsg Num : 5 -> then true
 func TestState_CheckPrevoteCondition_Satisfy(t *testing.T) {
 	// 6 rep
 	satisfyPrevoteConditionState := setUpState()
 	prevoteMsgs := make([]PrevoteMsg, 0)
-	for i := 0; i < 4; i++ {
+	for i := 0; i < 5; i++ {
 		prevoteMsgs = append(prevoteMsgs, PrevoteMsg{
 			StateID:  StateID{"state1"},
 			SenderID: "user1",
@@ -22,11 +22,11 @@ func TestState_CheckPrevoteCondition_Satisfy(t *testing.T) {
 	assert.Equal(t, true, satisfyPrevoteConditionState.CheckPrevoteCondition())
 }
 
-// When Representative Number : 6, prevoteMsg Number : 2 -> then false
+// When Representative Number : 6, prevoteMsg Number : 4 -> then false
 func TestState_CheckPrevoteCondition_UnSatisfy(t *testing.T) {
 	unSatisfyPrevoteConditionState := setUpState()
 	prevoteMsgs := make([]PrevoteMsg, 0)
-	for i := 0; i < 2; i++ {
+	for i := 0; i < 4; i++ {
 		prevoteMsgs = append(prevoteMsgs, PrevoteMsg{
 			StateID:  StateID{"state1"},
 			SenderID: "user1",
@@ -38,11 +38,11 @@ func TestState_CheckPrevoteCondition_UnSatisfy(t *testing.T) {
 	assert.Equal(t, false, unSatisfyPrevoteConditionState.CheckPrevoteCondition())
 }
 
-// When Representative Number : 6, prevoteCommitMsg Number : 4 -> then true
+// When Representative Number : 6, prevoteCommitMsg Number : 5 -> then true
 func TestState_CheckPreCommitCondition_Satisfy(t *testing.T) {
 	satisfyPrecommitConditionState := setUpState()
 	precommitMsgs := make([]PreCommitMsg, 0)
-	for i := 0; i < 4; i++ {
+	for i := 0; i < 5; i++ {
 		precommitMsgs = append(precommitMsgs, PreCommitMsg{
 			StateID:  StateID{"state1"},
 			SenderID: "user1",
@@ -53,12 +53,12 @@ func TestState_CheckPreCommitCondition_Satisfy(t *testing.T) {
 	assert.Equal(t, true, satisfyPrecommitConditionState.CheckPreCommitCondition())
 }
 
-// When Representative Number : 6, prevoteCommitMsg Number : 2 -> then false
+// When Representative Number : 6, prevoteCommitMsg Number : 4 -> then false
 func TestState_CheckPreCommitCondition_UnSatisfy(t *testing.T) {
 
 	unSatisfyPrecommitConditionState := setUpState()
 	precommitMsgs := make([]PreCommitMsg, 0)
-	for i := 0; i < 2; i++ {
+	for i := 0; i < 4; i++ {
 		precommitMsgs = append(precommitMsgs, PreCommitMsg{
 			StateID:  StateID{"state1"},
 			SenderID: "user1",
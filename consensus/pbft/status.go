@@ -0,0 +1,50 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pbft
+
+import "time"
+
+// Status is a snapshot of the consensus of a node
+type Status struct {
+	Leader              string
+	Representatives     []string
+	ElectionState       ElectionState
+	Term                int
+	VoteCount           int
+	State               *StateStatus // nil when no consensus is in progress
+	LastFinalizedHeight uint64
+	LastFinalizedAt     time.Time
+	SinceLastFinalized  time.Duration // zero until a block is finalized
+}
+
+type StateStatus struct {
+	StateID        string
+	Stage          Stage
+	BlockHeight    uint64
+	PrevoteCount   int
+	PreCommitCount int
+}
+
+func NewStateStatus(state State) *StateStatus {
+	return &StateStatus{
+		StateID:        state.StateID.ID,
+		Stage:          state.CurrentStage,
+		BlockHeight:    state.Block.Height,
+		PrevoteCount:   len(state.PrevoteMsgPool.Get()),
+		PreCommitCount: len(state.PreCommitMsgPool.Get()),
+	}
+}
@@ -17,6 +17,7 @@
 package mock
 
 import (
+	"github.com/it-chain/engine/common"
 	"github.com/it-chain/engine/consensus/pbft"
 )
 
@@ -103,3 +104,43 @@ func (m ParliamentService) GetLeader() *pbft.Leader {
 func (m ParliamentService) FindRepresentativeByIpAddress(ipAddress string) *pbft.Representative {
 	return m.FindRepresentativeByIpAddressFunc(ipAddress)
 }
+
+type Signer struct {
+	SignFunc func(data []byte) (common.Signature, error)
+}
+
+func (m Signer) Sign(data []byte) (common.Signature, error) {
+	return m.SignFunc(data)
+}
+
+type SignatureVerifier struct {
+	VerifyFunc func(signature common.Signature, data []byte) error
+}
+
+func (m SignatureVerifier) Verify(signature common.Signature, data []byte) error {
+	return m.VerifyFunc(signature, data)
+}
+
+type ChainState struct {
+	LastBlockFunc func() (uint64, []byte, error)
+}
+
+func (m ChainState) LastBlock() (uint64, []byte, error) {
+	return m.LastBlockFunc()
+}
+
+type BlockValidator struct {
+	ValidateProposedBlockFunc func(block pbft.ProposedBlock) error
+}
+
+func (m BlockValidator) ValidateProposedBlock(block pbft.ProposedBlock) error {
+	return m.ValidateProposedBlockFunc(block)
+}
+
+type MisbehaviourReporter struct {
+	ReportFunc func(evidence pbft.Evidence) error
+}
+
+func (m MisbehaviourReporter) Report(evidence pbft.Evidence) error {
+	return m.ReportFunc(evidence)
+}
@@ -17,7 +17,13 @@
 package test
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+
 	"github.com/it-chain/avengers/mock"
+	"github.com/it-chain/engine/common"
 	"github.com/it-chain/engine/common/logger"
 	"github.com/it-chain/engine/consensus/pbft"
 	"github.com/it-chain/engine/consensus/pbft/api"
@@ -38,6 +44,19 @@ func SetTestEnvironment(processList []string) struct {
 	processMap := make(map[string]*mock.Process)
 	eventServiceMap := make(map[string]*mock.EventService)
 
+	// every process signs with its own key, and verifies the others with the shared key table
+	priKeys := make(map[string]*ecdsa.PrivateKey)
+	nodeIds := make(map[string]string)
+	for _, id := range processList {
+		priKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		pubKey, _ := x509.MarshalPKIXPublicKey(&priKey.PublicKey)
+		priKeys[id] = priKey
+		nodeIds[string(pubKey)] = id
+	}
+	signatureVerifier := common.NewECDSAVerifier(func(pubKey []byte) (string, error) {
+		return nodeIds[string(pubKey)], nil
+	})
+
 	for _, id := range processList {
 
 		// setup process
@@ -56,10 +75,14 @@ func SetTestEnvironment(processList []string) struct {
 		eventService := mock.NewEventService(id, networkManager.Publish)
 		propagateService := pbft.NewPropagateService(eventService)
 
-		electionApi := api.NewElectionApi(electionService, parliamentRepository, eventService)
+		signer := common.NewECDSASigner(id, priKeys[id])
+
+		electionApi := api.NewElectionApi(electionService, parliamentRepository, mem.NewElectionTermRepository(), genesisChainState{}, signer, signatureVerifier, eventService)
 		leaderApi := api.NewParliamentApi(id, parliamentRepository, eventService)
 
-		stateApi := api.NewStateApi(id, propagateService, eventService, parliamentRepository, stateRepository)
+		reporter := api.NewEvidenceApi(mem.NewEvidenceRepository(), signatureVerifier, eventService)
+
+		stateApi := api.NewStateApi(id, propagateService, eventService, signer, signatureVerifier, acceptAllBlockValidator{}, reporter, parliamentRepository, stateRepository)
 
 		grpcCommandHandler := adapter.NewElectionCommandHandler(leaderApi, electionApi)
 		pbftHandler := adapter.NewPbftMsgHandler(stateApi)
@@ -94,3 +117,17 @@ func SetTestEnvironment(processList []string) struct {
 		EventServiceMap: eventServiceMap,
 	}
 }
+
+// the simulated processes have no chain to validate a proposal with
+type acceptAllBlockValidator struct{}
+
+func (acceptAllBlockValidator) ValidateProposedBlock(block pbft.ProposedBlock) error {
+	return nil
+}
+
+// every process of the test environment is at the genesis block
+type genesisChainState struct{}
+
+func (genesisChainState) LastBlock() (uint64, []byte, error) {
+	return 0, []byte("genesis"), nil
+}
@@ -0,0 +1,79 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package simulation
+
+import (
+	"crypto/sha256"
+	"math/rand"
+
+	"github.com/it-chain/engine/common"
+	"github.com/it-chain/engine/consensus/pbft"
+)
+
+// Byzantine rewrites the messages a byzantine node sends. It may drop, change or multiply them
+type Byzantine interface {
+	Tamper(sender *Node, msg Message, rand *rand.Rand) []Message
+}
+
+// Silent is a crashed node, nothing it sends reaches the others
+type Silent struct{}
+
+func (Silent) Tamper(sender *Node, msg Message, rand *rand.Rand) []Message {
+	return nil
+}
+
+// DoublePrevoter also signs a prevote for another block than the proposed one,
+// and sends either or both of the prevotes to each representative
+type DoublePrevoter struct{}
+
+func (DoublePrevoter) Tamper(sender *Node, msg Message, rand *rand.Rand) []Message {
+	if msg.Protocol != "PrevoteMsgProtocol" {
+		return []Message{msg}
+	}
+
+	prevoteMsg := pbft.PrevoteMsg{}
+	if err := common.Deserialize(msg.Body, &prevoteMsg); err != nil {
+		return []Message{msg}
+	}
+
+	hash := sha256.Sum256(append([]byte("conflict"), prevoteMsg.BlockHash...))
+	prevoteMsg.BlockHash = hash[:]
+
+	// the prevote is for the block following the last one the sender committed
+	signature, err := sender.signer.Sign(common.VoteSigningData(common.PrevoteVote, prevoteMsg.StateID.ID, sender.height+1, prevoteMsg.BlockHash))
+	if err != nil {
+		return []Message{msg}
+	}
+	prevoteMsg.Signature = signature
+
+	body, err := common.Serialize(prevoteMsg)
+	if err != nil {
+		return []Message{msg}
+	}
+
+	conflicting := msg
+	conflicting.Body = body
+
+	switch rand.Intn(3) {
+	case 0:
+		return []Message{msg}
+	case 1:
+		return []Message{conflicting}
+	default:
+		return []Message{msg, conflicting}
+	}
+}
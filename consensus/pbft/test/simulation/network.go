@@ -0,0 +1,139 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package simulation
+
+// Message is a grpc message on its way from a node to another
+type Message struct {
+	From     string
+	To       string
+	Protocol string
+	Body     []byte
+}
+
+// Faults are injected into the delivery of every message
+type Faults struct {
+	// probability that a message is lost
+	DropRate float64
+	// a message is delivered after a delay drawn from [MinDelay, MaxDelay] in millisecond,
+	// so messages sent close together may arrive in another order
+	MinDelay int64
+	MaxDelay int64
+}
+
+// Network delivers the messages between the nodes of a simulation
+type Network struct {
+	scheduler *Scheduler
+	faults    Faults
+	nodes     map[string]*Node
+	groups    map[string]int
+	byzantine map[string]Byzantine
+	delivered int
+	dropped   int
+}
+
+func NewNetwork(scheduler *Scheduler, faults Faults) *Network {
+	return &Network{
+		scheduler: scheduler,
+		faults:    faults,
+		nodes:     make(map[string]*Node),
+		groups:    make(map[string]int),
+		byzantine: make(map[string]Byzantine),
+		delivered: 0,
+		dropped:   0,
+	}
+}
+
+func (n *Network) join(node *Node) {
+	n.nodes[node.ID] = node
+	n.groups[node.ID] = 0
+}
+
+// SetByzantine passes every message the node sends through the behaviour
+func (n *Network) SetByzantine(nodeID string, behaviour Byzantine) {
+	n.byzantine[nodeID] = behaviour
+}
+
+// Partition splits the nodes into groups which can not reach each other.
+// Nodes which are not in any of the groups stay together in a group of their own.
+// Messages in flight between the groups are lost as well.
+func (n *Network) Partition(groups ...[]string) {
+	n.Heal()
+
+	for i, group := range groups {
+		for _, nodeID := range group {
+			n.groups[nodeID] = i + 1
+		}
+	}
+}
+
+// Heal reconnects all the nodes
+func (n *Network) Heal() {
+	for nodeID := range n.groups {
+		n.groups[nodeID] = 0
+	}
+}
+
+func (n *Network) Delivered() int {
+	return n.delivered
+}
+
+func (n *Network) Dropped() int {
+	return n.dropped
+}
+
+func (n *Network) Send(msg Message) {
+	messages := []Message{msg}
+	if behaviour, ok := n.byzantine[msg.From]; ok {
+		messages = behaviour.Tamper(n.nodes[msg.From], msg, n.scheduler.Rand())
+	}
+
+	for _, m := range messages {
+		n.transmit(m)
+	}
+}
+
+func (n *Network) transmit(msg Message) {
+	receiver, ok := n.nodes[msg.To]
+	if !ok || !n.isConnected(msg.From, msg.To) {
+		n.dropped++
+		return
+	}
+
+	if n.faults.DropRate > 0 && n.scheduler.Rand().Float64() < n.faults.DropRate {
+		n.dropped++
+		return
+	}
+
+	delay := n.faults.MinDelay
+	if n.faults.MaxDelay > n.faults.MinDelay {
+		delay += n.scheduler.Rand().Int63n(n.faults.MaxDelay - n.faults.MinDelay + 1)
+	}
+
+	n.scheduler.After(delay, func() {
+		if !n.isConnected(msg.From, msg.To) {
+			n.dropped++
+			return
+		}
+
+		n.delivered++
+		receiver.receive(msg)
+	})
+}
+
+func (n *Network) isConnected(from string, to string) bool {
+	return n.groups[from] == n.groups[to]
+}
@@ -0,0 +1,268 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package simulation
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"sort"
+
+	"github.com/it-chain/engine/common"
+	"github.com/it-chain/engine/common/command"
+	"github.com/it-chain/engine/common/event"
+	"github.com/it-chain/engine/consensus/pbft"
+	"github.com/it-chain/engine/consensus/pbft/api"
+	"github.com/it-chain/engine/consensus/pbft/infra/adapter"
+	"github.com/it-chain/engine/consensus/pbft/infra/mem"
+)
+
+var ErrUnexpectedEvent = errors.New("unexpected event is published")
+var ErrUnexpectedHeight = errors.New("proposed block is not the next block")
+
+// Block is the body of a block proposed in the simulation
+type Block struct {
+	Height   uint64
+	Proposer string
+	Nonce    int64
+}
+
+// Node is a representative which runs the consensus of this repository,
+// with the simulated network and a ledger of the committed block seals in place of the other components
+type Node struct {
+	ID                   string
+	simulation           *Simulation
+	signer               *signer
+	parliamentRepository *mem.ParliamentRepository
+	stateRepository      *mem.StateRepository
+	electionApi          *api.ElectionApi
+	evidenceApi          *api.EvidenceApi
+	stateApi             *api.StateApi
+	handlers             []func(command command.ReceiveGrpc) error
+	committed            map[uint64][]byte
+	doubleCommits        []uint64
+	height               uint64
+	lastCommittedAt      int64
+	electedAt            int64
+}
+
+func newNode(id string, simulation *Simulation, signer *signer, verifier common.SignatureVerifier, representatives []string) *Node {
+	node := &Node{
+		ID:              id,
+		simulation:      simulation,
+		signer:          signer,
+		handlers:        make([]func(command command.ReceiveGrpc) error, 0),
+		committed:       make(map[uint64][]byte),
+		doubleCommits:   make([]uint64, 0),
+		height:          0,
+		lastCommittedAt: 0,
+		electedAt:       0,
+	}
+
+	parliament := pbft.NewParliament()
+	for _, representative := range representatives {
+		parliament.AddRepresentative(pbft.NewRepresentative(representative))
+	}
+	if !simulation.config.Election {
+		parliament.SetLeader(representatives[0])
+	}
+
+	eventService := eventService{node: node}
+	node.parliamentRepository = mem.NewParliamentRepositoryWithParliament(parliament)
+	node.stateRepository = mem.NewStateRepository()
+
+	electionService := pbft.NewElectionService(id, 30, pbft.NORMAL, 0)
+	electionService.SetRandomizer(func(min, max int) int {
+		return min + simulation.scheduler.Rand().Intn(max-min)
+	})
+
+	node.electionApi = api.NewElectionApi(electionService, node.parliamentRepository, mem.NewElectionTermRepository(), node, signer, verifier, eventService)
+	parliamentApi := api.NewParliamentApi(id, node.parliamentRepository, eventService)
+	node.evidenceApi = api.NewEvidenceApi(mem.NewEvidenceRepository(), verifier, eventService)
+	node.stateApi = api.NewStateApi(id, pbft.NewPropagateService(eventService), eventService, signer, verifier, node, node.evidenceApi, node.parliamentRepository, node.stateRepository)
+	node.stateApi.SetRoundTimeout(simulation.config.RoundTimeout)
+
+	node.handlers = append(node.handlers,
+		adapter.NewElectionCommandHandler(parliamentApi, node.electionApi).HandleMessageReceive,
+		adapter.NewPbftMsgHandler(node.stateApi).HandleGrpcMsgCommand,
+	)
+
+	return node
+}
+
+// Height is the height of the last block committed by the node
+func (n *Node) Height() uint64 {
+	return n.height
+}
+
+// LastBlock reports the chain of the node to the election
+func (n *Node) LastBlock() (uint64, []byte, error) {
+	return n.height, n.committed[n.height], nil
+}
+
+// Committed returns the seal of the block committed by the node at the height
+func (n *Node) Committed(height uint64) ([]byte, bool) {
+	seal, ok := n.committed[height]
+	return seal, ok
+}
+
+func (n *Node) IsLeader() bool {
+	parliament := n.parliamentRepository.Load()
+	return parliament.GetLeader().GetID() == n.ID
+}
+
+func (n *Node) Evidences() []pbft.Evidence {
+	return n.evidenceApi.GetEvidences()
+}
+
+// ValidateProposedBlock only accepts the block right after the last committed block,
+// as the blockchain does for a proposal
+func (n *Node) ValidateProposedBlock(block pbft.ProposedBlock) error {
+	if block.Height != n.height+1 {
+		return ErrUnexpectedHeight
+	}
+
+	return nil
+}
+
+func (n *Node) start() {
+	scheduler := n.simulation.scheduler
+
+	if n.simulation.config.Election {
+		n.electionApi.SetState(pbft.TICKING)
+		n.electionApi.ElectionService.InitLeftTime()
+
+		scheduler.Every(1, n.electionApi.Tick)
+		scheduler.Every(api.HeartbeatInterval, n.electionApi.Heartbeat)
+	}
+
+	scheduler.Every(1, n.stateApi.Tick)
+	scheduler.Every(n.simulation.config.ProposeInterval, n.propose)
+}
+
+// the leader proposes the next block when no consensus is in progress. It waits the block interval
+// after its election and its last commit, so the others can learn the leader and catch up.
+// When a round is given up the block is proposed again right away
+func (n *Node) propose() {
+	if !n.IsLeader() {
+		return
+	}
+
+	if _, err := n.stateRepository.Load(); err == nil {
+		return
+	}
+
+	now := n.simulation.scheduler.Now()
+	if now-n.electedAt < n.simulation.config.BlockInterval || now-n.lastCommittedAt < n.simulation.config.BlockInterval {
+		return
+	}
+
+	block := Block{
+		Height:   n.height + 1,
+		Proposer: n.ID,
+		Nonce:    n.simulation.scheduler.Rand().Int63(),
+	}
+
+	body, err := common.Serialize(block)
+	if err != nil {
+		return
+	}
+	seal := sha256.Sum256(body)
+
+	n.stateApi.StartConsensus(pbft.ProposedBlock{Seal: seal[:], Height: block.Height, Body: body})
+}
+
+func (n *Node) receive(msg Message) {
+	receiveCommand := command.ReceiveGrpc{
+		MessageId:    "",
+		Body:         msg.Body,
+		ConnectionID: msg.From,
+		Protocol:     msg.Protocol,
+	}
+
+	for _, handle := range n.handlers {
+		handle(receiveCommand)
+	}
+}
+
+func (n *Node) commit(finished event.ConsensusFinished) {
+	block := Block{}
+	if err := common.Deserialize(finished.Body, &block); err != nil {
+		return
+	}
+
+	if seal, ok := n.committed[block.Height]; ok && !bytes.Equal(seal, finished.Seal) {
+		n.doubleCommits = append(n.doubleCommits, block.Height)
+	}
+
+	n.committed[block.Height] = finished.Seal
+	if block.Height > n.height {
+		n.height = block.Height
+	}
+	n.lastCommittedAt = n.simulation.scheduler.Now()
+}
+
+// eventService hands what a node publishes to the simulation instead of rabbitmq
+type eventService struct {
+	node *Node
+}
+
+func (e eventService) Publish(topic string, evt interface{}) error {
+	switch topic {
+	case "message.deliver":
+		deliverCommand, ok := evt.(command.DeliverGrpc)
+		if !ok {
+			return ErrUnexpectedEvent
+		}
+
+		// recipients are listed from a map, sort them to keep the schedule reproducible
+		recipients := append([]string{}, deliverCommand.RecipientList...)
+		sort.Strings(recipients)
+
+		for _, recipient := range recipients {
+			e.node.simulation.network.Send(Message{
+				From:     e.node.ID,
+				To:       recipient,
+				Protocol: deliverCommand.Protocol,
+				Body:     deliverCommand.Body,
+			})
+		}
+
+	case "block.confirm":
+		finished, ok := evt.(event.ConsensusFinished)
+		if !ok {
+			return ErrUnexpectedEvent
+		}
+
+		e.node.commit(finished)
+
+	case "leader.updated":
+		updated, ok := evt.(event.LeaderUpdated)
+		if !ok {
+			return ErrUnexpectedEvent
+		}
+
+		if updated.LeaderId == e.node.ID {
+			e.node.electedAt = e.node.simulation.scheduler.Now()
+			e.node.simulation.recordLeader(e.node.electionApi.ElectionService.GetTerm(), e.node.ID)
+		}
+	}
+
+	return nil
+}
+
+func (e eventService) Close() {}
@@ -0,0 +1,126 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package simulation
+
+import (
+	"container/heap"
+	"math/rand"
+)
+
+// Scheduler runs the events of a simulation on a virtual clock in millisecond.
+// Events due at the same time run in the order they were scheduled, and every random
+// choice is drawn from the seeded source, so a seed always replays the same schedule.
+type Scheduler struct {
+	now    int64
+	seq    uint64
+	events eventQueue
+	rand   *rand.Rand
+}
+
+func NewScheduler(seed int64) *Scheduler {
+	return &Scheduler{
+		now:    0,
+		seq:    0,
+		events: make(eventQueue, 0),
+		rand:   rand.New(rand.NewSource(seed)),
+	}
+}
+
+func (s *Scheduler) Now() int64 {
+	return s.now
+}
+
+func (s *Scheduler) Rand() *rand.Rand {
+	return s.rand
+}
+
+// After schedules the function to run after the delay
+func (s *Scheduler) After(delay int64, fn func()) {
+	if delay < 0 {
+		delay = 0
+	}
+
+	s.seq++
+	heap.Push(&s.events, &scheduledEvent{
+		at:  s.now + delay,
+		seq: s.seq,
+		fn:  fn,
+	})
+}
+
+// Every schedules the function to run once in every interval
+func (s *Scheduler) Every(interval int64, fn func()) {
+	if interval < 1 {
+		interval = 1
+	}
+
+	s.After(interval, func() {
+		fn()
+		s.Every(interval, fn)
+	})
+}
+
+// RunUntil runs the events due until the end time, and moves the clock to the end time
+func (s *Scheduler) RunUntil(end int64) {
+	for len(s.events) > 0 && s.events[0].at <= end {
+		e := heap.Pop(&s.events).(*scheduledEvent)
+		s.now = e.at
+		e.fn()
+	}
+
+	if end > s.now {
+		s.now = end
+	}
+}
+
+type scheduledEvent struct {
+	at  int64
+	seq uint64
+	fn  func()
+}
+
+type eventQueue []*scheduledEvent
+
+func (q eventQueue) Len() int {
+	return len(q)
+}
+
+func (q eventQueue) Less(i, j int) bool {
+	if q[i].at != q[j].at {
+		return q[i].at < q[j].at
+	}
+
+	return q[i].seq < q[j].seq
+}
+
+func (q eventQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+}
+
+func (q *eventQueue) Push(x interface{}) {
+	*q = append(*q, x.(*scheduledEvent))
+}
+
+func (q *eventQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+
+	return e
+}
@@ -0,0 +1,293 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package simulation runs many representatives of the pbft consensus in one process on a seeded schedule,
+// with message drops, delays, reordering, partitions and byzantine senders injected by the simulated network.
+package simulation
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"fmt"
+	"sort"
+
+	"github.com/it-chain/engine/common"
+)
+
+type Config struct {
+	Seed int64
+	// number of representatives, at least 4 are needed for a consensus
+	Nodes  int
+	Faults Faults
+	// elect the leader with raft instead of making the first node the leader
+	Election bool
+	// time in millisecond the leader checks whether to propose the next block
+	ProposeInterval int64
+	// time in millisecond the leader waits after its last commit before proposing the next block
+	BlockInterval int64
+	// time in millisecond a representative waits for a round to finish before it gives the round up
+	RoundTimeout int
+}
+
+func NewConfig(seed int64, nodes int) Config {
+	return Config{
+		Seed:  seed,
+		Nodes: nodes,
+		Faults: Faults{
+			DropRate: 0,
+			MinDelay: 1,
+			MaxDelay: 20,
+		},
+		Election:        false,
+		ProposeInterval: 10,
+		BlockInterval:   100,
+		RoundTimeout:    500,
+	}
+}
+
+// Simulation runs representatives in one process on a virtual clock.
+// The simulation is single threaded, so the seed of the config decides the whole schedule
+type Simulation struct {
+	config    Config
+	scheduler *Scheduler
+	network   *Network
+	nodes     []*Node
+	leaders   map[int][]string
+}
+
+func NewSimulation(config Config) (*Simulation, error) {
+	scheduler := NewScheduler(config.Seed)
+	simulation := &Simulation{
+		config:    config,
+		scheduler: scheduler,
+		network:   NewNetwork(scheduler, config.Faults),
+		nodes:     make([]*Node, 0),
+		leaders:   make(map[int][]string),
+	}
+
+	ids := make([]string, 0)
+	for i := 0; i < config.Nodes; i++ {
+		ids = append(ids, fmt.Sprintf("node%d", i))
+	}
+	sort.Strings(ids)
+
+	nodeIds := make(map[string]string)
+	verifier := newCachingVerifier(common.NewECDSAVerifier(func(pubKey []byte) (string, error) {
+		id, ok := nodeIds[string(pubKey)]
+		if !ok {
+			return "", common.ErrInvalidKey
+		}
+		return id, nil
+	}))
+
+	signers := make(map[string]*signer)
+	for _, id := range ids {
+		priKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+
+		pubKey, err := x509.MarshalPKIXPublicKey(&priKey.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+
+		signers[id] = &signer{signer: common.NewECDSASigner(id, priKey), verifier: verifier}
+		nodeIds[string(pubKey)] = id
+	}
+
+	for _, id := range ids {
+		node := newNode(id, simulation, signers[id], verifier, ids)
+		simulation.nodes = append(simulation.nodes, node)
+		simulation.network.join(node)
+	}
+
+	for _, node := range simulation.nodes {
+		node.start()
+	}
+
+	return simulation, nil
+}
+
+func (s *Simulation) Scheduler() *Scheduler {
+	return s.scheduler
+}
+
+func (s *Simulation) Network() *Network {
+	return s.network
+}
+
+// Nodes are ordered by id
+func (s *Simulation) Nodes() []*Node {
+	return s.nodes
+}
+
+func (s *Simulation) Node(id string) *Node {
+	for _, node := range s.nodes {
+		if node.ID == id {
+			return node
+		}
+	}
+
+	return nil
+}
+
+// Leader returns the node which the most nodes follow as the leader
+func (s *Simulation) Leader() *Node {
+	votes := make(map[string]int)
+	for _, node := range s.nodes {
+		parliament := node.parliamentRepository.Load()
+		votes[parliament.GetLeader().GetID()]++
+	}
+
+	var leader *Node
+	for _, node := range s.nodes {
+		if votes[node.ID] > 0 && (leader == nil || votes[node.ID] > votes[leader.ID]) {
+			leader = node
+		}
+	}
+
+	return leader
+}
+
+// Run advances the virtual clock by the duration in millisecond
+func (s *Simulation) Run(duration int64) {
+	s.scheduler.RunUntil(s.scheduler.Now() + duration)
+}
+
+// MinHeight is the lowest height committed among the nodes
+func (s *Simulation) MinHeight(nodes []*Node) uint64 {
+	if len(nodes) == 0 {
+		return 0
+	}
+
+	height := nodes[0].Height()
+	for _, node := range nodes[1:] {
+		if node.Height() < height {
+			height = node.Height()
+		}
+	}
+
+	return height
+}
+
+// CheckSafety fails when two different blocks are committed at the same height
+func (s *Simulation) CheckSafety() error {
+	heights := make(map[uint64]*Node)
+
+	for _, node := range s.nodes {
+		if len(node.doubleCommits) != 0 {
+			return fmt.Errorf("%s committed two blocks at height %d", node.ID, node.doubleCommits[0])
+		}
+
+		for height, seal := range node.committed {
+			other, ok := heights[height]
+			if !ok {
+				heights[height] = node
+				continue
+			}
+
+			if !bytes.Equal(other.committed[height], seal) {
+				return fmt.Errorf("%s and %s committed different blocks at height %d", other.ID, node.ID, height)
+			}
+		}
+	}
+
+	return nil
+}
+
+// CheckElectionSafety fails when more than one leader is elected in a term
+func (s *Simulation) CheckElectionSafety() error {
+	for term, leaders := range s.leaders {
+		if len(leaders) > 1 {
+			return fmt.Errorf("%v are elected in term %d", leaders, term)
+		}
+	}
+
+	return nil
+}
+
+// Terms returns the terms in which a leader is elected
+func (s *Simulation) Terms() []int {
+	terms := make([]int, 0)
+	for term := range s.leaders {
+		terms = append(terms, term)
+	}
+	sort.Ints(terms)
+
+	return terms
+}
+
+func (s *Simulation) recordLeader(term int, nodeID string) {
+	for _, leader := range s.leaders[term] {
+		if leader == nodeID {
+			return
+		}
+	}
+
+	s.leaders[term] = append(s.leaders[term], nodeID)
+}
+
+// cachingVerifier remembers the result of each verification. A vote is broadcast to every representative,
+// so without it most of a simulation is spent checking the same signatures again
+type cachingVerifier struct {
+	verifier common.SignatureVerifier
+	results  map[string]error
+}
+
+func newCachingVerifier(verifier common.SignatureVerifier) *cachingVerifier {
+	return &cachingVerifier{
+		verifier: verifier,
+		results:  make(map[string]error),
+	}
+}
+
+func (v *cachingVerifier) Verify(signature common.Signature, data []byte) error {
+	key := verificationKey(signature, data)
+	if err, ok := v.results[key]; ok {
+		return err
+	}
+
+	err := v.verifier.Verify(signature, data)
+	v.results[key] = err
+
+	return err
+}
+
+func verificationKey(signature common.Signature, data []byte) string {
+	return fmt.Sprintf("%s/%x/%x/%x", signature.SignerID, signature.PubKey, signature.Value, data)
+}
+
+// signer signs with the key of a node. A signature it made is valid for the data it signed,
+// so the verifier does not have to check it. A message changed on the way is still checked
+type signer struct {
+	signer   *common.ECDSASigner
+	verifier *cachingVerifier
+}
+
+func (s *signer) Sign(data []byte) (common.Signature, error) {
+	signature, err := s.signer.Sign(data)
+	if err != nil {
+		return signature, err
+	}
+
+	s.verifier.results[verificationKey(signature, data)] = nil
+
+	return signature, nil
+}
@@ -0,0 +1,256 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package simulation_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/it-chain/engine/consensus/pbft/test/simulation"
+	"github.com/stretchr/testify/assert"
+)
+
+// number of random schedules each property is checked on, a fiftieth of them in a short run
+func schedules(t *testing.T, n int) int {
+	if testing.Short() {
+		return n / 50
+	}
+
+	return n
+}
+
+func randomConfig(seed int64) simulation.Config {
+	config := simulation.NewConfig(seed, 4+int(seed%4))
+	config.Faults.MaxDelay = 1 + seed%20
+
+	return config
+}
+
+func TestSimulation_Replay(t *testing.T) {
+	// given
+	run := func() []string {
+		s, err := simulation.NewSimulation(simulation.NewConfig(7, 5))
+		assert.NoError(t, err)
+		s.Run(2000)
+
+		ledgers := make([]string, 0)
+		for _, node := range s.Nodes() {
+			ledger := fmt.Sprintf("%s:%d", node.ID, node.Height())
+			for height := uint64(1); height <= node.Height(); height++ {
+				seal, _ := node.Committed(height)
+				ledger += fmt.Sprintf(":%x", seal)
+			}
+			ledgers = append(ledgers, ledger)
+		}
+		return ledgers
+	}
+
+	// when
+	first := run()
+	second := run()
+
+	// then
+	assert.Equal(t, first, second)
+}
+
+func TestSimulation_SafetyAndLiveness(t *testing.T) {
+	for seed := int64(0); seed < int64(schedules(t, 1000)); seed++ {
+		// given
+		s, err := simulation.NewSimulation(randomConfig(seed))
+		assert.NoError(t, err)
+
+		// when
+		s.Run(3000)
+
+		// then
+		assert.NoError(t, s.CheckSafety(), fmt.Sprintf("seed %d", seed))
+		assert.True(t, s.MinHeight(s.Nodes()) >= 10, fmt.Sprintf("seed %d: committed only %d blocks", seed, s.MinHeight(s.Nodes())))
+	}
+}
+
+func TestSimulation_Safety_LossyNetwork(t *testing.T) {
+	for seed := int64(0); seed < int64(schedules(t, 1000)); seed++ {
+		// given
+		config := randomConfig(seed)
+		config.Faults.DropRate = float64(seed%5) / 10
+		s, err := simulation.NewSimulation(config)
+		assert.NoError(t, err)
+
+		// when
+		s.Run(3000)
+
+		// then
+		assert.NoError(t, s.CheckSafety(), fmt.Sprintf("seed %d", seed))
+	}
+}
+
+func TestSimulation_Safety_Partition(t *testing.T) {
+	for seed := int64(0); seed < int64(schedules(t, 1000)); seed++ {
+		// given
+		s, err := simulation.NewSimulation(randomConfig(seed))
+		assert.NoError(t, err)
+
+		nodes := s.Nodes()
+		rand := s.Scheduler().Rand()
+		group := make([]string, 0)
+		for _, node := range nodes {
+			if rand.Intn(2) == 0 {
+				group = append(group, node.ID)
+			}
+		}
+
+		// when
+		s.Run(rand.Int63n(1000))
+		s.Network().Partition(group)
+		s.Run(rand.Int63n(1000))
+		s.Network().Heal()
+		s.Run(1000)
+
+		// then
+		assert.NoError(t, s.CheckSafety(), fmt.Sprintf("seed %d", seed))
+	}
+}
+
+// a third of the representatives minus one may be faulty, the honest ones still commit the blocks
+func TestSimulation_Byzantine(t *testing.T) {
+	tests := map[string]struct {
+		input struct {
+			behaviour simulation.Byzantine
+			faulty    func(nodes int) int
+		}
+	}{
+		"silent member": {
+			input: struct {
+				behaviour simulation.Byzantine
+				faulty    func(nodes int) int
+			}{behaviour: simulation.Silent{}, faulty: one},
+		},
+		"f silent members": {
+			input: struct {
+				behaviour simulation.Byzantine
+				faulty    func(nodes int) int
+			}{behaviour: simulation.Silent{}, faulty: maxFaulty},
+		},
+		"double prevoting member": {
+			input: struct {
+				behaviour simulation.Byzantine
+				faulty    func(nodes int) int
+			}{behaviour: simulation.DoublePrevoter{}, faulty: one},
+		},
+	}
+
+	for testName, test := range tests {
+		t.Logf("running test case %s", testName)
+
+		detected := 0
+		for seed := int64(0); seed < int64(schedules(t, 500)); seed++ {
+			// given
+			s, err := simulation.NewSimulation(randomConfig(seed))
+			assert.NoError(t, err)
+
+			// the leader, the first node, stays honest
+			nodes := s.Nodes()
+			byzantine := make(map[string]bool)
+			for i := 0; i < test.input.faulty(len(nodes)); i++ {
+				node := nodes[1+(int(seed)+i)%(len(nodes)-1)]
+				byzantine[node.ID] = true
+				s.Network().SetByzantine(node.ID, test.input.behaviour)
+			}
+
+			// when
+			s.Run(3000)
+
+			// then
+			assert.NoError(t, s.CheckSafety(), fmt.Sprintf("seed %d", seed))
+
+			honest := make([]*simulation.Node, 0)
+			for _, node := range nodes {
+				if byzantine[node.ID] {
+					continue
+				}
+				honest = append(honest, node)
+
+				for _, evidence := range node.Evidences() {
+					assert.True(t, byzantine[evidence.OffenderID], fmt.Sprintf("seed %d: %s is reported", seed, evidence.OffenderID))
+					detected++
+				}
+			}
+
+			assert.True(t, s.MinHeight(honest) >= 10, fmt.Sprintf("seed %d: committed only %d blocks", seed, s.MinHeight(honest)))
+		}
+
+		if _, ok := test.input.behaviour.(simulation.DoublePrevoter); ok {
+			assert.True(t, detected > 0)
+		}
+	}
+}
+
+func one(nodes int) int {
+	return 1
+}
+
+// the most faulty representatives a quorum of 2n/3+1 tolerates
+func maxFaulty(nodes int) int {
+	return (nodes - 1) / 3
+}
+
+func TestSimulation_Election(t *testing.T) {
+	for seed := int64(0); seed < int64(schedules(t, 200)); seed++ {
+		// given
+		config := randomConfig(seed)
+		config.Election = true
+		s, err := simulation.NewSimulation(config)
+		assert.NoError(t, err)
+
+		// when
+		s.Run(3000)
+
+		// then
+		assert.NoError(t, s.CheckElectionSafety(), fmt.Sprintf("seed %d", seed))
+		assert.NoError(t, s.CheckSafety(), fmt.Sprintf("seed %d", seed))
+		assert.True(t, len(s.Terms()) > 0, fmt.Sprintf("seed %d: no leader is elected", seed))
+		assert.True(t, s.MinHeight(s.Nodes()) > 0, fmt.Sprintf("seed %d: no block is committed", seed))
+	}
+}
+
+func TestSimulation_Election_LeaderPartitioned(t *testing.T) {
+	for seed := int64(0); seed < int64(schedules(t, 200)); seed++ {
+		// given
+		config := randomConfig(seed)
+		config.Election = true
+		s, err := simulation.NewSimulation(config)
+		assert.NoError(t, err)
+		s.Run(1000)
+
+		leader := s.Leader()
+		assert.NotNil(t, leader, fmt.Sprintf("seed %d: no leader is elected", seed))
+		if leader == nil {
+			continue
+		}
+
+		// when
+		s.Network().Partition([]string{leader.ID})
+		s.Run(1000)
+		s.Network().Heal()
+		s.Run(1000)
+
+		// then
+		assert.NoError(t, s.CheckElectionSafety(), fmt.Sprintf("seed %d", seed))
+		assert.NoError(t, s.CheckSafety(), fmt.Sprintf("seed %d", seed))
+		assert.True(t, len(s.Terms()) > 1, fmt.Sprintf("seed %d: no leader is elected after the partition", seed))
+	}
+}
@@ -0,0 +1,292 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pbft
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+)
+
+const (
+	AddValidator    ValidatorAction = "addValidator"
+	RemoveValidator ValidatorAction = "removeValidator"
+)
+
+var ErrNotValidator = errors.New("voter is not a validator")
+var ErrInvalidValidatorAction = errors.New("invalid validator action")
+var ErrEmptyValidatorId = errors.New("validator id is empty")
+var ErrPastEffectiveHeight = errors.New("effective height has already passed")
+var ErrValidatorAlreadyExist = errors.New("validator already exist")
+var ErrValidatorDoesNotExist = errors.New("validator does not exist")
+var ErrInvalidGovernanceArgs = errors.New("governance transaction needs validator id and effective height")
+var ErrEmptyProposalId = errors.New("proposal id is empty")
+var ErrProposalDecided = errors.New("proposal has already been decided")
+var ErrProposalMismatch = errors.New("proposal id is used for another change")
+
+type ValidatorAction string
+
+// ValidatorVote is a governance transaction sent by a validator.
+// The vote is bound to its proposal, so it is not counted again once the proposal is decided
+type ValidatorVote struct {
+	Voter           string
+	Action          ValidatorAction
+	ValidatorID     string
+	EffectiveHeight uint64
+	ProposalID      string
+}
+
+// NewValidatorVote parses the function and args of a governance transaction,
+// args are [validator id, effective height, proposal id]. The votes of the genesis block have no proposal id
+func NewValidatorVote(voter string, function string, args []string) (ValidatorVote, error) {
+	if len(args) < 2 {
+		return ValidatorVote{}, ErrInvalidGovernanceArgs
+	}
+
+	effectiveHeight, err := strconv.ParseUint(args[1], 10, 64)
+	if err != nil {
+		return ValidatorVote{}, ErrInvalidGovernanceArgs
+	}
+
+	proposalId := ""
+	if len(args) > 2 {
+		proposalId = args[2]
+	}
+
+	return ValidatorVote{
+		Voter:           voter,
+		Action:          ValidatorAction(function),
+		ValidatorID:     args[0],
+		EffectiveHeight: effectiveHeight,
+		ProposalID:      proposalId,
+	}, nil
+}
+
+// ValidatorChange is a membership change which has reached the quorum
+// and waits for its effective height
+type ValidatorChange struct {
+	ProposalID      string
+	Action          ValidatorAction
+	ValidatorID     string
+	EffectiveHeight uint64
+	Voters          map[string]bool
+}
+
+func (c ValidatorChange) isSameChange(other ValidatorChange) bool {
+	return c.Action == other.Action && c.ValidatorID == other.ValidatorID && c.EffectiveHeight == other.EffectiveHeight
+}
+
+func (c ValidatorChange) copy() ValidatorChange {
+	voters := make(map[string]bool)
+	for voter, ok := range c.Voters {
+		voters[voter] = ok
+	}
+	c.Voters = voters
+
+	return c
+}
+
+// ValidatorSet is rebuilt from the governance transactions of the committed blocks.
+// While it is empty the parliament follows the connected peers.
+// Proposals are kept by their id, and the ids of the decided proposals are never counted again
+type ValidatorSet struct {
+	Validators map[string]bool
+	Proposals  map[string]ValidatorChange
+	Scheduled  []ValidatorChange
+	Decided    map[string]bool
+}
+
+func NewValidatorSet() ValidatorSet {
+	return ValidatorSet{
+		Validators: make(map[string]bool),
+		Proposals:  make(map[string]ValidatorChange),
+		Scheduled:  make([]ValidatorChange, 0),
+		Decided:    make(map[string]bool),
+	}
+}
+
+// Copy returns a deep copy, so the copy can be changed without changing the validator set
+func (v ValidatorSet) Copy() ValidatorSet {
+	copied := NewValidatorSet()
+
+	for id, ok := range v.Validators {
+		copied.Validators[id] = ok
+	}
+
+	for key, proposal := range v.Proposals {
+		copied.Proposals[key] = proposal.copy()
+	}
+
+	for _, change := range v.Scheduled {
+		copied.Scheduled = append(copied.Scheduled, change.copy())
+	}
+
+	for id, ok := range v.Decided {
+		copied.Decided[id] = ok
+	}
+
+	return copied
+}
+
+func (v ValidatorSet) IsEnabled() bool {
+	return len(v.Validators) > 0
+}
+
+func (v ValidatorSet) IsValidator(id string) bool {
+	return v.Validators[id]
+}
+
+func (v ValidatorSet) GetValidators() []string {
+	ids := make([]string, 0)
+	for id := range v.Validators {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids
+}
+
+// AddGenesisValidator adds the validators listed in the genesis block, they need no votes
+func (v *ValidatorSet) AddGenesisValidator(id string) error {
+	if id == "" {
+		return ErrEmptyValidatorId
+	}
+
+	v.Validators[id] = true
+	return nil
+}
+
+// CountVote counts a vote committed in the block of the height.
+// The validators of the genesis block need no votes
+func (v *ValidatorSet) CountVote(vote ValidatorVote, height uint64) error {
+	if height != 0 {
+		return v.Vote(vote, height)
+	}
+
+	if vote.Action != AddValidator {
+		return ErrInvalidValidatorAction
+	}
+
+	return v.AddGenesisValidator(vote.ValidatorID)
+}
+
+// CountTransaction counts a governance transaction committed in the block of the height
+func (v *ValidatorSet) CountTransaction(voter string, function string, args []string, height uint64) error {
+	vote, err := NewValidatorVote(voter, function, args)
+	if err != nil {
+		return err
+	}
+
+	return v.CountVote(vote, height)
+}
+
+// Vote counts the vote of a validator in the block of the height.
+// The change is scheduled once a majority of the current validators voted for it
+func (v *ValidatorSet) Vote(vote ValidatorVote, height uint64) error {
+	if !v.IsValidator(vote.Voter) {
+		return ErrNotValidator
+	}
+
+	if vote.ValidatorID == "" {
+		return ErrEmptyValidatorId
+	}
+
+	if vote.ProposalID == "" {
+		return ErrEmptyProposalId
+	}
+
+	if v.Decided[vote.ProposalID] {
+		return ErrProposalDecided
+	}
+
+	if vote.EffectiveHeight <= height {
+		return ErrPastEffectiveHeight
+	}
+
+	switch vote.Action {
+	case AddValidator:
+		if v.IsValidator(vote.ValidatorID) {
+			return ErrValidatorAlreadyExist
+		}
+	case RemoveValidator:
+		if !v.IsValidator(vote.ValidatorID) {
+			return ErrValidatorDoesNotExist
+		}
+	default:
+		return ErrInvalidValidatorAction
+	}
+
+	change := ValidatorChange{
+		ProposalID:      vote.ProposalID,
+		Action:          vote.Action,
+		ValidatorID:     vote.ValidatorID,
+		EffectiveHeight: vote.EffectiveHeight,
+		Voters:          make(map[string]bool),
+	}
+
+	if proposal, ok := v.Proposals[change.ProposalID]; ok {
+		if !proposal.isSameChange(change) {
+			return ErrProposalMismatch
+		}
+		change = proposal
+	}
+	change.Voters[vote.Voter] = true
+
+	if len(change.Voters) >= Majority(len(v.Validators)) {
+		delete(v.Proposals, change.ProposalID)
+		v.Decided[change.ProposalID] = true
+		v.Scheduled = append(v.Scheduled, change)
+		return nil
+	}
+
+	v.Proposals[change.ProposalID] = change
+	return nil
+}
+
+// ApplyScheduled applies the changes effective up to the height in the order they were scheduled
+func (v *ValidatorSet) ApplyScheduled(height uint64) []ValidatorChange {
+	applied := make([]ValidatorChange, 0)
+	remained := make([]ValidatorChange, 0)
+
+	for _, change := range v.Scheduled {
+		if change.EffectiveHeight > height {
+			remained = append(remained, change)
+			continue
+		}
+
+		switch change.Action {
+		case AddValidator:
+			v.Validators[change.ValidatorID] = true
+		case RemoveValidator:
+			delete(v.Validators, change.ValidatorID)
+		}
+		applied = append(applied, change)
+	}
+	v.Scheduled = remained
+
+	return applied
+}
+
+// Advance applies the changes effective up to the height
+func (v *ValidatorSet) Advance(height uint64) {
+	v.ApplyScheduled(height)
+}
+
+type ValidatorSetRepository interface {
+	Save(validatorSet ValidatorSet)
+	Load() ValidatorSet
+}
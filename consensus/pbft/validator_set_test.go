@@ -0,0 +1,188 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pbft_test
+
+import (
+	"testing"
+
+	"github.com/it-chain/engine/consensus/pbft"
+	"github.com/stretchr/testify/assert"
+)
+
+func setValidatorSet(ids ...string) pbft.ValidatorSet {
+	validatorSet := pbft.NewValidatorSet()
+	for _, id := range ids {
+		validatorSet.AddGenesisValidator(id)
+	}
+
+	return validatorSet
+}
+
+func TestValidatorSet_Vote(t *testing.T) {
+	tests := map[string]struct {
+		input struct {
+			vote   pbft.ValidatorVote
+			height uint64
+		}
+		err error
+	}{
+		"vote from not a validator": {
+			input: struct {
+				vote   pbft.ValidatorVote
+				height uint64
+			}{vote: pbft.ValidatorVote{Voter: "4", Action: pbft.AddValidator, ValidatorID: "5", EffectiveHeight: 3, ProposalID: "p1"}, height: 1},
+			err: pbft.ErrNotValidator,
+		},
+		"past effective height": {
+			input: struct {
+				vote   pbft.ValidatorVote
+				height uint64
+			}{vote: pbft.ValidatorVote{Voter: "1", Action: pbft.AddValidator, ValidatorID: "4", EffectiveHeight: 1, ProposalID: "p1"}, height: 1},
+			err: pbft.ErrPastEffectiveHeight,
+		},
+		"add existing validator": {
+			input: struct {
+				vote   pbft.ValidatorVote
+				height uint64
+			}{vote: pbft.ValidatorVote{Voter: "1", Action: pbft.AddValidator, ValidatorID: "2", EffectiveHeight: 3, ProposalID: "p1"}, height: 1},
+			err: pbft.ErrValidatorAlreadyExist,
+		},
+		"remove unknown validator": {
+			input: struct {
+				vote   pbft.ValidatorVote
+				height uint64
+			}{vote: pbft.ValidatorVote{Voter: "1", Action: pbft.RemoveValidator, ValidatorID: "4", EffectiveHeight: 3, ProposalID: "p1"}, height: 1},
+			err: pbft.ErrValidatorDoesNotExist,
+		},
+		"invalid action": {
+			input: struct {
+				vote   pbft.ValidatorVote
+				height uint64
+			}{vote: pbft.ValidatorVote{Voter: "1", Action: "kick", ValidatorID: "2", EffectiveHeight: 3, ProposalID: "p1"}, height: 1},
+			err: pbft.ErrInvalidValidatorAction,
+		},
+		"empty proposal id": {
+			input: struct {
+				vote   pbft.ValidatorVote
+				height uint64
+			}{vote: pbft.ValidatorVote{Voter: "1", Action: pbft.AddValidator, ValidatorID: "4", EffectiveHeight: 3}, height: 1},
+			err: pbft.ErrEmptyProposalId,
+		},
+		"proposal id used for another change": {
+			input: struct {
+				vote   pbft.ValidatorVote
+				height uint64
+			}{vote: pbft.ValidatorVote{Voter: "1", Action: pbft.AddValidator, ValidatorID: "5", EffectiveHeight: 3, ProposalID: "pending"}, height: 1},
+			err: pbft.ErrProposalMismatch,
+		},
+		"vote for decided proposal": {
+			input: struct {
+				vote   pbft.ValidatorVote
+				height uint64
+			}{vote: pbft.ValidatorVote{Voter: "1", Action: pbft.AddValidator, ValidatorID: "4", EffectiveHeight: 3, ProposalID: "decided"}, height: 1},
+			err: pbft.ErrProposalDecided,
+		},
+		"valid vote": {
+			input: struct {
+				vote   pbft.ValidatorVote
+				height uint64
+			}{vote: pbft.ValidatorVote{Voter: "1", Action: pbft.AddValidator, ValidatorID: "4", EffectiveHeight: 3, ProposalID: "p1"}, height: 1},
+			err: nil,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Logf("running test case %s", testName)
+
+		// given
+		validatorSet := setValidatorSet("1", "2", "3")
+		validatorSet.Vote(pbft.ValidatorVote{Voter: "2", Action: pbft.AddValidator, ValidatorID: "4", EffectiveHeight: 3, ProposalID: "pending"}, 1)
+		validatorSet.Vote(pbft.ValidatorVote{Voter: "2", Action: pbft.RemoveValidator, ValidatorID: "3", EffectiveHeight: 3, ProposalID: "decided"}, 1)
+		validatorSet.Vote(pbft.ValidatorVote{Voter: "3", Action: pbft.RemoveValidator, ValidatorID: "3", EffectiveHeight: 3, ProposalID: "decided"}, 1)
+
+		// when
+		err := validatorSet.Vote(test.input.vote, test.input.height)
+
+		// then
+		assert.Equal(t, test.err, err)
+	}
+}
+
+func TestValidatorSet_ApplyScheduled(t *testing.T) {
+	// given
+	validatorSet := setValidatorSet("1", "2", "3")
+	vote := pbft.ValidatorVote{Voter: "1", Action: pbft.AddValidator, ValidatorID: "4", EffectiveHeight: 3, ProposalID: "p1"}
+
+	// when
+	validatorSet.Vote(vote, 1)
+
+	// then
+	assert.Equal(t, 0, len(validatorSet.Scheduled))
+
+	// when
+	vote.Voter = "2"
+	validatorSet.Vote(vote, 1)
+
+	// then
+	assert.Equal(t, 1, len(validatorSet.Scheduled))
+	assert.Equal(t, 0, len(validatorSet.Proposals))
+
+	// when
+	vote.Voter = "3"
+	err := validatorSet.Vote(vote, 1)
+
+	// then
+	assert.Equal(t, pbft.ErrProposalDecided, err)
+	assert.Equal(t, 1, len(validatorSet.Scheduled))
+	assert.Equal(t, 0, len(validatorSet.Proposals))
+
+	// when
+	changes := validatorSet.ApplyScheduled(2)
+
+	// then
+	assert.Equal(t, 0, len(changes))
+	assert.False(t, validatorSet.IsValidator("4"))
+
+	// when
+	changes = validatorSet.ApplyScheduled(3)
+
+	// then
+	assert.Equal(t, 1, len(changes))
+	assert.Equal(t, []string{"1", "2", "3", "4"}, validatorSet.GetValidators())
+
+}
+
+func TestValidatorSet_Copy(t *testing.T) {
+	// given
+	validatorSet := setValidatorSet("1", "2", "3", "4")
+	validatorSet.Vote(pbft.ValidatorVote{Voter: "1", Action: pbft.AddValidator, ValidatorID: "5", EffectiveHeight: 3, ProposalID: "p1"}, 1)
+
+	// when
+	copied := validatorSet.Copy()
+	copied.AddGenesisValidator("6")
+	copied.Vote(pbft.ValidatorVote{Voter: "2", Action: pbft.AddValidator, ValidatorID: "5", EffectiveHeight: 3, ProposalID: "p1"}, 1)
+	copied.Vote(pbft.ValidatorVote{Voter: "3", Action: pbft.AddValidator, ValidatorID: "5", EffectiveHeight: 3, ProposalID: "p1"}, 1)
+
+	// then
+	assert.Equal(t, []string{"1", "2", "3", "4"}, validatorSet.GetValidators())
+	assert.Equal(t, 1, len(validatorSet.Proposals))
+	assert.Equal(t, 0, len(validatorSet.Scheduled))
+	for _, proposal := range validatorSet.Proposals {
+		assert.Equal(t, map[string]bool{"1": true}, proposal.Voters)
+	}
+	assert.Equal(t, 1, len(copied.Scheduled))
+}
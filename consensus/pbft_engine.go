@@ -0,0 +1,52 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package consensus
+
+import (
+	"github.com/it-chain/engine/common/command"
+	"github.com/it-chain/iLogger"
+)
+
+// PbftEngine hands proposed blocks to the pbft component which tolerates byzantine faults.
+// The agreed block comes back as a 'block.confirm' event.
+type PbftEngine struct {
+	eventService EventService
+}
+
+func NewPbftEngine(eventService EventService) *PbftEngine {
+	return &PbftEngine{
+		eventService: eventService,
+	}
+}
+
+func (e *PbftEngine) Mode() string {
+	return PbftMode
+}
+
+func (e *PbftEngine) IsLeaderBased() bool {
+	return true
+}
+
+func (e *PbftEngine) ConsentBlock(block command.StartConsensus) (bool, error) {
+	iLogger.Infof(nil, "[Consensus] Pbft consent - Height: [%d]", block.Height)
+
+	if err := e.eventService.Publish("block.consent", block); err != nil {
+		return false, err
+	}
+
+	return false, nil
+}
@@ -0,0 +1,372 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"sync"
+
+	"github.com/it-chain/engine/common"
+	"github.com/it-chain/engine/common/command"
+	"github.com/it-chain/engine/common/event"
+	"github.com/it-chain/engine/consensus/raft"
+	"github.com/it-chain/iLogger"
+	"github.com/rs/xid"
+)
+
+type ReplicationApi struct {
+	nodeId        string
+	cluster       raft.Cluster
+	logRepository raft.LogRepository
+	eventService  raft.EventService
+	commitIndex   uint64
+	nextIndex     map[string]uint64
+	matchIndex    map[string]uint64
+	// the term nextIndex and matchIndex were built in, they are only valid while this node leads that term
+	leaderTerm int
+	mux        sync.Mutex
+}
+
+func NewReplicationApi(nodeId string, cluster raft.Cluster, logRepository raft.LogRepository, eventService raft.EventService) *ReplicationApi {
+	return &ReplicationApi{
+		nodeId:        nodeId,
+		cluster:       cluster,
+		logRepository: logRepository,
+		eventService:  eventService,
+		commitIndex:   logRepository.CommitIndex(),
+		nextIndex:     make(map[string]uint64),
+		matchIndex:    make(map[string]uint64),
+		mux:           sync.Mutex{},
+	}
+}
+
+// Propose appends the block to the leader's log and replicates it to the followers
+func (r *ReplicationApi) Propose(block command.StartConsensus) error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	if !r.isLeader() {
+		return raft.ErrNotLeader
+	}
+	r.resetOnNewTerm()
+
+	if block.Seal == nil {
+		return raft.ErrEmptySeal
+	}
+
+	body, err := common.Serialize(block)
+	if err != nil {
+		return err
+	}
+
+	entry := raft.Entry{
+		Index: r.logRepository.LastIndex() + 1,
+		Term:  r.cluster.GetTerm(),
+		Seal:  block.Seal,
+		Body:  body,
+	}
+
+	if err := r.logRepository.Append(entry); err != nil {
+		return err
+	}
+
+	iLogger.Infof(nil, "[Raft] Append entry - Index: [%d], Term: [%d]", entry.Index, entry.Term)
+
+	if err := r.advanceCommitIndex(); err != nil {
+		return err
+	}
+
+	for _, follower := range r.followers() {
+		if err := r.sendAppendEntries(follower); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// HandleAppendEntries stores the entries of the leader when the log matches at PrevLogIndex
+func (r *ReplicationApi) HandleAppendEntries(msg raft.AppendEntriesMessage) error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	result := raft.AppendEntriesResultMessage{
+		Term:       r.cluster.GetTerm(),
+		SenderID:   r.nodeId,
+		Success:    false,
+		MatchIndex: 0,
+	}
+
+	if msg.Term < r.cluster.GetTerm() || msg.LeaderID != r.cluster.GetLeaderID() {
+		iLogger.Infof(nil, "[Raft] Reject append entries - Leader: [%s], Term: [%d]", msg.LeaderID, msg.Term)
+		return r.deliver(raft.AppendEntriesResultProtocol, result, msg.LeaderID)
+	}
+
+	if !r.matchLog(msg.PrevLogIndex, msg.PrevLogTerm) {
+		iLogger.Infof(nil, "[Raft] Log does not match - PrevLogIndex: [%d], PrevLogTerm: [%d]", msg.PrevLogIndex, msg.PrevLogTerm)
+		return r.deliver(raft.AppendEntriesResultProtocol, result, msg.LeaderID)
+	}
+
+	for _, entry := range msg.Entries {
+		stored, err := r.logRepository.Get(entry.Index)
+		if err == nil && stored.Term == entry.Term {
+			continue
+		}
+
+		if err == nil {
+			r.logRepository.TruncateFrom(entry.Index)
+		}
+
+		if err := r.logRepository.Append(entry); err != nil {
+			return err
+		}
+	}
+
+	matchIndex := msg.PrevLogIndex + uint64(len(msg.Entries))
+
+	if msg.LeaderCommit > r.commitIndex {
+		if err := r.commitTo(min(msg.LeaderCommit, matchIndex)); err != nil {
+			return err
+		}
+	}
+
+	result.Success = true
+	result.MatchIndex = matchIndex
+
+	return r.deliver(raft.AppendEntriesResultProtocol, result, msg.LeaderID)
+}
+
+// HandleAppendEntriesResult moves the commit index forward once a majority stored an entry,
+// and walks back the follower's next index when its log does not match
+func (r *ReplicationApi) HandleAppendEntriesResult(msg raft.AppendEntriesResultMessage) error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	if !r.isLeader() {
+		return nil
+	}
+	r.resetOnNewTerm()
+
+	// an answer to the entries of a previous term says nothing about this term's log
+	if msg.Term < r.cluster.GetTerm() {
+		return nil
+	}
+
+	if !msg.Success {
+		if msg.Term > r.cluster.GetTerm() {
+			iLogger.Infof(nil, "[Raft] Follower has higher term - Follower: [%s], Term: [%d]", msg.SenderID, msg.Term)
+			return nil
+		}
+
+		if next := r.getNextIndex(msg.SenderID); next > 1 {
+			r.nextIndex[msg.SenderID] = next - 1
+		}
+
+		return r.sendAppendEntries(msg.SenderID)
+	}
+
+	if msg.MatchIndex > r.matchIndex[msg.SenderID] {
+		r.matchIndex[msg.SenderID] = msg.MatchIndex
+	}
+	r.nextIndex[msg.SenderID] = r.matchIndex[msg.SenderID] + 1
+
+	prevCommitIndex := r.commitIndex
+	if err := r.advanceCommitIndex(); err != nil {
+		return err
+	}
+
+	// let the followers know the new commit index
+	if r.commitIndex > prevCommitIndex {
+		for _, follower := range r.followers() {
+			if err := r.sendAppendEntries(follower); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if r.nextIndex[msg.SenderID] <= r.logRepository.LastIndex() {
+		return r.sendAppendEntries(msg.SenderID)
+	}
+
+	return nil
+}
+
+func (r *ReplicationApi) GetCommitIndex() uint64 {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	return r.commitIndex
+}
+
+func (r *ReplicationApi) sendAppendEntries(follower string) error {
+	next := r.getNextIndex(follower)
+	prevLogIndex := next - 1
+	prevLogTerm := 0
+
+	if prevLogIndex > 0 {
+		prevEntry, err := r.logRepository.Get(prevLogIndex)
+		if err != nil {
+			return err
+		}
+		prevLogTerm = prevEntry.Term
+	}
+
+	msg := raft.AppendEntriesMessage{
+		Term:         r.cluster.GetTerm(),
+		LeaderID:     r.nodeId,
+		PrevLogIndex: prevLogIndex,
+		PrevLogTerm:  prevLogTerm,
+		Entries:      r.logRepository.GetFrom(next),
+		LeaderCommit: r.commitIndex,
+	}
+
+	return r.deliver(raft.AppendEntriesProtocol, msg, follower)
+}
+
+// a follower which has not answered yet is assumed to have every entry but the last one
+func (r *ReplicationApi) getNextIndex(follower string) uint64 {
+	next, ok := r.nextIndex[follower]
+	if !ok {
+		next = r.logRepository.LastIndex()
+		if next == 0 {
+			next = 1
+		}
+		r.nextIndex[follower] = next
+	}
+
+	return next
+}
+
+// only entries of the current term are committed by counting replicas
+func (r *ReplicationApi) advanceCommitIndex() error {
+	quorum := len(r.cluster.GetMemberIDs())/2 + 1
+	currentTerm := r.cluster.GetTerm()
+
+	for index := r.logRepository.LastIndex(); index > r.commitIndex; index-- {
+		entry, err := r.logRepository.Get(index)
+		if err != nil {
+			return err
+		}
+
+		if entry.Term != currentTerm {
+			return nil
+		}
+
+		replicas := 1
+		for _, follower := range r.followers() {
+			if r.matchIndex[follower] >= index {
+				replicas++
+			}
+		}
+
+		if replicas >= quorum {
+			return r.commitTo(index)
+		}
+	}
+
+	return nil
+}
+
+// commitTo hands every entry up to the index over to the blockchain component in order
+func (r *ReplicationApi) commitTo(index uint64) error {
+	for r.commitIndex < index {
+		entry, err := r.logRepository.Get(r.commitIndex + 1)
+		if err != nil {
+			return err
+		}
+
+		iLogger.Infof(nil, "[Raft] Commit entry - Index: [%d], Term: [%d]", entry.Index, entry.Term)
+
+		if err := r.eventService.Publish("block.confirm", event.ConsensusFinished{
+			Seal: entry.Seal,
+			Body: entry.Body,
+		}); err != nil {
+			return err
+		}
+
+		if err := r.logRepository.SetCommitIndex(entry.Index); err != nil {
+			return err
+		}
+		r.commitIndex = entry.Index
+	}
+
+	return nil
+}
+
+func (r *ReplicationApi) matchLog(prevLogIndex uint64, prevLogTerm int) bool {
+	if prevLogIndex == 0 {
+		return true
+	}
+
+	entry, err := r.logRepository.Get(prevLogIndex)
+	if err != nil {
+		return false
+	}
+
+	return entry.Term == prevLogTerm
+}
+
+// resetOnNewTerm forgets what the followers stored when this node leads a new term,
+// the followers may have taken entries from another leader in between
+func (r *ReplicationApi) resetOnNewTerm() {
+	term := r.cluster.GetTerm()
+	if term == r.leaderTerm {
+		return
+	}
+
+	r.leaderTerm = term
+	r.nextIndex = make(map[string]uint64)
+	r.matchIndex = make(map[string]uint64)
+}
+
+func (r *ReplicationApi) isLeader() bool {
+	return r.cluster.GetLeaderID() == r.nodeId
+}
+
+func (r *ReplicationApi) followers() []string {
+	followers := make([]string, 0)
+	for _, id := range r.cluster.GetMemberIDs() {
+		if id != r.nodeId {
+			followers = append(followers, id)
+		}
+	}
+
+	return followers
+}
+
+func (r *ReplicationApi) deliver(protocol string, msg interface{}, recipient string) error {
+	body, err := common.Serialize(msg)
+	if err != nil {
+		return err
+	}
+
+	return r.eventService.Publish("message.deliver", command.DeliverGrpc{
+		MessageId:     xid.New().String(),
+		RecipientList: []string{recipient},
+		Body:          body,
+		Protocol:      protocol,
+	})
+}
+
+func min(a uint64, b uint64) uint64 {
+	if a < b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,265 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api_test
+
+import (
+	"testing"
+
+	"github.com/it-chain/engine/common"
+	"github.com/it-chain/engine/common/command"
+	"github.com/it-chain/engine/common/event"
+	"github.com/it-chain/engine/consensus/raft"
+	"github.com/it-chain/engine/consensus/raft/api"
+	"github.com/it-chain/engine/consensus/raft/infra/mem"
+	"github.com/it-chain/engine/consensus/raft/test/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplicationApi_Propose(t *testing.T) {
+
+	tests := map[string]struct {
+		input struct {
+			nodeId string
+			block  command.StartConsensus
+		}
+		err error
+	}{
+		"success": {
+			input: struct {
+				nodeId string
+				block  command.StartConsensus
+			}{nodeId: "leader", block: command.StartConsensus{Seal: []byte("seal")}},
+			err: nil,
+		},
+		"not leader": {
+			input: struct {
+				nodeId string
+				block  command.StartConsensus
+			}{nodeId: "follower1", block: command.StartConsensus{Seal: []byte("seal")}},
+			err: raft.ErrNotLeader,
+		},
+		"empty seal": {
+			input: struct {
+				nodeId string
+				block  command.StartConsensus
+			}{nodeId: "leader", block: command.StartConsensus{}},
+			err: raft.ErrEmptySeal,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Logf("running test case %s", testName)
+
+		// given
+		recipients := make([]string, 0)
+		eventService := mock.EventService{}
+		eventService.PublishFunc = func(topic string, e interface{}) error {
+			assert.Equal(t, "message.deliver", topic)
+			deliver := e.(command.DeliverGrpc)
+			assert.Equal(t, raft.AppendEntriesProtocol, deliver.Protocol)
+			recipients = append(recipients, deliver.RecipientList...)
+			return nil
+		}
+
+		cluster := &mock.Cluster{LeaderID: "leader", MemberIDs: []string{"leader", "follower1", "follower2"}, Term: 1}
+		replicationApi := api.NewReplicationApi(test.input.nodeId, cluster, mem.NewLogRepository(), eventService)
+
+		// when
+		err := replicationApi.Propose(test.input.block)
+
+		// then
+		assert.Equal(t, test.err, err)
+		if err == nil {
+			assert.ElementsMatch(t, []string{"follower1", "follower2"}, recipients)
+		}
+	}
+}
+
+func TestReplicationApi_HandleAppendEntries(t *testing.T) {
+
+	// given
+	var result raft.AppendEntriesResultMessage
+	confirmed := make([]event.ConsensusFinished, 0)
+
+	eventService := mock.EventService{}
+	eventService.PublishFunc = func(topic string, e interface{}) error {
+		switch topic {
+		case "message.deliver":
+			deliver := e.(command.DeliverGrpc)
+			assert.Equal(t, raft.AppendEntriesResultProtocol, deliver.Protocol)
+			assert.Equal(t, []string{"leader"}, deliver.RecipientList)
+			common.Deserialize(deliver.Body, &result)
+		case "block.confirm":
+			confirmed = append(confirmed, e.(event.ConsensusFinished))
+		}
+		return nil
+	}
+
+	cluster := &mock.Cluster{LeaderID: "leader", MemberIDs: []string{"leader", "follower1", "follower2"}, Term: 2}
+	logRepository := mem.NewLogRepository()
+	replicationApi := api.NewReplicationApi("follower1", cluster, logRepository, eventService)
+
+	// when: term of the message is behind
+	err := replicationApi.HandleAppendEntries(raft.AppendEntriesMessage{Term: 1, LeaderID: "leader"})
+
+	// then
+	assert.NoError(t, err)
+	assert.False(t, result.Success)
+
+	// when: log does not have the previous entry
+	err = replicationApi.HandleAppendEntries(raft.AppendEntriesMessage{Term: 2, LeaderID: "leader", PrevLogIndex: 1, PrevLogTerm: 2})
+
+	// then
+	assert.NoError(t, err)
+	assert.False(t, result.Success)
+
+	// when
+	err = replicationApi.HandleAppendEntries(raft.AppendEntriesMessage{
+		Term:     2,
+		LeaderID: "leader",
+		Entries: []raft.Entry{
+			{Index: 1, Term: 2, Seal: []byte("seal1")},
+			{Index: 2, Term: 2, Seal: []byte("seal2")},
+		},
+		LeaderCommit: 1,
+	})
+
+	// then
+	assert.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, uint64(2), result.MatchIndex)
+	assert.Equal(t, uint64(2), logRepository.LastIndex())
+	assert.Equal(t, uint64(1), replicationApi.GetCommitIndex())
+	assert.Equal(t, 1, len(confirmed))
+	assert.Equal(t, []byte("seal1"), confirmed[0].Seal)
+}
+
+func TestReplicationApi_HandleAppendEntries_ConflictingEntry(t *testing.T) {
+
+	// given
+	eventService := mock.EventService{}
+	eventService.PublishFunc = func(topic string, e interface{}) error {
+		return nil
+	}
+
+	cluster := &mock.Cluster{LeaderID: "leader", MemberIDs: []string{"leader", "follower1", "follower2"}, Term: 3}
+	logRepository := mem.NewLogRepository()
+	logRepository.Append(raft.Entry{Index: 1, Term: 1, Seal: []byte("seal1")})
+	logRepository.Append(raft.Entry{Index: 2, Term: 2, Seal: []byte("stale")})
+	logRepository.Append(raft.Entry{Index: 3, Term: 2, Seal: []byte("stale")})
+	replicationApi := api.NewReplicationApi("follower1", cluster, logRepository, eventService)
+
+	// when
+	err := replicationApi.HandleAppendEntries(raft.AppendEntriesMessage{
+		Term:         3,
+		LeaderID:     "leader",
+		PrevLogIndex: 1,
+		PrevLogTerm:  1,
+		Entries: []raft.Entry{
+			{Index: 2, Term: 3, Seal: []byte("seal2")},
+		},
+	})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), logRepository.LastIndex())
+
+	entry, _ := logRepository.Get(2)
+	assert.Equal(t, []byte("seal2"), entry.Seal)
+}
+
+func TestReplicationApi_HandleAppendEntriesResult(t *testing.T) {
+
+	// given
+	confirmed := make([]event.ConsensusFinished, 0)
+	eventService := mock.EventService{}
+	eventService.PublishFunc = func(topic string, e interface{}) error {
+		if topic == "block.confirm" {
+			confirmed = append(confirmed, e.(event.ConsensusFinished))
+		}
+		return nil
+	}
+
+	cluster := &mock.Cluster{LeaderID: "leader", MemberIDs: []string{"leader", "follower1", "follower2", "follower3"}, Term: 1}
+	replicationApi := api.NewReplicationApi("leader", cluster, mem.NewLogRepository(), eventService)
+
+	err := replicationApi.Propose(command.StartConsensus{Seal: []byte("seal1")})
+	assert.NoError(t, err)
+
+	// when: leader and one follower stored the entry
+	err = replicationApi.HandleAppendEntriesResult(raft.AppendEntriesResultMessage{Term: 1, SenderID: "follower1", Success: true, MatchIndex: 1})
+
+	// then: no majority of four members
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), replicationApi.GetCommitIndex())
+	assert.Equal(t, 0, len(confirmed))
+
+	// when
+	err = replicationApi.HandleAppendEntriesResult(raft.AppendEntriesResultMessage{Term: 1, SenderID: "follower2", Success: true, MatchIndex: 1})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), replicationApi.GetCommitIndex())
+	assert.Equal(t, 1, len(confirmed))
+	assert.Equal(t, []byte("seal1"), confirmed[0].Seal)
+}
+
+func TestReplicationApi_NewTerm(t *testing.T) {
+
+	// given
+	sent := make([]raft.AppendEntriesMessage, 0)
+	eventService := mock.EventService{}
+	eventService.PublishFunc = func(topic string, e interface{}) error {
+		if topic == "message.deliver" {
+			msg := raft.AppendEntriesMessage{}
+			common.Deserialize(e.(command.DeliverGrpc).Body, &msg)
+			sent = append(sent, msg)
+		}
+		return nil
+	}
+
+	cluster := &mock.Cluster{LeaderID: "leader", MemberIDs: []string{"leader", "follower1"}, Term: 1}
+	replicationApi := api.NewReplicationApi("leader", cluster, mem.NewLogRepository(), eventService)
+
+	for _, seal := range []string{"seal1", "seal2", "seal3"} {
+		assert.NoError(t, replicationApi.Propose(command.StartConsensus{Seal: []byte(seal)}))
+	}
+
+	// follower1 rejects until the leader walks back to the first entry
+	assert.NoError(t, replicationApi.HandleAppendEntriesResult(raft.AppendEntriesResultMessage{Term: 1, SenderID: "follower1", Success: false}))
+	assert.NoError(t, replicationApi.HandleAppendEntriesResult(raft.AppendEntriesResultMessage{Term: 1, SenderID: "follower1", Success: false}))
+	assert.Equal(t, uint64(0), sent[len(sent)-1].PrevLogIndex)
+
+	// when: the leader wins the election of the next term
+	cluster.Term = 2
+	sent = sent[:0]
+	err := replicationApi.HandleAppendEntriesResult(raft.AppendEntriesResultMessage{Term: 1, SenderID: "follower1", Success: true, MatchIndex: 3})
+
+	// then: the answer of the previous term is ignored
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(sent))
+	assert.Equal(t, uint64(0), replicationApi.GetCommitIndex())
+
+	// when
+	err = replicationApi.Propose(command.StartConsensus{Seal: []byte("seal4")})
+
+	// then: the follower's next index is guessed again instead of reusing the previous term's
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(sent))
+	assert.Equal(t, uint64(3), sent[0].PrevLogIndex)
+	assert.Equal(t, 2, sent[0].Term)
+}
@@ -0,0 +1,55 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"github.com/it-chain/engine/consensus/pbft"
+)
+
+// ParliamentCluster lets the raft replication follow the parliament and the leader election
+// of the consensus component
+type ParliamentCluster struct {
+	parliamentRepository pbft.ParliamentRepository
+	electionService      *pbft.ElectionService
+}
+
+func NewParliamentCluster(parliamentRepository pbft.ParliamentRepository, electionService *pbft.ElectionService) *ParliamentCluster {
+	return &ParliamentCluster{
+		parliamentRepository: parliamentRepository,
+		electionService:      electionService,
+	}
+}
+
+func (c *ParliamentCluster) GetLeaderID() string {
+	parliament := c.parliamentRepository.Load()
+	return parliament.GetLeader().LeaderId
+}
+
+func (c *ParliamentCluster) GetMemberIDs() []string {
+	parliament := c.parliamentRepository.Load()
+
+	memberIds := make([]string, 0)
+	for _, r := range parliament.GetRepresentatives() {
+		memberIds = append(memberIds, r.ID)
+	}
+
+	return memberIds
+}
+
+func (c *ParliamentCluster) GetTerm() int {
+	return c.electionService.GetTerm()
+}
@@ -0,0 +1,45 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"github.com/it-chain/engine/common/command"
+	"github.com/it-chain/iLogger"
+)
+
+type ProposeApi interface {
+	Propose(block command.StartConsensus) error
+}
+
+type ReplicateCommandHandler struct {
+	rApi ProposeApi
+}
+
+func NewReplicateCommandHandler(rApi ProposeApi) *ReplicateCommandHandler {
+	return &ReplicateCommandHandler{
+		rApi: rApi,
+	}
+}
+
+func (r *ReplicateCommandHandler) HandleReplicateCommand(command command.StartConsensus) {
+
+	iLogger.Infof(nil, "[Raft] Replicate block - Height: [%d]", command.Height)
+
+	if err := r.rApi.Propose(command); err != nil {
+		iLogger.Errorf(nil, "[Raft] Replicating block is failed! - %s", err.Error())
+	}
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"errors"
+
+	"github.com/it-chain/engine/common"
+	"github.com/it-chain/engine/common/command"
+	"github.com/it-chain/engine/consensus/raft"
+	"github.com/it-chain/iLogger"
+)
+
+var ErrDeserializing = errors.New("Message deserializing is failed.")
+
+type ReplicationMsgApi interface {
+	HandleAppendEntries(msg raft.AppendEntriesMessage) error
+	HandleAppendEntriesResult(msg raft.AppendEntriesResultMessage) error
+}
+
+type ReplicationMsgHandler struct {
+	rApi ReplicationMsgApi
+}
+
+func NewReplicationMsgHandler(rApi ReplicationMsgApi) *ReplicationMsgHandler {
+	return &ReplicationMsgHandler{
+		rApi: rApi,
+	}
+}
+
+func (r *ReplicationMsgHandler) HandleGrpcMsgCommand(command command.ReceiveGrpc) error {
+
+	switch command.Protocol {
+
+	case raft.AppendEntriesProtocol:
+		msg := raft.AppendEntriesMessage{}
+		if err := common.Deserialize(command.Body, &msg); err != nil {
+			iLogger.Errorf(nil, "[Raft] %s", ErrDeserializing.Error())
+			return err
+		}
+
+		if err := r.rApi.HandleAppendEntries(msg); err != nil {
+			iLogger.Errorf(nil, "[Raft] Cannot handle append entries - Error: [%s]", err.Error())
+		}
+
+	case raft.AppendEntriesResultProtocol:
+		msg := raft.AppendEntriesResultMessage{}
+		if err := common.Deserialize(command.Body, &msg); err != nil {
+			iLogger.Errorf(nil, "[Raft] %s", ErrDeserializing.Error())
+			return err
+		}
+
+		if err := r.rApi.HandleAppendEntriesResult(msg); err != nil {
+			iLogger.Errorf(nil, "[Raft] Cannot handle append entries result - Error: [%s]", err.Error())
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"sync"
+
+	"github.com/it-chain/engine/consensus/raft"
+)
+
+type LogRepository struct {
+	entries     []raft.Entry
+	commitIndex uint64
+	sync.RWMutex
+}
+
+func NewLogRepository() *LogRepository {
+	return &LogRepository{
+		entries: make([]raft.Entry, 0),
+		RWMutex: sync.RWMutex{},
+	}
+}
+
+func (repo *LogRepository) Append(entry raft.Entry) error {
+	repo.Lock()
+	defer repo.Unlock()
+
+	if entry.Index != uint64(len(repo.entries))+1 {
+		return raft.ErrInvalidIndex
+	}
+
+	repo.entries = append(repo.entries, entry)
+
+	return nil
+}
+
+func (repo *LogRepository) Get(index uint64) (raft.Entry, error) {
+	repo.RLock()
+	defer repo.RUnlock()
+
+	if index == 0 || index > uint64(len(repo.entries)) {
+		return raft.Entry{}, raft.ErrEntryNotFound
+	}
+
+	return repo.entries[index-1], nil
+}
+
+func (repo *LogRepository) GetFrom(index uint64) []raft.Entry {
+	repo.RLock()
+	defer repo.RUnlock()
+
+	if index == 0 {
+		index = 1
+	}
+
+	if index > uint64(len(repo.entries)) {
+		return []raft.Entry{}
+	}
+
+	entries := make([]raft.Entry, len(repo.entries[index-1:]))
+	copy(entries, repo.entries[index-1:])
+
+	return entries
+}
+
+// TruncateFrom removes the entry with the index and every entry after it
+func (repo *LogRepository) TruncateFrom(index uint64) {
+	repo.Lock()
+	defer repo.Unlock()
+
+	if index == 0 || index > uint64(len(repo.entries)) {
+		return
+	}
+
+	repo.entries = repo.entries[:index-1]
+}
+
+func (repo *LogRepository) LastIndex() uint64 {
+	repo.RLock()
+	defer repo.RUnlock()
+
+	return uint64(len(repo.entries))
+}
+
+func (repo *LogRepository) LastTerm() int {
+	repo.RLock()
+	defer repo.RUnlock()
+
+	if len(repo.entries) == 0 {
+		return 0
+	}
+
+	return repo.entries[len(repo.entries)-1].Term
+}
+
+func (repo *LogRepository) CommitIndex() uint64 {
+	repo.RLock()
+	defer repo.RUnlock()
+
+	return repo.commitIndex
+}
+
+func (repo *LogRepository) SetCommitIndex(index uint64) error {
+	repo.Lock()
+	defer repo.Unlock()
+
+	repo.commitIndex = index
+
+	return nil
+}
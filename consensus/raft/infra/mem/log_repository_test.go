@@ -0,0 +1,86 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem_test
+
+import (
+	"testing"
+
+	"github.com/it-chain/engine/consensus/raft"
+	"github.com/it-chain/engine/consensus/raft/infra/mem"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogRepository_Append(t *testing.T) {
+
+	repo := mem.NewLogRepository()
+
+	err := repo.Append(raft.Entry{Index: 1, Term: 1})
+	assert.NoError(t, err)
+
+	// case: index is not next to the last index
+	err = repo.Append(raft.Entry{Index: 3, Term: 1})
+	assert.Equal(t, raft.ErrInvalidIndex, err)
+
+	assert.Equal(t, uint64(1), repo.LastIndex())
+	assert.Equal(t, 1, repo.LastTerm())
+}
+
+func TestLogRepository_Get(t *testing.T) {
+
+	repo := mem.NewLogRepository()
+	repo.Append(raft.Entry{Index: 1, Term: 1, Seal: []byte("seal1")})
+
+	entry, err := repo.Get(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("seal1"), entry.Seal)
+
+	_, err = repo.Get(0)
+	assert.Equal(t, raft.ErrEntryNotFound, err)
+
+	_, err = repo.Get(2)
+	assert.Equal(t, raft.ErrEntryNotFound, err)
+}
+
+func TestLogRepository_GetFrom(t *testing.T) {
+
+	repo := mem.NewLogRepository()
+	repo.Append(raft.Entry{Index: 1, Term: 1})
+	repo.Append(raft.Entry{Index: 2, Term: 1})
+	repo.Append(raft.Entry{Index: 3, Term: 2})
+
+	entries := repo.GetFrom(2)
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, uint64(2), entries[0].Index)
+
+	assert.Equal(t, 0, len(repo.GetFrom(4)))
+}
+
+func TestLogRepository_TruncateFrom(t *testing.T) {
+
+	repo := mem.NewLogRepository()
+	repo.Append(raft.Entry{Index: 1, Term: 1})
+	repo.Append(raft.Entry{Index: 2, Term: 1})
+	repo.Append(raft.Entry{Index: 3, Term: 2})
+
+	repo.TruncateFrom(2)
+
+	assert.Equal(t, uint64(1), repo.LastIndex())
+	assert.Equal(t, 1, repo.LastTerm())
+
+	err := repo.Append(raft.Entry{Index: 2, Term: 3})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,244 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package repo
+
+import (
+	"encoding/binary"
+	"sync"
+
+	"github.com/it-chain/engine/common"
+	"github.com/it-chain/engine/consensus/raft"
+	"github.com/it-chain/iLogger"
+	"github.com/it-chain/leveldb-wrapper"
+)
+
+var entryKeyPrefix = []byte("entry_")
+var lastIndexKey = []byte("last_index")
+var commitIndexKey = []byte("commit_index")
+
+// LogRepository keeps the raft log and the commit index on disk,
+// a restarted node must not lose the entries it acknowledged to the leader
+type LogRepository struct {
+	leveldb     *leveldbwrapper.DB
+	lastIndex   uint64
+	lastTerm    int
+	commitIndex uint64
+	sync.RWMutex
+}
+
+func NewLogRepository(path string) (*LogRepository, error) {
+	db := leveldbwrapper.CreateNewDB(path)
+	db.Open()
+
+	repo := &LogRepository{
+		leveldb: db,
+		RWMutex: sync.RWMutex{},
+	}
+
+	lastIndex, err := repo.loadIndex(lastIndexKey)
+	if err != nil {
+		return nil, err
+	}
+
+	commitIndex, err := repo.loadIndex(commitIndexKey)
+	if err != nil {
+		return nil, err
+	}
+
+	repo.lastIndex = lastIndex
+	repo.commitIndex = commitIndex
+
+	if lastIndex > 0 {
+		entry, err := repo.get(lastIndex)
+		if err != nil {
+			return nil, err
+		}
+		repo.lastTerm = entry.Term
+	}
+
+	return repo, nil
+}
+
+func (repo *LogRepository) Append(entry raft.Entry) error {
+	repo.Lock()
+	defer repo.Unlock()
+
+	if entry.Index != repo.lastIndex+1 {
+		return raft.ErrInvalidIndex
+	}
+
+	b, err := common.Serialize(entry)
+	if err != nil {
+		return err
+	}
+
+	if err := repo.leveldb.WriteBatch(map[string][]byte{
+		string(entryKey(entry.Index)): b,
+		string(lastIndexKey):          encodeIndex(entry.Index),
+	}, true); err != nil {
+		return err
+	}
+
+	repo.lastIndex = entry.Index
+	repo.lastTerm = entry.Term
+
+	return nil
+}
+
+func (repo *LogRepository) Get(index uint64) (raft.Entry, error) {
+	repo.RLock()
+	defer repo.RUnlock()
+
+	if index == 0 || index > repo.lastIndex {
+		return raft.Entry{}, raft.ErrEntryNotFound
+	}
+
+	return repo.get(index)
+}
+
+func (repo *LogRepository) GetFrom(index uint64) []raft.Entry {
+	repo.RLock()
+	defer repo.RUnlock()
+
+	if index == 0 {
+		index = 1
+	}
+
+	entries := make([]raft.Entry, 0)
+	for ; index <= repo.lastIndex; index++ {
+		entry, err := repo.get(index)
+		if err != nil {
+			iLogger.Errorf(nil, "[Raft] Cannot read log entry - Index: [%d], Error: [%s]", index, err.Error())
+			break
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+// TruncateFrom removes the entry with the index and every entry after it
+func (repo *LogRepository) TruncateFrom(index uint64) {
+	repo.Lock()
+	defer repo.Unlock()
+
+	if index == 0 || index > repo.lastIndex {
+		return
+	}
+
+	batch := map[string][]byte{
+		string(lastIndexKey): encodeIndex(index - 1),
+	}
+	for i := index; i <= repo.lastIndex; i++ {
+		batch[string(entryKey(i))] = nil
+	}
+
+	if err := repo.leveldb.WriteBatch(batch, true); err != nil {
+		iLogger.Errorf(nil, "[Raft] Cannot truncate log - Index: [%d], Error: [%s]", index, err.Error())
+		return
+	}
+
+	repo.lastIndex = index - 1
+	repo.lastTerm = 0
+	if repo.lastIndex > 0 {
+		entry, err := repo.get(repo.lastIndex)
+		if err != nil {
+			iLogger.Errorf(nil, "[Raft] Cannot read log entry - Index: [%d], Error: [%s]", repo.lastIndex, err.Error())
+			return
+		}
+		repo.lastTerm = entry.Term
+	}
+}
+
+func (repo *LogRepository) LastIndex() uint64 {
+	repo.RLock()
+	defer repo.RUnlock()
+
+	return repo.lastIndex
+}
+
+func (repo *LogRepository) LastTerm() int {
+	repo.RLock()
+	defer repo.RUnlock()
+
+	return repo.lastTerm
+}
+
+func (repo *LogRepository) CommitIndex() uint64 {
+	repo.RLock()
+	defer repo.RUnlock()
+
+	return repo.commitIndex
+}
+
+func (repo *LogRepository) SetCommitIndex(index uint64) error {
+	repo.Lock()
+	defer repo.Unlock()
+
+	if err := repo.leveldb.Put(commitIndexKey, encodeIndex(index), true); err != nil {
+		return err
+	}
+
+	repo.commitIndex = index
+
+	return nil
+}
+
+func (repo *LogRepository) Close() {
+	repo.leveldb.Close()
+}
+
+func (repo *LogRepository) get(index uint64) (raft.Entry, error) {
+	b, err := repo.leveldb.Get(entryKey(index))
+	if err != nil {
+		return raft.Entry{}, err
+	}
+
+	if len(b) == 0 {
+		return raft.Entry{}, raft.ErrEntryNotFound
+	}
+
+	entry := raft.Entry{}
+	if err := common.Deserialize(b, &entry); err != nil {
+		return raft.Entry{}, err
+	}
+
+	return entry, nil
+}
+
+func (repo *LogRepository) loadIndex(key []byte) (uint64, error) {
+	b, err := repo.leveldb.Get(key)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(b) == 0 {
+		return 0, nil
+	}
+
+	return binary.BigEndian.Uint64(b), nil
+}
+
+func entryKey(index uint64) []byte {
+	return append(append([]byte{}, entryKeyPrefix...), encodeIndex(index)...)
+}
+
+func encodeIndex(index uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, index)
+	return b
+}
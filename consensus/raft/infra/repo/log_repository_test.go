@@ -0,0 +1,102 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package repo_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/it-chain/engine/consensus/raft"
+	"github.com/it-chain/engine/consensus/raft/infra/repo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogRepository_Append(t *testing.T) {
+
+	dbPath := "./.db"
+	defer os.RemoveAll(dbPath)
+
+	logRepository, err := repo.NewLogRepository(dbPath)
+	assert.NoError(t, err)
+	defer logRepository.Close()
+
+	assert.NoError(t, logRepository.Append(raft.Entry{Index: 1, Term: 1, Seal: []byte("seal1")}))
+	assert.NoError(t, logRepository.Append(raft.Entry{Index: 2, Term: 2, Seal: []byte("seal2")}))
+
+	// case: index is not next to the last index
+	assert.Equal(t, raft.ErrInvalidIndex, logRepository.Append(raft.Entry{Index: 4, Term: 2}))
+
+	assert.Equal(t, uint64(2), logRepository.LastIndex())
+	assert.Equal(t, 2, logRepository.LastTerm())
+	assert.Len(t, logRepository.GetFrom(1), 2)
+
+	_, err = logRepository.Get(3)
+	assert.Equal(t, raft.ErrEntryNotFound, err)
+}
+
+func TestLogRepository_TruncateFrom(t *testing.T) {
+
+	dbPath := "./.db"
+	defer os.RemoveAll(dbPath)
+
+	logRepository, err := repo.NewLogRepository(dbPath)
+	assert.NoError(t, err)
+	defer logRepository.Close()
+
+	for index := uint64(1); index <= 3; index++ {
+		assert.NoError(t, logRepository.Append(raft.Entry{Index: index, Term: int(index)}))
+	}
+
+	// when
+	logRepository.TruncateFrom(2)
+
+	// then
+	assert.Equal(t, uint64(1), logRepository.LastIndex())
+	assert.Equal(t, 1, logRepository.LastTerm())
+
+	_, err = logRepository.Get(2)
+	assert.Equal(t, raft.ErrEntryNotFound, err)
+	assert.NoError(t, logRepository.Append(raft.Entry{Index: 2, Term: 3}))
+}
+
+func TestLogRepository_Reopen(t *testing.T) {
+
+	dbPath := "./.db"
+	defer os.RemoveAll(dbPath)
+
+	logRepository, err := repo.NewLogRepository(dbPath)
+	assert.NoError(t, err)
+
+	assert.NoError(t, logRepository.Append(raft.Entry{Index: 1, Term: 1, Seal: []byte("seal1")}))
+	assert.NoError(t, logRepository.Append(raft.Entry{Index: 2, Term: 1, Seal: []byte("seal2")}))
+	assert.NoError(t, logRepository.SetCommitIndex(1))
+	logRepository.Close()
+
+	// when
+	logRepository, err = repo.NewLogRepository(dbPath)
+	assert.NoError(t, err)
+	defer logRepository.Close()
+
+	// then
+	assert.Equal(t, uint64(2), logRepository.LastIndex())
+	assert.Equal(t, 1, logRepository.LastTerm())
+	assert.Equal(t, uint64(1), logRepository.CommitIndex())
+
+	entry, err := logRepository.Get(2)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("seal2"), entry.Seal)
+}
@@ -0,0 +1,39 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package raft
+
+// Entry is a block replicated through the raft log.
+// Body holds the serialized block which is handed to the blockchain component on commit
+type Entry struct {
+	Index uint64
+	Term  int
+	Seal  []byte
+	Body  []byte
+}
+
+// Log indices start with 1, index 0 means the log is empty.
+// The commit index is kept with the log, so a restarted node does not hand committed entries over twice
+type LogRepository interface {
+	Append(entry Entry) error
+	Get(index uint64) (Entry, error)
+	GetFrom(index uint64) []Entry
+	TruncateFrom(index uint64)
+	LastIndex() uint64
+	LastTerm() int
+	CommitIndex() uint64
+	SetCommitIndex(index uint64) error
+}
@@ -0,0 +1,43 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mock
+
+type EventService struct {
+	PublishFunc func(topic string, event interface{}) error
+}
+
+func (m EventService) Publish(topic string, event interface{}) error {
+	return m.PublishFunc(topic, event)
+}
+
+type Cluster struct {
+	LeaderID  string
+	MemberIDs []string
+	Term      int
+}
+
+func (c *Cluster) GetLeaderID() string {
+	return c.LeaderID
+}
+
+func (c *Cluster) GetMemberIDs() []string {
+	return c.MemberIDs
+}
+
+func (c *Cluster) GetTerm() int {
+	return c.Term
+}
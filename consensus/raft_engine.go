@@ -0,0 +1,53 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package consensus
+
+import (
+	"github.com/it-chain/engine/common/command"
+	"github.com/it-chain/iLogger"
+)
+
+// RaftEngine hands proposed blocks to the raft component which replicates them to the followers.
+// It only tolerates crash faults, so it is meant for permissioned deployments.
+// The replicated block comes back as a 'block.confirm' event once a majority stored it.
+type RaftEngine struct {
+	eventService EventService
+}
+
+func NewRaftEngine(eventService EventService) *RaftEngine {
+	return &RaftEngine{
+		eventService: eventService,
+	}
+}
+
+func (e *RaftEngine) Mode() string {
+	return RaftMode
+}
+
+func (e *RaftEngine) IsLeaderBased() bool {
+	return true
+}
+
+func (e *RaftEngine) ConsentBlock(block command.StartConsensus) (bool, error) {
+	iLogger.Infof(nil, "[Consensus] Raft consent - Height: [%d]", block.Height)
+
+	if err := e.eventService.Publish("block.replicate", block); err != nil {
+		return false, err
+	}
+
+	return false, nil
+}
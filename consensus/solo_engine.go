@@ -0,0 +1,43 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package consensus
+
+import (
+	"github.com/it-chain/engine/common/command"
+	"github.com/it-chain/iLogger"
+)
+
+// SoloEngine is used when a single node makes up the network.
+// Every proposed block is final as soon as it is proposed.
+type SoloEngine struct{}
+
+func NewSoloEngine() *SoloEngine {
+	return &SoloEngine{}
+}
+
+func (e *SoloEngine) Mode() string {
+	return SoloMode
+}
+
+func (e *SoloEngine) IsLeaderBased() bool {
+	return false
+}
+
+func (e *SoloEngine) ConsentBlock(block command.StartConsensus) (bool, error) {
+	iLogger.Infof(nil, "[Consensus] Solo consent - Height: [%d]", block.Height)
+	return true, nil
+}
@@ -77,6 +77,7 @@ func createConnectionCreatedEvent(connection grpc_gateway.Connection) event.Conn
 		ConnectionID:       connection.ConnectionID,
 		GrpcGatewayAddress: connection.GrpcGatewayAddress,
 		ApiGatewayAddress:  connection.ApiGatewayAddress,
+		Role:               connection.Role,
 	}
 }
 
@@ -163,6 +164,7 @@ func createNetworkjoinedEvent(connectionList []grpc_gateway.Connection) event.Ne
 			ConnectionID:       c.ConnectionID,
 			ApiGatewayAddress:  c.ApiGatewayAddress,
 			GrpcGatewayAddress: c.GrpcGatewayAddress,
+			Role:               c.Role,
 		})
 	}
 
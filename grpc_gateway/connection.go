@@ -20,4 +20,5 @@ type Connection struct {
 	ConnectionID       string
 	ApiGatewayAddress  string
 	GrpcGatewayAddress string
+	Role               string
 }
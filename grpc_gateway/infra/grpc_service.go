@@ -43,6 +43,7 @@ type HostID string
 type HostInfo struct {
 	GrpcGatewayAddress string
 	ApiGatewayAddress  string
+	Role               string
 }
 
 type GrpcHostService struct {
@@ -61,6 +62,7 @@ func NewGrpcHostService(priKey key.PriKey, pubKey key.PubKey, publish Publish, h
 	metaData := make(map[string]string, 0)
 	metaData["GrpcGatewayAddress"] = hostInfo.GrpcGatewayAddress
 	metaData["ApiGatewayAddress"] = hostInfo.ApiGatewayAddress
+	metaData["Role"] = hostInfo.Role
 
 	s := server.New(bifrost.KeyOpts{PriKey: priKey, PubKey: pubKey}, metaData)
 
@@ -119,6 +121,7 @@ func toGatewayConnectionModel(connection bifrost.Connection) grpc_gateway.Connec
 	if metaData != nil {
 		grpcConnection.GrpcGatewayAddress = metaData["GrpcGatewayAddress"]
 		grpcConnection.ApiGatewayAddress = metaData["ApiGatewayAddress"]
+		grpcConnection.Role = metaData["Role"]
 	}
 
 	return grpcConnection
@@ -24,8 +24,10 @@ import (
 	"github.com/it-chain/iLogger"
 
 	"github.com/it-chain/engine/cmd/connection"
+	"github.com/it-chain/engine/cmd/consensus"
 	"github.com/it-chain/engine/cmd/ivm"
 	"github.com/it-chain/engine/cmd/on"
+	"github.com/it-chain/engine/cmd/transaction"
 	"github.com/it-chain/engine/common"
 	"github.com/it-chain/engine/conf"
 	"github.com/urfave/cli"
@@ -56,6 +58,8 @@ func main() {
 	app.Commands = []cli.Command{}
 	app.Commands = append(app.Commands, ivm.IcodeCmd())
 	app.Commands = append(app.Commands, connection.Cmd())
+	app.Commands = append(app.Commands, consensus.Cmd())
+	app.Commands = append(app.Commands, transaction.Cmd())
 	app.Before = func(c *cli.Context) error {
 		if configPath := c.String("config"); configPath != "" {
 			absPath, err := common.RelativeToAbsolutePath(configPath)
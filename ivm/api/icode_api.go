@@ -17,6 +17,8 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"fmt"
 
 	"github.com/it-chain/engine/common"
@@ -25,18 +27,26 @@ import (
 	"github.com/it-chain/iLogger"
 )
 
+var errStartContainer = errors.New("fail to start icode container")
+
 type ICodeApi struct {
 	ContainerService ivm.ContainerService
 	GitService       ivm.GitService
 	EventService     common.EventService
+	ExecutionService *ivm.ExecutionService
+	VersionRegistry  *ivm.VersionRegistry
+	DeployerSet      *ivm.DeployerSet
 }
 
-func NewICodeApi(containerService ivm.ContainerService, gitService ivm.GitService, eventService common.EventService) ICodeApi {
+func NewICodeApi(containerService ivm.ContainerService, gitService ivm.GitService, eventService common.EventService, executionService *ivm.ExecutionService, versionRegistry *ivm.VersionRegistry, deployerSet *ivm.DeployerSet) ICodeApi {
 
 	return ICodeApi{
 		ContainerService: containerService,
 		GitService:       gitService,
 		EventService:     eventService,
+		ExecutionService: executionService,
+		VersionRegistry:  versionRegistry,
+		DeployerSet:      deployerSet,
 	}
 }
 func (i ICodeApi) DeployFromRawSsh(baseSaveUrl string, gitUrl string, rawSsh []byte, password string) (ivm.ICode, error) {
@@ -85,6 +95,222 @@ func (i ICodeApi) Deploy(baseSaveUrl string, gitUrl string, sshPath string, pass
 	return icode, nil
 }
 
+// FetchDeployment clones the code of a deployment transaction at its commit, so the icode gets the same ID on every node.
+// It needs no lock and is called before the block is executed, a commit which is already running is not cloned again.
+// A transaction which is not a valid deployment has nothing to fetch and returns ivm.ErrInvalidDeployment
+func (i ICodeApi) FetchDeployment(ctx context.Context, baseSaveUrl string, tx ivm.Request) (ivm.ICode, error) {
+	deployment, err := ivm.NewDeployment(tx.Function, tx.Args)
+	if err != nil {
+		return ivm.ICode{}, err
+	}
+
+	if icode, running := i.findRunningICode(deployment.GitUrl, deployment.CommitHash); running {
+		return icode, nil
+	}
+
+	return i.GitService.CloneCommit(ctx, baseSaveUrl, deployment.GitUrl, deployment.CommitHash, deployment.Version)
+}
+
+// DeployTransaction deploys the icode fetched for a deployment transaction committed at the height.
+// A deployed icode is a version under its name, which is active from the height or from the activation height of an upgrade.
+// Only the signers of the deployer set deploy and upgrade icodes.
+// The signer of the first deployment of a name owns it, and an upgrade is accepted only from the owner on a runtime keeping the state by name.
+// A deployment which fails on every node, such as an invalid activation height, is published as the execution result of the transaction.
+// An error is returned when the icode could not be started on this node, no result is published then
+func (i ICodeApi) DeployTransaction(height uint64, tx ivm.Request, icode ivm.ICode) error {
+	icode, err := i.deployTransaction(height, tx, icode)
+
+	if err == errStartContainer {
+		return err
+	}
+
+	if err != nil {
+		iLogger.Error(nil, fmt.Sprintf("[IVM] Fail to deploy icode - txID: [%s], message: [%s]", tx.TxID, err.Error()))
+		i.publishTxExecuted(tx, ivm.Result{Err: err.Error()})
+		return nil
+	}
+
+	i.publishTxExecuted(tx, ivm.Result{
+		Data: map[string]string{
+			"ICodeID":    icode.ID,
+			"CommitHash": icode.CommitHash,
+			"Version":    icode.Version,
+		},
+	})
+
+	return nil
+}
+
+func (i ICodeApi) deployTransaction(height uint64, tx ivm.Request, icode ivm.ICode) (ivm.ICode, error) {
+	deployment, err := ivm.NewDeployment(tx.Function, tx.Args)
+	if err != nil {
+		return ivm.ICode{}, err
+	}
+
+	if tx.Signer == "" {
+		return ivm.ICode{}, ivm.ErrUnsignedDeployment
+	}
+
+	if !i.DeployerSet.IsDeployer(tx.Signer) {
+		return ivm.ICode{}, ivm.ErrNotDeployer
+	}
+
+	activationHeight := height
+	if deployment.IsUpgrade() {
+		if !i.keepsStateByName() {
+			return ivm.ICode{}, ivm.ErrUpgradeUnsupported
+		}
+
+		if deployment.ActivationHeight <= height {
+			return ivm.ICode{}, ivm.ErrInvalidActivationHeight
+		}
+		activationHeight = deployment.ActivationHeight
+	}
+
+	iLogger.Info(nil, fmt.Sprintf("[IVM] Deploying icode - url: [%s], commit: [%s]", deployment.GitUrl, deployment.CommitHash))
+
+	// a deployment executed again, as when the blocks are synced again after a restart, keeps its version
+	if i.VersionRegistry.Applied(ivm.NewICodeVersion(icode, activationHeight, deployment.MigrationFunction, tx.Signer)) {
+		return i.startVersion(icode)
+	}
+
+	// the name belongs to the signer of its first deployment, only the owner deploys the other versions
+	if owner, ok := i.VersionRegistry.Owner(icode.RepositoryName); ok && owner != tx.Signer {
+		return ivm.ICode{}, ivm.ErrNotICodeOwner
+	}
+
+	if !deployment.IsUpgrade() && i.VersionRegistry.HasName(icode.RepositoryName) {
+		return ivm.ICode{}, ivm.ErrICodeNameTaken
+	}
+
+	if deployment.IsUpgrade() && !i.VersionRegistry.HasName(icode.RepositoryName) {
+		return ivm.ICode{}, ivm.ErrUnknownICodeName
+	}
+
+	_, running := i.findRunningICode(deployment.GitUrl, deployment.CommitHash)
+
+	// a commit which is already a version is not deployed again
+	if running && i.VersionRegistry.Exists(icode.ID) {
+		return icode, nil
+	}
+
+	if err := i.VersionRegistry.Add(ivm.NewICodeVersion(icode, activationHeight, deployment.MigrationFunction, tx.Signer)); err != nil {
+		return ivm.ICode{}, err
+	}
+
+	if running {
+		return icode, nil
+	}
+
+	if _, err := i.startVersion(icode); err != nil {
+		i.VersionRegistry.Remove(icode.ID)
+		return ivm.ICode{}, err
+	}
+
+	return icode, nil
+}
+
+// RestoreVersions starts the versions restored by a persistent version registry, fetching their code again.
+// An error is returned when a version could not be started, the node can not execute the blocks with it then
+func (i ICodeApi) RestoreVersions(ctx context.Context, baseSaveUrl string) error {
+	for _, version := range i.VersionRegistry.All() {
+		if _, running := i.findRunningICode(version.GitUrl, version.CommitHash); running {
+			continue
+		}
+
+		icode, err := i.GitService.CloneCommit(ctx, baseSaveUrl, version.GitUrl, version.CommitHash, version.Version)
+		if err != nil {
+			return err
+		}
+
+		if _, err := i.startVersion(icode); err != nil {
+			return err
+		}
+
+		iLogger.Info(nil, fmt.Sprintf("[IVM] ICode version is restored - name: [%s], icodeID: [%s]", version.Name, icode.ID))
+	}
+
+	return nil
+}
+
+// startVersion starts the icode of a version unless it is running
+func (i ICodeApi) startVersion(icode ivm.ICode) (ivm.ICode, error) {
+	if _, running := i.findRunningICode(icode.GitUrl, icode.CommitHash); running {
+		return icode, nil
+	}
+
+	if err := i.ContainerService.StartContainer(icode); err != nil {
+		iLogger.Error(nil, fmt.Sprintf("[IVM] Fail to start icode - icodeID: [%s], message: [%s]", icode.ID, err.Error()))
+		return ivm.ICode{}, errStartContainer
+	}
+
+	icodeCreatedEvent := createMetaCreatedEvent(icode)
+	icodeCreatedEvent.Name = icode.RepositoryName
+
+	if err := i.EventService.Publish("icode.created", icodeCreatedEvent); err != nil {
+		iLogger.Error(nil, fmt.Sprintf("[IVM] Fail to publish icode created event - icodeID: [%s], message: [%s]", icode.ID, err.Error()))
+	}
+
+	iLogger.Info(nil, fmt.Sprintf("[IVM] ICode has deployed - icodeID: [%s]", icode.ID))
+	return icode, nil
+}
+
+func (i ICodeApi) keepsStateByName() bool {
+	stateKeeper, ok := i.ContainerService.(ivm.StateKeeper)
+	return ok && stateKeeper.KeepsStateByName()
+}
+
+func (i ICodeApi) findRunningICode(gitUrl string, commitHash string) (ivm.ICode, bool) {
+	for _, icode := range i.GetRunningICodeList() {
+		if icode.GitUrl == gitUrl && icode.CommitHash == commitHash {
+			return icode, true
+		}
+	}
+
+	return ivm.ICode{}, false
+}
+
+// ActivateVersions moves the versions to the committed height and returns the versions activated by it which have a migration function.
+// The migrations have to run on the new versions before the transactions of the block
+func (i ICodeApi) ActivateVersions(height uint64) []ivm.ICodeVersion {
+	migrations := make([]ivm.ICodeVersion, 0)
+
+	for _, version := range i.VersionRegistry.Activate(height) {
+		iLogger.Info(nil, fmt.Sprintf("[IVM] ICode version is activated - name: [%s], icodeID: [%s], height: [%d]", version.Name, version.ICodeID, height))
+
+		if version.MigrationFunction != "" {
+			migrations = append(migrations, version)
+		}
+	}
+
+	return migrations
+}
+
+// Migrate runs the migration function of an activated version, a failed migration is logged and the version stays active.
+// An error is returned when the migration could not be executed on this node
+func (i ICodeApi) Migrate(version ivm.ICodeVersion) error {
+	result, err := i.ExecutionService.ExecuteCommittedRequest(ivm.Request{
+		ICodeID:  version.ICodeID,
+		Function: version.MigrationFunction,
+		Args:     []string{},
+		Type:     "invoke",
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if result.Err != "" {
+		iLogger.Error(nil, fmt.Sprintf("[IVM] Fail to migrate icode - icodeID: [%s], message: [%s]", version.ICodeID, result.Err))
+	}
+
+	return nil
+}
+
+func (i ICodeApi) GetVersions(name string) []ivm.ICodeVersion {
+	return i.VersionRegistry.Versions(name)
+}
+
 func createMetaCreatedEvent(icode ivm.ICode) event.ICodeCreated {
 	return event.ICodeCreated{
 		ID:             icode.ID,
@@ -96,8 +322,15 @@ func createMetaCreatedEvent(icode ivm.ICode) event.ICodeCreated {
 	}
 }
 
+// UnDeploy stops an icode deployed on this node only.
+// A version deployed through a transaction executes the committed blocks of every node, so it is not undeployed
 func (i ICodeApi) UnDeploy(id ivm.ID) error {
 	iLogger.Info(nil, fmt.Sprintf("[IVM] Undeploying icode - icodeID: [%s]", id))
+
+	if i.VersionRegistry.Exists(id) {
+		return ivm.ErrDeployedByTransaction
+	}
+
 	// stop iCode container
 	err := i.ContainerService.StopContainer(id)
 
@@ -110,29 +343,77 @@ func (i ICodeApi) UnDeploy(id ivm.ID) error {
 	return i.EventService.Publish("icode.deleted", event.ICodeDeleted{ICodeID: id})
 }
 
-func (i ICodeApi) ExecuteRequestList(RequestList []ivm.Request) []ivm.Result {
+// ExecuteRequestList executes the requests of a committed block in order, a request to the name of an icode is executed by its active version.
+// A request to an icode which is not deployed through a transaction fails on every node, even if it is deployed on this node.
+// It stops at the first request which could not be executed on this node and returns the results before it with the error
+func (i ICodeApi) ExecuteRequestList(RequestList []ivm.Request) ([]ivm.Result, error) {
 
-	resultList := make([]ivm.Result, 0)
+	resultList := make([]ivm.Result, 0, len(RequestList))
 
 	for _, request := range RequestList {
+		request.ICodeID = i.VersionRegistry.Resolve(request.ICodeID)
 
-		result, err := i.ExecuteRequest(request)
-
-		if err != nil {
-			iLogger.Error(nil, fmt.Sprintf("[IVM] Fail to invoke icode - message: [%s] ", err.Error()))
-			result = ivm.Result{Err: err.Error()}
+		result := ivm.Result{Err: ivm.ErrICodeNotDeployed.Error()}
+		if i.isDeployed(request.ICodeID) {
+			var err error
+			result, err = i.ExecutionService.ExecuteCommittedRequest(request)
+			if err != nil {
+				iLogger.Error(nil, fmt.Sprintf("[IVM] Fail to invoke icode - icodeID: [%s], message: [%s]", request.ICodeID, err.Error()))
+				return resultList, err
+			}
 		}
 
 		resultList = append(resultList, result)
+
+		if request.TxID != "" {
+			i.publishTxExecuted(request, result)
+		}
+	}
+
+	return resultList, nil
+}
+
+// only the versions deployed through transactions are the same on every node,
+// an icode deployed locally with ivm.deploy is not seen by the committed blocks
+func (i ICodeApi) isDeployed(id ivm.ID) bool {
+	return i.VersionRegistry.Exists(id)
+}
+
+func (i ICodeApi) publishTxExecuted(request ivm.Request, result ivm.Result) {
+	txExecutedEvent := event.TxExecuted{
+		TransactionId: request.TxID,
+		ICodeID:       request.ICodeID,
+		Data:          result.Data,
+		Err:           result.Err,
 	}
 
-	return resultList
+	if err := i.EventService.Publish("tx.executed", txExecutedEvent); err != nil {
+		iLogger.Error(nil, fmt.Sprintf("[IVM] Fail to publish execution result - txID: [%s], message: [%s] ", request.TxID, err.Error()))
+	}
 }
 
 func (i ICodeApi) ExecuteRequest(request ivm.Request) (ivm.Result, error) {
-	return i.ContainerService.ExecuteRequest(request)
+	request.ICodeID = i.VersionRegistry.Resolve(request.ICodeID)
+	return i.ExecutionService.ExecuteRequest(request)
 }
 
 func (i ICodeApi) GetRunningICodeList() []ivm.ICode {
 	return i.ContainerService.GetRunningICodeList()
 }
+
+// GetRunningICodeEvents returns the running icodes as they are published when started,
+// the versions deployed through transactions are published with their name
+func (i ICodeApi) GetRunningICodeEvents() []event.ICodeCreated {
+	icodeCreatedEvents := make([]event.ICodeCreated, 0)
+
+	for _, icode := range i.GetRunningICodeList() {
+		icodeCreatedEvent := createMetaCreatedEvent(icode)
+		if i.VersionRegistry.Exists(icode.ID) {
+			icodeCreatedEvent.Name = icode.RepositoryName
+		}
+
+		icodeCreatedEvents = append(icodeCreatedEvents, icodeCreatedEvent)
+	}
+
+	return icodeCreatedEvents
+}
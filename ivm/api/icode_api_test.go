@@ -26,6 +26,7 @@ import (
 	"encoding/hex"
 
 	"github.com/it-chain/engine/common"
+	"github.com/it-chain/engine/consensus/pbft"
 	"github.com/it-chain/engine/ivm"
 	"github.com/it-chain/engine/ivm/api"
 	"github.com/it-chain/engine/ivm/infra/git"
@@ -110,7 +111,7 @@ func TestICodeApi_ExecuteRequestList(t *testing.T) {
 	icode, err := api.Deploy(savePath, "github.com/junbeomlee/learn-icode", sshPath, "")
 	defer api.UnDeploy(icode.ID)
 
-	results := api.ExecuteRequestList([]ivm.Request{
+	results, err := api.ExecuteRequestList([]ivm.Request{
 		ivm.Request{
 			ICodeID:  icode.ID,
 			Function: "initA",
@@ -125,6 +126,7 @@ func TestICodeApi_ExecuteRequestList(t *testing.T) {
 		},
 	})
 
+	assert.NoError(t, err)
 	assert.Equal(t, len(results), 2)
 	for _, result := range results {
 		assert.Equal(t, result.Err, "")
@@ -162,7 +164,9 @@ func setUp(t *testing.T) (*api.ICodeApi, *tesseract.ContainerService) {
 	storeApi := git.NewRepositoryService()
 	containerService := tesseract.NewContainerService()
 	eventService := common.NewEventService("", "Event")
-	icodeApi := api.NewICodeApi(containerService, storeApi, eventService)
+	executionService := ivm.NewExecutionService(containerService, 0, 0, ivm.NewCircuitBreaker(0, 0))
+	validatorSet := pbft.NewValidatorSet()
+	icodeApi := api.NewICodeApi(containerService, storeApi, eventService, executionService, ivm.NewVersionRegistry(), ivm.NewDeployerSet([]string{}, &validatorSet))
 
 	return &icodeApi, containerService
 }
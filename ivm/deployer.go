@@ -0,0 +1,95 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ivm
+
+import (
+	"errors"
+	"sync"
+)
+
+var ErrNotDeployer = errors.New("signer is not allowed to deploy icodes")
+
+// ValidatorSet follows the validators voted by the governance transactions on the ledger.
+// It is provided by the consensus engine
+type ValidatorSet interface {
+	GetValidators() []string
+
+	// CountTransaction counts a governance transaction committed in the block of the height
+	CountTransaction(voter string, function string, args []string, height uint64) error
+
+	// Advance applies the changes effective up to the height
+	Advance(height uint64)
+}
+
+// DeployerSet decides who can deploy and upgrade icodes through transactions.
+// The configured deployers are allowed when there are any, otherwise the validators on the ledger at the height of the block.
+// Every node has to configure the same deployers, a network with neither deployers nor validators rejects every deployment
+type DeployerSet struct {
+	sync.RWMutex
+	deployers    map[string]bool
+	validatorSet ValidatorSet
+}
+
+func NewDeployerSet(deployers []string, validatorSet ValidatorSet) *DeployerSet {
+	deployerSet := &DeployerSet{
+		deployers:    make(map[string]bool),
+		validatorSet: validatorSet,
+	}
+
+	for _, id := range deployers {
+		deployerSet.deployers[id] = true
+	}
+
+	return deployerSet
+}
+
+func (d *DeployerSet) IsDeployer(id string) bool {
+	if id == "" {
+		return false
+	}
+
+	if len(d.deployers) != 0 {
+		return d.deployers[id]
+	}
+
+	d.RLock()
+	defer d.RUnlock()
+
+	for _, validator := range d.validatorSet.GetValidators() {
+		if validator == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CountTransaction counts a governance transaction committed in the block of the height, the voter is its verified signer
+func (d *DeployerSet) CountTransaction(voter string, function string, args []string, height uint64) error {
+	d.Lock()
+	defer d.Unlock()
+
+	return d.validatorSet.CountTransaction(voter, function, args, height)
+}
+
+// Advance applies the validator changes effective up to the height
+func (d *DeployerSet) Advance(height uint64) {
+	d.Lock()
+	defer d.Unlock()
+
+	d.validatorSet.Advance(height)
+}
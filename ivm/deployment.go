@@ -0,0 +1,122 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ivm
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+)
+
+// Deploying an icode to the network is an ordinary transaction addressed to a reserved icode id,
+// so every node deploys the same commit when it executes the block which contains the transaction.
+// Args of a deployment transaction are [git url, commit hash, version].
+// An upgrade deploys a new version under the name of a deployed icode, its args are
+// [git url, commit hash, version, activation height] and optionally the migration function run on activation
+const (
+	DeploymentICodeID = "icode-deployment"
+	DeployFunction    = "deploy"
+	UpgradeFunction   = "upgrade"
+)
+
+var ErrInvalidDeployment = errors.New("invalid deployment transaction, args should be [git url, commit hash, version] or to upgrade [git url, commit hash, version, activation height, migration function]")
+
+var ErrUnsignedDeployment = errors.New("deployment transaction is not signed by a valid key")
+var ErrUpgradeUnsupported = errors.New("icode runtime can not carry the state over to a new version, upgrade is not supported")
+
+var commitHashPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+type Deployment struct {
+	GitUrl     string
+	CommitHash string
+	Version    Version
+	// set only for an upgrade
+	ActivationHeight  uint64
+	MigrationFunction string
+}
+
+func IsDeploymentTransaction(icodeId string) bool {
+	return icodeId == DeploymentICodeID
+}
+
+// NewDeployment reads the deployment from the function and args of a deployment transaction.
+// The commit hash must be a full hash, a branch or a short hash could point to different code on each node
+func NewDeployment(function string, args []string) (Deployment, error) {
+	switch function {
+	case DeployFunction:
+		if len(args) != 3 {
+			return Deployment{}, ErrInvalidDeployment
+		}
+	case UpgradeFunction:
+		if len(args) != 4 && len(args) != 5 {
+			return Deployment{}, ErrInvalidDeployment
+		}
+	default:
+		return Deployment{}, ErrInvalidDeployment
+	}
+
+	if args[0] == "" || !commitHashPattern.MatchString(args[1]) {
+		return Deployment{}, ErrInvalidDeployment
+	}
+
+	deployment := Deployment{
+		GitUrl:     args[0],
+		CommitHash: args[1],
+		Version:    args[2],
+	}
+
+	if function == DeployFunction {
+		return deployment, nil
+	}
+
+	activationHeight, err := strconv.ParseUint(args[3], 10, 64)
+	if err != nil || activationHeight == 0 {
+		return Deployment{}, ErrInvalidDeployment
+	}
+
+	deployment.ActivationHeight = activationHeight
+	if len(args) == 5 {
+		deployment.MigrationFunction = args[4]
+	}
+
+	return deployment, nil
+}
+
+func (d Deployment) IsUpgrade() bool {
+	return d.ActivationHeight != 0
+}
+
+func (d Deployment) Function() string {
+	if d.IsUpgrade() {
+		return UpgradeFunction
+	}
+
+	return DeployFunction
+}
+
+func (d Deployment) Args() []string {
+	if !d.IsUpgrade() {
+		return []string{d.GitUrl, d.CommitHash, d.Version}
+	}
+
+	args := []string{d.GitUrl, d.CommitHash, d.Version, strconv.FormatUint(d.ActivationHeight, 10)}
+	if d.MigrationFunction != "" {
+		args = append(args, d.MigrationFunction)
+	}
+
+	return args
+}
@@ -0,0 +1,133 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ivm_test
+
+import (
+	"testing"
+
+	"github.com/it-chain/engine/ivm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDeployment(t *testing.T) {
+
+	commitHash := "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+	tests := map[string]struct {
+		input struct {
+			function string
+			args     []string
+		}
+		output ivm.Deployment
+		err    error
+	}{
+		"valid": {
+			input: struct {
+				function string
+				args     []string
+			}{function: ivm.DeployFunction, args: []string{"github.com/it-chain/learn-icode", commitHash, "1.0"}},
+			output: ivm.Deployment{GitUrl: "github.com/it-chain/learn-icode", CommitHash: commitHash, Version: "1.0"},
+			err:    nil,
+		},
+		"wrong function": {
+			input: struct {
+				function string
+				args     []string
+			}{function: "initA", args: []string{"github.com/it-chain/learn-icode", commitHash, "1.0"}},
+			output: ivm.Deployment{},
+			err:    ivm.ErrInvalidDeployment,
+		},
+		"missing version": {
+			input: struct {
+				function string
+				args     []string
+			}{function: ivm.DeployFunction, args: []string{"github.com/it-chain/learn-icode", commitHash}},
+			output: ivm.Deployment{},
+			err:    ivm.ErrInvalidDeployment,
+		},
+		"empty git url": {
+			input: struct {
+				function string
+				args     []string
+			}{function: ivm.DeployFunction, args: []string{"", commitHash, "1.0"}},
+			output: ivm.Deployment{},
+			err:    ivm.ErrInvalidDeployment,
+		},
+		"short commit hash": {
+			input: struct {
+				function string
+				args     []string
+			}{function: ivm.DeployFunction, args: []string{"github.com/it-chain/learn-icode", "4b825dc", "1.0"}},
+			output: ivm.Deployment{},
+			err:    ivm.ErrInvalidDeployment,
+		},
+		"branch name": {
+			input: struct {
+				function string
+				args     []string
+			}{function: ivm.DeployFunction, args: []string{"github.com/it-chain/learn-icode", "master", "1.0"}},
+			output: ivm.Deployment{},
+			err:    ivm.ErrInvalidDeployment,
+		},
+		"upgrade": {
+			input: struct {
+				function string
+				args     []string
+			}{function: ivm.UpgradeFunction, args: []string{"github.com/it-chain/learn-icode", commitHash, "2.0", "10", "migrate"}},
+			output: ivm.Deployment{GitUrl: "github.com/it-chain/learn-icode", CommitHash: commitHash, Version: "2.0", ActivationHeight: 10, MigrationFunction: "migrate"},
+			err:    nil,
+		},
+		"upgrade without migration": {
+			input: struct {
+				function string
+				args     []string
+			}{function: ivm.UpgradeFunction, args: []string{"github.com/it-chain/learn-icode", commitHash, "2.0", "10"}},
+			output: ivm.Deployment{GitUrl: "github.com/it-chain/learn-icode", CommitHash: commitHash, Version: "2.0", ActivationHeight: 10},
+			err:    nil,
+		},
+		"upgrade without activation height": {
+			input: struct {
+				function string
+				args     []string
+			}{function: ivm.UpgradeFunction, args: []string{"github.com/it-chain/learn-icode", commitHash, "2.0", "0"}},
+			output: ivm.Deployment{},
+			err:    ivm.ErrInvalidDeployment,
+		},
+		"upgrade with invalid activation height": {
+			input: struct {
+				function string
+				args     []string
+			}{function: ivm.UpgradeFunction, args: []string{"github.com/it-chain/learn-icode", commitHash, "2.0", "ten"}},
+			output: ivm.Deployment{},
+			err:    ivm.ErrInvalidDeployment,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Logf("running test case %s", testName)
+
+		deployment, err := ivm.NewDeployment(test.input.function, test.input.args)
+
+		assert.Equal(t, test.err, err)
+		assert.Equal(t, test.output, deployment)
+
+		if err == nil {
+			assert.Equal(t, test.input.function, deployment.Function())
+			assert.Equal(t, test.input.args, deployment.Args())
+		}
+	}
+}
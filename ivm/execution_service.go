@@ -0,0 +1,272 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ivm
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/it-chain/iLogger"
+)
+
+var ErrExecutionTimeout = errors.New("icode execution timed out")
+var ErrBlockBudgetExceeded = errors.New("execution budget of the block is exceeded")
+var ErrCircuitOpen = errors.New("icode is disabled after repeated failures")
+var ErrICodeNotDeployed = errors.New("icode is not deployed")
+
+// ExecutionStatus tells whether the node stopped executing the committed blocks.
+// Height is the block which could not be executed, zero when the versions could not be restored at startup.
+// The halted block is retried Attempts times so far, the blocks committed in the meantime are queued
+type ExecutionStatus struct {
+	Halted       bool
+	Height       uint64
+	Reason       string
+	Attempts     int
+	QueuedBlocks int
+}
+
+// RequestCanceller is implemented by the container services which can abort a running request of an icode
+type RequestCanceller interface {
+	CancelRequest(id ID) error
+}
+
+// ExecutionService runs requests on the container service so that an icode can not stall the others.
+// A request is given up after the request timeout, a panic of the icode is returned as an error
+// and an icode failing repeatedly is disabled by the circuit breaker.
+// The requests of a committed block are run by ExecuteCommittedRequest and share the block budget,
+// a request exceeding a limit depending on the node is returned as an error instead of a result
+type ExecutionService struct {
+	sync.Mutex
+	containerService ContainerService
+	requestTimeout   time.Duration
+	blockBudget      time.Duration
+	blockDeadline    time.Time
+	circuitBreaker   *CircuitBreaker
+}
+
+// a zero requestTimeout or blockBudget means no limit
+func NewExecutionService(containerService ContainerService, requestTimeout time.Duration, blockBudget time.Duration, circuitBreaker *CircuitBreaker) *ExecutionService {
+	return &ExecutionService{
+		containerService: containerService,
+		requestTimeout:   requestTimeout,
+		blockBudget:      blockBudget,
+		circuitBreaker:   circuitBreaker,
+		Mutex:            sync.Mutex{},
+	}
+}
+
+// BeginBlock starts the budget shared by the committed requests until the next block begins
+func (s *ExecutionService) BeginBlock() {
+	s.Lock()
+	defer s.Unlock()
+
+	s.blockDeadline = time.Time{}
+	if s.blockBudget > 0 {
+		s.blockDeadline = time.Now().Add(s.blockBudget)
+	}
+}
+
+// ExecuteRequest runs a request which is not part of a block, such as a query
+func (s *ExecutionService) ExecuteRequest(request Request) (Result, error) {
+
+	if !s.circuitBreaker.Allow(request.ICodeID) {
+		return Result{}, ErrCircuitOpen
+	}
+
+	result, err := s.execute(request, s.requestTimeout)
+	if err != nil {
+		s.circuitBreaker.RecordFailure(request.ICodeID)
+		return Result{}, err
+	}
+
+	s.circuitBreaker.RecordSuccess(request.ICodeID)
+	return result, nil
+}
+
+// ExecuteCommittedRequest runs a request of a committed block, whose result has to be the same on every node.
+// A panic of the icode happens on every node, so it is returned as Result.Err.
+// A timeout, the block budget running out or a failure of the container service depends on the node and is returned as an error,
+// the caller has to stop executing blocks instead of recording it as the result of the transaction
+func (s *ExecutionService) ExecuteCommittedRequest(request Request) (Result, error) {
+
+	timeout, err := s.committedTimeout()
+	if err != nil {
+		return Result{}, err
+	}
+
+	result, err := s.execute(request, timeout)
+	if panicErr, ok := err.(icodePanic); ok {
+		return Result{Err: panicErr.Error()}, nil
+	}
+
+	return result, err
+}
+
+// committedTimeout is the request timeout, shortened to what is left of the block budget
+func (s *ExecutionService) committedTimeout() (time.Duration, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.blockDeadline.IsZero() {
+		return s.requestTimeout, nil
+	}
+
+	remaining := time.Until(s.blockDeadline)
+	if remaining <= 0 {
+		return 0, ErrBlockBudgetExceeded
+	}
+
+	if s.requestTimeout <= 0 || remaining < s.requestTimeout {
+		return remaining, nil
+	}
+
+	return s.requestTimeout, nil
+}
+
+type icodePanic struct {
+	value interface{}
+}
+
+func (p icodePanic) Error() string {
+	return fmt.Sprintf("icode panicked: %v", p.value)
+}
+
+type outcome struct {
+	result Result
+	err    error
+}
+
+func (s *ExecutionService) execute(request Request, timeout time.Duration) (Result, error) {
+
+	outcomeCh := make(chan outcome, 1)
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				outcomeCh <- outcome{err: icodePanic{value: r}}
+			}
+		}()
+
+		result, err := s.containerService.ExecuteRequest(request)
+		outcomeCh <- outcome{result: result, err: err}
+	}()
+
+	var timeoutCh <-chan time.Time
+	if timeout > 0 {
+		timeoutCh = time.After(timeout)
+	}
+
+	select {
+	case o := <-outcomeCh:
+		return o.result, o.err
+
+	case <-timeoutCh:
+		if o, finished := s.abort(request, outcomeCh); finished {
+			return o.result, o.err
+		}
+		return Result{}, ErrExecutionTimeout
+	}
+}
+
+// abort makes sure that a request which timed out writes nothing once the next request starts.
+// A query is left to finish and an invoke is cancelled by the container service.
+// An invoke which can not be cancelled, e.g. because it has just finished, is waited for and its outcome is returned
+func (s *ExecutionService) abort(request Request, outcomeCh <-chan outcome) (outcome, bool) {
+
+	if request.Type == "query" {
+		return outcome{}, false
+	}
+
+	if canceller, ok := s.containerService.(RequestCanceller); ok {
+		err := canceller.CancelRequest(request.ICodeID)
+		if err == nil {
+			return outcome{}, false
+		}
+
+		iLogger.Error(nil, fmt.Sprintf("[IVM] Fail to cancel icode request - icodeID: [%s], message: [%s]", request.ICodeID, err.Error()))
+	}
+
+	return <-outcomeCh, true
+}
+
+// CircuitBreaker disables an icode for the cooldown after it fails threshold times in a row.
+// After the cooldown one request is let through, the icode is enabled again if it succeeds.
+// Only the failures of the execution count, an error returned by the icode logic does not.
+// The cooldown is wall-clock time, so it only guards the requests outside blocks
+type CircuitBreaker struct {
+	sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  map[ID]int
+	openedAt  map[ID]time.Time
+}
+
+// a zero threshold never disables an icode
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		failures:  make(map[ID]int),
+		openedAt:  make(map[ID]time.Time),
+		Mutex:     sync.Mutex{},
+	}
+}
+
+func (c *CircuitBreaker) Allow(id ID) bool {
+	c.Lock()
+	defer c.Unlock()
+
+	openedAt, ok := c.openedAt[id]
+	if !ok {
+		return true
+	}
+
+	if time.Since(openedAt) < c.cooldown {
+		return false
+	}
+
+	// one more failure opens it again
+	delete(c.openedAt, id)
+	c.failures[id] = c.threshold - 1
+
+	return true
+}
+
+func (c *CircuitBreaker) RecordSuccess(id ID) {
+	c.Lock()
+	defer c.Unlock()
+
+	delete(c.failures, id)
+	delete(c.openedAt, id)
+}
+
+func (c *CircuitBreaker) RecordFailure(id ID) {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.threshold <= 0 {
+		return
+	}
+
+	c.failures[id]++
+	if c.failures[id] >= c.threshold {
+		iLogger.Errorf(nil, "[IVM] ICode is disabled after repeated failures - icodeID: [%s], cooldown: [%s]", id, c.cooldown)
+		c.openedAt[id] = time.Now()
+	}
+}
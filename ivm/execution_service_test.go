@@ -0,0 +1,225 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ivm_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/it-chain/engine/ivm"
+	"github.com/stretchr/testify/assert"
+)
+
+// functionContainerService runs the function named by the request
+type functionContainerService struct {
+	functions map[string]func() (ivm.Result, error)
+}
+
+func (f functionContainerService) StartContainer(icode ivm.ICode) error { return nil }
+
+func (f functionContainerService) StopContainer(id ivm.ID) error { return nil }
+
+func (f functionContainerService) GetRunningICodeList() []ivm.ICode { return nil }
+
+func (f functionContainerService) ExecuteRequest(request ivm.Request) (ivm.Result, error) {
+	return f.functions[request.Function]()
+}
+
+func newContainerService() functionContainerService {
+	return functionContainerService{
+		functions: map[string]func() (ivm.Result, error){
+			"ok": func() (ivm.Result, error) {
+				return ivm.Result{Data: map[string]string{"A": "1"}}, nil
+			},
+			"hang": func() (ivm.Result, error) {
+				time.Sleep(200 * time.Millisecond)
+				return ivm.Result{Data: map[string]string{"A": "2"}}, nil
+			},
+			"panic": func() (ivm.Result, error) {
+				panic("nil map")
+			},
+			"fail": func() (ivm.Result, error) {
+				return ivm.Result{}, errors.New("connection lost")
+			},
+		},
+	}
+}
+
+// cancellableContainerService records the icodes whose requests are cancelled
+type cancellableContainerService struct {
+	functionContainerService
+	cancelled []ivm.ID
+}
+
+func (c *cancellableContainerService) CancelRequest(id ivm.ID) error {
+	c.cancelled = append(c.cancelled, id)
+	return nil
+}
+
+func TestExecutionService_ExecuteRequest(t *testing.T) {
+
+	// given
+	executionService := ivm.NewExecutionService(newContainerService(), 50*time.Millisecond, 0, ivm.NewCircuitBreaker(0, 0))
+
+	// when
+	result, err := executionService.ExecuteRequest(ivm.Request{ICodeID: "icode01", Function: "ok"})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"A": "1"}, result.Data)
+
+	// when
+	start := time.Now()
+	_, err = executionService.ExecuteRequest(ivm.Request{ICodeID: "icode01", Function: "hang", Type: "query"})
+
+	// then a query is given up at the timeout
+	assert.Equal(t, ivm.ErrExecutionTimeout, err)
+	assert.True(t, time.Since(start) < 200*time.Millisecond)
+
+	// when
+	_, err = executionService.ExecuteRequest(ivm.Request{ICodeID: "icode01", Function: "panic"})
+
+	// then
+	assert.EqualError(t, err, "icode panicked: nil map")
+}
+
+func TestExecutionService_ExecuteRequest_TimedOutInvoke(t *testing.T) {
+
+	// given
+	executionService := ivm.NewExecutionService(newContainerService(), 50*time.Millisecond, 0, ivm.NewCircuitBreaker(0, 0))
+
+	// when
+	start := time.Now()
+	result, err := executionService.ExecuteRequest(ivm.Request{ICodeID: "icode01", Function: "hang", Type: "invoke"})
+
+	// then an invoke which can not be cancelled is waited for, its writes are not lost
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"A": "2"}, result.Data)
+	assert.True(t, time.Since(start) >= 200*time.Millisecond)
+
+	// given
+	containerService := &cancellableContainerService{functionContainerService: newContainerService()}
+	executionService = ivm.NewExecutionService(containerService, 50*time.Millisecond, 0, ivm.NewCircuitBreaker(0, 0))
+
+	// when
+	start = time.Now()
+	_, err = executionService.ExecuteRequest(ivm.Request{ICodeID: "icode01", Function: "hang", Type: "invoke"})
+
+	// then
+	assert.Equal(t, ivm.ErrExecutionTimeout, err)
+	assert.True(t, time.Since(start) < 200*time.Millisecond)
+	assert.Equal(t, []ivm.ID{"icode01"}, containerService.cancelled)
+}
+
+func TestExecutionService_ExecuteCommittedRequest(t *testing.T) {
+
+	// given
+	containerService := &cancellableContainerService{functionContainerService: newContainerService()}
+	executionService := ivm.NewExecutionService(containerService, 50*time.Millisecond, 0, ivm.NewCircuitBreaker(1, time.Minute))
+
+	// when
+	result, err := executionService.ExecuteCommittedRequest(ivm.Request{ICodeID: "icode01", Function: "panic", Type: "invoke"})
+
+	// then a panic is the result of the transaction
+	assert.NoError(t, err)
+	assert.Equal(t, "icode panicked: nil map", result.Err)
+
+	// when
+	_, err = executionService.ExecuteCommittedRequest(ivm.Request{ICodeID: "icode01", Function: "fail", Type: "invoke"})
+
+	// then
+	assert.EqualError(t, err, "connection lost")
+
+	// when
+	_, err = executionService.ExecuteCommittedRequest(ivm.Request{ICodeID: "icode01", Function: "hang", Type: "invoke"})
+
+	// then
+	assert.Equal(t, ivm.ErrExecutionTimeout, err)
+
+	// when
+	result, err = executionService.ExecuteCommittedRequest(ivm.Request{ICodeID: "icode01", Function: "ok", Type: "invoke"})
+
+	// then the circuit breaker does not skip the requests of a block
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"A": "1"}, result.Data)
+}
+
+func TestExecutionService_ExecuteCommittedRequest_BlockBudget(t *testing.T) {
+
+	// given
+	containerService := &cancellableContainerService{functionContainerService: newContainerService()}
+	executionService := ivm.NewExecutionService(containerService, time.Second, 100*time.Millisecond, ivm.NewCircuitBreaker(0, 0))
+	executionService.BeginBlock()
+
+	// when
+	_, err1 := executionService.ExecuteCommittedRequest(ivm.Request{ICodeID: "icode01", Function: "ok", Type: "invoke"})
+	_, err2 := executionService.ExecuteCommittedRequest(ivm.Request{ICodeID: "icode01", Function: "hang", Type: "invoke"})
+	_, err3 := executionService.ExecuteCommittedRequest(ivm.Request{ICodeID: "icode01", Function: "ok", Type: "invoke"})
+
+	// then the request is given up when the budget runs out, the rest of the block is not executed
+	assert.NoError(t, err1)
+	assert.Equal(t, ivm.ErrExecutionTimeout, err2)
+	assert.Equal(t, ivm.ErrBlockBudgetExceeded, err3)
+	assert.Equal(t, []ivm.ID{"icode01"}, containerService.cancelled)
+
+	// when
+	executionService.BeginBlock()
+	result, err := executionService.ExecuteCommittedRequest(ivm.Request{ICodeID: "icode01", Function: "ok", Type: "invoke"})
+
+	// then the next block has its own budget
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"A": "1"}, result.Data)
+}
+
+func TestCircuitBreaker(t *testing.T) {
+
+	// given
+	executionService := ivm.NewExecutionService(newContainerService(), 0, 0, ivm.NewCircuitBreaker(2, 100*time.Millisecond))
+
+	// when
+	_, err1 := executionService.ExecuteRequest(ivm.Request{ICodeID: "icode01", Function: "fail"})
+	_, err2 := executionService.ExecuteRequest(ivm.Request{ICodeID: "icode01", Function: "fail"})
+	_, err3 := executionService.ExecuteRequest(ivm.Request{ICodeID: "icode01", Function: "ok"})
+	_, err4 := executionService.ExecuteRequest(ivm.Request{ICodeID: "icode02", Function: "ok"})
+
+	// then
+	assert.Error(t, err1)
+	assert.Error(t, err2)
+	assert.Equal(t, ivm.ErrCircuitOpen, err3)
+	assert.NoError(t, err4)
+
+	// when the cooldown passed, one failure disables it again
+	time.Sleep(150 * time.Millisecond)
+	_, err1 = executionService.ExecuteRequest(ivm.Request{ICodeID: "icode01", Function: "fail"})
+	_, err2 = executionService.ExecuteRequest(ivm.Request{ICodeID: "icode01", Function: "ok"})
+
+	// then
+	assert.Error(t, err1)
+	assert.Equal(t, ivm.ErrCircuitOpen, err2)
+
+	// when a request succeeds after the cooldown
+	time.Sleep(150 * time.Millisecond)
+	_, err1 = executionService.ExecuteRequest(ivm.Request{ICodeID: "icode01", Function: "ok"})
+	_, err2 = executionService.ExecuteRequest(ivm.Request{ICodeID: "icode01", Function: "fail"})
+	_, err3 = executionService.ExecuteRequest(ivm.Request{ICodeID: "icode01", Function: "ok"})
+
+	// then
+	assert.NoError(t, err1)
+	assert.Error(t, err2)
+	assert.NoError(t, err3)
+}
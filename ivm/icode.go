@@ -29,6 +29,11 @@ type ICode struct {
 	Version        Version
 }
 
+// StateNamespace is where the state of the icode is kept, the versions of an icode share it
+func (i ICode) StateNamespace() string {
+	return i.RepositoryName
+}
+
 func NewICode(id string, repositoryName string, gitUrl string, path string, commitHash string, version string) ICode {
 
 	return ICode{
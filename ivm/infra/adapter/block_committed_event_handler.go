@@ -17,44 +17,396 @@
 package adapter
 
 import (
+	"context"
+	"fmt"
 	"sync"
+	"time"
 
+	"github.com/it-chain/engine/common"
 	"github.com/it-chain/engine/common/event"
 	"github.com/it-chain/engine/ivm"
 	"github.com/it-chain/engine/ivm/api"
+	"github.com/it-chain/iLogger"
 )
 
+// the halted block execution is retried after retryBackoff, doubled after each failure up to maxRetryBackoff
+const retryBackoff = time.Second
+const maxRetryBackoff = time.Minute
+
+// BlockCommittedEventHandler executes the transactions of the committed blocks.
+// When a transaction could not be executed on this node, e.g. it timed out or its icode could not be fetched,
+// the node halts instead of recording a result the other nodes may not get.
+// The halted block is retried with a backoff from the transaction which failed,
+// the blocks committed in the meantime are queued and executed once it succeeds
 type BlockCommittedEventHandler struct {
-	icodeApi api.ICodeApi
-	mutex    *sync.Mutex
+	icodeApi          api.ICodeApi
+	signatureVerifier common.SignatureVerifier
+	mutex             *sync.Mutex
+	// serializes the retries, they fetch the code of the deployments without holding the mutex
+	resuming      *sync.Mutex
+	halt          *halt
+	fetchTimeout  time.Duration
+	fetchAttempts int
+}
+
+// halt is where block execution stopped on this node
+type halt struct {
+	// the versions are restored before any block is executed
+	restore  bool
+	progress *blockProgress
+	queue    []event.BlockCommitted
+	reason   string
+	attempts int
+}
+
+// blockProgress is how far a block is executed, so a halted block is resumed without executing a transaction twice
+type blockProgress struct {
+	block      event.BlockCommitted
+	fetched    map[string]ivm.ICode
+	activated  bool
+	migrations []ivm.ICodeVersion
+	executed   int
 }
 
-func NewBlockCommittedEventHandler(icodeApi api.ICodeApi) *BlockCommittedEventHandler {
+// the code of a deployment is fetched at most fetchAttempts times, each given up after fetchTimeout. A zero timeout means no limit
+func NewBlockCommittedEventHandler(icodeApi api.ICodeApi, signatureVerifier common.SignatureVerifier, fetchTimeout time.Duration, fetchAttempts int) *BlockCommittedEventHandler {
 	return &BlockCommittedEventHandler{
-		icodeApi: icodeApi,
-		mutex:    &sync.Mutex{},
+		icodeApi:          icodeApi,
+		signatureVerifier: signatureVerifier,
+		mutex:             &sync.Mutex{},
+		resuming:          &sync.Mutex{},
+		fetchTimeout:      fetchTimeout,
+		fetchAttempts:     fetchAttempts,
 	}
 }
 
 func (b *BlockCommittedEventHandler) HandleBlockCommittedEventHandler(blockCommittedEvent event.BlockCommitted) {
+	if b.queueIfHalted(blockCommittedEvent) {
+		return
+	}
+
+	// the code of the deployments is fetched before taking the lock, so a slow clone does not block the other requests
+	fetched, fetchErr := b.fetchDeployments(blockCommittedEvent.TxList)
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.halt != nil {
+		b.queue(blockCommittedEvent)
+		return
+	}
+
+	progress := &blockProgress{block: blockCommittedEvent, fetched: fetched}
+
+	err := fetchErr
+	if err == nil {
+		err = b.executeBlock(progress)
+	}
+
+	if err != nil {
+		b.stop(&halt{progress: progress}, err)
+	}
+}
+
+func (b *BlockCommittedEventHandler) queueIfHalted(blockCommittedEvent event.BlockCommitted) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.halt == nil {
+		return false
+	}
+
+	b.queue(blockCommittedEvent)
+	return true
+}
+
+func (b *BlockCommittedEventHandler) queue(blockCommittedEvent event.BlockCommitted) {
+	iLogger.Error(nil, fmt.Sprintf("[IVM] Block execution is halted, block is queued - height: [%d]", blockCommittedEvent.Height))
+	b.halt.queue = append(b.halt.queue, blockCommittedEvent)
+}
+
+// stop halts block execution and starts retrying it
+func (b *BlockCommittedEventHandler) stop(halt *halt, err error) {
+	b.halt = halt
+	b.fail(err)
+
+	go b.retry()
+}
+
+func (b *BlockCommittedEventHandler) fail(err error) {
+	b.halt.attempts++
+	b.halt.reason = err.Error()
+
+	if b.halt.progress != nil {
+		iLogger.Error(nil, fmt.Sprintf("[IVM] Halting block execution - height: [%d], attempts: [%d], message: [%s]", b.halt.progress.block.Height, b.halt.attempts, err.Error()))
+		return
+	}
+
+	iLogger.Error(nil, fmt.Sprintf("[IVM] Halting block execution, fail to restore icode versions - attempts: [%d], message: [%s]", b.halt.attempts, err.Error()))
+}
+
+// retry resumes the halted block execution with a backoff until the node catches up
+func (b *BlockCommittedEventHandler) retry() {
+	backoff := retryBackoff
+
+	for {
+		time.Sleep(backoff)
+
+		if b.resume() {
+			return
+		}
+
+		backoff *= 2
+		if backoff > maxRetryBackoff {
+			backoff = maxRetryBackoff
+		}
+	}
+}
+
+// Resume retries the halted block execution right away, e.g. after the cause of the halt is fixed
+func (b *BlockCommittedEventHandler) Resume() ivm.ExecutionStatus {
+	b.resume()
+	return b.Status()
+}
+
+// resume executes the halted block from where it stopped and then the queued blocks.
+// It returns whether the node caught up
+func (b *BlockCommittedEventHandler) resume() bool {
+	b.resuming.Lock()
+	defer b.resuming.Unlock()
+
+	for {
+		b.mutex.Lock()
+
+		if b.halt == nil {
+			b.mutex.Unlock()
+			return true
+		}
+
+		if b.halt.restore {
+			if err := b.restoreVersions(); err != nil {
+				b.fail(err)
+				b.mutex.Unlock()
+				return false
+			}
+			b.halt.restore = false
+		}
+
+		if b.halt.progress == nil {
+			if len(b.halt.queue) == 0 {
+				iLogger.Info(nil, "[IVM] Block execution is resumed")
+				b.halt = nil
+				b.mutex.Unlock()
+				return true
+			}
+
+			b.halt.progress = &blockProgress{block: b.halt.queue[0]}
+			b.halt.queue = b.halt.queue[1:]
+		}
+
+		progress := b.halt.progress
+		b.mutex.Unlock()
+
+		var err error
+		if progress.fetched == nil {
+			progress.fetched, err = b.fetchDeployments(progress.block.TxList)
+		}
+
+		b.mutex.Lock()
+
+		if err == nil {
+			err = b.executeBlock(progress)
+		}
+
+		if err != nil {
+			b.fail(err)
+			b.mutex.Unlock()
+			return false
+		}
+
+		b.halt.progress = nil
+		b.mutex.Unlock()
+	}
+}
+
+// Status tells whether block execution is halted on this node
+func (b *BlockCommittedEventHandler) Status() ivm.ExecutionStatus {
 	b.mutex.Lock()
 	defer b.mutex.Unlock()
 
-	b.icodeApi.ExecuteRequestList(createRequestList(blockCommittedEvent.TxList))
+	if b.halt == nil {
+		return ivm.ExecutionStatus{}
+	}
+
+	status := ivm.ExecutionStatus{
+		Halted:       true,
+		Reason:       b.halt.reason,
+		Attempts:     b.halt.attempts,
+		QueuedBlocks: len(b.halt.queue),
+	}
+
+	if b.halt.progress != nil {
+		status.Height = b.halt.progress.block.Height
+	}
+
+	return status
 }
 
-func createRequestList(transactionList []event.Tx) []ivm.Request {
+// RestoreVersions starts the versions restored from the disk when the node starts.
+// The blocks wait for it, and the node halts when a version can not be started
+func (b *BlockCommittedEventHandler) RestoreVersions() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
 
-	requestList := make([]ivm.Request, 0)
+	if err := b.restoreVersions(); err != nil {
+		b.stop(&halt{restore: true}, err)
+	}
+}
+
+func (b *BlockCommittedEventHandler) restoreVersions() error {
+	attempts := b.fetchAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	ctx, cancel := context.Background(), func() {}
+	if b.fetchTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, b.fetchTimeout*time.Duration(attempts))
+	}
+	defer cancel()
+
+	return b.icodeApi.RestoreVersions(ctx, icodeSavePath())
+}
+
+// fetchDeployments returns the icodes of the deployment transactions by transaction id
+func (b *BlockCommittedEventHandler) fetchDeployments(transactionList []event.Tx) (map[string]ivm.ICode, error) {
+
+	fetched := make(map[string]ivm.ICode)
 
 	for _, transaction := range transactionList {
-		requestList = append(requestList, ivm.Request{
-			Function: transaction.Function,
-			Args:     transaction.Args,
-			ICodeID:  transaction.ICodeID,
-			Type:     "invoke",
-		})
+		if !ivm.IsDeploymentTransaction(transaction.ICodeID) {
+			continue
+		}
+
+		icode, err := b.fetchDeployment(createRequest(transaction))
+
+		// an invalid deployment fails on every node when it is executed
+		if err == ivm.ErrInvalidDeployment {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		fetched[transaction.ID] = icode
+	}
+
+	return fetched, nil
+}
+
+func (b *BlockCommittedEventHandler) fetchDeployment(deployment ivm.Request) (ivm.ICode, error) {
+
+	var err error
+
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.Background(), func() {}
+		if b.fetchTimeout > 0 {
+			ctx, cancel = context.WithTimeout(ctx, b.fetchTimeout)
+		}
+
+		var icode ivm.ICode
+		icode, err = b.icodeApi.FetchDeployment(ctx, icodeSavePath(), deployment)
+		cancel()
+
+		if err == nil || err == ivm.ErrInvalidDeployment || attempt >= b.fetchAttempts {
+			return icode, err
+		}
+
+		iLogger.Error(nil, fmt.Sprintf("[IVM] Fail to fetch icode, retrying - txID: [%s], attempt: [%d], message: [%s]", deployment.TxID, attempt, err.Error()))
+		time.Sleep(time.Duration(attempt) * time.Second)
+	}
+}
+
+// executeBlock runs the transactions in the order of the block from where the progress stopped.
+// A deployment takes effect at its position, so only the transactions after it can invoke the deployed icode
+func (b *BlockCommittedEventHandler) executeBlock(progress *blockProgress) error {
+	b.icodeApi.ExecutionService.BeginBlock()
+
+	height := progress.block.Height
+
+	// versions activated at the height are migrated before any transaction of the block is executed
+	if !progress.activated {
+		progress.migrations = b.icodeApi.ActivateVersions(height)
+		progress.activated = true
+	}
+
+	for len(progress.migrations) != 0 {
+		if err := b.icodeApi.Migrate(progress.migrations[0]); err != nil {
+			return err
+		}
+		progress.migrations = progress.migrations[1:]
+	}
+
+	for ; progress.executed < len(progress.block.TxList); progress.executed++ {
+		transaction := progress.block.TxList[progress.executed]
+
+		// governance transactions are handled by consensus, not by an icode.
+		// They are counted for the deployer set, which follows the validators on the ledger
+		if common.IsGovernanceTransaction(transaction.ICodeID) {
+			b.countValidatorVote(height, transaction)
+			continue
+		}
+
+		if !ivm.IsDeploymentTransaction(transaction.ICodeID) {
+			if _, err := b.icodeApi.ExecuteRequestList([]ivm.Request{createRequest(transaction)}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		deployment := createRequest(transaction)
+		deployment.Signer = b.verifySigner(transaction)
+
+		if err := b.icodeApi.DeployTransaction(height, deployment, progress.fetched[transaction.ID]); err != nil {
+			return err
+		}
 	}
 
-	return requestList
+	b.icodeApi.DeployerSet.Advance(height + 1)
+
+	return nil
+}
+
+func (b *BlockCommittedEventHandler) countValidatorVote(height uint64, transaction event.Tx) {
+	signingData := common.TransactionSigningData(transaction.Jsonrpc, transaction.ICodeID, transaction.Function, transaction.Args, transaction.IdempotencyKey, transaction.Deadline, transaction.MaxHeight)
+
+	voter, err := common.GovernanceVoter(b.signatureVerifier, height, transaction.Signature, signingData)
+	if err != nil {
+		return
+	}
+
+	b.icodeApi.DeployerSet.CountTransaction(voter, transaction.Function, transaction.Args, height)
+}
+
+// verifySigner returns the signer of the transaction, or empty when it is not signed by a valid key
+func (b *BlockCommittedEventHandler) verifySigner(transaction event.Tx) string {
+	signingData := common.TransactionSigningData(transaction.Jsonrpc, transaction.ICodeID, transaction.Function, transaction.Args, transaction.IdempotencyKey, transaction.Deadline, transaction.MaxHeight)
+
+	signer, err := common.VerifyTransactionSigner(b.signatureVerifier, transaction.Signature, signingData)
+	if err != nil {
+		return ""
+	}
+
+	return signer
+}
+
+func createRequest(transaction event.Tx) ivm.Request {
+	return ivm.Request{
+		Function: transaction.Function,
+		Args:     transaction.Args,
+		ICodeID:  transaction.ICodeID,
+		Type:     "invoke",
+		TxID:     transaction.ID,
+	}
 }
@@ -17,6 +17,11 @@
 package adapter_test
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
 	"errors"
 	"testing"
 
@@ -24,6 +29,7 @@ import (
 
 	"github.com/it-chain/engine/common"
 	"github.com/it-chain/engine/common/event"
+	"github.com/it-chain/engine/consensus/pbft"
 	"github.com/it-chain/engine/ivm"
 	"github.com/it-chain/engine/ivm/api"
 	"github.com/it-chain/engine/ivm/infra/adapter"
@@ -65,6 +71,378 @@ func TestBlockCommittedEventHandler_HandleBlockCommittedEventHandler(t *testing.
 	assert.Equal(t, result.Err, "")
 }
 
+const commitHash = "0123456789abcdef0123456789abcdef01234567"
+const upgradeCommitHash = "89abcdef0123456789abcdef0123456789abcdef"
+
+// clients sign transactions with keys of their own, the verifier knows them by their public keys
+type clients struct {
+	ids map[string]string
+}
+
+func (c *clients) verifier() common.SignatureVerifier {
+	return common.NewECDSAVerifier(func(pubKey []byte) (string, error) {
+		return c.ids[string(pubKey)], nil
+	})
+}
+
+func (c *clients) sign(t *testing.T, id string, transaction event.Tx) event.Tx {
+	priKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	transaction.Jsonrpc = "2.0"
+	transaction.IdempotencyKey = transaction.ID
+	signature, err := common.NewECDSASigner(id, priKey).Sign(common.TransactionSigningData(transaction.Jsonrpc, transaction.ICodeID, transaction.Function, transaction.Args, transaction.IdempotencyKey, transaction.Deadline, transaction.MaxHeight))
+	assert.NoError(t, err)
+
+	c.ids[string(signature.PubKey)] = id
+	transaction.Signature, _ = json.Marshal(signature)
+
+	return transaction
+}
+
+// gitService clones nothing, it only fails while failing is set
+type gitService struct {
+	ivm.GitService
+	failing bool
+	clones  int
+}
+
+func (g *gitService) CloneCommit(ctx context.Context, baseSavePath string, repositoryUrl string, commitHash string, version string) (ivm.ICode, error) {
+	g.clones++
+	if g.failing {
+		return ivm.ICode{}, errors.New("connection refused")
+	}
+
+	return ivm.NewICode("icode_"+commitHash, "icode", repositoryUrl, baseSavePath, commitHash, version), nil
+}
+
+// containerService answers a request with the id of the icode which executed it, the failing functions fail on this node
+type containerService struct {
+	running    map[ivm.ID]ivm.ICode
+	keepsState bool
+	failing    map[string]bool
+	executed   []string
+}
+
+func (c *containerService) KeepsStateByName() bool {
+	return c.keepsState
+}
+
+func (c *containerService) StartContainer(icode ivm.ICode) error {
+	c.running[icode.ID] = icode
+	return nil
+}
+
+func (c *containerService) StopContainer(id ivm.ID) error {
+	delete(c.running, id)
+	return nil
+}
+
+func (c *containerService) ExecuteRequest(request ivm.Request) (ivm.Result, error) {
+	if c.failing[request.Function] {
+		return ivm.Result{}, errors.New("container is not responding")
+	}
+
+	c.executed = append(c.executed, request.TxID)
+	return ivm.Result{Data: map[string]string{"ICodeID": request.ICodeID}}, nil
+}
+
+func (c *containerService) GetRunningICodeList() []ivm.ICode {
+	iCodeList := make([]ivm.ICode, 0)
+	for _, icode := range c.running {
+		iCodeList = append(iCodeList, icode)
+	}
+
+	return iCodeList
+}
+
+// eventService keeps the published execution results by transaction id
+type eventService struct {
+	results map[string]event.TxExecuted
+}
+
+func (e *eventService) Publish(topic string, published interface{}) error {
+	if txExecuted, ok := published.(event.TxExecuted); ok {
+		e.results[txExecuted.TransactionId] = txExecuted
+	}
+
+	return nil
+}
+
+func (e *eventService) Close() {}
+
+func setUpFake(failing bool, keepsState bool) (*adapter.BlockCommittedEventHandler, *gitService, *eventService, *clients) {
+	validatorSet := pbft.NewValidatorSet()
+	handler, _, gitService, eventService, clients := setUpFakeWithDeployers(failing, keepsState, ivm.NewDeployerSet([]string{"client01", "client02"}, &validatorSet))
+
+	return handler, gitService, eventService, clients
+}
+
+func setUpFakeWithDeployers(failing bool, keepsState bool, deployerSet *ivm.DeployerSet) (*adapter.BlockCommittedEventHandler, api.ICodeApi, *gitService, *eventService, *clients) {
+	gitService := &gitService{failing: failing}
+	containerService := &containerService{running: make(map[ivm.ID]ivm.ICode), keepsState: keepsState, failing: make(map[string]bool)}
+	eventService := &eventService{results: make(map[string]event.TxExecuted)}
+	executionService := ivm.NewExecutionService(containerService, 0, 0, ivm.NewCircuitBreaker(0, 0))
+	icodeApi := api.NewICodeApi(containerService, gitService, eventService, executionService, ivm.NewVersionRegistry(), deployerSet)
+	clients := &clients{ids: make(map[string]string)}
+
+	return adapter.NewBlockCommittedEventHandler(icodeApi, clients.verifier(), 0, 2), icodeApi, gitService, eventService, clients
+}
+
+func deployment(id string, function string, args ...string) event.Tx {
+	return event.Tx{ID: id, ICodeID: ivm.DeploymentICodeID, Function: function, Args: args}
+}
+
+func TestBlockCommittedEventHandler_DeploymentOrder(t *testing.T) {
+
+	//given
+	handler, _, eventService, clients := setUpFake(false, false)
+
+	testBlock := event.BlockCommitted{
+		Height: 1,
+		TxList: []event.Tx{
+			{ID: "tx01", ICodeID: "icode", Function: "invoke", Args: []string{}},
+			clients.sign(t, "client01", deployment("tx02", ivm.DeployFunction, "github.com/it-chain/icode", commitHash, "1.0")),
+			{ID: "tx03", ICodeID: "icode", Function: "invoke", Args: []string{}},
+		},
+	}
+
+	//when
+	handler.HandleBlockCommittedEventHandler(testBlock)
+
+	//then the transaction before the deployment does not see the icode
+	assert.Equal(t, ivm.ErrICodeNotDeployed.Error(), eventService.results["tx01"].Err)
+	assert.Equal(t, "icode_"+commitHash, eventService.results["tx02"].Data["ICodeID"])
+	assert.Equal(t, "", eventService.results["tx03"].Err)
+	assert.Equal(t, "icode_"+commitHash, eventService.results["tx03"].Data["ICodeID"])
+}
+
+func TestBlockCommittedEventHandler_FetchFailureHalts(t *testing.T) {
+
+	//given
+	handler, gitService, eventService, clients := setUpFake(true, false)
+
+	testBlock := event.BlockCommitted{
+		Height: 1,
+		TxList: []event.Tx{
+			clients.sign(t, "client01", deployment("tx01", ivm.DeployFunction, "github.com/it-chain/icode", commitHash, "1.0")),
+			{ID: "tx02", ICodeID: "icode", Function: "invoke", Args: []string{}},
+		},
+	}
+
+	//when
+	handler.HandleBlockCommittedEventHandler(testBlock)
+
+	//then the fetch is retried and no result is recorded
+	assert.Equal(t, 2, gitService.clones)
+	assert.Len(t, eventService.results, 0)
+
+	//when the code can be fetched again
+	gitService.failing = false
+	handler.HandleBlockCommittedEventHandler(event.BlockCommitted{
+		Height: 2,
+		TxList: []event.Tx{
+			{ID: "tx03", ICodeID: "icode", Function: "invoke", Args: []string{}},
+		},
+	})
+
+	//then the halted node queues the later block
+	assert.Len(t, eventService.results, 0)
+	assert.Equal(t, ivm.ExecutionStatus{Halted: true, Height: 1, Reason: "connection refused", Attempts: 1, QueuedBlocks: 1}, handler.Status())
+
+	//when
+	status := handler.Resume()
+
+	//then the halted block and the queued block are executed
+	assert.Equal(t, ivm.ExecutionStatus{}, status)
+	assert.Equal(t, "icode_"+commitHash, eventService.results["tx01"].Data["ICodeID"])
+	assert.Equal(t, "icode_"+commitHash, eventService.results["tx02"].Data["ICodeID"])
+	assert.Equal(t, "icode_"+commitHash, eventService.results["tx03"].Data["ICodeID"])
+}
+
+func TestBlockCommittedEventHandler_ResumeHaltedBlock(t *testing.T) {
+
+	//given
+	validatorSet := pbft.NewValidatorSet()
+	handler, icodeApi, _, eventService, _ := setUpFakeWithDeployers(false, false, ivm.NewDeployerSet([]string{}, &validatorSet))
+	icode := ivm.NewICode("icode_"+commitHash, "icode", "github.com/it-chain/icode", "", commitHash, "1.0")
+	assert.NoError(t, icodeApi.VersionRegistry.Add(ivm.NewICodeVersion(icode, 1, "", "client01")))
+	assert.NoError(t, icodeApi.ContainerService.StartContainer(icode))
+
+	containerService := icodeApi.ContainerService.(*containerService)
+	containerService.failing["slow"] = true
+
+	//when the second transaction can not be executed on this node
+	handler.HandleBlockCommittedEventHandler(event.BlockCommitted{
+		Height: 1,
+		TxList: []event.Tx{
+			{ID: "tx01", ICodeID: "icode", Function: "invoke", Args: []string{}},
+			{ID: "tx02", ICodeID: "icode", Function: "slow", Args: []string{}},
+			{ID: "tx03", ICodeID: "icode", Function: "invoke", Args: []string{}},
+		},
+	})
+
+	//then
+	assert.Equal(t, []string{"tx01"}, containerService.executed)
+	assert.Equal(t, ivm.ExecutionStatus{Halted: true, Height: 1, Reason: "container is not responding", Attempts: 1}, handler.Status())
+
+	//when it still fails
+	status := handler.Resume()
+
+	//then
+	assert.Equal(t, 2, status.Attempts)
+	assert.Equal(t, []string{"tx01"}, containerService.executed)
+
+	//when it can be executed again
+	containerService.failing["slow"] = false
+	status = handler.Resume()
+
+	//then the block resumes from the failed transaction
+	assert.Equal(t, ivm.ExecutionStatus{}, status)
+	assert.Equal(t, []string{"tx01", "tx02", "tx03"}, containerService.executed)
+	assert.Len(t, eventService.results, 3)
+}
+
+func TestBlockCommittedEventHandler_Ownership(t *testing.T) {
+
+	//given
+	handler, _, eventService, clients := setUpFake(false, true)
+
+	testBlock := event.BlockCommitted{
+		Height: 1,
+		TxList: []event.Tx{
+			deployment("tx01", ivm.DeployFunction, "github.com/it-chain/icode", commitHash, "1.0"),
+			clients.sign(t, "client01", deployment("tx02", ivm.DeployFunction, "github.com/it-chain/icode", commitHash, "1.0")),
+			clients.sign(t, "client02", deployment("tx03", ivm.DeployFunction, "github.com/attacker/icode", upgradeCommitHash, "2.0")),
+			clients.sign(t, "client01", deployment("tx04", ivm.DeployFunction, "github.com/it-chain/icode", upgradeCommitHash, "2.0")),
+			clients.sign(t, "client02", deployment("tx05", ivm.UpgradeFunction, "github.com/attacker/icode", upgradeCommitHash, "2.0", "10")),
+			clients.sign(t, "client01", deployment("tx06", ivm.UpgradeFunction, "github.com/it-chain/icode", upgradeCommitHash, "2.0", "10")),
+		},
+	}
+
+	//when
+	handler.HandleBlockCommittedEventHandler(testBlock)
+
+	//then
+	assert.Equal(t, ivm.ErrUnsignedDeployment.Error(), eventService.results["tx01"].Err)
+	assert.Equal(t, "", eventService.results["tx02"].Err)
+	assert.Equal(t, ivm.ErrNotICodeOwner.Error(), eventService.results["tx03"].Err)
+	assert.Equal(t, ivm.ErrICodeNameTaken.Error(), eventService.results["tx04"].Err)
+	assert.Equal(t, ivm.ErrNotICodeOwner.Error(), eventService.results["tx05"].Err)
+	assert.Equal(t, "", eventService.results["tx06"].Err)
+	assert.Equal(t, "icode_"+upgradeCommitHash, eventService.results["tx06"].Data["ICodeID"])
+}
+
+func TestBlockCommittedEventHandler_ValidatorDeployers(t *testing.T) {
+
+	//given no deployers are configured
+	validatorSet := pbft.NewValidatorSet()
+	handler, _, _, eventService, clients := setUpFakeWithDeployers(false, false, ivm.NewDeployerSet([]string{}, &validatorSet))
+
+	genesisBlock := event.BlockCommitted{
+		Height: 0,
+		TxList: []event.Tx{
+			{ID: "genesis-validator-client01", ICodeID: common.GovernanceICodeID, Function: common.AddValidatorFunction, Args: []string{"client01", "0"}},
+		},
+	}
+
+	testBlock := event.BlockCommitted{
+		Height: 1,
+		TxList: []event.Tx{
+			clients.sign(t, "client02", deployment("tx01", ivm.DeployFunction, "github.com/it-chain/icode", commitHash, "1.0")),
+			clients.sign(t, "client01", deployment("tx02", ivm.DeployFunction, "github.com/it-chain/icode", commitHash, "1.0")),
+		},
+	}
+
+	//when
+	handler.HandleBlockCommittedEventHandler(genesisBlock)
+	handler.HandleBlockCommittedEventHandler(testBlock)
+
+	//then only the validator deploys
+	assert.Equal(t, ivm.ErrNotDeployer.Error(), eventService.results["tx01"].Err)
+	assert.Equal(t, "", eventService.results["tx02"].Err)
+}
+
+func TestBlockCommittedEventHandler_LocalDeployment(t *testing.T) {
+
+	//given an icode deployed only on this node and a version deployed through a transaction
+	validatorSet := pbft.NewValidatorSet()
+	handler, icodeApi, _, eventService, clients := setUpFakeWithDeployers(false, false, ivm.NewDeployerSet([]string{"client01"}, &validatorSet))
+	assert.NoError(t, icodeApi.ContainerService.StartContainer(ivm.ICode{ID: "local"}))
+
+	testBlock := event.BlockCommitted{
+		Height: 1,
+		TxList: []event.Tx{
+			clients.sign(t, "client01", deployment("tx01", ivm.DeployFunction, "github.com/it-chain/icode", commitHash, "1.0")),
+			{ID: "tx02", ICodeID: "local", Function: "invoke", Args: []string{}},
+		},
+	}
+
+	//when
+	handler.HandleBlockCommittedEventHandler(testBlock)
+
+	//then the local icode is not seen by the block
+	assert.Equal(t, ivm.ErrICodeNotDeployed.Error(), eventService.results["tx02"].Err)
+
+	//then only the local icode is undeployed locally
+	assert.Equal(t, ivm.ErrDeployedByTransaction, icodeApi.UnDeploy("icode_"+commitHash))
+	assert.NoError(t, icodeApi.UnDeploy("local"))
+	assert.True(t, icodeApi.VersionRegistry.Exists("icode_"+commitHash))
+}
+
+func TestBlockCommittedEventHandler_RestartReplay(t *testing.T) {
+
+	//given a version deployed before the node restarts
+	validatorSet := pbft.NewValidatorSet()
+	handler, icodeApi, gitService, eventService, clients := setUpFakeWithDeployers(false, false, ivm.NewDeployerSet([]string{"client01"}, &validatorSet))
+
+	testBlock := event.BlockCommitted{
+		Height: 1,
+		TxList: []event.Tx{
+			clients.sign(t, "client01", deployment("tx01", ivm.DeployFunction, "github.com/it-chain/icode", commitHash, "1.0")),
+		},
+	}
+	handler.HandleBlockCommittedEventHandler(testBlock)
+	assert.NoError(t, icodeApi.ContainerService.StopContainer("icode_"+commitHash))
+
+	//when the node starts again
+	handler.RestoreVersions()
+
+	//then the version is fetched and started again
+	assert.Equal(t, 2, gitService.clones)
+	assert.Len(t, icodeApi.GetRunningICodeList(), 1)
+
+	//when the block is synced and executed again
+	delete(eventService.results, "tx01")
+	handler.HandleBlockCommittedEventHandler(testBlock)
+
+	//then the deployment keeps its version instead of failing on the taken name
+	assert.Equal(t, "", eventService.results["tx01"].Err)
+	assert.Equal(t, "icode_"+commitHash, eventService.results["tx01"].Data["ICodeID"])
+	assert.Len(t, icodeApi.GetVersions("icode"), 1)
+}
+
+func TestBlockCommittedEventHandler_UpgradeWithoutState(t *testing.T) {
+
+	//given
+	handler, _, eventService, clients := setUpFake(false, false)
+
+	testBlock := event.BlockCommitted{
+		Height: 1,
+		TxList: []event.Tx{
+			clients.sign(t, "client01", deployment("tx01", ivm.DeployFunction, "github.com/it-chain/icode", commitHash, "1.0")),
+			clients.sign(t, "client01", deployment("tx02", ivm.UpgradeFunction, "github.com/it-chain/icode", upgradeCommitHash, "2.0", "10")),
+		},
+	}
+
+	//when
+	handler.HandleBlockCommittedEventHandler(testBlock)
+
+	//then the runtime can not carry the state over
+	assert.Equal(t, "", eventService.results["tx01"].Err)
+	assert.Equal(t, ivm.ErrUpgradeUnsupported.Error(), eventService.results["tx02"].Err)
+}
+
 // setup handler and on container
 func setUp(t *testing.T) (*adapter.BlockCommittedEventHandler, *tesseract.ContainerService, func()) {
 	GOPATH := os.Getenv("GOPATH")
@@ -78,7 +456,10 @@ func setUp(t *testing.T) (*adapter.BlockCommittedEventHandler, *tesseract.Contai
 	storeApi := git.NewRepositoryService()
 	containerService := tesseract.NewContainerService()
 	eventService := common.NewEventService("", "Event")
-	icodeApi := api.NewICodeApi(containerService, storeApi, eventService)
+	executionService := ivm.NewExecutionService(containerService, 0, 0, ivm.NewCircuitBreaker(0, 0))
+	validatorSet := pbft.NewValidatorSet()
+	versionRegistry := ivm.NewVersionRegistry()
+	icodeApi := api.NewICodeApi(containerService, storeApi, eventService, executionService, versionRegistry, ivm.NewDeployerSet([]string{}, &validatorSet))
 
 	icode := ivm.ICode{
 		ID:             "1",
@@ -87,10 +468,16 @@ func setUp(t *testing.T) (*adapter.BlockCommittedEventHandler, *tesseract.Contai
 		GitUrl:         "github.com/mock",
 	}
 
+	// only the icodes deployed through transactions execute the committed blocks
+	assert.NoError(t, versionRegistry.Add(ivm.NewICodeVersion(icode, 0, "", "client01")))
+
 	err := containerService.StartContainer(icode)
 	assert.NoError(t, err)
 
-	blockCommittedEventHandler := adapter.NewBlockCommittedEventHandler(icodeApi)
+	verifier := common.NewECDSAVerifier(func(pubKey []byte) (string, error) {
+		return common.GetNodeIDFromPubKey(pubKey, "ECDSA256")
+	})
+	blockCommittedEventHandler := adapter.NewBlockCommittedEventHandler(icodeApi, verifier, 0, 1)
 
 	return blockCommittedEventHandler, containerService, func() {
 		containerService.StopContainer(icode.ID)
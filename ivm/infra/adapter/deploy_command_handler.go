@@ -28,6 +28,10 @@ import (
 	"github.com/it-chain/engine/ivm/api"
 )
 
+func icodeSavePath() string {
+	return os.Getenv("GOPATH") + "/src/github.com/it-chain/engine/.tmp/"
+}
+
 type DeployCommandHandler struct {
 	icodeApi api.ICodeApi
 }
@@ -39,7 +43,7 @@ func NewDeployCommandHandler(icodeApi api.ICodeApi) *DeployCommandHandler {
 }
 
 func (d *DeployCommandHandler) HandleDeployCommand(deployCommand command.Deploy) (ivm.ICode, rpc.Error) {
-	savePath := os.Getenv("GOPATH") + "/src/github.com/it-chain/engine/.tmp/"
+	savePath := icodeSavePath()
 
 	if deployCommand.SshPath != "" {
 		icode, err := d.icodeApi.Deploy(savePath, deployCommand.Url, deployCommand.SshPath, deployCommand.Password)
@@ -0,0 +1,41 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"github.com/it-chain/engine/common/command"
+	"github.com/it-chain/engine/common/rabbitmq/rpc"
+	"github.com/it-chain/engine/ivm"
+)
+
+type ExecutionCommandHandler struct {
+	blockCommittedEventHandler *BlockCommittedEventHandler
+}
+
+func NewExecutionCommandHandler(blockCommittedEventHandler *BlockCommittedEventHandler) *ExecutionCommandHandler {
+	return &ExecutionCommandHandler{
+		blockCommittedEventHandler: blockCommittedEventHandler,
+	}
+}
+
+func (e *ExecutionCommandHandler) HandleStatusCommand(getICodeExecutionStatusCommand command.GetICodeExecutionStatus) (ivm.ExecutionStatus, rpc.Error) {
+	return e.blockCommittedEventHandler.Status(), rpc.Error{}
+}
+
+func (e *ExecutionCommandHandler) HandleResumeCommand(resumeICodeExecutionCommand command.ResumeICodeExecution) (ivm.ExecutionStatus, rpc.Error) {
+	return e.blockCommittedEventHandler.Resume(), rpc.Error{}
+}
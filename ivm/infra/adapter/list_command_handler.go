@@ -37,3 +37,9 @@ func (l *ListCommandHandler) HandleListCommand(getICodeListCommand command.GetIC
 
 	return command.ICodeList{ICodes: iCodes}, rpc.Error{}
 }
+
+func (l *ListCommandHandler) HandleVersionsCommand(getICodeVersionsCommand command.GetICodeVersions) (command.ICodeVersionList, rpc.Error) {
+	versions := l.icodeApi.GetVersions(getICodeVersionsCommand.Name)
+
+	return command.ICodeVersionList{Versions: versions}, rpc.Error{}
+}
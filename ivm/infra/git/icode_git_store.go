@@ -17,6 +17,7 @@
 package git
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -131,6 +132,58 @@ func (gApi *RepositoryService) CloneFromRawSsh(baseSavePath string, repositoryUr
 	return metaData, nil
 }
 
+// CloneCommit checks out the commit into a directory of its own, so the checkouts of other commits are kept.
+// Only public repositories are supported, the nodes deploying the icode do not share an ssh key
+func (gApi *RepositoryService) CloneCommit(ctx context.Context, baseSavePath string, repositoryUrl string, commitHash string, version string) (ivm.ICode, error) {
+	iLogger.Info(nil, fmt.Sprintf("[IVM] Cloning Icode - url: [%s], commit: [%s]", repositoryUrl, commitHash))
+
+	gitUrl, err := toGitUrl(repositoryUrl, "https")
+	if err != nil {
+		return ivm.ICode{}, err
+	}
+
+	name := getNameFromGitUrl(gitUrl)
+
+	if name == "" {
+		return ivm.ICode{}, errors.New(fmt.Sprintf("Invalid url name [%s]", repositoryUrl))
+	}
+
+	id := name + "_" + commitHash
+	path := baseSavePath + "/" + id
+
+	if _, err := os.Stat(path); err == nil {
+		if err = os.RemoveAll(path); err != nil {
+			return ivm.ICode{}, err
+		}
+	}
+
+	r, err := git.PlainCloneContext(ctx, path, false, &git.CloneOptions{
+		URL:               gitUrl,
+		RecurseSubmodules: git.DefaultSubmoduleRecursionDepth,
+	})
+
+	if err != nil {
+		return ivm.ICode{}, err
+	}
+
+	w, err := r.Worktree()
+	if err != nil {
+		return ivm.ICode{}, err
+	}
+
+	if err := w.Checkout(&git.CheckoutOptions{Hash: plumbing.NewHash(commitHash)}); err != nil {
+		return ivm.ICode{}, err
+	}
+
+	if version == "" {
+		version = defaultVersion
+	}
+
+	iLogger.Info(nil, fmt.Sprintf("[IVM] ICode has successfully cloned - url: [%s], icodeID: [%s], version[%s]", repositoryUrl, id, version))
+
+	return ivm.NewICode(id, name, repositoryUrl, path, commitHash, version), nil
+}
+
 func (gApi *RepositoryService) Clone(baseSavePath string, repositoryUrl string, sshPath string, password string) (ivm.ICode, error) {
 
 	if sshPath == "" {
@@ -0,0 +1,334 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package native
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/it-chain/engine/ivm"
+	"github.com/it-chain/iLogger"
+	"github.com/it-chain/leveldb-wrapper"
+	"github.com/it-chain/sdk"
+	"github.com/it-chain/sdk/pb"
+	"github.com/rs/xid"
+)
+
+var ErrContainerDoesNotExist = errors.New("container does not exist")
+var ErrICodeInfoMapNotEmpty = errors.New("ICode info struct in current container is not empty")
+var ErrHandlerDoesNotExist = errors.New("no handler is registered for the icode")
+var ErrEmptyResponse = errors.New("icode returned no response")
+var ErrRequestCancelled = errors.New("icode request is cancelled")
+var ErrRequestNotRunning = errors.New("no invoke of the icode is running")
+
+type ICodeInfo struct {
+	handler        sdk.TransactionHandler
+	state          *leveldbwrapper.DBHandle
+	stateNamespace string
+	iCode          ivm.ICode
+}
+
+// stateBatch keeps the writes of an invoke apart from the state of the icode.
+// The invoke runs on a copy of the state, the changed keys are written to the state when it finishes
+// unless it was cancelled in the meantime
+type stateBatch struct {
+	iCodeID   ivm.ID
+	namespace string
+	handle    *leveldbwrapper.DBHandle
+	base      map[string][]byte
+	cancelled bool
+}
+
+// ContainerService runs icodes inside the engine process instead of docker containers.
+// The handlers are compiled into the engine, an icode runs the handler whose name is the repository name of the icode.
+// The state of each icode is kept in a key/value store managed by the engine
+type ContainerService struct {
+	sync.RWMutex
+	handlers     map[string]sdk.TransactionHandler
+	iCodeInfoMap map[ivm.ID]ICodeInfo
+	dbProvider   *leveldbwrapper.DBProvider
+	// running invokes by the namespace of their batch
+	batches map[string]*stateBatch
+}
+
+func NewContainerService(statePath string, handlers ...sdk.TransactionHandler) *ContainerService {
+	handlerMap := make(map[string]sdk.TransactionHandler)
+	for _, handler := range handlers {
+		handlerMap[handler.Name()] = handler
+	}
+
+	return &ContainerService{
+		handlers:     handlerMap,
+		iCodeInfoMap: make(map[ivm.ID]ICodeInfo),
+		dbProvider:   leveldbwrapper.CreateNewDBProvider(statePath),
+		batches:      make(map[string]*stateBatch),
+		RWMutex:      sync.RWMutex{},
+	}
+}
+
+func (cs *ContainerService) StartContainer(icode ivm.ICode) error {
+	iLogger.Info(nil, fmt.Sprintf("[IVM] Starting in-process icode - icodeID: [%s]", icode.ID))
+	cs.Lock()
+	defer cs.Unlock()
+
+	handler, ok := cs.handlers[icode.RepositoryName]
+	if !ok {
+		return ErrHandlerDoesNotExist
+	}
+
+	if _, ok := cs.iCodeInfoMap[icode.ID]; ok {
+		return ErrICodeInfoMapNotEmpty
+	}
+
+	cs.iCodeInfoMap[icode.ID] = ICodeInfo{
+		handler:        handler,
+		state:          cs.dbProvider.GetDBHandle(icode.StateNamespace()),
+		stateNamespace: icode.StateNamespace(),
+		iCode:          icode,
+	}
+
+	return nil
+}
+
+// ExecuteRequest runs a query on the state of the icode and an invoke on a state batch,
+// so the writes of an invoke cancelled by CancelRequest are discarded
+func (cs *ContainerService) ExecuteRequest(request ivm.Request) (ivm.Result, error) {
+	iLogger.Info(nil, fmt.Sprintf("[IVM] Executing icode - icodeID: [%s]", request.ICodeID))
+
+	cs.RLock()
+	iCodeInfo, ok := cs.iCodeInfoMap[request.ICodeID]
+	cs.RUnlock()
+
+	if !ok {
+		return ivm.Result{}, ErrContainerDoesNotExist
+	}
+
+	pbRequest := &pb.Request{
+		Uuid:         xid.New().String(),
+		Type:         request.Type,
+		FunctionName: request.Function,
+		Args:         request.Args,
+	}
+
+	if request.Type == "query" {
+		return toResult(iCodeInfo.handler.Handle(pbRequest, &sdk.Cell{DBHandler: iCodeInfo.state}))
+	}
+
+	batch, err := cs.beginBatch(request.ICodeID, iCodeInfo)
+	if err != nil {
+		return ivm.Result{}, err
+	}
+	defer cs.endBatch(batch)
+
+	response := iCodeInfo.handler.Handle(pbRequest, &sdk.Cell{DBHandler: batch.handle})
+	if response == nil {
+		return ivm.Result{}, ErrEmptyResponse
+	}
+
+	if err := cs.commitBatch(batch, iCodeInfo.state); err != nil {
+		return ivm.Result{}, err
+	}
+
+	return toResult(response)
+}
+
+func toResult(response *pb.Response) (ivm.Result, error) {
+	if response == nil {
+		return ivm.Result{}, ErrEmptyResponse
+	}
+
+	data := make(map[string]string)
+	if len(response.Data) != 0 {
+		if err := json.Unmarshal(response.Data, &data); err != nil {
+			return ivm.Result{}, err
+		}
+	}
+
+	return ivm.Result{
+		Err:  response.Error,
+		Data: data,
+	}, nil
+}
+
+// CancelRequest discards the writes of the running invokes of the icode, they are not applied when the invokes finish
+func (cs *ContainerService) CancelRequest(id ivm.ID) error {
+	cs.Lock()
+	defer cs.Unlock()
+
+	cancelled := false
+	for _, batch := range cs.batches {
+		if batch.iCodeID == id && !batch.cancelled {
+			batch.cancelled = true
+			cancelled = true
+		}
+	}
+
+	if !cancelled {
+		return ErrRequestNotRunning
+	}
+
+	iLogger.Error(nil, fmt.Sprintf("[IVM] Cancelling icode request - icodeID: [%s]", id))
+	return nil
+}
+
+// beginBatch copies the state of the icode to a namespace which no running invoke uses.
+// The namespaces are reused, so the keys left in one by a crashed node are deleted first
+func (cs *ContainerService) beginBatch(id ivm.ID, iCodeInfo ICodeInfo) (*stateBatch, error) {
+	cs.Lock()
+	defer cs.Unlock()
+
+	namespace := ""
+	for n := 0; ; n++ {
+		namespace = fmt.Sprintf("pending/%s/%d", id, n)
+		if _, ok := cs.batches[namespace]; !ok {
+			break
+		}
+	}
+
+	batch := &stateBatch{
+		iCodeID:   id,
+		namespace: namespace,
+		handle:    cs.dbProvider.GetDBHandle(namespace),
+	}
+
+	base, err := readAll(iCodeInfo.state, iCodeInfo.stateNamespace)
+	if err != nil {
+		return nil, err
+	}
+	batch.base = base
+
+	left, err := readAll(batch.handle, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	kvs := make(map[string][]byte)
+	for key := range left {
+		kvs[key] = nil
+	}
+	for key, value := range base {
+		kvs[key] = value
+	}
+
+	if err := batch.handle.WriteBatch(kvs, false); err != nil {
+		return nil, err
+	}
+
+	cs.batches[namespace] = batch
+	return batch, nil
+}
+
+// commitBatch writes the keys changed by the invoke to the state, unless the invoke is cancelled
+func (cs *ContainerService) commitBatch(batch *stateBatch, state *leveldbwrapper.DBHandle) error {
+	cs.Lock()
+	defer cs.Unlock()
+
+	if batch.cancelled {
+		return ErrRequestCancelled
+	}
+
+	written, err := readAll(batch.handle, batch.namespace)
+	if err != nil {
+		return err
+	}
+
+	changes := make(map[string][]byte)
+	for key, value := range written {
+		if !bytes.Equal(batch.base[key], value) {
+			changes[key] = value
+		}
+	}
+
+	if len(changes) == 0 {
+		return nil
+	}
+
+	return state.WriteBatch(changes, true)
+}
+
+// endBatch empties the namespace of the batch so that another invoke can use it
+func (cs *ContainerService) endBatch(batch *stateBatch) {
+	cs.Lock()
+	defer cs.Unlock()
+
+	delete(cs.batches, batch.namespace)
+
+	written, err := readAll(batch.handle, batch.namespace)
+	if err == nil {
+		kvs := make(map[string][]byte)
+		for key := range written {
+			kvs[key] = nil
+		}
+		err = batch.handle.WriteBatch(kvs, false)
+	}
+
+	if err != nil {
+		iLogger.Error(nil, fmt.Sprintf("[IVM] Fail to clear icode state batch - icodeID: [%s], message: [%s]", batch.iCodeID, err.Error()))
+	}
+}
+
+// readAll returns the keys and values kept in the namespace of the handle
+func readAll(handle *leveldbwrapper.DBHandle, namespace string) (map[string][]byte, error) {
+	iter := handle.GetIteratorWithPrefix()
+	defer iter.Release()
+
+	prefix := namespace + "_"
+	kvs := make(map[string][]byte)
+	for iter.Next() {
+		key := string(iter.Key())
+		kvs[key[len(prefix):]] = append([]byte{}, iter.Value()...)
+	}
+
+	return kvs, iter.Error()
+}
+
+// KeepsStateByName is true, the state of an icode is kept in the namespace of its name
+func (cs *ContainerService) KeepsStateByName() bool {
+	return true
+}
+
+// StopContainer keeps the state of the icode, it is found again by the icodes deployed with the same name
+func (cs *ContainerService) StopContainer(id ivm.ID) error {
+	cs.Lock()
+	defer cs.Unlock()
+
+	if _, ok := cs.iCodeInfoMap[id]; !ok {
+		return ErrContainerDoesNotExist
+	}
+
+	delete(cs.iCodeInfoMap, id)
+	return nil
+}
+
+func (cs *ContainerService) GetRunningICodeList() []ivm.ICode {
+	cs.RLock()
+	defer cs.RUnlock()
+
+	iCodeList := make([]ivm.ICode, 0)
+	for _, iCodeInfo := range cs.iCodeInfoMap {
+		iCodeList = append(iCodeList, iCodeInfo.iCode)
+	}
+
+	return iCodeList
+}
+
+func (cs *ContainerService) Close() {
+	cs.dbProvider.Close()
+}
@@ -0,0 +1,187 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package native_test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/it-chain/engine/ivm"
+	"github.com/it-chain/engine/ivm/infra/native"
+	"github.com/it-chain/sdk"
+	"github.com/it-chain/sdk/pb"
+	"github.com/stretchr/testify/assert"
+)
+
+type counterHandler struct{}
+
+func (counterHandler) Name() string {
+	return "counter"
+}
+
+func (counterHandler) Versions() []string {
+	return []string{"1.0"}
+}
+
+func (counterHandler) Handle(request *pb.Request, cell *sdk.Cell) *pb.Response {
+	switch request.FunctionName {
+	case "inc":
+		data, err := cell.GetData("count")
+		if err != nil {
+			return &pb.Response{Uuid: request.Uuid, Error: err.Error()}
+		}
+
+		count, _ := strconv.Atoi(string(data))
+		if err := cell.PutData("count", []byte(strconv.Itoa(count+1))); err != nil {
+			return &pb.Response{Uuid: request.Uuid, Error: err.Error()}
+		}
+
+		return &pb.Response{Uuid: request.Uuid}
+	case "get":
+		data, err := cell.GetData("count")
+		if err != nil {
+			return &pb.Response{Uuid: request.Uuid, Error: err.Error()}
+		}
+
+		result, _ := json.Marshal(map[string]string{"count": string(data)})
+		return &pb.Response{Uuid: request.Uuid, Data: result}
+	default:
+		return &pb.Response{Uuid: request.Uuid, Error: errors.New("unknown function").Error()}
+	}
+}
+
+func TestContainerService_ExecuteRequest(t *testing.T) {
+
+	// given
+	statePath := "./.state"
+	containerService := native.NewContainerService(statePath, counterHandler{})
+	defer func() {
+		containerService.Close()
+		os.RemoveAll(statePath)
+	}()
+
+	icode := ivm.ICode{ID: "icode01", RepositoryName: "counter"}
+	assert.NoError(t, containerService.StartContainer(icode))
+	assert.Equal(t, native.ErrICodeInfoMapNotEmpty, containerService.StartContainer(icode))
+	assert.Equal(t, native.ErrHandlerDoesNotExist, containerService.StartContainer(ivm.ICode{ID: "icode02", RepositoryName: "unknown"}))
+
+	// when
+	for i := 0; i < 3; i++ {
+		result, err := containerService.ExecuteRequest(ivm.Request{ICodeID: "icode01", Function: "inc", Type: "invoke"})
+		assert.NoError(t, err)
+		assert.Equal(t, "", result.Err)
+	}
+	result, err := containerService.ExecuteRequest(ivm.Request{ICodeID: "icode01", Function: "get", Type: "query"})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"count": "3"}, result.Data)
+
+	// when
+	result, err = containerService.ExecuteRequest(ivm.Request{ICodeID: "icode01", Function: "dec", Type: "invoke"})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, "unknown function", result.Err)
+
+	// the state is kept after the icode is stopped
+	assert.NoError(t, containerService.StopContainer("icode01"))
+	assert.Len(t, containerService.GetRunningICodeList(), 0)
+
+	_, err = containerService.ExecuteRequest(ivm.Request{ICodeID: "icode01", Function: "get", Type: "query"})
+	assert.Equal(t, native.ErrContainerDoesNotExist, err)
+
+	assert.NoError(t, containerService.StartContainer(icode))
+	result, err = containerService.ExecuteRequest(ivm.Request{ICodeID: "icode01", Function: "get", Type: "query"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"count": "3"}, result.Data)
+
+	// another version of the icode shares the state
+	assert.NoError(t, containerService.StartContainer(ivm.ICode{ID: "icode03", RepositoryName: "counter"}))
+	result, err = containerService.ExecuteRequest(ivm.Request{ICodeID: "icode03", Function: "get", Type: "query"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"count": "3"}, result.Data)
+}
+
+// blockingHandler increases the count and waits for release before it returns
+type blockingHandler struct {
+	counterHandler
+	started chan struct{}
+	release chan struct{}
+}
+
+func (h blockingHandler) Handle(request *pb.Request, cell *sdk.Cell) *pb.Response {
+	response := h.counterHandler.Handle(request, cell)
+	if request.FunctionName == "inc" {
+		h.started <- struct{}{}
+		<-h.release
+	}
+
+	return response
+}
+
+func TestContainerService_CancelRequest(t *testing.T) {
+
+	// given
+	statePath := "./.state"
+	handler := blockingHandler{started: make(chan struct{}), release: make(chan struct{})}
+	containerService := native.NewContainerService(statePath, handler)
+	defer func() {
+		containerService.Close()
+		os.RemoveAll(statePath)
+	}()
+
+	assert.NoError(t, containerService.StartContainer(ivm.ICode{ID: "icode01", RepositoryName: "counter"}))
+	assert.Equal(t, native.ErrRequestNotRunning, containerService.CancelRequest("icode01"))
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := containerService.ExecuteRequest(ivm.Request{ICodeID: "icode01", Function: "inc", Type: "invoke"})
+		errCh <- err
+	}()
+	<-handler.started
+
+	// when
+	err := containerService.CancelRequest("icode01")
+	handler.release <- struct{}{}
+
+	// then the write of the cancelled invoke is discarded
+	assert.NoError(t, err)
+	assert.Equal(t, native.ErrRequestCancelled, <-errCh)
+
+	result, err := containerService.ExecuteRequest(ivm.Request{ICodeID: "icode01", Function: "get", Type: "query"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"count": ""}, result.Data)
+
+	// when the next invoke is not cancelled
+	go func() {
+		_, err := containerService.ExecuteRequest(ivm.Request{ICodeID: "icode01", Function: "inc", Type: "invoke"})
+		errCh <- err
+	}()
+	<-handler.started
+	handler.release <- struct{}{}
+
+	// then
+	assert.NoError(t, <-errCh)
+
+	result, err = containerService.ExecuteRequest(ivm.Request{ICodeID: "icode01", Function: "get", Type: "query"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"count": "1"}, result.Data)
+}
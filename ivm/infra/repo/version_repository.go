@@ -0,0 +1,76 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package repo
+
+import (
+	"encoding/json"
+
+	"github.com/it-chain/engine/ivm"
+	"github.com/it-chain/leveldb-wrapper"
+)
+
+var versionStateKey = []byte("versions")
+
+// VersionRepository keeps the state of the version registry on disk
+type VersionRepository struct {
+	leveldb *leveldbwrapper.DB
+}
+
+func NewVersionRepository(path string) *VersionRepository {
+	db := leveldbwrapper.CreateNewDB(path)
+	db.Open()
+
+	return &VersionRepository{
+		leveldb: db,
+	}
+}
+
+func (r *VersionRepository) Save(state ivm.VersionState) error {
+	b, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	return r.leveldb.Put(versionStateKey, b, true)
+}
+
+// Load returns an empty state when nothing is saved
+func (r *VersionRepository) Load() (ivm.VersionState, error) {
+	state := ivm.VersionState{
+		Versions: make(map[string][]ivm.ICodeVersion),
+		Owners:   make(map[string]string),
+	}
+
+	b, err := r.leveldb.Get(versionStateKey)
+	if err != nil {
+		return ivm.VersionState{}, err
+	}
+
+	if len(b) == 0 {
+		return state, nil
+	}
+
+	if err := json.Unmarshal(b, &state); err != nil {
+		return ivm.VersionState{}, err
+	}
+
+	return state, nil
+}
+
+func (r *VersionRepository) Close() {
+	r.leveldb.Close()
+}
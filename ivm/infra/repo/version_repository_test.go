@@ -0,0 +1,61 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package repo_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/it-chain/engine/ivm"
+	"github.com/it-chain/engine/ivm/infra/repo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersionRepository_AfterRestart(t *testing.T) {
+
+	//given
+	dbPath := "./.version-db"
+	defer os.RemoveAll(dbPath)
+
+	versionRepository := repo.NewVersionRepository(dbPath)
+	registry, err := ivm.NewPersistentVersionRegistry(versionRepository)
+	assert.NoError(t, err)
+
+	registry.Activate(3)
+	assert.NoError(t, registry.Add(ivm.NewICodeVersion(ivm.ICode{ID: "counter_1", RepositoryName: "counter", Version: "1.0"}, 3, "", "client01")))
+	assert.NoError(t, registry.Add(ivm.NewICodeVersion(ivm.ICode{ID: "counter_2", RepositoryName: "counter", Version: "2.0"}, 10, "migrate", "client01")))
+	versionRepository.Close()
+
+	//when
+	versionRepository = repo.NewVersionRepository(dbPath)
+	defer versionRepository.Close()
+
+	registry, err = ivm.NewPersistentVersionRegistry(versionRepository)
+	assert.NoError(t, err)
+
+	//then
+	assert.True(t, registry.Exists("counter_2"))
+	assert.Equal(t, "counter_1", registry.Resolve("counter"))
+	assert.Len(t, registry.All(), 2)
+
+	owner, ok := registry.Owner("counter")
+	assert.True(t, ok)
+	assert.Equal(t, "client01", owner)
+
+	// the committed height is restored, so the upgrade is activated at its height
+	assert.Len(t, registry.Activate(10), 1)
+}
@@ -33,10 +33,14 @@ import (
 
 var ErrContainerDoesNotExist = errors.New("container does not exist")
 var ErrICodeInfoMapNotEmpty = errors.New("ICode info struct in current container is not empty")
+var ErrEmptyResponse = errors.New("icode returned no response")
+var ErrRequestCancelled = errors.New("icode request is cancelled")
 
 type ICodeInfo struct {
 	container tesseract.Container
 	iCode     ivm.ICode
+	// closed when the container is closed by CancelRequest
+	cancelled chan struct{}
 }
 
 type ContainerService struct {
@@ -73,6 +77,7 @@ func (cs ContainerService) StartContainer(icode ivm.ICode) error {
 		iCodeInfo := ICodeInfo{
 			container: container,
 			iCode:     icode,
+			cancelled: make(chan struct{}),
 		}
 
 		cs.iCodeInfoMap[icode.ID] = iCodeInfo
@@ -91,12 +96,19 @@ func (cs ContainerService) ExecuteRequest(request ivm.Request) (ivm.Result, erro
 		return ivm.Result{}, ErrContainerDoesNotExist
 	}
 
-	resultCh := make(chan ivm.Result)
-	errCh := make(chan error)
+	// buffered, so a callback arriving after the execution service gave up does not block
+	resultCh := make(chan ivm.Result, 1)
+	errCh := make(chan error, 1)
 
 	var callback = func(response *pb.Response, err error) {
 		if err != nil {
 			errCh <- err
+			return
+		}
+
+		if response == nil {
+			errCh <- ErrEmptyResponse
+			return
 		}
 
 		data := make(map[string]string)
@@ -121,7 +133,8 @@ func (cs ContainerService) ExecuteRequest(request ivm.Request) (ivm.Result, erro
 	}, callback)
 
 	if err != nil {
-		errCh <- err
+		iLogger.Error(nil, fmt.Sprintf("[IVM] fail executing ivm, id:%s", request.ICodeID))
+		return ivm.Result{}, err
 	}
 
 	select {
@@ -130,7 +143,27 @@ func (cs ContainerService) ExecuteRequest(request ivm.Request) (ivm.Result, erro
 		return ivm.Result{}, err
 	case result := <-resultCh:
 		return result, nil
+	case <-iCodeInfo.cancelled:
+		return ivm.Result{}, ErrRequestCancelled
+	}
+}
+
+// CancelRequest aborts the running request of the icode by closing its container together with the writes in it.
+// The icode does not run on this node until it is deployed again
+func (cs ContainerService) CancelRequest(id ivm.ID) error {
+	cs.Lock()
+	defer cs.Unlock()
+
+	iCodeInfo, ok := cs.iCodeInfoMap[id]
+	if !ok {
+		return ErrContainerDoesNotExist
 	}
+
+	iLogger.Error(nil, fmt.Sprintf("[IVM] Cancelling icode request - icodeID: [%s]", id))
+	close(iCodeInfo.cancelled)
+	delete(cs.iCodeInfoMap, id)
+
+	return iCodeInfo.container.Close()
 }
 
 func (cs ContainerService) StopContainer(id ivm.ID) error {
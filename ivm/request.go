@@ -21,6 +21,10 @@ type Request struct {
 	Function string
 	Args     []string
 	Type     string
+	// set when the request comes from a committed transaction
+	TxID string
+	// the verified signer of a committed deployment transaction, empty when it is not signed
+	Signer string
 }
 
 type Invoke struct {
@@ -16,6 +16,8 @@
 
 package ivm
 
+import "context"
+
 type ContainerService interface {
 	StartContainer(icode ICode) error
 	StopContainer(id ID) error
@@ -23,10 +25,18 @@ type ContainerService interface {
 	GetRunningICodeList() []ICode
 }
 
+// StateKeeper is implemented by the container services which keep the state of an icode by its name outside of the icode,
+// so a new version of the icode continues with the state of the old ones. Icodes can be upgraded only on them
+type StateKeeper interface {
+	KeepsStateByName() bool
+}
+
 type GitService interface {
 	//clone code from deploy info
 	Clone(baseSavePath string, repositoryUrl string, sshPath string, password string) (ICode, error)
 	CloneFromRawSsh(baseSavePath string, repositoryUrl string, rawSsh []byte, password string) (ICode, error)
+	//clone code at the commit, the icode ID does not depend on the node. The clone is given up when the context is done
+	CloneCommit(ctx context.Context, baseSavePath string, repositoryUrl string, commitHash string, version string) (ICode, error)
 }
 
 type EventService interface {
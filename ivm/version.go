@@ -0,0 +1,292 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ivm
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/it-chain/iLogger"
+)
+
+var ErrUnknownICodeName = errors.New("no icode is deployed with the name")
+var ErrInvalidActivationHeight = errors.New("activation height should be greater than the heights of the other versions")
+var ErrICodeNameTaken = errors.New("an icode is already deployed with the name, upgrade it instead")
+var ErrNotICodeOwner = errors.New("only the owner of the icode name can deploy its versions")
+var ErrDeployedByTransaction = errors.New("icode is deployed through a transaction and can not be undeployed locally")
+
+// ICodeVersion is a deployment of an icode under its name.
+// The version with the greatest activation height not above the committed height is the active one,
+// transactions to the name are executed by it. Owner is the signer of the deployment transaction
+type ICodeVersion struct {
+	ICodeID           ID
+	Name              string
+	Version           Version
+	GitUrl            string
+	CommitHash        string
+	ActivationHeight  uint64
+	MigrationFunction string
+	Owner             string
+	Active            bool
+}
+
+func NewICodeVersion(icode ICode, activationHeight uint64, migrationFunction string, owner string) ICodeVersion {
+	return ICodeVersion{
+		ICodeID:           icode.ID,
+		Name:              icode.RepositoryName,
+		Version:           icode.Version,
+		GitUrl:            icode.GitUrl,
+		CommitHash:        icode.CommitHash,
+		ActivationHeight:  activationHeight,
+		MigrationFunction: migrationFunction,
+		Owner:             owner,
+	}
+}
+
+// VersionState is the content of a VersionRegistry kept by a VersionRepository
+type VersionState struct {
+	Versions map[string][]ICodeVersion
+	Owners   map[string]string
+	Height   uint64
+}
+
+// VersionRepository keeps the state of the version registry across restarts
+type VersionRepository interface {
+	Save(state VersionState) error
+	Load() (VersionState, error)
+}
+
+// VersionRegistry keeps the versions of the icodes deployed through transactions and the committed height.
+// The signer of the first version of a name owns it, the other versions of the name have to be deployed by the owner.
+// With a repository every change is saved, so the versions are restored when the node restarts
+type VersionRegistry struct {
+	sync.RWMutex
+	versions map[string][]ICodeVersion
+	// kept when the versions of the name are removed, so the name can not be taken by another signer
+	owners     map[string]string
+	height     uint64
+	repository VersionRepository
+}
+
+func NewVersionRegistry() *VersionRegistry {
+	return &VersionRegistry{
+		versions: make(map[string][]ICodeVersion),
+		owners:   make(map[string]string),
+		RWMutex:  sync.RWMutex{},
+	}
+}
+
+// NewPersistentVersionRegistry restores the versions saved in the repository and saves the changes to it
+func NewPersistentVersionRegistry(repository VersionRepository) (*VersionRegistry, error) {
+	state, err := repository.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	registry := NewVersionRegistry()
+	registry.repository = repository
+	registry.height = state.Height
+
+	for name, versions := range state.Versions {
+		registry.versions[name] = versions
+	}
+
+	for name, owner := range state.Owners {
+		registry.owners[name] = owner
+	}
+
+	return registry, nil
+}
+
+// Add registers the version, the versions of a name are kept in the order of their activation heights
+func (r *VersionRegistry) Add(version ICodeVersion) error {
+	r.Lock()
+	defer r.Unlock()
+
+	if owner, ok := r.owners[version.Name]; ok && owner != version.Owner {
+		return ErrNotICodeOwner
+	}
+
+	versions := r.versions[version.Name]
+	if len(versions) != 0 && versions[len(versions)-1].ActivationHeight >= version.ActivationHeight {
+		return ErrInvalidActivationHeight
+	}
+
+	r.versions[version.Name] = append(versions, version)
+	r.owners[version.Name] = version.Owner
+	r.persist()
+
+	return nil
+}
+
+// Applied tells whether the version is already registered, as when the deployment transaction of the version is executed again
+func (r *VersionRegistry) Applied(version ICodeVersion) bool {
+	r.RLock()
+	defer r.RUnlock()
+
+	for _, registered := range r.versions[version.Name] {
+		if registered.ICodeID == version.ICodeID && registered.ActivationHeight == version.ActivationHeight && registered.Owner == version.Owner {
+			return true
+		}
+	}
+
+	return false
+}
+
+// All returns the versions of every name
+func (r *VersionRegistry) All() []ICodeVersion {
+	r.RLock()
+	defer r.RUnlock()
+
+	all := make([]ICodeVersion, 0)
+	for _, versions := range r.versions {
+		all = append(all, versions...)
+	}
+
+	return all
+}
+
+// persist saves the registry to the repository, it is called with the lock held.
+// A failure only affects this node after a restart, so it is logged instead of failing the deployment on this node alone
+func (r *VersionRegistry) persist() {
+	if r.repository == nil {
+		return
+	}
+
+	err := r.repository.Save(VersionState{
+		Versions: r.versions,
+		Owners:   r.owners,
+		Height:   r.height,
+	})
+
+	if err != nil {
+		iLogger.Error(nil, fmt.Sprintf("[IVM] Fail to save icode versions - message: [%s]", err.Error()))
+	}
+}
+
+// Owner returns the signer owning the name, the name is owned from the deployment of its first version
+func (r *VersionRegistry) Owner(name string) (string, bool) {
+	r.RLock()
+	defer r.RUnlock()
+
+	owner, ok := r.owners[name]
+	return owner, ok
+}
+
+func (r *VersionRegistry) Remove(id ID) {
+	r.Lock()
+	defer r.Unlock()
+
+	for name, versions := range r.versions {
+		for index, version := range versions {
+			if version.ICodeID != id {
+				continue
+			}
+
+			versions = append(versions[:index:index], versions[index+1:]...)
+			if len(versions) == 0 {
+				delete(r.versions, name)
+			} else {
+				r.versions[name] = versions
+			}
+
+			r.persist()
+			return
+		}
+	}
+}
+
+func (r *VersionRegistry) HasName(name string) bool {
+	r.RLock()
+	defer r.RUnlock()
+
+	_, ok := r.versions[name]
+	return ok
+}
+
+func (r *VersionRegistry) Exists(id ID) bool {
+	r.RLock()
+	defer r.RUnlock()
+
+	for _, versions := range r.versions {
+		for _, version := range versions {
+			if version.ICodeID == id {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Activate moves the committed height and returns the versions which become active with it
+func (r *VersionRegistry) Activate(height uint64) []ICodeVersion {
+	r.Lock()
+	defer r.Unlock()
+
+	activated := make([]ICodeVersion, 0)
+	for _, versions := range r.versions {
+		for _, version := range versions {
+			if version.ActivationHeight > r.height && version.ActivationHeight <= height {
+				activated = append(activated, version)
+			}
+		}
+	}
+
+	r.height = height
+	r.persist()
+
+	return activated
+}
+
+// Resolve returns the id of the active version when the icode id is a name, other ids are returned as they are
+func (r *VersionRegistry) Resolve(icodeId string) ID {
+	r.RLock()
+	defer r.RUnlock()
+
+	if index := r.activeIndex(icodeId); index >= 0 {
+		return r.versions[icodeId][index].ICodeID
+	}
+
+	return icodeId
+}
+
+func (r *VersionRegistry) Versions(name string) []ICodeVersion {
+	r.RLock()
+	defer r.RUnlock()
+
+	active := r.activeIndex(name)
+	versions := make([]ICodeVersion, 0, len(r.versions[name]))
+	for index, version := range r.versions[name] {
+		version.Active = index == active
+		versions = append(versions, version)
+	}
+
+	return versions
+}
+
+func (r *VersionRegistry) activeIndex(name string) int {
+	active := -1
+	for index, version := range r.versions[name] {
+		if version.ActivationHeight <= r.height {
+			active = index
+		}
+	}
+
+	return active
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ivm_test
+
+import (
+	"testing"
+
+	"github.com/it-chain/engine/ivm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersionRegistry(t *testing.T) {
+
+	//given
+	registry := ivm.NewVersionRegistry()
+	v1 := ivm.NewICodeVersion(ivm.ICode{ID: "counter_1", RepositoryName: "counter", Version: "1.0"}, 3, "", "client01")
+	v2 := ivm.NewICodeVersion(ivm.ICode{ID: "counter_2", RepositoryName: "counter", Version: "2.0"}, 10, "migrate", "client01")
+
+	registry.Activate(3)
+	assert.NoError(t, registry.Add(v1))
+	assert.NoError(t, registry.Add(v2))
+
+	// versions should be added in the order of their activation heights
+	assert.Equal(t, ivm.ErrInvalidActivationHeight, registry.Add(ivm.NewICodeVersion(ivm.ICode{ID: "counter_3", RepositoryName: "counter"}, 10, "", "client01")))
+
+	// only the owner of the name adds its versions
+	assert.Equal(t, ivm.ErrNotICodeOwner, registry.Add(ivm.NewICodeVersion(ivm.ICode{ID: "counter_3", RepositoryName: "counter"}, 20, "", "client02")))
+
+	//then
+	assert.True(t, registry.HasName("counter"))
+	assert.True(t, registry.Exists("counter_2"))
+	assert.Equal(t, "counter_1", registry.Resolve("counter"))
+	assert.Equal(t, "counter_2", registry.Resolve("counter_2"))
+	assert.Equal(t, "unknown", registry.Resolve("unknown"))
+
+	//when
+	assert.Len(t, registry.Activate(9), 0)
+	activated := registry.Activate(10)
+
+	//then
+	assert.Equal(t, []ivm.ICodeVersion{v2}, activated)
+	assert.Equal(t, "counter_2", registry.Resolve("counter"))
+
+	versions := registry.Versions("counter")
+	assert.Len(t, versions, 2)
+	assert.False(t, versions[0].Active)
+	assert.True(t, versions[1].Active)
+
+	//when
+	registry.Remove("counter_2")
+
+	//then
+	assert.Equal(t, "counter_1", registry.Resolve("counter"))
+
+	registry.Remove("counter_1")
+	assert.False(t, registry.HasName("counter"))
+	assert.Len(t, registry.Versions("counter"), 0)
+
+	// the name stays owned after its versions are removed
+	owner, ok := registry.Owner("counter")
+	assert.True(t, ok)
+	assert.Equal(t, "client01", owner)
+	assert.Equal(t, ivm.ErrNotICodeOwner, registry.Add(ivm.NewICodeVersion(ivm.ICode{ID: "counter_3", RepositoryName: "counter"}, 20, "", "client02")))
+}
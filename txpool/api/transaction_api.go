@@ -17,25 +17,37 @@
 package api
 
 import (
+	"time"
+
 	"github.com/it-chain/engine/txpool"
 	"github.com/it-chain/iLogger"
 )
 
 type TransactionApi struct {
-	nodeId                string
-	transactionRepository txpool.TransactionRepository
-	leaderRepository      txpool.LeaderRepository
-	transferService       *txpool.TransferService
-	blockProposalService  *txpool.BlockProposalService
+	nodeId                         string
+	transactionRepository          txpool.TransactionRepository
+	committedTransactionRepository txpool.CommittedTransactionRepository
+	leaderRepository               txpool.LeaderRepository
+	gossipService                  *txpool.GossipService
+	blockProposalService           *txpool.BlockProposalService
+	inFlightService                *txpool.InFlightService
+	capacityService                *txpool.CapacityService
+	expiryService                  *txpool.ExpiryService
+	txValidator                    txpool.TxValidator
 }
 
-func NewTransactionApi(nodeId string, transactionRepository txpool.TransactionRepository, leaderRepository txpool.LeaderRepository, transferService *txpool.TransferService, blockProposalService *txpool.BlockProposalService) *TransactionApi {
+func NewTransactionApi(nodeId string, transactionRepository txpool.TransactionRepository, committedTransactionRepository txpool.CommittedTransactionRepository, leaderRepository txpool.LeaderRepository, gossipService *txpool.GossipService, blockProposalService *txpool.BlockProposalService, inFlightService *txpool.InFlightService, capacityService *txpool.CapacityService, expiryService *txpool.ExpiryService, txValidator txpool.TxValidator) *TransactionApi {
 	return &TransactionApi{
-		nodeId:                nodeId,
-		transactionRepository: transactionRepository,
-		leaderRepository:      leaderRepository,
-		transferService:       transferService,
-		blockProposalService:  blockProposalService,
+		nodeId:                         nodeId,
+		transactionRepository:          transactionRepository,
+		committedTransactionRepository: committedTransactionRepository,
+		leaderRepository:               leaderRepository,
+		gossipService:                  gossipService,
+		blockProposalService:           blockProposalService,
+		inFlightService:                inFlightService,
+		capacityService:                capacityService,
+		expiryService:                  expiryService,
+		txValidator:                    txValidator,
 	}
 }
 
@@ -48,80 +60,213 @@ func (t TransactionApi) CreateTransaction(txData txpool.TxData) (txpool.Transact
 		return txpool.Transaction{}, err
 	}
 
-	err = t.transactionRepository.Save(transaction)
+	if err := t.txValidator.Validate(transaction); err != nil {
+		iLogger.Infof(nil, "[Txpool] Reject invalid transaction - ICodeID: [%s], Function: [%s], Err: [%s]", transaction.ICodeID, transaction.Function, err)
+		return txpool.Transaction{}, err
+	}
+
+	evicted, err := t.capacityService.Add(transaction)
+	t.gossipService.Forget(evicted)
+	if err == txpool.ErrTransactionAlreadyPending || err == txpool.ErrTransactionAlreadyCommitted {
+		iLogger.Infof(nil, "[Txpool] Reject duplicated transaction - ID: [%s], Err: [%s]", transaction.ID, err)
+		return txpool.Transaction{}, err
+	}
+
+	if err != nil {
+		return txpool.Transaction{}, err
+	}
+
+	return transaction, nil
+}
+
+// CreateTransactions creates the transactions one by one, a rejected transaction does not stop the others.
+// The returned transactions and errors are in the order of txDatas
+func (t TransactionApi) CreateTransactions(txDatas []txpool.TxData) ([]txpool.Transaction, []error) {
+
+	transactions := make([]txpool.Transaction, len(txDatas))
+	errs := make([]error, len(txDatas))
+
+	for i, txData := range txDatas {
+		transactions[i], errs[i] = t.CreateTransaction(txData)
+	}
+
+	return transactions, errs
+}
+
+// ReceiveTransactions saves the transactions gossiped by a peer,
+// which is not sent back the ones kept in the pool
+func (t TransactionApi) ReceiveTransactions(peerID string, transactions []txpool.Transaction) error {
 
-	return transaction, err
+	pooled, err := t.saveTransactions(transactions)
+	t.gossipService.MarkSeen(peerID, pooled)
+
+	return err
 }
 
+// transactions received from other nodes may be delivered more than once,
+// the duplicates are skipped so the state of the pending ones is kept.
+// The dropped transactions are not in the pool, so they are forgotten by the gossip
 func (t TransactionApi) SaveTransactions(transactions []txpool.Transaction) error {
 
+	_, err := t.saveTransactions(transactions)
+
+	return err
+}
+
+// saveTransactions returns the ids of the transactions which are in the pool after saving,
+// the admitted ones and the ones which were already pending
+func (t TransactionApi) saveTransactions(transactions []txpool.Transaction) ([]txpool.TransactionId, error) {
+
+	pooled := make([]txpool.TransactionId, 0)
+	dropped := make([]txpool.TransactionId, 0)
+	defer func() {
+		t.gossipService.Forget(dropped)
+	}()
+
 	for _, tx := range transactions {
 
-		if err := t.transactionRepository.Save(tx); err != nil {
-			return err
+		if t.committedTransactionRepository.Exists(tx.ID) {
+			dropped = append(dropped, tx.ID)
+			continue
+		}
+
+		// skips the validation of the pending transactions, the capacity service checks it again when adding
+		if t.isPending(tx.ID) {
+			pooled = append(pooled, tx.ID)
+			continue
+		}
+
+		if err := t.txValidator.Validate(tx); err != nil {
+			iLogger.Infof(nil, "[Txpool] Drop invalid transaction - ID: [%s], PeerID: [%s], Err: [%s]", tx.ID, tx.PeerID, err)
+			dropped = append(dropped, tx.ID)
+			continue
+		}
+
+		// in flight is the state of the sender, the transaction is pending here
+		tx.ProposedAt = time.Time{}
+
+		evicted, err := t.capacityService.Add(tx)
+		dropped = append(dropped, evicted...)
+		pooled = without(pooled, evicted)
+
+		if err == txpool.ErrTransactionAlreadyPending {
+			pooled = append(pooled, tx.ID)
+			continue
+		}
+
+		if err == txpool.ErrTransactionAlreadyCommitted {
+			dropped = append(dropped, tx.ID)
+			continue
+		}
+
+		if err == txpool.ErrPoolFull {
+			iLogger.Infof(nil, "[Txpool] Drop transaction from full pool - ID: [%s]", tx.ID)
+			dropped = append(dropped, tx.ID)
+			continue
+		}
+
+		if err != nil {
+			return pooled, err
 		}
+
+		pooled = append(pooled, tx.ID)
 	}
 
-	return nil
+	return pooled, nil
+}
+
+func (t TransactionApi) isPending(id txpool.TransactionId) bool {
+
+	_, err := t.transactionRepository.FindById(id)
+	return err == nil
+}
+
+func without(ids []txpool.TransactionId, removed []txpool.TransactionId) []txpool.TransactionId {
+
+	if len(removed) == 0 {
+		return ids
+	}
+
+	removedSet := make(map[txpool.TransactionId]struct{})
+	for _, id := range removed {
+		removedSet[id] = struct{}{}
+	}
+
+	remained := make([]txpool.TransactionId, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := removedSet[id]; !ok {
+			remained = append(remained, id)
+		}
+	}
+
+	return remained
 }
 
 func (t TransactionApi) DeleteTransaction(id txpool.TransactionId) {
 
 	t.transactionRepository.Remove(id)
+	t.gossipService.Forget([]txpool.TransactionId{id})
 }
 
-func (t TransactionApi) ProposeBlock(engineMode string) error {
+// RemoveCommittedTransactions removes the transactions of a committed block from the pool.
+// They are indexed before the removal, so they are never accepted again
+func (t TransactionApi) RemoveCommittedTransactions(ids []txpool.TransactionId) {
 
-	switch engineMode {
+	t.capacityService.Commit(ids)
+	t.inFlightService.RemoveCommittedTransactions(ids)
+	t.gossipService.Forget(ids)
+}
 
-	case "solo":
-		return t.blockProposalService.ProposeBlock()
+// SetCommittedHeight keeps the height which the max height of transactions is compared with
+func (t TransactionApi) SetCommittedHeight(height uint64) {
 
-	case "pbft":
-		if t.isLeader() {
-			return t.blockProposalService.ProposeBlock()
-		}
+	t.expiryService.SetCommittedHeight(height)
+}
 
-		return nil
+func (t TransactionApi) DropExpiredTransactions() error {
 
-	default:
-		return nil
-	}
+	expired, err := t.expiryService.DropExpiredTransactions()
+	t.gossipService.Forget(expired)
+
+	return err
 }
 
-func (t TransactionApi) SendLeaderTransaction(engineMode string) error {
+// ReleaseInFlightTransactions puts every in-flight transaction back to pending,
+// so they are proposed again
+func (t TransactionApi) ReleaseInFlightTransactions() error {
 
-	switch engineMode {
+	return t.inFlightService.ReleaseTransactions()
+}
 
-	case "pbft":
-		if t.isLeader() {
-			return nil
-		}
+func (t TransactionApi) ReleaseExpiredTransactions() error {
 
-		if !t.isLeaderExistent() {
-			return nil
-		}
+	return t.inFlightService.ReleaseExpiredTransactions()
+}
 
-		return t.transferService.SendLeaderTransactions()
+func (t TransactionApi) ProposeBlock(engine txpool.ConsensusEngine) error {
 
-	default:
+	if engine.IsLeaderBased() && !t.isLeader() {
 		return nil
 	}
-}
 
-func (t TransactionApi) isLeader() bool {
+	// a block with an expired transaction is rejected
+	if err := t.DropExpiredTransactions(); err != nil {
+		return err
+	}
 
-	leader := t.leaderRepository.Get()
+	return t.blockProposalService.ProposeBlock()
+}
 
-	return txpool.IsLeader(t.nodeId, leader)
+// GossipTransactions spreads the pool to the other validators,
+// every validator holds the pending transactions whoever the leader is
+func (t TransactionApi) GossipTransactions() error {
+
+	return t.gossipService.GossipTransactions()
 }
 
-func (t TransactionApi) isLeaderExistent() bool {
-	leader := t.leaderRepository.Get()
+func (t TransactionApi) isLeader() bool {
 
-	if leader.Id == "" {
-		return false
-	}
+	leader := t.leaderRepository.Get()
 
-	return true
+	return txpool.IsLeader(t.nodeId, leader)
 }
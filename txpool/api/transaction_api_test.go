@@ -17,13 +17,16 @@
 package api_test
 
 import (
+	"encoding/json"
 	"testing"
+	"time"
 
 	"sync"
 
 	"github.com/it-chain/engine/common"
 	"github.com/it-chain/engine/common/command"
 	"github.com/it-chain/engine/common/rabbitmq/pubsub"
+	"github.com/it-chain/engine/consensus"
 	"github.com/it-chain/engine/txpool"
 	"github.com/it-chain/engine/txpool/api"
 	"github.com/it-chain/engine/txpool/infra/mem"
@@ -57,10 +60,17 @@ func TestTransactionApi_CreateTransaction(t *testing.T) {
 
 	transactionRepository := mem.NewTransactionRepository()
 	leaderRepository := mem.NewLeaderRepository()
-	eventService := mock.EventService{}
-	transferService := txpool.NewTransferService(transactionRepository, leaderRepository, eventService)
-	blockProposalService := txpool.NewBlockProposalService(transactionRepository, eventService)
-	transactionApi := api.NewTransactionApi("zf", transactionRepository, leaderRepository, transferService, blockProposalService)
+	eventService := mock.EventService{
+		PublishFunc: func(topic string, event interface{}) error {
+			return nil
+		},
+	}
+	gossipService := txpool.NewGossipService(transactionRepository, mem.NewPeerRepository(), eventService, time.Second, 0)
+	blockProposalService := txpool.NewBlockProposalService(transactionRepository, eventService, txpool.FifoPolicy{})
+	inFlightService := txpool.NewInFlightService(transactionRepository, eventService, time.Second)
+	committedTransactionRepository := mem.NewCommittedTransactionRepository()
+	capacityService := txpool.NewCapacityService(transactionRepository, committedTransactionRepository, eventService, txpool.PoolLimit{})
+	transactionApi := api.NewTransactionApi("zf", transactionRepository, committedTransactionRepository, leaderRepository, gossipService, blockProposalService, inFlightService, capacityService, txpool.NewExpiryService(transactionRepository, eventService), txpool.NewTxValidatorChain())
 
 	for _, test := range tests {
 		tx, err := transactionApi.CreateTransaction(test.input.txData)
@@ -74,6 +84,106 @@ func TestTransactionApi_CreateTransaction(t *testing.T) {
 	}
 }
 
+func TestTransactionApi_CreateTransaction_Duplicated(t *testing.T) {
+
+	transactionRepository := mem.NewTransactionRepository()
+	leaderRepository := mem.NewLeaderRepository()
+	eventService := mock.EventService{
+		PublishFunc: func(topic string, event interface{}) error {
+			return nil
+		},
+	}
+	gossipService := txpool.NewGossipService(transactionRepository, mem.NewPeerRepository(), eventService, time.Second, 0)
+	blockProposalService := txpool.NewBlockProposalService(transactionRepository, eventService, txpool.FifoPolicy{})
+	inFlightService := txpool.NewInFlightService(transactionRepository, eventService, time.Second)
+	committedTransactionRepository := mem.NewCommittedTransactionRepository()
+	capacityService := txpool.NewCapacityService(transactionRepository, committedTransactionRepository, eventService, txpool.PoolLimit{})
+	transactionApi := api.NewTransactionApi("zf", transactionRepository, committedTransactionRepository, leaderRepository, gossipService, blockProposalService, inFlightService, capacityService, txpool.NewExpiryService(transactionRepository, eventService), txpool.NewTxValidatorChain())
+
+	txData := txpool.TxData{
+		ICodeID:        "gg",
+		Function:       "transfer",
+		Args:           []string{"a", "b", "10"},
+		Jsonrpc:        "2.0",
+		IdempotencyKey: "client-request-1",
+	}
+
+	//when
+	tx, err := transactionApi.CreateTransaction(txData)
+	assert.NoError(t, err)
+
+	_, err = transactionApi.CreateTransaction(txData)
+
+	//then
+	assert.Equal(t, txpool.ErrTransactionAlreadyPending, err)
+
+	//when
+	transactionApi.RemoveCommittedTransactions([]txpool.TransactionId{tx.ID})
+	_, err = transactionApi.CreateTransaction(txData)
+
+	//then
+	assert.Equal(t, txpool.ErrTransactionAlreadyCommitted, err)
+
+	// transactions from other nodes are skipped silently
+	assert.NoError(t, transactionApi.SaveTransactions([]txpool.Transaction{tx}))
+	_, err = transactionRepository.FindById(tx.ID)
+	assert.Equal(t, mem.ErrTransactionDoesNotExist, err)
+
+	//when
+	otherSubmitter := txData
+	otherSubmitter.Signature, _ = json.Marshal(common.Signature{SignerID: "client02"})
+	other, err := transactionApi.CreateTransaction(otherSubmitter)
+
+	//then the same key of another submitter is another transaction
+	assert.NoError(t, err)
+	assert.Equal(t, "client02", other.Submitter)
+	assert.NotEqual(t, tx.ID, other.ID)
+}
+
+func TestTransactionApi_CreateTransactions(t *testing.T) {
+
+	transactionRepository := mem.NewTransactionRepository()
+	leaderRepository := mem.NewLeaderRepository()
+	eventService := mock.EventService{
+		PublishFunc: func(topic string, event interface{}) error {
+			return nil
+		},
+	}
+	gossipService := txpool.NewGossipService(transactionRepository, mem.NewPeerRepository(), eventService, time.Second, 0)
+	blockProposalService := txpool.NewBlockProposalService(transactionRepository, eventService, txpool.FifoPolicy{})
+	inFlightService := txpool.NewInFlightService(transactionRepository, eventService, time.Second)
+	committedTransactionRepository := mem.NewCommittedTransactionRepository()
+	capacityService := txpool.NewCapacityService(transactionRepository, committedTransactionRepository, eventService, txpool.PoolLimit{})
+	txValidator := txpool.NewTxValidatorChain(txpool.JsonrpcValidator{})
+	transactionApi := api.NewTransactionApi("zf", transactionRepository, committedTransactionRepository, leaderRepository, gossipService, blockProposalService, inFlightService, capacityService, txpool.NewExpiryService(transactionRepository, eventService), txValidator)
+
+	txDatas := []txpool.TxData{
+		{ICodeID: "gg", Function: "transfer", Args: []string{"a"}, Jsonrpc: "2.0", IdempotencyKey: "key-1"},
+		{ICodeID: "gg", Function: "transfer", Args: []string{"b"}, Jsonrpc: "1.0"},
+		{ICodeID: "gg", Function: "transfer", Args: []string{"a"}, Jsonrpc: "2.0", IdempotencyKey: "key-1"},
+		{ICodeID: "gg", Function: "transfer", Args: []string{"c"}, Jsonrpc: "2.0"},
+	}
+
+	//when
+	transactions, errs := transactionApi.CreateTransactions(txDatas)
+
+	//then
+	assert.Len(t, transactions, 4)
+	assert.Len(t, errs, 4)
+
+	assert.NoError(t, errs[0])
+	assert.IsType(t, txpool.InvalidTransactionError{}, errs[1])
+	assert.Equal(t, txpool.ErrTransactionAlreadyPending, errs[2])
+	assert.NoError(t, errs[3])
+
+	saved, err := transactionRepository.FindAll()
+	assert.NoError(t, err)
+	assert.Len(t, saved, 2)
+
+	_, err = transactionRepository.FindById(transactions[3].ID)
+	assert.NoError(t, err)
+}
+
 func TestTransactionApi_DeleteTransaction(t *testing.T) {
 
 	tests := map[string]struct {
@@ -88,10 +198,17 @@ func TestTransactionApi_DeleteTransaction(t *testing.T) {
 
 	transactionRepository := mem.NewTransactionRepository()
 	leaderRepository := mem.NewLeaderRepository()
-	eventService := mock.EventService{}
-	transferService := txpool.NewTransferService(transactionRepository, leaderRepository, eventService)
-	blockProposalService := txpool.NewBlockProposalService(transactionRepository, eventService)
-	transactionApi := api.NewTransactionApi("zf", transactionRepository, leaderRepository, transferService, blockProposalService)
+	eventService := mock.EventService{
+		PublishFunc: func(topic string, event interface{}) error {
+			return nil
+		},
+	}
+	gossipService := txpool.NewGossipService(transactionRepository, mem.NewPeerRepository(), eventService, time.Second, 0)
+	blockProposalService := txpool.NewBlockProposalService(transactionRepository, eventService, txpool.FifoPolicy{})
+	inFlightService := txpool.NewInFlightService(transactionRepository, eventService, time.Second)
+	committedTransactionRepository := mem.NewCommittedTransactionRepository()
+	capacityService := txpool.NewCapacityService(transactionRepository, committedTransactionRepository, eventService, txpool.PoolLimit{})
+	transactionApi := api.NewTransactionApi("zf", transactionRepository, committedTransactionRepository, leaderRepository, gossipService, blockProposalService, inFlightService, capacityService, txpool.NewExpiryService(transactionRepository, eventService), txpool.NewTxValidatorChain())
 
 	transactionRepository.Save(txpool.Transaction{
 		ID: "transactionID",
@@ -105,6 +222,47 @@ func TestTransactionApi_DeleteTransaction(t *testing.T) {
 	}
 }
 
+func TestTransactionApi_ReceiveTransactions_Dropped(t *testing.T) {
+
+	//given
+	transactionRepository := mem.NewTransactionRepository()
+	transactionRepository.Save(txpool.Transaction{ID: "tx01"})
+
+	peerRepository := mem.NewPeerRepository()
+	peerRepository.Add("peer01")
+
+	delivered := make([]string, 0)
+	eventService := mock.EventService{
+		PublishFunc: func(topic string, event interface{}) error {
+			if topic != "message.deliver" {
+				return nil
+			}
+
+			transactions := []txpool.Transaction{}
+			assert.NoError(t, common.Deserialize(event.(command.DeliverGrpc).Body, &transactions))
+			for _, tx := range transactions {
+				delivered = append(delivered, tx.ID)
+			}
+			return nil
+		},
+	}
+	gossipService := txpool.NewGossipService(transactionRepository, peerRepository, eventService, time.Hour, 0)
+	blockProposalService := txpool.NewBlockProposalService(transactionRepository, eventService, txpool.FifoPolicy{})
+	inFlightService := txpool.NewInFlightService(transactionRepository, eventService, time.Second)
+	committedTransactionRepository := mem.NewCommittedTransactionRepository()
+	capacityService := txpool.NewCapacityService(transactionRepository, committedTransactionRepository, eventService, txpool.PoolLimit{MaxTransactions: 1, Eviction: txpool.RejectEviction})
+	transactionApi := api.NewTransactionApi("node01", transactionRepository, committedTransactionRepository, mem.NewLeaderRepository(), gossipService, blockProposalService, inFlightService, capacityService, txpool.NewExpiryService(transactionRepository, eventService), txpool.NewTxValidatorChain())
+
+	//when tx02 of peer01 is dropped from the full pool, and comes back after tx01 leaves the pool
+	assert.NoError(t, transactionApi.ReceiveTransactions("peer01", []txpool.Transaction{{ID: "tx02"}}))
+	transactionApi.DeleteTransaction("tx01")
+	assert.NoError(t, transactionApi.SaveTransactions([]txpool.Transaction{{ID: "tx02"}}))
+	assert.NoError(t, transactionApi.GossipTransactions())
+
+	//then peer01 is not assumed to hold the dropped transaction
+	assert.Equal(t, []string{"tx02"}, delivered)
+}
+
 func TestTransactionApi_ProposeBlock_Solo(t *testing.T) {
 
 	//publish 하는 걸 잘 받는지.
@@ -161,13 +319,19 @@ func TestTransactionApi_ProposeBlock_Solo(t *testing.T) {
 		eventService := common.NewEventService("", "Event")
 
 		//set service
-		transferService := txpool.NewTransferService(txPoolRepo, leaderRepo, eventService)
-		blockProposalService := txpool.NewBlockProposalService(txPoolRepo, eventService)
+		gossipService := txpool.NewGossipService(txPoolRepo, mem.NewPeerRepository(), eventService, time.Second, 0)
+		blockProposalService := txpool.NewBlockProposalService(txPoolRepo, eventService, txpool.FifoPolicy{})
+		inFlightService := txpool.NewInFlightService(txPoolRepo, eventService, time.Second)
 
 		//set api
-		transactionApi := api.NewTransactionApi("node01", txPoolRepo, leaderRepo, transferService, blockProposalService)
+		committedTransactionRepository := mem.NewCommittedTransactionRepository()
+		capacityService := txpool.NewCapacityService(txPoolRepo, committedTransactionRepository, eventService, txpool.PoolLimit{})
+		transactionApi := api.NewTransactionApi("node01", txPoolRepo, committedTransactionRepository, leaderRepo, gossipService, blockProposalService, inFlightService, capacityService, txpool.NewExpiryService(txPoolRepo, eventService), txpool.NewTxValidatorChain())
 
-		err := transactionApi.ProposeBlock(test.engineMode)
+		engine, err := consensus.NewConsensusEngine(test.engineMode, eventService)
+		assert.NoError(t, err)
+
+		err = transactionApi.ProposeBlock(engine)
 
 		assert.NoError(t, err)
 	}
@@ -203,13 +367,19 @@ func TestTransactionApi_ProposeBlock_Solo_NoTransaction(t *testing.T) {
 		eventService := common.NewEventService("", "Event")
 
 		//set service
-		transferService := txpool.NewTransferService(txPoolRepo, leaderRepo, eventService)
-		blockProposalService := txpool.NewBlockProposalService(txPoolRepo, eventService)
+		gossipService := txpool.NewGossipService(txPoolRepo, mem.NewPeerRepository(), eventService, time.Second, 0)
+		blockProposalService := txpool.NewBlockProposalService(txPoolRepo, eventService, txpool.FifoPolicy{})
+		inFlightService := txpool.NewInFlightService(txPoolRepo, eventService, time.Second)
 
 		//set api
-		transactionApi := api.NewTransactionApi("node01", txPoolRepo, leaderRepo, transferService, blockProposalService)
+		committedTransactionRepository := mem.NewCommittedTransactionRepository()
+		capacityService := txpool.NewCapacityService(txPoolRepo, committedTransactionRepository, eventService, txpool.PoolLimit{})
+		transactionApi := api.NewTransactionApi("node01", txPoolRepo, committedTransactionRepository, leaderRepo, gossipService, blockProposalService, inFlightService, capacityService, txpool.NewExpiryService(txPoolRepo, eventService), txpool.NewTxValidatorChain())
+
+		engine, err := consensus.NewConsensusEngine(test.engineMode, eventService)
+		assert.NoError(t, err)
 
-		err := transactionApi.ProposeBlock(test.engineMode)
+		err = transactionApi.ProposeBlock(engine)
 
 		assert.NoError(t, err)
 	}
@@ -270,13 +440,19 @@ func TestTransactionApi_ProposeBlock_PBFT(t *testing.T) {
 		eventService := common.NewEventService("", "Event")
 
 		//set service
-		transferService := txpool.NewTransferService(txPoolRepo, leaderRepo, eventService)
-		blockProposalService := txpool.NewBlockProposalService(txPoolRepo, eventService)
+		gossipService := txpool.NewGossipService(txPoolRepo, mem.NewPeerRepository(), eventService, time.Second, 0)
+		blockProposalService := txpool.NewBlockProposalService(txPoolRepo, eventService, txpool.FifoPolicy{})
+		inFlightService := txpool.NewInFlightService(txPoolRepo, eventService, time.Second)
 
 		//set api
-		transactionApi := api.NewTransactionApi("leader", txPoolRepo, leaderRepo, transferService, blockProposalService)
+		committedTransactionRepository := mem.NewCommittedTransactionRepository()
+		capacityService := txpool.NewCapacityService(txPoolRepo, committedTransactionRepository, eventService, txpool.PoolLimit{})
+		transactionApi := api.NewTransactionApi("leader", txPoolRepo, committedTransactionRepository, leaderRepo, gossipService, blockProposalService, inFlightService, capacityService, txpool.NewExpiryService(txPoolRepo, eventService), txpool.NewTxValidatorChain())
+
+		engine, err := consensus.NewConsensusEngine(test.engineMode, eventService)
+		assert.NoError(t, err)
 
-		err := transactionApi.ProposeBlock(test.engineMode)
+		err = transactionApi.ProposeBlock(engine)
 
 		assert.NoError(t, err)
 	}
@@ -285,18 +461,16 @@ func TestTransactionApi_ProposeBlock_PBFT(t *testing.T) {
 
 }
 
-func TestTransactionApi_SendLeaderTransaction(t *testing.T) {
+func TestTransactionApi_GossipTransactions(t *testing.T) {
 
 	wg := sync.WaitGroup{}
 	wg.Add(1)
 
 	tests := map[string]struct {
-		engineMode string
-		txList     []txpool.Transaction
-		leader     txpool.Leader
+		txList []txpool.Transaction
+		peer   string
 	}{
-		"success-pbft": {
-			engineMode: "pbft",
+		"success": {
 			txList: []txpool.Transaction{
 				{
 					ID: "tx03",
@@ -308,8 +482,7 @@ func TestTransactionApi_SendLeaderTransaction(t *testing.T) {
 					ID: "tx05",
 				},
 			},
-
-			leader: txpool.Leader{Id: "leader"},
+			peer: "peer01",
 		},
 	}
 
@@ -321,8 +494,8 @@ func TestTransactionApi_SendLeaderTransaction(t *testing.T) {
 
 		handler := &mock.SendTransactionCommandHandler{}
 		handler.HandleFunc = func(command command.DeliverGrpc) {
-			assert.Equal(t, txpool.SendTransactionsToLeader, command.Protocol)
-			assert.Equal(t, test.leader.Id, command.RecipientList[0])
+			assert.Equal(t, txpool.GossipTransactionsProtocol, command.Protocol)
+			assert.Equal(t, test.peer, command.RecipientList[0])
 			wg.Done()
 
 		}
@@ -338,18 +511,27 @@ func TestTransactionApi_SendLeaderTransaction(t *testing.T) {
 		}
 
 		leaderRepo := mem.NewLeaderRepository()
-		leaderRepo.Set(test.leader)
+		peerRepo := mem.NewPeerRepository()
+		peerRepo.Add(test.peer)
 		eventService := common.NewEventService("", "Event")
 
 		//set service
-		transferService := txpool.NewTransferService(txPoolRepo, leaderRepo, eventService)
-		blockProposalService := txpool.NewBlockProposalService(txPoolRepo, eventService)
+		gossipService := txpool.NewGossipService(txPoolRepo, peerRepo, eventService, time.Second, 0)
+		blockProposalService := txpool.NewBlockProposalService(txPoolRepo, eventService, txpool.FifoPolicy{})
+		inFlightService := txpool.NewInFlightService(txPoolRepo, eventService, time.Second)
 
 		//set api
-		transactionApi := api.NewTransactionApi("node01", txPoolRepo, leaderRepo, transferService, blockProposalService)
+		committedTransactionRepository := mem.NewCommittedTransactionRepository()
+		capacityService := txpool.NewCapacityService(txPoolRepo, committedTransactionRepository, eventService, txpool.PoolLimit{})
+		transactionApi := api.NewTransactionApi("node01", txPoolRepo, committedTransactionRepository, leaderRepo, gossipService, blockProposalService, inFlightService, capacityService, txpool.NewExpiryService(txPoolRepo, eventService), txpool.NewTxValidatorChain())
+
+		err := transactionApi.GossipTransactions()
+		assert.NoError(t, err)
 
-		err := transactionApi.SendLeaderTransaction(test.engineMode)
+		// transactions stay in the pool after they are gossiped
+		transactions, err := txPoolRepo.FindAll()
 		assert.NoError(t, err)
+		assert.Len(t, transactions, len(test.txList))
 	}
 
 	wg.Wait()
@@ -20,26 +20,28 @@ import (
 	"sync"
 
 	"github.com/it-chain/engine/common/command"
+	"github.com/it-chain/engine/common/event"
 	"github.com/it-chain/iLogger"
 )
 
 type BlockProposalService struct {
 	txpoolRepository TransactionRepository
 	eventService     EventService
+	orderingPolicy   OrderingPolicy
 	sync.RWMutex
 }
 
-func NewBlockProposalService(txpoolRepository TransactionRepository, eventService EventService) *BlockProposalService {
+func NewBlockProposalService(txpoolRepository TransactionRepository, eventService EventService, orderingPolicy OrderingPolicy) *BlockProposalService {
 	return &BlockProposalService{
 		txpoolRepository: txpoolRepository,
 		eventService:     eventService,
+		orderingPolicy:   orderingPolicy,
 		RWMutex:          sync.RWMutex{},
 	}
 }
 
-// todo do not delete transaction immediately
-// todo transaction will be deleted when block are committed
-func (b BlockProposalService) ProposeBlock() error {
+// proposed transactions stay in the pool as in-flight until the block which contains them is committed
+func (b *BlockProposalService) ProposeBlock() error {
 
 	b.Lock()
 	defer b.Unlock()
@@ -47,26 +49,34 @@ func (b BlockProposalService) ProposeBlock() error {
 	// todo transaction size, number of tx
 	transactions, err := b.txpoolRepository.FindAll()
 
-	iLogger.Debugf(nil, "[Txpool] transaction number - tx: [%d]", len(transactions))
 	if err != nil {
 		return err
 	}
 
+	transactions = b.orderingPolicy.Order(filter(transactions, IsPending))
+	iLogger.Debugf(nil, "[Txpool] transaction number - tx: [%d]", len(transactions))
+
 	if len(transactions) == 0 {
 		return nil
 	}
 
+	// marked before publishing, so a fast commit can not be overwritten by the mark
+	if err := markInFlight(b.txpoolRepository, transactions); err != nil {
+		return err
+	}
+
 	if err := b.sendBlockProposal(transactions); err != nil {
+		b.restoreTransactions(transactions)
 		return err
 	}
 
-	b.clearTransactions(transactions)
+	publishStatus(b.eventService, "tx.proposed", event.TxProposed{TransactionIds: transactionIds(transactions)})
 
 	return nil
 
 }
 
-func (b BlockProposalService) sendBlockProposal(transactions []Transaction) error {
+func (b *BlockProposalService) sendBlockProposal(transactions []Transaction) error {
 
 	ProposeBlockEvent := createProposeBlockCommand(transactions)
 
@@ -79,9 +89,9 @@ func (b BlockProposalService) sendBlockProposal(transactions []Transaction) erro
 	return nil
 }
 
-func (b BlockProposalService) clearTransactions(transactions []Transaction) {
+func (b *BlockProposalService) restoreTransactions(transactions []Transaction) {
 	for _, tx := range transactions {
-		b.txpoolRepository.Remove(tx.ID)
+		b.txpoolRepository.Save(tx)
 	}
 }
 
@@ -102,13 +112,16 @@ func convertTxListType(transactions []Transaction) []command.Tx {
 
 func convertTxType(tx Transaction) command.Tx {
 	return command.Tx{
-		ID:        tx.ID,
-		ICodeID:   tx.ICodeID,
-		PeerID:    tx.PeerID,
-		TimeStamp: tx.TimeStamp,
-		Jsonrpc:   tx.Jsonrpc,
-		Function:  tx.Function,
-		Args:      tx.Args,
-		Signature: tx.Signature,
+		ID:             tx.ID,
+		ICodeID:        tx.ICodeID,
+		PeerID:         tx.PeerID,
+		TimeStamp:      tx.TimeStamp,
+		Jsonrpc:        tx.Jsonrpc,
+		Function:       tx.Function,
+		Args:           tx.Args,
+		Signature:      tx.Signature,
+		Deadline:       tx.Deadline,
+		MaxHeight:      tx.MaxHeight,
+		IdempotencyKey: tx.IdempotencyKey,
 	}
 }
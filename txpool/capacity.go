@@ -0,0 +1,243 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package txpool
+
+import (
+	"errors"
+	"sync"
+)
+
+const (
+	RejectEviction = "reject"
+	OldestEviction = "oldest"
+)
+
+// ErrCodePoolFull is the rpc error code of ErrPoolFull,
+// so the callers of transaction.create can tell a full pool from the other errors
+const ErrCodePoolFull = "pool_full"
+
+// ErrCodeBatchTooLarge is the rpc error code of ErrBatchTooLarge
+const ErrCodeBatchTooLarge = "batch_too_large"
+
+var ErrPoolFull = errors.New("transaction pool is full")
+var ErrBatchTooLarge = errors.New("too many transactions in the batch")
+var ErrUnknownEvictionPolicy = errors.New("unknown txpool eviction policy")
+
+// PoolLimit caps the pending transactions of the pool, globally and per submitter.
+// The submitter is the verified signer of the transaction, unsigned transactions share one quota.
+// A limit of zero means no limit
+type PoolLimit struct {
+	MaxTransactions             int
+	MaxBytes                    int
+	MaxTransactionsPerSubmitter int
+	MaxBytesPerSubmitter        int
+	// what to do when the pool is full, one of "reject" and "oldest".
+	// "oldest" evicts the oldest pending transactions to make room for the new one
+	Eviction string
+}
+
+func NewPoolLimit(maxTransactions int, maxBytes int, maxTransactionsPerSubmitter int, maxBytesPerSubmitter int, eviction string) (PoolLimit, error) {
+	switch eviction {
+	case "":
+		eviction = RejectEviction
+	case RejectEviction, OldestEviction:
+	default:
+		return PoolLimit{}, ErrUnknownEvictionPolicy
+	}
+
+	return PoolLimit{
+		MaxTransactions:             maxTransactions,
+		MaxBytes:                    maxBytes,
+		MaxTransactionsPerSubmitter: maxTransactionsPerSubmitter,
+		MaxBytesPerSubmitter:        maxBytesPerSubmitter,
+		Eviction:                    eviction,
+	}, nil
+}
+
+// Admit decides whether the transaction fits in the pool and returns the transactions to evict for it.
+// Only pending transactions are evicted, the in-flight ones may already be in a block
+func (l PoolLimit) Admit(pool []Transaction, transaction Transaction) ([]Transaction, error) {
+	return l.admit(newPoolUsage(pool, transaction.Submitter), transaction, func() ([]Transaction, error) {
+		return pool, nil
+	})
+}
+
+// admit decides with the usage of the pool, the pool is only read when transactions have to be evicted
+func (l PoolLimit) admit(usage PoolUsage, transaction Transaction, findPool func() ([]Transaction, error)) ([]Transaction, error) {
+	usage.add(transaction)
+
+	if l.fits(usage) {
+		return nil, nil
+	}
+
+	if l.Eviction != OldestEviction {
+		return nil, ErrPoolFull
+	}
+
+	pool, err := findPool()
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := FifoPolicy{}.Order(filter(pool, IsPending))
+	evicted := make([]Transaction, 0)
+	for _, candidate := range candidates {
+		if !l.relieves(usage, candidate) {
+			continue
+		}
+
+		usage.remove(candidate)
+		evicted = append(evicted, candidate)
+
+		if l.fits(usage) {
+			return evicted, nil
+		}
+	}
+
+	return nil, ErrPoolFull
+}
+
+func (l PoolLimit) fits(usage PoolUsage) bool {
+	return !exceeds(usage.Transactions, l.MaxTransactions) &&
+		!exceeds(usage.Bytes, l.MaxBytes) &&
+		!exceeds(usage.SubmitterTransactions, l.MaxTransactionsPerSubmitter) &&
+		!exceeds(usage.SubmitterBytes, l.MaxBytesPerSubmitter)
+}
+
+// a transaction of another submitter only helps when a global limit is exceeded
+func (l PoolLimit) relieves(usage PoolUsage, candidate Transaction) bool {
+	if exceeds(usage.Transactions, l.MaxTransactions) || exceeds(usage.Bytes, l.MaxBytes) {
+		return true
+	}
+
+	return candidate.Submitter == usage.Submitter
+}
+
+func exceeds(value int, limit int) bool {
+	return limit > 0 && value > limit
+}
+
+// PoolUsage is what the transactions of the pool take, globally and of one submitter
+type PoolUsage struct {
+	Submitter             string
+	Transactions          int
+	Bytes                 int
+	SubmitterTransactions int
+	SubmitterBytes        int
+}
+
+func newPoolUsage(pool []Transaction, submitter string) PoolUsage {
+	usage := PoolUsage{Submitter: submitter}
+	for _, tx := range pool {
+		usage.add(tx)
+	}
+
+	return usage
+}
+
+func (u *PoolUsage) add(transaction Transaction) {
+	u.Transactions++
+	u.Bytes += transaction.Size()
+
+	if transaction.Submitter == u.Submitter {
+		u.SubmitterTransactions++
+		u.SubmitterBytes += transaction.Size()
+	}
+}
+
+func (u *PoolUsage) remove(transaction Transaction) {
+	u.Transactions--
+	u.Bytes -= transaction.Size()
+
+	if transaction.Submitter == u.Submitter {
+		u.SubmitterTransactions--
+		u.SubmitterBytes -= transaction.Size()
+	}
+}
+
+// UsageCounter keeps the usage of the pool as transactions are saved and removed,
+// so the capacity is checked without reading the whole pool.
+// The transaction repositories update it together with the transactions they keep
+type UsageCounter struct {
+	sync.Mutex
+	transactions int
+	bytes        int
+	submitters   map[string]*PoolUsage
+}
+
+func NewUsageCounter() *UsageCounter {
+	return &UsageCounter{
+		submitters: make(map[string]*PoolUsage),
+		Mutex:      sync.Mutex{},
+	}
+}
+
+func (c *UsageCounter) Add(transaction Transaction) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.transactions++
+	c.bytes += transaction.Size()
+
+	usage, ok := c.submitters[transaction.Submitter]
+	if !ok {
+		usage = &PoolUsage{Submitter: transaction.Submitter}
+		c.submitters[transaction.Submitter] = usage
+	}
+
+	usage.SubmitterTransactions++
+	usage.SubmitterBytes += transaction.Size()
+}
+
+func (c *UsageCounter) Remove(transaction Transaction) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.transactions--
+	c.bytes -= transaction.Size()
+
+	usage, ok := c.submitters[transaction.Submitter]
+	if !ok {
+		return
+	}
+
+	usage.SubmitterTransactions--
+	usage.SubmitterBytes -= transaction.Size()
+
+	if usage.SubmitterTransactions <= 0 {
+		delete(c.submitters, transaction.Submitter)
+	}
+}
+
+// Usage returns the usage of the pool and of the submitter
+func (c *UsageCounter) Usage(submitter string) PoolUsage {
+	c.Lock()
+	defer c.Unlock()
+
+	usage := PoolUsage{
+		Submitter:    submitter,
+		Transactions: c.transactions,
+		Bytes:        c.bytes,
+	}
+
+	if submitterUsage, ok := c.submitters[submitter]; ok {
+		usage.SubmitterTransactions = submitterUsage.SubmitterTransactions
+		usage.SubmitterBytes = submitterUsage.SubmitterBytes
+	}
+
+	return usage
+}
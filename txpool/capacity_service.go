@@ -0,0 +1,105 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package txpool
+
+import (
+	"sync"
+
+	"github.com/it-chain/engine/common/event"
+	"github.com/it-chain/iLogger"
+)
+
+// CapacityService adds transactions to the pool within its limit.
+// A transaction is checked against the pool and the committed transactions in the same critical section it is saved in,
+// so concurrent submissions of one transaction admit it once
+type CapacityService struct {
+	txpoolRepository               TransactionRepository
+	committedTransactionRepository CommittedTransactionRepository
+	eventService                   EventService
+	limit                          PoolLimit
+	sync.Mutex
+}
+
+func NewCapacityService(txpoolRepository TransactionRepository, committedTransactionRepository CommittedTransactionRepository, eventService EventService, limit PoolLimit) *CapacityService {
+	return &CapacityService{
+		txpoolRepository:               txpoolRepository,
+		committedTransactionRepository: committedTransactionRepository,
+		eventService:                   eventService,
+		limit:                          limit,
+		Mutex:                          sync.Mutex{},
+	}
+}
+
+// Add saves the transaction to the pool and returns the IDs of the transactions evicted for it.
+// A transaction which is pending or committed already is rejected
+func (c *CapacityService) Add(transaction Transaction) ([]TransactionId, error) {
+
+	c.Lock()
+	defer c.Unlock()
+
+	if err := c.checkDuplicate(transaction.ID); err != nil {
+		return nil, err
+	}
+
+	evicted, err := c.limit.admit(c.txpoolRepository.Usage(transaction.Submitter), transaction, c.txpoolRepository.FindAll)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tx := range evicted {
+		iLogger.Infof(nil, "[Txpool] Evict transaction from full pool - ID: [%s], PeerID: [%s]", tx.ID, tx.PeerID)
+		c.txpoolRepository.Remove(tx.ID)
+	}
+
+	if len(evicted) != 0 {
+		publishStatus(c.eventService, "tx.dropped", event.TxDropped{TransactionIds: transactionIds(evicted), Reason: DroppedByEviction})
+	}
+
+	if err := c.txpoolRepository.Save(transaction); err != nil {
+		return transactionIds(evicted), err
+	}
+
+	publishStatus(c.eventService, "tx.pending", event.TxPending{TransactionIds: []string{transaction.ID}})
+
+	return transactionIds(evicted), nil
+}
+
+// Commit indexes the committed transactions, so they are never added again
+func (c *CapacityService) Commit(ids []TransactionId) {
+
+	c.Lock()
+	defer c.Unlock()
+
+	for _, id := range ids {
+		if err := c.committedTransactionRepository.Save(id); err != nil {
+			iLogger.Errorf(nil, "[Txpool] Fail to index committed transaction - ID: [%s], Err: [%s]", id, err.Error())
+		}
+	}
+}
+
+func (c *CapacityService) checkDuplicate(id TransactionId) error {
+
+	if c.committedTransactionRepository.Exists(id) {
+		return ErrTransactionAlreadyCommitted
+	}
+
+	if _, err := c.txpoolRepository.FindById(id); err == nil {
+		return ErrTransactionAlreadyPending
+	}
+
+	return nil
+}
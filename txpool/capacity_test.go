@@ -0,0 +1,175 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package txpool_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/it-chain/engine/common/event"
+	"github.com/it-chain/engine/txpool"
+	"github.com/it-chain/engine/txpool/infra/mem"
+	"github.com/it-chain/engine/txpool/test/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPoolLimit_Admit(t *testing.T) {
+	now := time.Now()
+
+	pool := []txpool.Transaction{
+		{ID: "p1-1", PeerID: "node01", Submitter: "p1", TimeStamp: now},
+		{ID: "p2-1", PeerID: "node01", Submitter: "p2", TimeStamp: now.Add(1 * time.Second), ProposedAt: now},
+		{ID: "p2-2", PeerID: "node01", Submitter: "p2", TimeStamp: now.Add(2 * time.Second)},
+		{ID: "p1-2", PeerID: "node02", Submitter: "p1", TimeStamp: now.Add(3 * time.Second)},
+	}
+
+	tests := map[string]struct {
+		limit   txpool.PoolLimit
+		input   txpool.Transaction
+		evicted []string
+		err     error
+	}{
+		"no limit": {
+			limit: txpool.PoolLimit{},
+			input: txpool.Transaction{ID: "new", Submitter: "p1"},
+		},
+		"global limit reject": {
+			limit: txpool.PoolLimit{MaxTransactions: 4, Eviction: txpool.RejectEviction},
+			input: txpool.Transaction{ID: "new", Submitter: "p1"},
+			err:   txpool.ErrPoolFull,
+		},
+		"global limit evicts oldest pending": {
+			limit:   txpool.PoolLimit{MaxTransactions: 3, Eviction: txpool.OldestEviction},
+			input:   txpool.Transaction{ID: "new", Submitter: "p3"},
+			evicted: []string{"p1-1", "p2-2"},
+		},
+		"submitter limit evicts own transactions": {
+			limit:   txpool.PoolLimit{MaxTransactionsPerSubmitter: 2, Eviction: txpool.OldestEviction},
+			input:   txpool.Transaction{ID: "new", Submitter: "p2"},
+			evicted: []string{"p2-2"},
+		},
+		"submitter limit is per client, not per receiving node": {
+			limit: txpool.PoolLimit{MaxTransactionsPerSubmitter: 2, Eviction: txpool.RejectEviction},
+			input: txpool.Transaction{ID: "new", PeerID: "node01", Submitter: "p3"},
+		},
+		"submitter limit with only in-flight transactions": {
+			limit: txpool.PoolLimit{MaxTransactionsPerSubmitter: 1, Eviction: txpool.OldestEviction},
+			input: txpool.Transaction{ID: "new", Submitter: "p2"},
+			err:   txpool.ErrPoolFull,
+		},
+		"byte limit": {
+			limit: txpool.PoolLimit{MaxBytes: 10},
+			input: txpool.Transaction{ID: "new", Submitter: "p1", Args: []string{"0123456789"}},
+			err:   txpool.ErrPoolFull,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Logf("Running test case %s", testName)
+
+		evicted, err := test.limit.Admit(pool, test.input)
+
+		assert.Equal(t, test.err, err)
+		if test.evicted == nil {
+			assert.Empty(t, evicted)
+			continue
+		}
+		assert.Equal(t, test.evicted, ids(evicted))
+	}
+}
+
+func TestNewPoolLimit(t *testing.T) {
+	limit, err := txpool.NewPoolLimit(1, 2, 3, 4, "")
+	assert.NoError(t, err)
+	assert.Equal(t, txpool.RejectEviction, limit.Eviction)
+
+	_, err = txpool.NewPoolLimit(1, 2, 3, 4, "random")
+	assert.Equal(t, txpool.ErrUnknownEvictionPolicy, err)
+}
+
+func TestCapacityService_Add(t *testing.T) {
+
+	//given
+	repo := mem.NewTransactionRepository()
+	dropped := make([]string, 0)
+	eventService := mock.EventService{
+		PublishFunc: func(topic string, e interface{}) error {
+			if topic == "tx.dropped" {
+				dropped = append(dropped, e.(event.TxDropped).TransactionIds...)
+			}
+			return nil
+		},
+	}
+	committedTransactionRepository := mem.NewCommittedTransactionRepository()
+	capacityService := txpool.NewCapacityService(repo, committedTransactionRepository, eventService, txpool.PoolLimit{MaxTransactions: 1, Eviction: txpool.OldestEviction})
+
+	//when
+	_, err := capacityService.Add(txpool.Transaction{ID: "old", TimeStamp: time.Now()})
+	assert.NoError(t, err)
+	evicted, err := capacityService.Add(txpool.Transaction{ID: "new", TimeStamp: time.Now()})
+	assert.NoError(t, err)
+
+	//then
+	assert.Equal(t, []string{"old"}, evicted)
+	transactions, err := repo.FindAll()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"new"}, ids(transactions))
+	assert.Equal(t, []string{"old"}, dropped)
+}
+
+func TestCapacityService_Add_Duplicate(t *testing.T) {
+
+	//given
+	repo := mem.NewTransactionRepository()
+	committedTransactionRepository := mem.NewCommittedTransactionRepository()
+	capacityService := txpool.NewCapacityService(repo, committedTransactionRepository, mock.EventService{PublishFunc: func(topic string, e interface{}) error { return nil }}, txpool.PoolLimit{})
+
+	//when
+	_, err1 := capacityService.Add(txpool.Transaction{ID: "tx01", TimeStamp: time.Now()})
+	_, err2 := capacityService.Add(txpool.Transaction{ID: "tx01", TimeStamp: time.Now()})
+	capacityService.Commit([]txpool.TransactionId{"tx02"})
+	_, err3 := capacityService.Add(txpool.Transaction{ID: "tx02", TimeStamp: time.Now()})
+
+	//then
+	assert.NoError(t, err1)
+	assert.Equal(t, txpool.ErrTransactionAlreadyPending, err2)
+	assert.Equal(t, txpool.ErrTransactionAlreadyCommitted, err3)
+	assert.True(t, committedTransactionRepository.Exists("tx02"))
+}
+
+func TestUsageCounter(t *testing.T) {
+
+	//given
+	repo := mem.NewTransactionRepository()
+	tx01 := txpool.Transaction{ID: "tx01", Submitter: "client01", Args: []string{"a"}}
+	tx02 := txpool.Transaction{ID: "tx02", Submitter: "client02"}
+
+	//when
+	repo.Save(tx01)
+	repo.Save(tx02)
+	repo.Save(tx01)
+
+	//then a saved transaction is counted once
+	assert.Equal(t, txpool.PoolUsage{Submitter: "client01", Transactions: 2, Bytes: tx01.Size() + tx02.Size(), SubmitterTransactions: 1, SubmitterBytes: tx01.Size()}, repo.Usage("client01"))
+
+	//when
+	repo.Remove("tx01")
+	repo.Remove("tx01")
+
+	//then
+	assert.Equal(t, txpool.PoolUsage{Submitter: "client01", Transactions: 1, Bytes: tx02.Size()}, repo.Usage("client01"))
+}
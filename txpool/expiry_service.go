@@ -0,0 +1,108 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package txpool
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/it-chain/engine/common/event"
+	"github.com/it-chain/iLogger"
+)
+
+var ErrTransactionExpired = errors.New("transaction is expired")
+
+// ExpiryService drops the pending transactions whose deadline or max height has passed.
+// In-flight transactions are left to their block, they are dropped if they come back to pending
+type ExpiryService struct {
+	txpoolRepository TransactionRepository
+	eventService     EventService
+	committedHeight  uint64
+	sync.RWMutex
+}
+
+func NewExpiryService(txpoolRepository TransactionRepository, eventService EventService) *ExpiryService {
+	return &ExpiryService{
+		txpoolRepository: txpoolRepository,
+		eventService:     eventService,
+		RWMutex:          sync.RWMutex{},
+	}
+}
+
+// SetCommittedHeight records the height of the last committed block
+func (s *ExpiryService) SetCommittedHeight(height uint64) {
+	s.Lock()
+	defer s.Unlock()
+
+	if height > s.committedHeight {
+		s.committedHeight = height
+	}
+}
+
+// IsExpired tells whether the transaction can be included in the next block
+func (s *ExpiryService) IsExpired(transaction Transaction) bool {
+	s.RLock()
+	defer s.RUnlock()
+
+	return transaction.IsExpired(time.Now(), s.committedHeight+1)
+}
+
+// DropExpiredTransactions removes the expired pending transactions from the pool and returns their IDs
+func (s *ExpiryService) DropExpiredTransactions() ([]TransactionId, error) {
+
+	transactions, err := s.txpoolRepository.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	expired := filter(transactions, func(transaction Transaction) bool {
+		return IsPending(transaction) && s.IsExpired(transaction)
+	})
+
+	if len(expired) == 0 {
+		return nil, nil
+	}
+
+	for _, tx := range expired {
+		s.txpoolRepository.Remove(tx.ID)
+	}
+
+	iLogger.Infof(nil, "[Txpool] Expired transactions are dropped - count: [%d]", len(expired))
+	publishStatus(s.eventService, "tx.expired", event.TxExpired{TransactionIds: transactionIds(expired)})
+
+	return transactionIds(expired), nil
+}
+
+// ExpiryValidator rejects transactions which are already expired when they arrive
+type ExpiryValidator struct {
+	expiryService *ExpiryService
+}
+
+func NewExpiryValidator(expiryService *ExpiryService) ExpiryValidator {
+	return ExpiryValidator{
+		expiryService: expiryService,
+	}
+}
+
+func (v ExpiryValidator) Validate(transaction Transaction) error {
+	if v.expiryService.IsExpired(transaction) {
+		return ErrTransactionExpired
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package txpool_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/it-chain/engine/common/event"
+	"github.com/it-chain/engine/txpool"
+	"github.com/it-chain/engine/txpool/infra/mem"
+	"github.com/it-chain/engine/txpool/test/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpiryService_DropExpiredTransactions(t *testing.T) {
+
+	//given
+	repo := mem.NewTransactionRepository()
+	repo.Save(txpool.Transaction{ID: "tx01"})
+	repo.Save(txpool.Transaction{ID: "tx02", Deadline: time.Now().Add(-time.Minute)})
+	repo.Save(txpool.Transaction{ID: "tx03", MaxHeight: 3})
+	repo.Save(txpool.Transaction{ID: "tx04", MaxHeight: 4})
+	repo.Save(txpool.Transaction{ID: "tx05", Deadline: time.Now().Add(-time.Minute), ProposedAt: time.Now()})
+
+	expired := make([]string, 0)
+	eventService := mock.EventService{
+		PublishFunc: func(topic string, e interface{}) error {
+			if topic == "tx.expired" {
+				expired = append(expired, e.(event.TxExpired).TransactionIds...)
+			}
+			return nil
+		},
+	}
+	expiryService := txpool.NewExpiryService(repo, eventService)
+	expiryService.SetCommittedHeight(3)
+
+	//when
+	dropped, err := expiryService.DropExpiredTransactions()
+
+	//then
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"tx02", "tx03"}, dropped)
+
+	transactions, err := repo.FindAll()
+	assert.NoError(t, err)
+	assert.Len(t, transactions, 3)
+	assert.ElementsMatch(t, []string{"tx02", "tx03"}, expired)
+
+	_, err = repo.FindById("tx05")
+	assert.NoError(t, err)
+}
+
+func TestExpiryService_SetCommittedHeight(t *testing.T) {
+
+	//given
+	expiryService := txpool.NewExpiryService(mem.NewTransactionRepository(), mock.EventService{})
+	tx := txpool.Transaction{ID: "tx01", MaxHeight: 5}
+
+	//when
+	expiryService.SetCommittedHeight(5)
+	expiryService.SetCommittedHeight(2)
+
+	//then
+	assert.True(t, expiryService.IsExpired(tx))
+	assert.Equal(t, txpool.ErrTransactionExpired, txpool.NewExpiryValidator(expiryService).Validate(tx))
+	assert.NoError(t, txpool.NewExpiryValidator(expiryService).Validate(txpool.Transaction{ID: "tx02"}))
+}
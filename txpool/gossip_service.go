@@ -0,0 +1,214 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package txpool
+
+import (
+	"sync"
+	"time"
+
+	"github.com/it-chain/engine/common"
+	"github.com/it-chain/engine/common/command"
+	"github.com/it-chain/engine/common/event"
+	"github.com/it-chain/iLogger"
+	"github.com/rs/xid"
+)
+
+// GossipService spreads the transactions of the pool to every validator,
+// so whoever proposes next holds them and a leader failover does not lose them.
+// The seen-set records which peer is known to hold which transaction, and a transaction is sent to a peer
+// again only after resendInterval, in case the delivery was lost.
+// The transactions are sent in messages of at most maxMessageBytes, zero means a single message
+type GossipService struct {
+	txpoolRepository TransactionRepository
+	peerRepository   PeerRepository
+	eventService     EventService
+	resendInterval   time.Duration
+	maxMessageBytes  int
+	seen             map[TransactionId]map[string]time.Time
+	sync.Mutex
+}
+
+func NewGossipService(txpoolRepository TransactionRepository, peerRepository PeerRepository, eventService EventService, resendInterval time.Duration, maxMessageBytes int) *GossipService {
+	return &GossipService{
+		txpoolRepository: txpoolRepository,
+		peerRepository:   peerRepository,
+		eventService:     eventService,
+		resendInterval:   resendInterval,
+		maxMessageBytes:  maxMessageBytes,
+		seen:             make(map[TransactionId]map[string]time.Time),
+		Mutex:            sync.Mutex{},
+	}
+}
+
+// GossipTransactions sends every peer the transactions of the pool it is not known to hold.
+// In-flight transactions are gossiped too, they are lost with the leader otherwise
+func (g *GossipService) GossipTransactions() error {
+
+	g.Lock()
+	defer g.Unlock()
+
+	transactions, err := g.txpoolRepository.FindAll()
+	if err != nil {
+		return err
+	}
+
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	forwarded := make(map[TransactionId]struct{})
+
+	for _, peerID := range g.peerRepository.FindAll() {
+		unseen := filter(transactions, func(tx Transaction) bool {
+			return !g.isSeen(tx.ID, peerID, now)
+		})
+
+		if len(unseen) == 0 {
+			continue
+		}
+
+		// the messages sent before a failure are marked, the rest is sent again with the next gossip
+		for _, message := range chunk(unseen, g.maxMessageBytes) {
+			if err := g.send(peerID, message); err != nil {
+				iLogger.Errorf(nil, "[Txpool] Fail to gossip transactions - PeerID: [%s], Err: [%s]", peerID, err.Error())
+				break
+			}
+
+			for _, tx := range message {
+				g.markSeen(tx.ID, peerID, now)
+				forwarded[tx.ID] = struct{}{}
+			}
+		}
+	}
+
+	if len(forwarded) != 0 {
+		ids := make([]string, 0)
+		for _, tx := range transactions {
+			if _, ok := forwarded[tx.ID]; ok {
+				ids = append(ids, tx.ID)
+			}
+		}
+
+		publishStatus(g.eventService, "tx.forwarded", event.TxForwarded{TransactionIds: ids})
+	}
+
+	return nil
+}
+
+// MarkSeen records that the peer holds the transactions, so they are not sent back to it
+func (g *GossipService) MarkSeen(peerID string, ids []TransactionId) {
+
+	g.Lock()
+	defer g.Unlock()
+
+	now := time.Now()
+	for _, id := range ids {
+		g.markSeen(id, peerID, now)
+	}
+}
+
+// Forget drops the transactions which left the pool from the seen-set,
+// the committed, expired, evicted and dropped ones
+func (g *GossipService) Forget(ids []TransactionId) {
+
+	g.Lock()
+	defer g.Unlock()
+
+	for _, id := range ids {
+		delete(g.seen, id)
+	}
+}
+
+func (g *GossipService) isSeen(id TransactionId, peerID string, now time.Time) bool {
+	seenAt, ok := g.seen[id][peerID]
+	if !ok {
+		return false
+	}
+
+	return now.Sub(seenAt) < g.resendInterval
+}
+
+func (g *GossipService) markSeen(id TransactionId, peerID string, now time.Time) {
+	if _, ok := g.seen[id]; !ok {
+		g.seen[id] = make(map[string]time.Time)
+	}
+
+	g.seen[id][peerID] = now
+}
+
+func (g *GossipService) send(peerID string, transactions []Transaction) error {
+	deliverCommand, err := createGrpcDeliverCommand(GossipTransactionsProtocol, transactions)
+	if err != nil {
+		return err
+	}
+
+	deliverCommand.RecipientList = append(deliverCommand.RecipientList, peerID)
+
+	if err := g.eventService.Publish("message.deliver", deliverCommand); err != nil {
+		return err
+	}
+
+	iLogger.Debugf(nil, "[Txpool] Transactions are gossiped - PeerID: [%s], count: [%d]", peerID, len(transactions))
+
+	return nil
+}
+
+// chunk splits the transactions into messages of at most maxBytes in order,
+// a transaction larger than maxBytes is sent alone. Zero maxBytes keeps them in one message
+func chunk(transactions []Transaction, maxBytes int) [][]Transaction {
+	if maxBytes <= 0 {
+		return [][]Transaction{transactions}
+	}
+
+	messages := make([][]Transaction, 0)
+	message := make([]Transaction, 0)
+	messageBytes := 0
+
+	for _, tx := range transactions {
+		if len(message) != 0 && messageBytes+tx.Size() > maxBytes {
+			messages = append(messages, message)
+			message = make([]Transaction, 0)
+			messageBytes = 0
+		}
+
+		message = append(message, tx)
+		messageBytes += tx.Size()
+	}
+
+	if len(message) != 0 {
+		messages = append(messages, message)
+	}
+
+	return messages
+}
+
+func createGrpcDeliverCommand(protocol string, body interface{}) (command.DeliverGrpc, error) {
+
+	data, err := common.Serialize(body)
+
+	if err != nil {
+		return command.DeliverGrpc{}, err
+	}
+
+	return command.DeliverGrpc{
+		MessageId:     xid.New().String(),
+		RecipientList: make([]string, 0),
+		Body:          data,
+		Protocol:      protocol,
+	}, err
+}
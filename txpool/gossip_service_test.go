@@ -0,0 +1,135 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package txpool_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/it-chain/engine/common"
+	"github.com/it-chain/engine/common/command"
+	"github.com/it-chain/engine/txpool"
+	"github.com/it-chain/engine/txpool/infra/mem"
+	"github.com/it-chain/engine/txpool/test/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGossipService_GossipTransactions(t *testing.T) {
+
+	//given
+	repo := mem.NewTransactionRepository()
+	repo.Save(txpool.Transaction{ID: "tx01"})
+	repo.Save(txpool.Transaction{ID: "tx02", ProposedAt: time.Now()})
+
+	peerRepo := mem.NewPeerRepository()
+	peerRepo.Add("peer01")
+	peerRepo.Add("peer02")
+
+	delivered := make(map[string][]string)
+	eventService := mock.EventService{
+		PublishFunc: func(topic string, event interface{}) error {
+			if topic != "message.deliver" {
+				return nil
+			}
+
+			deliverCommand := event.(command.DeliverGrpc)
+			assert.Equal(t, txpool.GossipTransactionsProtocol, deliverCommand.Protocol)
+
+			transactions := []txpool.Transaction{}
+			assert.NoError(t, common.Deserialize(deliverCommand.Body, &transactions))
+
+			peerID := deliverCommand.RecipientList[0]
+			delivered[peerID] = append(delivered[peerID], ids(transactions)...)
+			return nil
+		},
+	}
+	gossipService := txpool.NewGossipService(repo, peerRepo, eventService, time.Hour, 0)
+
+	// peer02 gossiped tx01 to this node
+	gossipService.MarkSeen("peer02", []txpool.TransactionId{"tx01"})
+
+	//when
+	assert.NoError(t, gossipService.GossipTransactions())
+	assert.NoError(t, gossipService.GossipTransactions())
+
+	//then
+	assert.Equal(t, []string{"tx01", "tx02"}, delivered["peer01"])
+	assert.Equal(t, []string{"tx02"}, delivered["peer02"])
+}
+
+func TestGossipService_GossipTransactions_Resend(t *testing.T) {
+
+	//given
+	repo := mem.NewTransactionRepository()
+	repo.Save(txpool.Transaction{ID: "tx01"})
+
+	peerRepo := mem.NewPeerRepository()
+	peerRepo.Add("peer01")
+
+	sent := 0
+	eventService := mock.EventService{
+		PublishFunc: func(topic string, event interface{}) error {
+			if topic == "message.deliver" {
+				sent++
+			}
+			return nil
+		},
+	}
+	gossipService := txpool.NewGossipService(repo, peerRepo, eventService, 0, 0)
+
+	//when
+	assert.NoError(t, gossipService.GossipTransactions())
+	assert.NoError(t, gossipService.GossipTransactions())
+
+	//then
+	assert.Equal(t, 2, sent)
+}
+
+func TestGossipService_GossipTransactions_Chunk(t *testing.T) {
+
+	//given
+	repo := mem.NewTransactionRepository()
+	repo.Save(txpool.Transaction{ID: "tx01", Args: []string{"0123456789"}, TimeStamp: time.Now()})
+	repo.Save(txpool.Transaction{ID: "tx02", Args: []string{"0123456789"}, TimeStamp: time.Now().Add(time.Second)})
+	repo.Save(txpool.Transaction{ID: "tx03", Args: []string{"0123456789"}, TimeStamp: time.Now().Add(2 * time.Second)})
+
+	peerRepo := mem.NewPeerRepository()
+	peerRepo.Add("peer01")
+
+	messages := make([][]string, 0)
+	eventService := mock.EventService{
+		PublishFunc: func(topic string, event interface{}) error {
+			if topic != "message.deliver" {
+				return nil
+			}
+
+			transactions := []txpool.Transaction{}
+			assert.NoError(t, common.Deserialize(event.(command.DeliverGrpc).Body, &transactions))
+			messages = append(messages, ids(transactions))
+			return nil
+		},
+	}
+
+	// each transaction takes 14 bytes, so two of them fit in a message
+	gossipService := txpool.NewGossipService(repo, peerRepo, eventService, time.Hour, 30)
+
+	//when
+	assert.NoError(t, gossipService.GossipTransactions())
+
+	//then
+	assert.Equal(t, [][]string{{"tx01", "tx02"}, {"tx03"}}, messages)
+}
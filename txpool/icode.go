@@ -0,0 +1,28 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package txpool
+
+// ICodeRepository is the registry of the icodes deployed on this node,
+// transactions to other icodes can not be executed
+type ICodeRepository interface {
+	// Add registers the icode, transactions can also be sent to its name unless the name is empty
+	Add(icodeID string, name string)
+
+	// Remove unregisters the icode, and its name once no other icode has it
+	Remove(icodeID string)
+	Exists(icodeID string) bool
+}
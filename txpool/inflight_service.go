@@ -0,0 +1,115 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package txpool
+
+import (
+	"sync"
+	"time"
+
+	"github.com/it-chain/engine/common/event"
+	"github.com/it-chain/iLogger"
+)
+
+// InFlightService keeps track of the transactions which are proposed or sent to the leader.
+// They are removed from the pool when their block is committed,
+// and go back to pending when the round fails, the leader changes or no commit arrives in time
+type InFlightService struct {
+	txpoolRepository TransactionRepository
+	eventService     EventService
+	timeout          time.Duration
+	sync.Mutex
+}
+
+func NewInFlightService(txpoolRepository TransactionRepository, eventService EventService, timeout time.Duration) *InFlightService {
+	return &InFlightService{
+		txpoolRepository: txpoolRepository,
+		eventService:     eventService,
+		timeout:          timeout,
+		Mutex:            sync.Mutex{},
+	}
+}
+
+func (s *InFlightService) RemoveCommittedTransactions(ids []TransactionId) {
+
+	s.Lock()
+	defer s.Unlock()
+
+	for _, id := range ids {
+		s.txpoolRepository.Remove(id)
+	}
+}
+
+// ReleaseTransactions puts every in-flight transaction back to pending
+func (s *InFlightService) ReleaseTransactions() error {
+
+	s.Lock()
+	defer s.Unlock()
+
+	return s.release(func(transaction Transaction) bool {
+		return transaction.IsInFlight()
+	})
+}
+
+// ReleaseExpiredTransactions puts the transactions which are in flight longer than the timeout back to pending
+func (s *InFlightService) ReleaseExpiredTransactions() error {
+
+	s.Lock()
+	defer s.Unlock()
+
+	now := time.Now()
+
+	return s.release(func(transaction Transaction) bool {
+		return transaction.IsInFlight() && now.Sub(transaction.ProposedAt) > s.timeout
+	})
+}
+
+func (s *InFlightService) release(f func(Transaction) bool) error {
+
+	transactions, err := s.txpoolRepository.FindAll()
+	if err != nil {
+		return err
+	}
+
+	released := filter(transactions, f)
+	for _, tx := range released {
+		tx.ProposedAt = time.Time{}
+		if err := s.txpoolRepository.Save(tx); err != nil {
+			return err
+		}
+	}
+
+	if len(released) != 0 {
+		iLogger.Infof(nil, "[Txpool] In-flight transactions are back to pending - count: [%d]", len(released))
+		publishStatus(s.eventService, "tx.pending", event.TxPending{TransactionIds: transactionIds(released)})
+	}
+
+	return nil
+}
+
+func markInFlight(txpoolRepository TransactionRepository, transactions []Transaction) error {
+
+	proposedAt := time.Now()
+
+	for _, tx := range transactions {
+		tx.ProposedAt = proposedAt
+		if err := txpoolRepository.Save(tx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
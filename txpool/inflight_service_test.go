@@ -0,0 +1,152 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package txpool_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/it-chain/engine/common/event"
+	"github.com/it-chain/engine/txpool"
+	"github.com/it-chain/engine/txpool/infra/mem"
+	"github.com/it-chain/engine/txpool/test/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockProposalService_ProposeBlock_KeepsInFlightTransactions(t *testing.T) {
+
+	//given
+	repo := mem.NewTransactionRepository()
+	repo.Save(txpool.Transaction{ID: "tx01"})
+	repo.Save(txpool.Transaction{ID: "tx02"})
+
+	proposed := 0
+	eventService := mock.EventService{
+		PublishFunc: func(topic string, event interface{}) error {
+			if topic == "block.propose" {
+				proposed++
+			}
+			return nil
+		},
+	}
+	blockProposalService := txpool.NewBlockProposalService(repo, eventService, txpool.FifoPolicy{})
+
+	//when
+	assert.NoError(t, blockProposalService.ProposeBlock())
+	assert.NoError(t, blockProposalService.ProposeBlock())
+
+	//then
+	transactions, err := repo.FindAll()
+	assert.NoError(t, err)
+	assert.Len(t, transactions, 2)
+	for _, tx := range transactions {
+		assert.True(t, tx.IsInFlight())
+	}
+	assert.Equal(t, 1, proposed)
+}
+
+func TestBlockProposalService_ProposeBlock_PublishFailed(t *testing.T) {
+
+	//given
+	repo := mem.NewTransactionRepository()
+	repo.Save(txpool.Transaction{ID: "tx01"})
+
+	eventService := mock.EventService{
+		PublishFunc: func(topic string, event interface{}) error {
+			return errors.New("publish failed")
+		},
+	}
+	blockProposalService := txpool.NewBlockProposalService(repo, eventService, txpool.FifoPolicy{})
+
+	//when
+	assert.Error(t, blockProposalService.ProposeBlock())
+
+	//then
+	tx, err := repo.FindById("tx01")
+	assert.NoError(t, err)
+	assert.False(t, tx.IsInFlight())
+}
+
+func TestInFlightService_RemoveCommittedTransactions(t *testing.T) {
+
+	//given
+	repo := mem.NewTransactionRepository()
+	repo.Save(txpool.Transaction{ID: "tx01", ProposedAt: time.Now()})
+	repo.Save(txpool.Transaction{ID: "tx02", ProposedAt: time.Now()})
+	inFlightService := txpool.NewInFlightService(repo, mock.EventService{}, time.Minute)
+
+	//when
+	inFlightService.RemoveCommittedTransactions([]txpool.TransactionId{"tx01"})
+
+	//then
+	_, err := repo.FindById("tx01")
+	assert.Equal(t, mem.ErrTransactionDoesNotExist, err)
+
+	tx, err := repo.FindById("tx02")
+	assert.NoError(t, err)
+	assert.True(t, tx.IsInFlight())
+}
+
+func TestInFlightService_ReleaseExpiredTransactions(t *testing.T) {
+
+	//given
+	repo := mem.NewTransactionRepository()
+	repo.Save(txpool.Transaction{ID: "expired", ProposedAt: time.Now().Add(-time.Hour)})
+	repo.Save(txpool.Transaction{ID: "recent", ProposedAt: time.Now()})
+
+	pending := make([]string, 0)
+	eventService := mock.EventService{
+		PublishFunc: func(topic string, e interface{}) error {
+			assert.Equal(t, "tx.pending", topic)
+			pending = append(pending, e.(event.TxPending).TransactionIds...)
+			return nil
+		},
+	}
+	inFlightService := txpool.NewInFlightService(repo, eventService, time.Minute)
+
+	//when
+	assert.NoError(t, inFlightService.ReleaseExpiredTransactions())
+
+	//then
+	expired, _ := repo.FindById("expired")
+	assert.False(t, expired.IsInFlight())
+
+	recent, _ := repo.FindById("recent")
+	assert.True(t, recent.IsInFlight())
+	assert.Equal(t, []string{"expired"}, pending)
+}
+
+func TestInFlightService_ReleaseTransactions(t *testing.T) {
+
+	//given
+	repo := mem.NewTransactionRepository()
+	repo.Save(txpool.Transaction{ID: "tx01", ProposedAt: time.Now()})
+	eventService := mock.EventService{
+		PublishFunc: func(topic string, event interface{}) error {
+			return nil
+		},
+	}
+	inFlightService := txpool.NewInFlightService(repo, eventService, time.Minute)
+
+	//when
+	assert.NoError(t, inFlightService.ReleaseTransactions())
+
+	//then
+	tx, _ := repo.FindById("tx01")
+	assert.False(t, tx.IsInFlight())
+}
@@ -0,0 +1,49 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"github.com/it-chain/engine/common/event"
+	"github.com/it-chain/engine/txpool"
+)
+
+type CommittedTransactionApi interface {
+	RemoveCommittedTransactions(ids []txpool.TransactionId)
+	SetCommittedHeight(height uint64)
+}
+
+type BlockCommittedEventHandler struct {
+	transactionApi CommittedTransactionApi
+}
+
+func NewBlockCommittedEventHandler(transactionApi CommittedTransactionApi) *BlockCommittedEventHandler {
+	return &BlockCommittedEventHandler{
+		transactionApi: transactionApi,
+	}
+}
+
+// transactions are removed from the pool only when the block which contains them is committed
+func (b *BlockCommittedEventHandler) HandleBlockCommittedEvent(event event.BlockCommitted) {
+
+	ids := make([]txpool.TransactionId, 0)
+	for _, tx := range event.TxList {
+		ids = append(ids, tx.ID)
+	}
+
+	b.transactionApi.RemoveCommittedTransactions(ids)
+	b.transactionApi.SetCommittedHeight(event.Height)
+}
@@ -0,0 +1,46 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"github.com/it-chain/engine/common"
+	"github.com/it-chain/engine/common/event"
+	"github.com/it-chain/engine/txpool"
+)
+
+type ConnectionEventHandler struct {
+	peerRepository txpool.PeerRepository
+}
+
+func NewConnectionEventHandler(peerRepository txpool.PeerRepository) *ConnectionEventHandler {
+	return &ConnectionEventHandler{
+		peerRepository: peerRepository,
+	}
+}
+
+// observers never propose a block, so pending transactions are gossiped only to validators
+func (c *ConnectionEventHandler) HandleConnectionCreatedEvent(event event.ConnectionCreated) {
+	if event.Role == common.ObserverRole {
+		return
+	}
+
+	c.peerRepository.Add(event.ConnectionID)
+}
+
+func (c *ConnectionEventHandler) HandleConnectionClosedEvent(event event.ConnectionClosed) {
+	c.peerRepository.Remove(event.ConnectionID)
+}
@@ -24,7 +24,7 @@ import (
 )
 
 type transactionApiForSave interface {
-	SaveTransactions(transactions []txpool.Transaction) error
+	ReceiveTransactions(peerID string, transactions []txpool.Transaction) error
 }
 
 type GrpcMessageHandler struct {
@@ -44,14 +44,17 @@ func (g GrpcMessageHandler) HandleMessageReceiveCommand(command command.ReceiveG
 	body := command.Body
 
 	switch protocol {
-	case txpool.SendTransactionsToLeader:
+	case txpool.GossipTransactionsProtocol:
 		transactionList := []txpool.Transaction{}
 
 		if err := common.Deserialize(body, &transactionList); err != nil {
 			iLogger.Errorf(nil, "[Txpool] Fail to deserialize grpcMessage - Err: [%s]", err.Error())
+			return
 		}
 
-		g.transactionApi.SaveTransactions(transactionList)
+		if err := g.transactionApi.ReceiveTransactions(command.ConnectionID, transactionList); err != nil {
+			iLogger.Errorf(nil, "[Txpool] Fail to save gossiped transactions - Err: [%s]", err.Error())
+		}
 
 	}
 
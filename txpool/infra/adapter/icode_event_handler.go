@@ -0,0 +1,50 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"github.com/it-chain/engine/common/event"
+	"github.com/it-chain/engine/txpool"
+)
+
+// ICodeEventHandler keeps the icode registry of the validators up to date with the deployments of ivm
+type ICodeEventHandler struct {
+	icodeRepository txpool.ICodeRepository
+}
+
+func NewICodeEventHandler(icodeRepository txpool.ICodeRepository) *ICodeEventHandler {
+	return &ICodeEventHandler{
+		icodeRepository: icodeRepository,
+	}
+}
+
+func (i *ICodeEventHandler) HandleICodeCreatedEvent(event event.ICodeCreated) {
+	i.icodeRepository.Add(event.ID, event.Name)
+}
+
+// the name of a deleted icode is removed with it, unless another version still has the name
+func (i *ICodeEventHandler) HandleICodeDeletedEvent(event event.ICodeDeleted) {
+	i.icodeRepository.Remove(event.ICodeID)
+}
+
+// Restore registers the icodes running on this node, as when the node restarts
+// and the icodes are started before the handler subscribes to their events
+func (i *ICodeEventHandler) Restore(icodes []event.ICodeCreated) {
+	for _, icode := range icodes {
+		i.HandleICodeCreatedEvent(icode)
+	}
+}
@@ -19,26 +19,54 @@ package adapter
 import (
 	"github.com/it-chain/engine/common/event"
 	"github.com/it-chain/engine/txpool"
+	"github.com/it-chain/iLogger"
 )
 
+type InFlightTransactionApi interface {
+	ReleaseInFlightTransactions() error
+}
+
 type LeaderEventHandler struct {
 	leaderRepository txpool.LeaderRepository
+	transactionApi   InFlightTransactionApi
 }
 
-func NewLeaderEventHandler(leaderRepository txpool.LeaderRepository) *LeaderEventHandler {
+func NewLeaderEventHandler(leaderRepository txpool.LeaderRepository, transactionApi InFlightTransactionApi) *LeaderEventHandler {
 	return &LeaderEventHandler{
 		leaderRepository: leaderRepository,
+		transactionApi:   transactionApi,
 	}
 
 }
 
+// transactions in flight to the previous leader may never be committed,
+// so they go back to pending and are sent to the new leader
 func (l LeaderEventHandler) HandleLeaderUpdatedEvent(event event.LeaderUpdated) error {
 	Leader := txpool.Leader{
 		Id: event.LeaderId,
 	}
 
+	previous := l.leaderRepository.Get()
 	l.leaderRepository.Set(Leader)
 
-	return nil
+	if previous.Id == "" || previous.Id == Leader.Id {
+		return nil
+	}
+
+	return l.releaseInFlightTransactions()
+}
 
+func (l LeaderEventHandler) HandleLeaderDeletedEvent(event event.LeaderDeleted) error {
+	l.leaderRepository.Set(txpool.Leader{})
+
+	return l.releaseInFlightTransactions()
+}
+
+func (l LeaderEventHandler) releaseInFlightTransactions() error {
+	if err := l.transactionApi.ReleaseInFlightTransactions(); err != nil {
+		iLogger.Errorf(nil, "[Txpool] Fail to release in-flight transactions - Err: [%s]", err.Error())
+		return err
+	}
+
+	return nil
 }
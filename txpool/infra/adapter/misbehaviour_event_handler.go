@@ -0,0 +1,75 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package adapter
+
+import (
+	"strconv"
+
+	"github.com/it-chain/engine/common"
+	"github.com/it-chain/engine/common/event"
+	"github.com/it-chain/engine/txpool"
+	"github.com/it-chain/iLogger"
+)
+
+type GovernanceTransactionApi interface {
+	CreateTransaction(txData txpool.TxData) (txpool.Transaction, error)
+}
+
+// MisbehaviourEventHandler votes for removing the offender of a detected misbehaviour
+// from the validator set, by creating a governance transaction signed with the key of the node.
+type MisbehaviourEventHandler struct {
+	transactionApi GovernanceTransactionApi
+	signer         common.Signer
+}
+
+func NewMisbehaviourEventHandler(transactionApi GovernanceTransactionApi, signer common.Signer) *MisbehaviourEventHandler {
+	return &MisbehaviourEventHandler{
+		transactionApi: transactionApi,
+		signer:         signer,
+	}
+}
+
+func (m *MisbehaviourEventHandler) HandleConsensusMisbehaviourEvent(event event.ConsensusMisbehaviour) error {
+	effectiveHeight := event.Height + common.MisbehaviourRemovalDelay
+
+	// the proposal id is derived from the misbehaviour, so every detecting node votes for the same proposal.
+	// The key is the proposal id, so a misbehaviour detected again is rejected as a duplicate vote
+	proposalId := common.RemoveValidatorFunction + "/" + event.OffenderID + "/" + strconv.FormatUint(effectiveHeight, 10)
+	txData := txpool.TxData{
+		Jsonrpc:        "2.0",
+		ICodeID:        common.GovernanceICodeID,
+		Function:       common.RemoveValidatorFunction,
+		Args:           []string{event.OffenderID, strconv.FormatUint(effectiveHeight, 10), proposalId},
+		IdempotencyKey: proposalId,
+	}
+
+	signature, err := common.SignTransaction(m.signer, common.TransactionSigningData(txData.Jsonrpc, txData.ICodeID, txData.Function, txData.Args, txData.IdempotencyKey, txData.Deadline, txData.MaxHeight))
+	if err != nil {
+		iLogger.Errorf(nil, "[Txpool] Fail to sign the vote for removing misbehaving validator - OffenderID: [%s], Err: [%s]", event.OffenderID, err.Error())
+		return err
+	}
+	txData.Signature = signature
+
+	if _, err := m.transactionApi.CreateTransaction(txData); err != nil {
+		iLogger.Errorf(nil, "[Txpool] Fail to vote for removing misbehaving validator - OffenderID: [%s], Err: [%s]", event.OffenderID, err.Error())
+		return err
+	}
+
+	iLogger.Infof(nil, "[Txpool] Voted for removing misbehaving validator - OffenderID: [%s], EffectiveHeight: [%d]", event.OffenderID, effectiveHeight)
+
+	return nil
+}
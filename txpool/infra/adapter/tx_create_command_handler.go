@@ -25,29 +25,82 @@ import (
 
 type TxCommandHandler struct {
 	transactionApi *api.TransactionApi
+	// the most transactions of a batch, zero means no limit
+	maxBatchTransactions int
 }
 
-func NewTxCommandHandler(transactionApi *api.TransactionApi) *TxCommandHandler {
+func NewTxCommandHandler(transactionApi *api.TransactionApi, maxBatchTransactions int) *TxCommandHandler {
 	return &TxCommandHandler{
-		transactionApi: transactionApi,
+		transactionApi:       transactionApi,
+		maxBatchTransactions: maxBatchTransactions,
 	}
 }
 
 func (t *TxCommandHandler) HandleTxCreateCommand(txCreateCommand command.CreateTransaction) (txpool.Transaction, rpc.Error) {
 
-	txData := txpool.TxData{
-		ICodeID:   txCreateCommand.ICodeID,
-		Jsonrpc:   txCreateCommand.Jsonrpc,
-		Function:  txCreateCommand.Function,
-		Signature: txCreateCommand.Signature,
-		Args:      txCreateCommand.Args,
+	tx, err := t.transactionApi.CreateTransaction(toTxData(txCreateCommand))
+	if err != nil {
+		return txpool.Transaction{}, toRpcError(err)
 	}
 
-	tx, err := t.transactionApi.CreateTransaction(txData)
+	return tx, rpc.Error{}
+}
+
+// a batch larger than the limit is rejected as a whole, so it can not get around the capacity of the pool
+func (t *TxCommandHandler) HandleTxCreateBatchCommand(txCreateCommands command.CreateTransactions) (command.CreateTransactionsResult, rpc.Error) {
 
-	if err != nil {
-		return txpool.Transaction{}, rpc.Error{Message: err.Error()}
+	if t.maxBatchTransactions != 0 && len(txCreateCommands.Transactions) > t.maxBatchTransactions {
+		return command.CreateTransactionsResult{}, toRpcError(txpool.ErrBatchTooLarge)
 	}
 
-	return tx, rpc.Error{}
+	txDatas := make([]txpool.TxData, 0, len(txCreateCommands.Transactions))
+	for _, txCreateCommand := range txCreateCommands.Transactions {
+		txDatas = append(txDatas, toTxData(txCreateCommand))
+	}
+
+	transactions, errs := t.transactionApi.CreateTransactions(txDatas)
+
+	results := make([]command.CreateTransactionResult, 0, len(transactions))
+	for i, tx := range transactions {
+		if errs[i] != nil {
+			rpcErr := toRpcError(errs[i])
+			results = append(results, command.CreateTransactionResult{Error: rpcErr.Message, Code: rpcErr.Code})
+			continue
+		}
+
+		results = append(results, command.CreateTransactionResult{TransactionId: tx.ID})
+	}
+
+	return command.CreateTransactionsResult{Results: results}, rpc.Error{}
+}
+
+func toTxData(txCreateCommand command.CreateTransaction) txpool.TxData {
+	return txpool.TxData{
+		ICodeID:        txCreateCommand.ICodeID,
+		Jsonrpc:        txCreateCommand.Jsonrpc,
+		Function:       txCreateCommand.Function,
+		Signature:      txCreateCommand.Signature,
+		Args:           txCreateCommand.Args,
+		IdempotencyKey: txCreateCommand.IdempotencyKey,
+		Deadline:       txCreateCommand.Deadline,
+		MaxHeight:      txCreateCommand.MaxHeight,
+	}
+}
+
+// the codes let the callers tell a full pool and an invalid transaction from the other errors
+func toRpcError(err error) rpc.Error {
+
+	if err == txpool.ErrPoolFull {
+		return rpc.Error{Message: err.Error(), Code: txpool.ErrCodePoolFull}
+	}
+
+	if err == txpool.ErrBatchTooLarge {
+		return rpc.Error{Message: err.Error(), Code: txpool.ErrCodeBatchTooLarge}
+	}
+
+	if _, ok := err.(txpool.InvalidTransactionError); ok {
+		return rpc.Error{Message: err.Error(), Code: txpool.ErrCodeInvalidTransaction}
+	}
+
+	return rpc.Error{Message: err.Error()}
 }
@@ -0,0 +1,59 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"sync"
+
+	"github.com/it-chain/engine/txpool"
+)
+
+type CommittedTransactionRepository struct {
+	committed map[txpool.TransactionId]struct{}
+	sync.RWMutex
+}
+
+func NewCommittedTransactionRepository() *CommittedTransactionRepository {
+	return &CommittedTransactionRepository{
+		committed: make(map[txpool.TransactionId]struct{}),
+		RWMutex:   sync.RWMutex{},
+	}
+}
+
+func (m *CommittedTransactionRepository) Save(id txpool.TransactionId) error {
+
+	m.Lock()
+	defer m.Unlock()
+
+	if id == "" {
+		return ErrEmptyID
+	}
+
+	m.committed[id] = struct{}{}
+
+	return nil
+}
+
+func (m *CommittedTransactionRepository) Exists(id txpool.TransactionId) bool {
+
+	m.RLock()
+	defer m.RUnlock()
+
+	_, ok := m.committed[id]
+
+	return ok
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import "sync"
+
+// ICodeRepository keeps the name of each icode, the versions of an icode share their name
+type ICodeRepository struct {
+	icodes map[string]string
+	names  map[string]map[string]struct{}
+	sync.RWMutex
+}
+
+func NewICodeRepository() *ICodeRepository {
+	return &ICodeRepository{
+		icodes:  make(map[string]string),
+		names:   make(map[string]map[string]struct{}),
+		RWMutex: sync.RWMutex{},
+	}
+}
+
+func (m *ICodeRepository) Add(icodeID string, name string) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.remove(icodeID)
+	m.icodes[icodeID] = name
+
+	if name == "" {
+		return
+	}
+
+	if _, ok := m.names[name]; !ok {
+		m.names[name] = make(map[string]struct{})
+	}
+	m.names[name][icodeID] = struct{}{}
+}
+
+func (m *ICodeRepository) Remove(icodeID string) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.remove(icodeID)
+}
+
+func (m *ICodeRepository) remove(icodeID string) {
+	name, ok := m.icodes[icodeID]
+	if !ok {
+		return
+	}
+	delete(m.icodes, icodeID)
+
+	if name == "" {
+		return
+	}
+
+	delete(m.names[name], icodeID)
+	if len(m.names[name]) == 0 {
+		delete(m.names, name)
+	}
+}
+
+func (m *ICodeRepository) Exists(icodeID string) bool {
+	m.RLock()
+	defer m.RUnlock()
+
+	if _, ok := m.icodes[icodeID]; ok {
+		return true
+	}
+
+	_, ok := m.names[icodeID]
+
+	return ok
+}
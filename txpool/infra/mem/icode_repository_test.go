@@ -0,0 +1,52 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem_test
+
+import (
+	"testing"
+
+	"github.com/it-chain/engine/txpool/infra/mem"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestICodeRepository_Remove(t *testing.T) {
+	// given
+	icodeRepository := mem.NewICodeRepository()
+	icodeRepository.Add("local", "")
+	icodeRepository.Add("v1", "token")
+	icodeRepository.Add("v2", "token")
+
+	// then
+	assert.True(t, icodeRepository.Exists("local"))
+	assert.True(t, icodeRepository.Exists("token"))
+
+	// when
+	icodeRepository.Remove("v1")
+
+	// then
+	assert.False(t, icodeRepository.Exists("v1"))
+	assert.True(t, icodeRepository.Exists("token"))
+
+	// when
+	icodeRepository.Remove("v2")
+	icodeRepository.Remove("local")
+
+	// then
+	assert.False(t, icodeRepository.Exists("v2"))
+	assert.False(t, icodeRepository.Exists("token"))
+	assert.False(t, icodeRepository.Exists("local"))
+}
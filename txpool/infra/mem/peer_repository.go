@@ -0,0 +1,61 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"sort"
+	"sync"
+)
+
+type PeerRepository struct {
+	peers map[string]struct{}
+	sync.RWMutex
+}
+
+func NewPeerRepository() *PeerRepository {
+	return &PeerRepository{
+		peers:   make(map[string]struct{}),
+		RWMutex: sync.RWMutex{},
+	}
+}
+
+func (m *PeerRepository) Add(peerID string) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.peers[peerID] = struct{}{}
+}
+
+func (m *PeerRepository) Remove(peerID string) {
+	m.Lock()
+	defer m.Unlock()
+
+	delete(m.peers, peerID)
+}
+
+func (m *PeerRepository) FindAll() []string {
+	m.RLock()
+	defer m.RUnlock()
+
+	peers := make([]string, 0)
+	for peerID := range m.peers {
+		peers = append(peers, peerID)
+	}
+	sort.Strings(peers)
+
+	return peers
+}
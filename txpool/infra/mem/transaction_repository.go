@@ -29,12 +29,14 @@ var ErrEmptyID = errors.New("transaction ID is empty")
 func NewTransactionRepository() *TransactionRepository {
 	return &TransactionRepository{
 		TxMap:   make(map[txpool.TransactionId]txpool.Transaction),
+		usage:   txpool.NewUsageCounter(),
 		RWMutex: sync.RWMutex{},
 	}
 }
 
 type TransactionRepository struct {
 	TxMap map[txpool.TransactionId]txpool.Transaction
+	usage *txpool.UsageCounter
 	sync.RWMutex
 }
 
@@ -49,17 +51,36 @@ func (m *TransactionRepository) Save(transaction txpool.Transaction) error {
 		return ErrEmptyID
 	}
 
+	if old, ok := m.TxMap[id]; ok {
+		m.usage.Remove(old)
+	}
+
 	m.TxMap[id] = transaction
+	m.usage.Add(transaction)
 
 	return nil
 }
 
 func (m *TransactionRepository) Remove(id txpool.TransactionId) {
+	m.Lock()
+	defer m.Unlock()
+
+	if old, ok := m.TxMap[id]; ok {
+		m.usage.Remove(old)
+	}
+
 	delete(m.TxMap, id)
 }
 
+func (m *TransactionRepository) Usage(submitter string) txpool.PoolUsage {
+	return m.usage.Usage(submitter)
+}
+
 func (m *TransactionRepository) FindById(id txpool.TransactionId) (txpool.Transaction, error) {
 
+	m.RLock()
+	defer m.RUnlock()
+
 	t, ok := m.TxMap[id]
 
 	if ok {
@@ -71,11 +92,15 @@ func (m *TransactionRepository) FindById(id txpool.TransactionId) (txpool.Transa
 
 func (m *TransactionRepository) FindAll() ([]txpool.Transaction, error) {
 
+	m.RLock()
+	defer m.RUnlock()
+
 	s := make([]txpool.Transaction, 0)
 
 	for _, transaction := range m.TxMap {
 		s = append(s, transaction)
 	}
 
-	return s, nil
+	// ranging over the map gives a random order
+	return txpool.FifoPolicy{}.Order(s), nil
 }
@@ -0,0 +1,62 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package repo
+
+import (
+	"github.com/it-chain/engine/txpool"
+	"github.com/it-chain/iLogger"
+	"github.com/it-chain/leveldb-wrapper"
+)
+
+// CommittedTransactionRepository keeps the IDs of the committed transactions on disk,
+// so a transaction committed before a restart is still rejected after it
+type CommittedTransactionRepository struct {
+	leveldb *leveldbwrapper.DB
+}
+
+func NewCommittedTransactionRepository(path string) *CommittedTransactionRepository {
+	db := leveldbwrapper.CreateNewDB(path)
+	db.Open()
+
+	return &CommittedTransactionRepository{
+		leveldb: db,
+	}
+}
+
+func (r *CommittedTransactionRepository) Save(id txpool.TransactionId) error {
+
+	if id == "" {
+		return ErrEmptyID
+	}
+
+	return r.leveldb.Put([]byte(id), []byte{1}, true)
+}
+
+func (r *CommittedTransactionRepository) Exists(id txpool.TransactionId) bool {
+
+	b, err := r.leveldb.Get([]byte(id))
+	if err != nil {
+		iLogger.Errorf(nil, "[Txpool] Fail to find committed transaction - ID: [%s], Err: [%s]", id, err.Error())
+		return false
+	}
+
+	return len(b) != 0
+}
+
+func (r *CommittedTransactionRepository) Close() {
+	r.leveldb.Close()
+}
@@ -0,0 +1,45 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package repo_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/it-chain/engine/txpool/infra/repo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommittedTransactionRepository_Exists_AfterRestart(t *testing.T) {
+
+	//given
+	dbPath := "./.committed-db"
+	committedRepository := repo.NewCommittedTransactionRepository(dbPath)
+	defer os.RemoveAll(dbPath)
+
+	assert.NoError(t, committedRepository.Save("1"))
+	assert.Equal(t, repo.ErrEmptyID, committedRepository.Save(""))
+	committedRepository.Close()
+
+	//when
+	committedRepository = repo.NewCommittedTransactionRepository(dbPath)
+	defer committedRepository.Close()
+
+	//then
+	assert.True(t, committedRepository.Exists("1"))
+	assert.False(t, committedRepository.Exists("2"))
+}
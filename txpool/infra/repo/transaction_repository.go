@@ -0,0 +1,155 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package repo
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/it-chain/engine/common"
+	"github.com/it-chain/engine/txpool"
+	"github.com/it-chain/iLogger"
+	"github.com/it-chain/leveldb-wrapper"
+)
+
+var ErrTransactionDoesNotExist = errors.New("transaction does not exist")
+var ErrEmptyID = errors.New("transaction ID is empty")
+
+// TransactionRepository keeps the pending transactions on disk,
+// so the transactions accepted before a crash or restart are proposed after it
+type TransactionRepository struct {
+	sync.Mutex
+	leveldb *leveldbwrapper.DB
+	usage   *txpool.UsageCounter
+}
+
+// the usage of the pool is counted from the transactions on disk once, and kept up to date by Save and Remove
+func NewTransactionRepository(path string) *TransactionRepository {
+	db := leveldbwrapper.CreateNewDB(path)
+	db.Open()
+
+	r := &TransactionRepository{
+		leveldb: db,
+		usage:   txpool.NewUsageCounter(),
+		Mutex:   sync.Mutex{},
+	}
+
+	transactions, err := r.FindAll()
+	if err != nil {
+		iLogger.Errorf(nil, "[Txpool] Fail to count transactions - Err: [%s]", err.Error())
+	}
+
+	for _, transaction := range transactions {
+		r.usage.Add(transaction)
+	}
+
+	return r
+}
+
+func (r *TransactionRepository) Save(transaction txpool.Transaction) error {
+
+	if transaction.ID == "" {
+		return ErrEmptyID
+	}
+
+	b, err := common.Serialize(transaction)
+	if err != nil {
+		return err
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	old, findErr := r.FindById(transaction.ID)
+
+	if err := r.leveldb.Put([]byte(transaction.ID), b, true); err != nil {
+		return err
+	}
+
+	if findErr == nil {
+		r.usage.Remove(old)
+	}
+	r.usage.Add(transaction)
+
+	return nil
+}
+
+func (r *TransactionRepository) Remove(id txpool.TransactionId) {
+	r.Lock()
+	defer r.Unlock()
+
+	old, findErr := r.FindById(id)
+
+	if err := r.leveldb.Delete([]byte(id), true); err != nil {
+		iLogger.Errorf(nil, "[Txpool] Fail to remove transaction - ID: [%s], Err: [%s]", id, err.Error())
+		return
+	}
+
+	if findErr == nil {
+		r.usage.Remove(old)
+	}
+}
+
+func (r *TransactionRepository) Usage(submitter string) txpool.PoolUsage {
+	return r.usage.Usage(submitter)
+}
+
+func (r *TransactionRepository) FindById(id txpool.TransactionId) (txpool.Transaction, error) {
+
+	b, err := r.leveldb.Get([]byte(id))
+	if err != nil {
+		return txpool.Transaction{}, err
+	}
+
+	if len(b) == 0 {
+		return txpool.Transaction{}, ErrTransactionDoesNotExist
+	}
+
+	transaction := txpool.Transaction{}
+	if err := common.Deserialize(b, &transaction); err != nil {
+		return txpool.Transaction{}, err
+	}
+
+	return transaction, nil
+}
+
+// FindAll returns the transactions in the order they were created
+func (r *TransactionRepository) FindAll() ([]txpool.Transaction, error) {
+
+	iter := r.leveldb.GetIteratorWithPrefix([]byte(""))
+	defer iter.Release()
+
+	transactions := make([]txpool.Transaction, 0)
+	for iter.Next() {
+		transaction := txpool.Transaction{}
+		if err := common.Deserialize(iter.Value(), &transaction); err != nil {
+			return nil, err
+		}
+
+		transactions = append(transactions, transaction)
+	}
+
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+
+	return txpool.FifoPolicy{}.Order(transactions), nil
+}
+
+func (r *TransactionRepository) Close() {
+	r.leveldb.Close()
+}
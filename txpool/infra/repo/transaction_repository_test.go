@@ -0,0 +1,120 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package repo_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/it-chain/engine/txpool"
+	"github.com/it-chain/engine/txpool/infra/repo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransactionRepository_Save(t *testing.T) {
+
+	tests := map[string]struct {
+		input txpool.Transaction
+		err   error
+	}{
+		"success": {
+			input: txpool.Transaction{
+				ID:       "1",
+				Function: "initA",
+				Jsonrpc:  "2.0",
+			},
+			err: nil,
+		},
+		"fail empty id": {
+			input: txpool.Transaction{
+				Function: "initA",
+				Jsonrpc:  "2.0",
+			},
+			err: repo.ErrEmptyID,
+		},
+	}
+
+	dbPath := "./.db"
+	transactionRepository := repo.NewTransactionRepository(dbPath)
+	defer func() {
+		transactionRepository.Close()
+		os.RemoveAll(dbPath)
+	}()
+
+	for testName, test := range tests {
+		t.Logf("running test case %s", testName)
+
+		err := transactionRepository.Save(test.input)
+		assert.Equal(t, test.err, err)
+	}
+}
+
+func TestTransactionRepository_FindById_Remove(t *testing.T) {
+
+	//given
+	dbPath := "./.db"
+	transactionRepository := repo.NewTransactionRepository(dbPath)
+	defer func() {
+		transactionRepository.Close()
+		os.RemoveAll(dbPath)
+	}()
+
+	tx := txpool.Transaction{ID: "1", ICodeID: "icode", Function: "initA", Args: []string{"a"}}
+	transactionRepository.Save(tx)
+
+	//when
+	found, err := transactionRepository.FindById("1")
+
+	//then
+	assert.NoError(t, err)
+	assert.Equal(t, tx.ID, found.ID)
+	assert.Equal(t, tx.Args, found.Args)
+
+	//when
+	transactionRepository.Remove("1")
+	_, err = transactionRepository.FindById("1")
+
+	//then
+	assert.Equal(t, repo.ErrTransactionDoesNotExist, err)
+}
+
+func TestTransactionRepository_FindAll_AfterRestart(t *testing.T) {
+
+	//given
+	dbPath := "./.db"
+	transactionRepository := repo.NewTransactionRepository(dbPath)
+	defer os.RemoveAll(dbPath)
+
+	transactionRepository.Save(txpool.Transaction{ID: "1"})
+	transactionRepository.Save(txpool.Transaction{ID: "2"})
+	transactionRepository.Save(txpool.Transaction{ID: "3"})
+	transactionRepository.Remove("2")
+	transactionRepository.Close()
+
+	//when
+	transactionRepository = repo.NewTransactionRepository(dbPath)
+	defer transactionRepository.Close()
+
+	transactions, err := transactionRepository.FindAll()
+
+	//then
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(transactions))
+	assert.Equal(t, "1", transactions[0].ID)
+	assert.Equal(t, "3", transactions[1].ID)
+	assert.Equal(t, txpool.PoolUsage{Transactions: 2, Bytes: 2, SubmitterTransactions: 2, SubmitterBytes: 2}, transactionRepository.Usage(""))
+}
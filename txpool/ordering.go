@@ -0,0 +1,140 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package txpool
+
+import (
+	"errors"
+	"sort"
+)
+
+const (
+	FifoOrdering     = "fifo"
+	FairOrdering     = "fair"
+	PriorityOrdering = "priority"
+)
+
+var ErrUnknownOrderingPolicy = errors.New("unknown transaction ordering policy")
+var ErrEmptyPriorityLanes = errors.New("priority ordering needs at least one lane")
+
+// OrderingPolicy decides the order of the transactions in a proposed block.
+// The order must only depend on the transactions, so every node orders the same pool in the same way
+type OrderingPolicy interface {
+	Order(transactions []Transaction) []Transaction
+}
+
+// FifoPolicy orders the transactions by the time they were created,
+// transactions created at the same time are ordered by their IDs
+type FifoPolicy struct{}
+
+func (p FifoPolicy) Order(transactions []Transaction) []Transaction {
+	ordered := make([]Transaction, len(transactions))
+	copy(ordered, transactions)
+
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return arrivedBefore(ordered[i], ordered[j])
+	})
+
+	return ordered
+}
+
+// FairPolicy takes one transaction of each submitter in turn,
+// so a submitter flooding the pool can not delay the transactions of the others.
+// The submitter is the verified signer of the transaction, unsigned transactions share one turn.
+// Transactions of the same submitter keep their FIFO order
+type FairPolicy struct{}
+
+func (p FairPolicy) Order(transactions []Transaction) []Transaction {
+	submitters := make([]string, 0)
+	queues := make(map[string][]Transaction)
+
+	fifo := FifoPolicy{}.Order(transactions)
+	for _, tx := range fifo {
+		if _, ok := queues[tx.Submitter]; !ok {
+			submitters = append(submitters, tx.Submitter)
+		}
+		queues[tx.Submitter] = append(queues[tx.Submitter], tx)
+	}
+
+	ordered := make([]Transaction, 0, len(transactions))
+	for len(ordered) < len(transactions) {
+		for _, submitter := range submitters {
+			queue := queues[submitter]
+			if len(queue) == 0 {
+				continue
+			}
+
+			ordered = append(ordered, queue[0])
+			queues[submitter] = queue[1:]
+		}
+	}
+
+	return ordered
+}
+
+// PriorityLanePolicy puts the transactions of the icodes in Lanes first, in the order of the lanes.
+// Transactions of the other icodes follow, and every lane keeps its FIFO order
+type PriorityLanePolicy struct {
+	Lanes []string
+}
+
+func (p PriorityLanePolicy) Order(transactions []Transaction) []Transaction {
+	ordered := FifoPolicy{}.Order(transactions)
+
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return p.lane(ordered[i]) < p.lane(ordered[j])
+	})
+
+	return ordered
+}
+
+func (p PriorityLanePolicy) lane(transaction Transaction) int {
+	for i, icodeID := range p.Lanes {
+		if transaction.ICodeID == icodeID {
+			return i
+		}
+	}
+
+	return len(p.Lanes)
+}
+
+func arrivedBefore(a Transaction, b Transaction) bool {
+	if !a.TimeStamp.Equal(b.TimeStamp) {
+		return a.TimeStamp.Before(b.TimeStamp)
+	}
+
+	return a.ID < b.ID
+}
+
+func NewOrderingPolicy(policy string, lanes []string) (OrderingPolicy, error) {
+	switch policy {
+	case "", FifoOrdering:
+		return FifoPolicy{}, nil
+
+	case FairOrdering:
+		return FairPolicy{}, nil
+
+	case PriorityOrdering:
+		if len(lanes) == 0 {
+			return nil, ErrEmptyPriorityLanes
+		}
+
+		return PriorityLanePolicy{Lanes: lanes}, nil
+
+	default:
+		return nil, ErrUnknownOrderingPolicy
+	}
+}
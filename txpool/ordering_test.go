@@ -0,0 +1,125 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package txpool_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/it-chain/engine/txpool"
+	"github.com/stretchr/testify/assert"
+)
+
+func ids(transactions []txpool.Transaction) []string {
+	result := make([]string, 0)
+	for _, tx := range transactions {
+		result = append(result, tx.ID)
+	}
+
+	return result
+}
+
+func TestFifoPolicy_Order(t *testing.T) {
+	now := time.Now()
+
+	transactions := []txpool.Transaction{
+		{ID: "c", TimeStamp: now.Add(2 * time.Second)},
+		{ID: "b", TimeStamp: now},
+		{ID: "a", TimeStamp: now},
+		{ID: "d", TimeStamp: now.Add(time.Second)},
+	}
+
+	assert.Equal(t, []string{"a", "b", "d", "c"}, ids(txpool.FifoPolicy{}.Order(transactions)))
+
+	// the input is not reordered in place
+	assert.Equal(t, "c", transactions[0].ID)
+}
+
+func TestFairPolicy_Order(t *testing.T) {
+	now := time.Now()
+
+	transactions := []txpool.Transaction{
+		{ID: "p1-1", PeerID: "node01", Submitter: "p1", TimeStamp: now},
+		{ID: "p1-2", PeerID: "node01", Submitter: "p1", TimeStamp: now.Add(1 * time.Second)},
+		{ID: "p1-3", PeerID: "node01", Submitter: "p1", TimeStamp: now.Add(2 * time.Second)},
+		{ID: "p2-1", PeerID: "node01", Submitter: "p2", TimeStamp: now.Add(3 * time.Second)},
+		{ID: "p3-1", PeerID: "node01", Submitter: "p3", TimeStamp: now.Add(4 * time.Second)},
+		{ID: "p2-2", PeerID: "node01", Submitter: "p2", TimeStamp: now.Add(5 * time.Second)},
+	}
+
+	assert.Equal(t, []string{"p1-1", "p2-1", "p3-1", "p1-2", "p2-2", "p1-3"}, ids(txpool.FairPolicy{}.Order(transactions)))
+}
+
+func TestPriorityLanePolicy_Order(t *testing.T) {
+	now := time.Now()
+
+	transactions := []txpool.Transaction{
+		{ID: "normal-1", ICodeID: "normal", TimeStamp: now},
+		{ID: "low-1", ICodeID: "low", TimeStamp: now.Add(1 * time.Second)},
+		{ID: "high-1", ICodeID: "high", TimeStamp: now.Add(2 * time.Second)},
+		{ID: "normal-2", ICodeID: "normal", TimeStamp: now.Add(3 * time.Second)},
+		{ID: "high-2", ICodeID: "high", TimeStamp: now.Add(4 * time.Second)},
+	}
+
+	policy := txpool.PriorityLanePolicy{Lanes: []string{"high", "low"}}
+
+	assert.Equal(t, []string{"high-1", "high-2", "low-1", "normal-1", "normal-2"}, ids(policy.Order(transactions)))
+}
+
+func TestNewOrderingPolicy(t *testing.T) {
+	tests := map[string]struct {
+		policy string
+		lanes  []string
+		output txpool.OrderingPolicy
+		err    error
+	}{
+		"default": {
+			policy: "",
+			output: txpool.FifoPolicy{},
+		},
+		"fifo": {
+			policy: txpool.FifoOrdering,
+			output: txpool.FifoPolicy{},
+		},
+		"fair": {
+			policy: txpool.FairOrdering,
+			output: txpool.FairPolicy{},
+		},
+		"priority": {
+			policy: txpool.PriorityOrdering,
+			lanes:  []string{"high"},
+			output: txpool.PriorityLanePolicy{Lanes: []string{"high"}},
+		},
+		"priority without lanes": {
+			policy: txpool.PriorityOrdering,
+			err:    txpool.ErrEmptyPriorityLanes,
+		},
+		"unknown": {
+			policy: "random",
+			err:    txpool.ErrUnknownOrderingPolicy,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Logf("Running test case %s", testName)
+
+		policy, err := txpool.NewOrderingPolicy(test.policy, test.lanes)
+
+		assert.Equal(t, test.err, err)
+		assert.Equal(t, test.output, policy)
+	}
+}
@@ -16,7 +16,7 @@
 
 package txpool
 
-const SendTransactionsToLeader = "SendTransactionsToLeaderProtocol"
+const GossipTransactionsProtocol = "GossipTransactionsProtocol"
 
 type TxpoolQueryService interface {
 	FindUncommittedTransactions() ([]Transaction, error)
@@ -43,3 +43,9 @@ func IsLeader(nodeId string, leader Leader) bool {
 type EventService interface {
 	Publish(topic string, event interface{}) error
 }
+
+// ConsensusEngine tells whether only the leader proposes blocks,
+// other nodes forward their pending transactions to the leader
+type ConsensusEngine interface {
+	IsLeaderBased() bool
+}
@@ -0,0 +1,41 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package txpool
+
+import "github.com/it-chain/iLogger"
+
+const (
+	DroppedByEviction = "evicted"
+	DroppedByExpiry   = "expired"
+)
+
+// the status events let the other components follow where a transaction is,
+// a failure to publish them does not fail the pool
+func publishStatus(eventService EventService, topic string, event interface{}) {
+	if err := eventService.Publish(topic, event); err != nil {
+		iLogger.Errorf(nil, "[Txpool] Fail to publish transaction status - Topic: [%s], Err: [%s]", topic, err.Error())
+	}
+}
+
+func transactionIds(transactions []Transaction) []TransactionId {
+	ids := make([]TransactionId, 0)
+	for _, tx := range transactions {
+		ids = append(ids, tx.ID)
+	}
+
+	return ids
+}
@@ -17,11 +17,19 @@
 package txpool
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"time"
 
+	"github.com/it-chain/engine/common"
 	"github.com/rs/xid"
 )
 
+var ErrTransactionAlreadyPending = errors.New("transaction is already pending")
+var ErrTransactionAlreadyCommitted = errors.New("transaction is already committed")
+
 type TransactionId = string
 
 type TxData struct {
@@ -30,6 +38,13 @@ type TxData struct {
 	Function  string
 	Args      []string
 	Signature []byte
+	// optional key chosen by the client. Transactions of the same submitter created with the same key get the same ID,
+	// so a retried submission is rejected as a duplicate instead of being executed twice.
+	// Keys of different submitters never collide
+	IdempotencyKey string
+	// optional expiry, see Transaction
+	Deadline  time.Time
+	MaxHeight uint64
 }
 
 //Aggregate root must implement aggregate interface
@@ -42,30 +57,104 @@ type Transaction struct {
 	Args      []string
 	Signature []byte
 	PeerID    string
+	// the client who submitted the transaction, the signer its signature claims.
+	// SignatureValidator rejects the transaction unless the signature is verified for the submitter,
+	// so it is empty only for an unsigned transaction
+	Submitter string
+	// the key the submitter chose, part of the signed content of a signed transaction, which derives its ID
+	IdempotencyKey string
+	// set when the transaction is proposed in a block or sent to the leader,
+	// zero while the transaction is pending in the pool
+	ProposedAt time.Time
+	// optional expiry. The transaction can not be included in a block after the deadline
+	// or in a block higher than MaxHeight, zero means no limit
+	Deadline  time.Time
+	MaxHeight uint64
+}
+
+// in-flight transactions are kept in the pool until the block which contains them is committed
+func (t Transaction) IsInFlight() bool {
+	return !t.ProposedAt.IsZero()
+}
+
+func IsPending(transaction Transaction) bool {
+	return !transaction.IsInFlight()
+}
+
+// IsExpired tells whether the transaction can no longer be included in a block of the height created at the time
+func (t Transaction) IsExpired(now time.Time, height uint64) bool {
+	if !t.Deadline.IsZero() && now.After(t.Deadline) {
+		return true
+	}
+
+	return t.MaxHeight != 0 && height > t.MaxHeight
+}
+
+// Size is the number of bytes the transaction takes in the pool
+func (t Transaction) Size() int {
+	size := len(t.ID) + len(t.Jsonrpc) + len(t.ICodeID) + len(t.Function) + len(t.Signature) + len(t.PeerID) + len(t.Submitter)
+	for _, arg := range t.Args {
+		size += len(arg)
+	}
+
+	return size
+}
+
+// SigningData is the content of the transaction a client signs, see common.TransactionSigningData
+func (t Transaction) SigningData() []byte {
+	return common.TransactionSigningData(t.Jsonrpc, t.ICodeID, t.Function, t.Args, t.IdempotencyKey, t.Deadline, t.MaxHeight)
 }
 
 func CreateTransaction(publisherId string, txData TxData) (Transaction, error) {
 
+	submitter := common.TransactionSigner(txData.Signature)
+
 	id := xid.New().String()
+	if txData.IdempotencyKey != "" {
+		id = createIdempotentId(submitter, txData.IdempotencyKey)
+	}
+
 	timeStamp := time.Now()
 
 	transaction := Transaction{
-		ID:        id,
-		PeerID:    publisherId,
-		TimeStamp: timeStamp,
-		ICodeID:   txData.ICodeID,
-		Jsonrpc:   txData.Jsonrpc,
-		Signature: txData.Signature,
-		Args:      txData.Args,
-		Function:  txData.Function,
+		ID:             id,
+		PeerID:         publisherId,
+		Submitter:      submitter,
+		TimeStamp:      timeStamp,
+		ICodeID:        txData.ICodeID,
+		Jsonrpc:        txData.Jsonrpc,
+		Signature:      txData.Signature,
+		Args:           txData.Args,
+		Function:       txData.Function,
+		IdempotencyKey: txData.IdempotencyKey,
+		Deadline:       txData.Deadline,
+		MaxHeight:      txData.MaxHeight,
 	}
 
 	return transaction, nil
 }
 
+// the key is namespaced by the submitter, which SignatureValidator verifies,
+// so a client can not take or collide with the keys of another client
+func createIdempotentId(submitter string, idempotencyKey string) TransactionId {
+	data, _ := json.Marshal([]string{submitter, idempotencyKey})
+	hash := sha256.Sum256(data)
+
+	return hex.EncodeToString(hash[:])
+}
+
 type TransactionRepository interface {
 	FindAll() ([]Transaction, error)
 	Save(transaction Transaction) error
 	Remove(id TransactionId)
 	FindById(id TransactionId) (Transaction, error)
+	// Usage is what the saved transactions take, globally and of the submitter, see UsageCounter
+	Usage(submitter string) PoolUsage
+}
+
+// CommittedTransactionRepository is the index of the transactions committed in blocks,
+// which rejects the resubmission of a committed transaction
+type CommittedTransactionRepository interface {
+	Save(id TransactionId) error
+	Exists(id TransactionId) bool
 }
@@ -0,0 +1,201 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package txpool
+
+import (
+	"errors"
+	"regexp"
+
+	"github.com/it-chain/engine/common"
+)
+
+const JsonrpcVersion = "2.0"
+
+const ErrCodeInvalidTransaction = "invalid_transaction"
+
+var ErrUnknownICode = errors.New("icode is not deployed")
+var ErrInvalidFunction = errors.New("invalid function name")
+var ErrTooManyArgs = errors.New("too many arguments")
+var ErrArgTooLarge = errors.New("argument is too large")
+var ErrInvalidJsonrpc = errors.New("unsupported jsonrpc version")
+var ErrMissingSignature = errors.New("transaction is not signed")
+var ErrInvalidSignature = errors.New("invalid transaction signature")
+var ErrSubmitterMismatch = errors.New("transaction submitter is not the signer")
+var ErrMissingIdempotencyKey = errors.New("signed transaction has no idempotency key")
+
+var functionNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,127}$`)
+
+// InvalidTransactionError is returned when a transaction is rejected by a validator
+type InvalidTransactionError struct {
+	Err error
+}
+
+func (e InvalidTransactionError) Error() string {
+	return e.Err.Error()
+}
+
+// TxValidator decides whether a transaction is admitted to the pool.
+// Validators run on the transactions created on this node and on the ones received from peers
+type TxValidator interface {
+	Validate(transaction Transaction) error
+}
+
+// TxValidatorFunc lets a function be used as a TxValidator
+type TxValidatorFunc func(transaction Transaction) error
+
+func (f TxValidatorFunc) Validate(transaction Transaction) error {
+	return f(transaction)
+}
+
+// TxValidatorChain runs the validators in order and stops at the first rejection
+type TxValidatorChain []TxValidator
+
+func NewTxValidatorChain(validators ...TxValidator) TxValidatorChain {
+	return TxValidatorChain(validators)
+}
+
+func (c TxValidatorChain) Validate(transaction Transaction) error {
+	for _, validator := range c {
+		if err := validator.Validate(transaction); err != nil {
+			return InvalidTransactionError{Err: err}
+		}
+	}
+
+	return nil
+}
+
+// ICodeValidator rejects transactions to icodes which are not deployed.
+// Governance transactions are handled by consensus, not by an icode, and the transactions
+// to a reserved icode id, such as a deployment whose icode does not exist yet, are checked by the validator of the id
+type ICodeValidator struct {
+	icodeRepository ICodeRepository
+	reserved        map[string]TxValidator
+}
+
+func NewICodeValidator(icodeRepository ICodeRepository, reserved map[string]TxValidator) ICodeValidator {
+	return ICodeValidator{
+		icodeRepository: icodeRepository,
+		reserved:        reserved,
+	}
+}
+
+func (v ICodeValidator) Validate(transaction Transaction) error {
+	if common.IsGovernanceTransaction(transaction.ICodeID) {
+		return nil
+	}
+
+	if validator, ok := v.reserved[transaction.ICodeID]; ok {
+		return validator.Validate(transaction)
+	}
+
+	if !v.icodeRepository.Exists(transaction.ICodeID) {
+		return ErrUnknownICode
+	}
+
+	return nil
+}
+
+type FunctionValidator struct{}
+
+func (v FunctionValidator) Validate(transaction Transaction) error {
+	if !functionNamePattern.MatchString(transaction.Function) {
+		return ErrInvalidFunction
+	}
+
+	return nil
+}
+
+// ArgsValidator limits the number of arguments and the size of each, zero means no limit
+type ArgsValidator struct {
+	MaxArgs     int
+	MaxArgBytes int
+}
+
+func (v ArgsValidator) Validate(transaction Transaction) error {
+	if v.MaxArgs != 0 && len(transaction.Args) > v.MaxArgs {
+		return ErrTooManyArgs
+	}
+
+	if v.MaxArgBytes == 0 {
+		return nil
+	}
+
+	for _, arg := range transaction.Args {
+		if len(arg) > v.MaxArgBytes {
+			return ErrArgTooLarge
+		}
+	}
+
+	return nil
+}
+
+type JsonrpcValidator struct{}
+
+func (v JsonrpcValidator) Validate(transaction Transaction) error {
+	if transaction.Jsonrpc != JsonrpcVersion {
+		return ErrInvalidJsonrpc
+	}
+
+	return nil
+}
+
+// SignatureValidator verifies the signature of a signed transaction over its SigningData,
+// and that the verified signer is the Submitter of the transaction.
+// The signature is a json encoded common.Signature, unsigned transactions are rejected when required.
+// A signed transaction must have an idempotency key, which derives its ID, so its signature can not be replayed
+// in another transaction.
+// A governance transaction is a vote of the validator who signed it, so it is always signed
+type SignatureValidator struct {
+	verifier common.SignatureVerifier
+	required bool
+}
+
+func NewSignatureValidator(verifier common.SignatureVerifier, required bool) SignatureValidator {
+	return SignatureValidator{
+		verifier: verifier,
+		required: required,
+	}
+}
+
+func (v SignatureValidator) Validate(transaction Transaction) error {
+	if len(transaction.Signature) == 0 {
+		if v.required || common.IsGovernanceTransaction(transaction.ICodeID) {
+			return ErrMissingSignature
+		}
+
+		if transaction.Submitter != "" {
+			return ErrSubmitterMismatch
+		}
+
+		return nil
+	}
+
+	if transaction.IdempotencyKey == "" {
+		return ErrMissingIdempotencyKey
+	}
+
+	signer, err := common.VerifyTransactionSigner(v.verifier, transaction.Signature, transaction.SigningData())
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	if signer != transaction.Submitter {
+		return ErrSubmitterMismatch
+	}
+
+	return nil
+}
@@ -0,0 +1,188 @@
+/*
+ * Copyright 2018 It-chain
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package txpool_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/it-chain/engine/common"
+	"github.com/it-chain/engine/txpool"
+	"github.com/it-chain/engine/txpool/infra/mem"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTxValidatorChain_Validate(t *testing.T) {
+
+	icodeRepository := mem.NewICodeRepository()
+	icodeRepository.Add("icode01", "")
+
+	errCustom := errors.New("custom")
+	errInvalidDeployment := errors.New("invalid deployment")
+	reserved := map[string]txpool.TxValidator{
+		"deployment": txpool.TxValidatorFunc(func(transaction txpool.Transaction) error {
+			if len(transaction.Args) == 0 {
+				return errInvalidDeployment
+			}
+			return nil
+		}),
+	}
+
+	chain := txpool.NewTxValidatorChain(
+		txpool.JsonrpcValidator{},
+		txpool.NewICodeValidator(icodeRepository, reserved),
+		txpool.FunctionValidator{},
+		txpool.ArgsValidator{MaxArgs: 2, MaxArgBytes: 8},
+		txpool.TxValidatorFunc(func(transaction txpool.Transaction) error {
+			if transaction.Function == "forbidden" {
+				return errCustom
+			}
+			return nil
+		}),
+	)
+
+	valid := txpool.Transaction{Jsonrpc: "2.0", ICodeID: "icode01", Function: "initA", Args: []string{"a"}}
+
+	tests := map[string]struct {
+		input struct {
+			transaction txpool.Transaction
+		}
+		err error
+	}{
+		"valid": {
+			input: struct{ transaction txpool.Transaction }{transaction: valid},
+			err:   nil,
+		},
+		"governance transaction": {
+			input: struct{ transaction txpool.Transaction }{transaction: txpool.Transaction{Jsonrpc: "2.0", ICodeID: common.GovernanceICodeID, Function: common.RemoveValidatorFunction}},
+			err:   nil,
+		},
+		"reserved icode": {
+			input: struct{ transaction txpool.Transaction }{transaction: txpool.Transaction{Jsonrpc: "2.0", ICodeID: "deployment", Function: "deploy", Args: []string{"a"}}},
+			err:   nil,
+		},
+		"invalid reserved icode": {
+			input: struct{ transaction txpool.Transaction }{transaction: txpool.Transaction{Jsonrpc: "2.0", ICodeID: "deployment", Function: "deploy"}},
+			err:   errInvalidDeployment,
+		},
+		"unknown jsonrpc": {
+			input: struct{ transaction txpool.Transaction }{transaction: txpool.Transaction{Jsonrpc: "1.0", ICodeID: "icode01", Function: "initA"}},
+			err:   txpool.ErrInvalidJsonrpc,
+		},
+		"unknown icode": {
+			input: struct{ transaction txpool.Transaction }{transaction: txpool.Transaction{Jsonrpc: "2.0", ICodeID: "icode02", Function: "initA"}},
+			err:   txpool.ErrUnknownICode,
+		},
+		"empty function": {
+			input: struct{ transaction txpool.Transaction }{transaction: txpool.Transaction{Jsonrpc: "2.0", ICodeID: "icode01", Function: ""}},
+			err:   txpool.ErrInvalidFunction,
+		},
+		"invalid function": {
+			input: struct{ transaction txpool.Transaction }{transaction: txpool.Transaction{Jsonrpc: "2.0", ICodeID: "icode01", Function: "init A"}},
+			err:   txpool.ErrInvalidFunction,
+		},
+		"too many args": {
+			input: struct{ transaction txpool.Transaction }{transaction: txpool.Transaction{Jsonrpc: "2.0", ICodeID: "icode01", Function: "initA", Args: []string{"a", "b", "c"}}},
+			err:   txpool.ErrTooManyArgs,
+		},
+		"arg too large": {
+			input: struct{ transaction txpool.Transaction }{transaction: txpool.Transaction{Jsonrpc: "2.0", ICodeID: "icode01", Function: "initA", Args: []string{strings.Repeat("a", 9)}}},
+			err:   txpool.ErrArgTooLarge,
+		},
+		"custom validator": {
+			input: struct{ transaction txpool.Transaction }{transaction: txpool.Transaction{Jsonrpc: "2.0", ICodeID: "icode01", Function: "forbidden"}},
+			err:   errCustom,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Logf("running test case %s", testName)
+
+		err := chain.Validate(test.input.transaction)
+
+		if test.err == nil {
+			assert.NoError(t, err)
+			continue
+		}
+		assert.Equal(t, txpool.InvalidTransactionError{Err: test.err}, err)
+	}
+}
+
+func TestSignatureValidator_Validate(t *testing.T) {
+
+	//given
+	priKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	signer := common.NewECDSASigner("client01", priKey)
+	verifier := common.NewECDSAVerifier(func(pubKey []byte) (string, error) {
+		return "client01", nil
+	})
+
+	transaction := txpool.Transaction{ID: "tx01", Jsonrpc: "2.0", ICodeID: "icode01", Function: "initA", IdempotencyKey: "key01", MaxHeight: 10}
+	signature, err := signer.Sign(transaction.SigningData())
+	assert.NoError(t, err)
+
+	signed := transaction
+	signed.Signature, _ = json.Marshal(signature)
+	signed.Submitter = "client01"
+
+	tampered := signed
+	tampered.Args = []string{"a"}
+
+	rekeyed := signed
+	rekeyed.IdempotencyKey = "key02"
+
+	extended := signed
+	extended.MaxHeight = 0
+
+	delayed := signed
+	delayed.Deadline = time.Now().Add(time.Hour)
+
+	keyless := signed
+	keyless.IdempotencyKey = ""
+
+	impersonated := signed
+	impersonated.Submitter = "client02"
+
+	unsignedWithSubmitter := transaction
+	unsignedWithSubmitter.Submitter = "client01"
+
+	//then
+	assert.NoError(t, txpool.NewSignatureValidator(verifier, true).Validate(signed))
+	assert.NoError(t, txpool.NewSignatureValidator(verifier, false).Validate(transaction))
+	assert.Equal(t, txpool.ErrMissingSignature, txpool.NewSignatureValidator(verifier, true).Validate(transaction))
+	assert.Equal(t, txpool.ErrInvalidSignature, txpool.NewSignatureValidator(verifier, false).Validate(tampered))
+	assert.Equal(t, txpool.ErrInvalidSignature, txpool.NewSignatureValidator(verifier, false).Validate(rekeyed))
+	assert.Equal(t, txpool.ErrInvalidSignature, txpool.NewSignatureValidator(verifier, false).Validate(extended))
+	assert.Equal(t, txpool.ErrInvalidSignature, txpool.NewSignatureValidator(verifier, false).Validate(delayed))
+	assert.Equal(t, txpool.ErrMissingIdempotencyKey, txpool.NewSignatureValidator(verifier, false).Validate(keyless))
+	assert.Equal(t, txpool.ErrSubmitterMismatch, txpool.NewSignatureValidator(verifier, false).Validate(impersonated))
+	assert.Equal(t, txpool.ErrSubmitterMismatch, txpool.NewSignatureValidator(verifier, false).Validate(unsignedWithSubmitter))
+
+	governance := txpool.Transaction{ID: "tx02", IdempotencyKey: "key02", Jsonrpc: "2.0", ICodeID: common.GovernanceICodeID, Function: common.RemoveValidatorFunction, Args: []string{"node01", "10"}}
+	assert.Equal(t, txpool.ErrMissingSignature, txpool.NewSignatureValidator(verifier, false).Validate(governance))
+
+	garbage := signed
+	garbage.Signature = []byte("garbage")
+	assert.Equal(t, txpool.ErrInvalidSignature, txpool.NewSignatureValidator(verifier, false).Validate(garbage))
+}